@@ -0,0 +1,106 @@
+//go:build !js
+
+package main
+
+import (
+	"path/filepath"
+	"strings"
+
+	"unruggable-go/internal/portfolio"
+	"unruggable-go/internal/price"
+	"unruggable-go/internal/wallet"
+)
+
+type balanceOutput struct {
+	Address string `json:"address"`
+	portfolio.Balances
+}
+
+// aggregateOutput is printed when balance is given several wallets.
+type aggregateOutput struct {
+	TotalUSD float64                `json:"totalUSD"`
+	Tokens   []portfolio.TokenTotal `json:"tokens"`
+	Wallets  []walletOutput         `json:"wallets"`
+}
+
+type walletOutput struct {
+	Address  string              `json:"address"`
+	ValueUSD float64             `json:"valueUSD"`
+	Balances *portfolio.Balances `json:"balances,omitempty"`
+	Error    string              `json:"error,omitempty"`
+}
+
+func runBalance(e *env, args []string) error {
+	fs := newFlagSet("balance", "balance [-prices SOURCES] [-all] WALLET...")
+	sources := fs.String("prices", "", "comma-separated price sources to trust: "+strings.Join(price.SourceNames, ", ")+" (default all)")
+	all := fs.Bool("all", false, "sum every wallet that is not hidden")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 && !*all {
+		return usageError("balance needs a wallet or -all")
+	}
+
+	var prices price.Config
+	if *sources != "" {
+		prices.Sources = strings.Split(*sources, ",")
+		if err := prices.Validate(); err != nil {
+			return usageError("%v", err)
+		}
+	}
+
+	if fs.NArg() == 1 && !*all {
+		info, err := e.findWallet(fs.Arg(0))
+		if err != nil {
+			return err
+		}
+		balances, err := e.fetcher(prices).Balances(info.Address)
+		if err != nil {
+			return err
+		}
+		return printJSON(balanceOutput{Address: info.Address, Balances: *balances})
+	}
+
+	var addresses []string
+	if *all {
+		infos, err := wallet.List(e.storage)
+		if err != nil {
+			return err
+		}
+		for _, info := range infos {
+			if !info.Hidden {
+				addresses = append(addresses, info.Address)
+			}
+		}
+	}
+	for _, ref := range fs.Args() {
+		info, err := e.findWallet(ref)
+		if err != nil {
+			return err
+		}
+		addresses = append(addresses, info.Address)
+	}
+
+	results := e.fetcher(prices).FetchAll(addresses, portfolio.DefaultWorkers)
+	output := aggregateOutput{Tokens: portfolio.Combine(results)}
+	for _, result := range results {
+		w := walletOutput{Address: result.Address, Balances: result.Balances, ValueUSD: result.Value()}
+		if result.Err != nil {
+			w.Error = result.Err.Error()
+		}
+		output.TotalUSD += w.ValueUSD
+		output.Wallets = append(output.Wallets, w)
+	}
+	return printJSON(output)
+}
+
+// fetcher reads balances on the selected network, sharing the GUI's token
+// metadata cache.
+func (e *env) fetcher(prices price.Config) portfolio.Fetcher {
+	return portfolio.Fetcher{
+		Network:  e.network,
+		RPC:      e.httpClient(),
+		Metadata: portfolio.OpenMetadataCache(filepath.Join(e.root, portfolio.MetadataCacheFile(e.network.Name))),
+		Prices:   prices,
+	}
+}
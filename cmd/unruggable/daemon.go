@@ -0,0 +1,481 @@
+//go:build !js
+
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"unruggable-go/internal/bot"
+	"unruggable-go/internal/fee"
+	"unruggable-go/internal/localapi"
+	"unruggable-go/internal/price"
+	"unruggable-go/internal/session"
+)
+
+// daemonSocket is the control socket in the storage root when the config
+// does not set listen.
+const daemonSocket = "daemon.sock"
+
+// daemonTokenFile in the storage root holds the bearer token a TCP control
+// endpoint requires. It is replaced on every start.
+const daemonTokenFile = "daemon.token"
+
+// shutdownTimeout bounds how long the control server waits for open requests
+// on shutdown.
+const shutdownTimeout = 5 * time.Second
+
+// daemonConfig is the file passed to the daemon command.
+type daemonConfig struct {
+	// LogFile receives one JSON object per line. Empty logs to stderr.
+	LogFile string `json:"logFile,omitempty"`
+	// Listen is a unix socket path or a loopback host:port for the control
+	// endpoint. Empty uses daemon.sock in the storage root. A TCP endpoint
+	// requires the bearer token from daemon.token in the storage root.
+	Listen string      `json:"listen,omitempty"`
+	Bots   []botConfig `json:"bots"`
+}
+
+type botConfig struct {
+	Name   string `json:"name"`
+	Type   string `json:"type"` // "calypso" or "conditional"
+	Wallet string `json:"wallet"`
+	// PasswordFile holds the wallet password. Without it the password is
+	// asked for at startup.
+	PasswordFile string `json:"passwordFile,omitempty"`
+	// Disabled bots are loaded but only run once started over the control
+	// endpoint.
+	Disabled bool `json:"disabled,omitempty"`
+	// PriorityFee overrides the -fee strategy for this bot's swaps.
+	PriorityFee string `json:"priorityFee,omitempty"`
+
+	Calypso *bot.CalypsoConfig `json:"calypso,omitempty"`
+
+	Trades   []*bot.ConditionalTrade `json:"trades,omitempty"`
+	Interval int                     `json:"interval,omitempty"` // seconds
+	// Prices selects the price sources of a conditional bot. Calypso bots
+	// set theirs in calypso.prices.
+	Prices price.Config `json:"prices,omitempty"`
+}
+
+// daemonBot is a configured bot and its current run.
+type daemonBot struct {
+	name    string
+	kind    string
+	wallet  string
+	runner  bot.Runner
+	logger  *slog.Logger
+	cancel  context.CancelFunc
+	running bool
+}
+
+type botStatus struct {
+	Name   string `json:"name"`
+	Type   string `json:"type"`
+	Wallet string `json:"wallet"`
+	bot.Status
+}
+
+// daemon supervises the bots until its context ends.
+type daemon struct {
+	ctx        context.Context
+	logger     *slog.Logger
+	configPath string
+
+	mu     sync.Mutex
+	config daemonConfig
+	bots   []*daemonBot
+	wg     sync.WaitGroup
+}
+
+func runDaemon(e *env, args []string) error {
+	fs := newFlagSet("daemon", "daemon -config FILE [-password-stdin]")
+	configPath := fs.String("config", "", "bot configuration file")
+	passwordStdin := fs.Bool("password-stdin", false, "read wallet passwords from stdin, one line per wallet without a passwordFile")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 || *configPath == "" {
+		return usageError("daemon needs -config and takes no arguments")
+	}
+
+	config, err := loadDaemonConfig(*configPath)
+	if err != nil {
+		return err
+	}
+
+	logOut := os.Stderr
+	if config.LogFile != "" {
+		logOut, err = os.OpenFile(config.LogFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+		if err != nil {
+			return fmt.Errorf("cannot open log file: %v", err)
+		}
+		defer logOut.Close()
+	}
+	logger := slog.New(slog.NewJSONHandler(logOut, nil))
+	// Route the log output of the shared packages, such as RPC failover, to
+	// the same file.
+	slog.SetDefault(logger)
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
+	defer stop()
+
+	d := &daemon{ctx: ctx, logger: logger, configPath: *configPath, config: config}
+	if err := d.load(e, *passwordStdin); err != nil {
+		return err
+	}
+
+	listener, err := listenControl(config.Listen, filepath.Join(e.root, daemonSocket))
+	if err != nil {
+		return err
+	}
+	// Any local process or web page can reach a TCP port, while the socket
+	// is only accessible to the owner.
+	var token string
+	if listener.Addr().Network() == "tcp" {
+		tokenPath := filepath.Join(e.root, daemonTokenFile)
+		os.Remove(tokenPath)
+		if token, err = localapi.NewToken(); err == nil {
+			err = os.WriteFile(tokenPath, []byte(token+"\n"), 0600)
+		}
+		if err != nil {
+			listener.Close()
+			return fmt.Errorf("cannot create control token: %v", err)
+		}
+		defer os.Remove(tokenPath)
+		logger.Info("control endpoint requires the bearer token", "tokenFile", tokenPath)
+	}
+	server := &http.Server{Handler: d.routes(token)}
+	go func() {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error("control endpoint failed", "error", err)
+		}
+	}()
+	logger.Info("daemon started", "network", e.network.Name, "listen", listener.Addr().String(), "bots", len(d.bots))
+
+	for _, b := range d.bots {
+		if !config.botConfig(b.name).Disabled {
+			d.start(b)
+		}
+	}
+
+	<-ctx.Done()
+	logger.Info("shutting down, waiting for running cycles to finish")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	server.Shutdown(shutdownCtx)
+	d.wg.Wait()
+	logger.Info("daemon stopped")
+	return nil
+}
+
+func loadDaemonConfig(path string) (daemonConfig, error) {
+	var config daemonConfig
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return config, fmt.Errorf("cannot read config: %v", err)
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return config, usageError("invalid config: %v", err)
+	}
+	if len(config.Bots) == 0 {
+		return config, usageError("config defines no bots")
+	}
+
+	names := make(map[string]bool)
+	for _, b := range config.Bots {
+		if b.Name == "" || strings.Contains(b.Name, "/") {
+			return config, usageError("invalid bot name %q", b.Name)
+		}
+		if names[b.Name] {
+			return config, usageError("duplicate bot name %q", b.Name)
+		}
+		names[b.Name] = true
+		if _, err := fee.ParseStrategy(b.PriorityFee); err != nil {
+			return config, usageError("bot %s: %v", b.Name, err)
+		}
+
+		switch b.Type {
+		case "calypso":
+			if b.Calypso == nil {
+				return config, usageError("bot %s: missing calypso settings", b.Name)
+			}
+			if err := b.Calypso.Validate(); err != nil {
+				return config, usageError("bot %s: %v", b.Name, err)
+			}
+		case "conditional":
+			if err := b.Prices.Validate(); err != nil {
+				return config, usageError("bot %s: %v", b.Name, err)
+			}
+			for _, trade := range b.Trades {
+				if err := trade.Validate(); err != nil {
+					return config, usageError("bot %s: %v", b.Name, err)
+				}
+			}
+		default:
+			return config, usageError("bot %s: unknown type %q", b.Name, b.Type)
+		}
+	}
+	return config, nil
+}
+
+func (c daemonConfig) botConfig(name string) botConfig {
+	for _, b := range c.Bots {
+		if b.Name == name {
+			return b
+		}
+	}
+	return botConfig{}
+}
+
+// load unlocks the wallet of every bot and creates its engine. A wallet used
+// by several bots is unlocked once.
+func (d *daemon) load(e *env, passwordStdin bool) error {
+	client := e.rpcClient()
+	signers := make(map[string]*session.Signer)
+
+	for _, cfg := range d.config.Bots {
+		info, err := e.findWallet(cfg.Wallet)
+		if err != nil {
+			return fmt.Errorf("bot %s: %v", cfg.Name, err)
+		}
+		signer, ok := signers[info.Address]
+		if !ok {
+			if cfg.PasswordFile != "" {
+				password, err := os.ReadFile(cfg.PasswordFile)
+				if err != nil {
+					return fmt.Errorf("bot %s: cannot read password file: %v", cfg.Name, err)
+				}
+				signer, err = e.unlockWith(info, strings.TrimRight(string(password), "\r\n"))
+			} else {
+				signer, err = e.unlock(info, passwordStdin)
+			}
+			if err != nil {
+				return fmt.Errorf("bot %s: %v", cfg.Name, err)
+			}
+			signers[info.Address] = signer
+		}
+
+		b := &daemonBot{
+			name:   cfg.Name,
+			kind:   cfg.Type,
+			wallet: info.Address,
+			logger: d.logger.With("bot", cfg.Name, "type", cfg.Type, "wallet", info.Address),
+		}
+		logf := func(message string) { b.logger.Info(message) }
+		onSubmit := func(id string, err error) {
+			if err != nil {
+				b.logger.Error("submission failed", "error", err)
+				return
+			}
+			b.logger.Info("submitted", "id", id)
+		}
+
+		strategy := e.fee
+		if cfg.PriorityFee != "" {
+			strategy, _ = fee.ParseStrategy(cfg.PriorityFee)
+		}
+
+		switch cfg.Type {
+		case "calypso":
+			b.runner = &bot.Calypso{
+				Config:   *cfg.Calypso,
+				Network:  e.network,
+				Client:   client,
+				RPC:      e.httpClient(),
+				Signer:   signer,
+				Log:      logf,
+				OnSubmit: onSubmit,
+				History:  e.history(),
+				Fee:      strategy,
+			}
+		case "conditional":
+			engine := &bot.Conditional{
+				Network:  e.network,
+				Client:   client,
+				Signer:   signer,
+				Interval: time.Duration(cfg.Interval) * time.Second,
+				Prices:   cfg.Prices,
+				Fee:      strategy,
+				Log:      logf,
+				OnSubmit: onSubmit,
+			}
+			engine.SetTrades(cfg.Trades)
+			name := cfg.Name
+			engine.OnChange = func() { d.saveTrades(name, engine.Trades()) }
+			b.runner = engine
+		}
+		d.bots = append(d.bots, b)
+	}
+	return nil
+}
+
+// start runs b until it is stopped or the daemon shuts down.
+func (d *daemon) start(b *daemonBot) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if b.running {
+		return fmt.Errorf("bot %s is already running", b.name)
+	}
+	ctx, cancel := context.WithCancel(d.ctx)
+	b.cancel = cancel
+	b.running = true
+
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+		if err := b.runner.Run(ctx); err != nil {
+			b.logger.Error("bot could not start", "error", err)
+		}
+		cancel()
+		d.mu.Lock()
+		b.running = false
+		b.cancel = nil
+		d.mu.Unlock()
+	}()
+	return nil
+}
+
+// stop cancels b. A cycle in progress finishes first.
+func (d *daemon) stop(b *daemonBot) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if b.cancel == nil {
+		return fmt.Errorf("bot %s is not running", b.name)
+	}
+	b.cancel()
+	b.cancel = nil
+	b.logger.Info("stop requested")
+	return nil
+}
+
+// saveTrades writes the trades of a conditional bot back to the config file
+// so executed trades are not run again after a restart.
+func (d *daemon) saveTrades(name string, trades []*bot.ConditionalTrade) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for i := range d.config.Bots {
+		if d.config.Bots[i].Name == name {
+			d.config.Bots[i].Trades = trades
+		}
+	}
+	data, err := json.MarshalIndent(d.config, "", "  ")
+	if err != nil {
+		d.logger.Error("failed to encode config", "error", err)
+		return
+	}
+	tmp := d.configPath + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		d.logger.Error("failed to save trades", "error", err)
+		return
+	}
+	if err := os.Rename(tmp, d.configPath); err != nil {
+		d.logger.Error("failed to save trades", "error", err)
+	}
+}
+
+func (d *daemon) find(name string) *daemonBot {
+	for _, b := range d.bots {
+		if b.name == name {
+			return b
+		}
+	}
+	return nil
+}
+
+// routes serves the control endpoint. A non-empty token must be sent as a
+// bearer token with every request.
+func (d *daemon) routes(token string) http.Handler {
+	router := mux.NewRouter()
+	router.HandleFunc("/status", d.handleStatus).Methods("GET")
+	router.HandleFunc("/bots/{name}/start", d.handleControl(d.start)).Methods("POST")
+	router.HandleFunc("/bots/{name}/stop", d.handleControl(d.stop)).Methods("POST")
+	if token != "" {
+		router.Use(func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				sent, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+				if !ok || subtle.ConstantTimeCompare([]byte(sent), []byte(token)) != 1 {
+					w.Header().Set("WWW-Authenticate", "Bearer")
+					writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid or missing bearer token"})
+					return
+				}
+				next.ServeHTTP(w, r)
+			})
+		})
+	}
+	return router
+}
+
+func (d *daemon) handleStatus(w http.ResponseWriter, r *http.Request) {
+	statuses := make([]botStatus, len(d.bots))
+	for i, b := range d.bots {
+		statuses[i] = botStatus{Name: b.name, Type: b.kind, Wallet: b.wallet, Status: b.runner.Status()}
+	}
+	writeJSON(w, http.StatusOK, statuses)
+}
+
+func (d *daemon) handleControl(action func(*daemonBot) error) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		b := d.find(mux.Vars(r)["name"])
+		if b == nil {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "unknown bot"})
+			return
+		}
+		if err := action(b); err != nil {
+			writeJSON(w, http.StatusConflict, map[string]string{"error": err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, botStatus{Name: b.name, Type: b.kind, Wallet: b.wallet, Status: b.runner.Status()})
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// listenControl opens the control endpoint. TCP addresses must be loopback;
+// unix sockets are only accessible to the owner.
+func listenControl(addr, defaultSocket string) (net.Listener, error) {
+	if addr == "" {
+		addr = defaultSocket
+	}
+	if !strings.Contains(addr, "/") {
+		host, _, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil, usageError("invalid listen address: %v", err)
+		}
+		if ip := net.ParseIP(host); host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+			return nil, usageError("listen address %s is not a loopback address", addr)
+		}
+		return net.Listen("tcp", addr)
+	}
+
+	// A socket left behind by a daemon that did not shut down cleanly.
+	if conn, err := net.Dial("unix", addr); err == nil {
+		conn.Close()
+		return nil, fmt.Errorf("another daemon is listening on %s", addr)
+	}
+	os.Remove(addr)
+	listener, err := listenPrivate(addr)
+	if err != nil {
+		return nil, fmt.Errorf("cannot listen on %s: %v", addr, err)
+	}
+	return listener, nil
+}
@@ -0,0 +1,223 @@
+//go:build !js
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/gagliardetto/solana-go/rpc/jsonrpc"
+
+	"unruggable-go/internal/fee"
+	"unruggable-go/internal/history"
+	"unruggable-go/internal/network"
+	"unruggable-go/internal/rpcpool"
+	"unruggable-go/internal/session"
+	"unruggable-go/internal/storage"
+	"unruggable-go/internal/wallet"
+)
+
+// preferencesFile is where Fyne keeps the GUI's preferences in the storage root.
+const preferencesFile = "preferences.json"
+
+type envOptions struct {
+	dataDir string
+	network string
+	rpcURL  string
+	fee     string
+}
+
+// env is what every command works against: the wallet storage and the
+// selected network.
+type env struct {
+	root    string
+	storage storage.WalletStorage
+	network network.Profile
+	pool    *rpcpool.Pool
+	fee     fee.Strategy
+}
+
+func newEnv(opts envOptions) (*env, error) {
+	root := opts.dataDir
+	if root == "" {
+		var err error
+		if root, err = storage.DefaultRoot(); err != nil {
+			return nil, fmt.Errorf("cannot locate wallet storage: %v", err)
+		}
+	}
+
+	profile, err := loadNetwork(root, opts.network)
+	if err != nil {
+		return nil, err
+	}
+	if opts.rpcURL != "" {
+		profile.RPCURL = opts.rpcURL
+		profile.ExtraRPCURLs = nil
+		profile.WSURL = ""
+	}
+
+	strategy, err := loadFee(root, opts.fee)
+	if err != nil {
+		return nil, err
+	}
+
+	pool, err := rpcpool.New(profile.RPCURLs(), rpcpool.Config{})
+	if err != nil {
+		return nil, usageError("%v", err)
+	}
+	return &env{
+		root:    root,
+		storage: storage.NewFileWalletStorage(root),
+		network: profile,
+		pool:    pool,
+		fee:     strategy,
+	}, nil
+}
+
+// readPreferences returns the GUI's preferences, or nil if it has none yet.
+func readPreferences(root string) (map[string]interface{}, error) {
+	data, err := os.ReadFile(filepath.Join(root, preferencesFile))
+	if err != nil {
+		return nil, nil
+	}
+	var prefs map[string]interface{}
+	if err := json.Unmarshal(data, &prefs); err != nil {
+		return nil, fmt.Errorf("invalid preferences file: %v", err)
+	}
+	return prefs, nil
+}
+
+// loadNetwork returns the profile called name, or the GUI's active profile if
+// name is empty. Custom profiles are read from the GUI's preferences.
+func loadNetwork(root, name string) (network.Profile, error) {
+	profiles := network.Builtin()
+	active := network.Mainnet
+
+	prefs, err := readPreferences(root)
+	if err != nil {
+		return network.Profile{}, err
+	}
+	if prefs != nil {
+		if stored, ok := prefs[network.ProfilesPreferenceKey].(string); ok && stored != "" {
+			var custom []network.Profile
+			if err := json.Unmarshal([]byte(stored), &custom); err != nil {
+				return network.Profile{}, fmt.Errorf("invalid network profiles: %v", err)
+			}
+			profiles = append(profiles, custom...)
+		}
+		if stored, ok := prefs[network.ActivePreferenceKey].(string); ok && stored != "" {
+			active = stored
+		}
+	}
+
+	if name == "" {
+		name = active
+	}
+	for _, p := range profiles {
+		if strings.EqualFold(p.Name, name) {
+			return p, nil
+		}
+	}
+	return network.Profile{}, usageError("unknown network %q", name)
+}
+
+// loadFee parses name, or the GUI's priority fee strategy if name is empty.
+func loadFee(root, name string) (fee.Strategy, error) {
+	if name != "" {
+		strategy, err := fee.ParseStrategy(name)
+		if err != nil {
+			return fee.Strategy{}, usageError("%v", err)
+		}
+		return strategy, nil
+	}
+	prefs, err := readPreferences(root)
+	if err != nil {
+		return fee.Strategy{}, err
+	}
+	stored, _ := prefs[fee.PreferenceKey].(string)
+	strategy, err := fee.ParseStrategy(stored)
+	if err != nil {
+		return fee.Default, nil
+	}
+	return strategy, nil
+}
+
+// httpClient sends hand-built JSON-RPC requests through the endpoint pool.
+func (e *env) httpClient() *http.Client {
+	return e.pool.HTTPClient()
+}
+
+// rpcClient returns a solana-go client whose calls go through the pool.
+func (e *env) rpcClient() *rpc.Client {
+	return rpc.NewWithCustomRPCClient(jsonrpc.NewClientWithOpts(e.network.RPCURL, &jsonrpc.RPCClientOpts{
+		HTTPClient: e.pool.HTTPClient(),
+	}))
+}
+
+// history returns the portfolio history the GUI records too.
+func (e *env) history() *history.Store {
+	return history.Open(filepath.Join(e.root, history.Dir))
+}
+
+// findWallet resolves ref, an address or a label, to a stored wallet.
+func (e *env) findWallet(ref string) (wallet.Info, error) {
+	if ref == "" {
+		return wallet.Info{}, usageError("no wallet given")
+	}
+	infos, err := wallet.List(e.storage)
+	if err != nil {
+		return wallet.Info{}, err
+	}
+
+	var matches []wallet.Info
+	for _, info := range infos {
+		if info.Address == ref {
+			return info, nil
+		}
+		if info.Label != "" && strings.EqualFold(info.Label, ref) {
+			matches = append(matches, info)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return wallet.Info{}, fmt.Errorf("wallet %q not found", ref)
+	case 1:
+		return matches[0], nil
+	default:
+		return wallet.Info{}, fmt.Errorf("label %q matches %d wallets, use the address", ref, len(matches))
+	}
+}
+
+// unlock asks for the password of info and returns a signer for it.
+func (e *env) unlock(info wallet.Info, passwordStdin bool) (*session.Signer, error) {
+	if info.WatchOnly {
+		return nil, fmt.Errorf("wallet %s: %w", info.Address, wallet.ErrWatchOnly)
+	}
+	name := info.Address
+	if info.Label != "" {
+		name = info.Label
+	}
+	password, err := readPassword(fmt.Sprintf("Password for %s: ", name), passwordStdin)
+	if err != nil {
+		return nil, err
+	}
+	return e.unlockWith(info, password)
+}
+
+// unlockWith decrypts info with password and returns a signer for it. Every
+// call gets its own session, so several wallets can be unlocked at once.
+func (e *env) unlockWith(info wallet.Info, password string) (*session.Signer, error) {
+	if info.WatchOnly {
+		return nil, fmt.Errorf("wallet %s: %w", info.Address, wallet.ErrWatchOnly)
+	}
+	key, err := wallet.Unlock(e.storage, info.Address, password)
+	if err != nil {
+		return nil, err
+	}
+	return session.New(0).Unlock(info.Address, key), nil
+}
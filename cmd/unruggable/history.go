@@ -0,0 +1,154 @@
+//go:build !js
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"unruggable-go/internal/history"
+	"unruggable-go/internal/price"
+	"unruggable-go/internal/wallet"
+)
+
+// rangeUsage documents the -range flag of the history subcommands.
+var rangeUsage = "time range: " + strings.Join(history.Ranges, ", ")
+
+func runHistory(e *env, args []string) error {
+	if len(args) == 0 {
+		return usageError("history needs a subcommand: record, export or pnl")
+	}
+	switch args[0] {
+	case "record":
+		return historyRecord(e, args[1:])
+	case "export":
+		return historyExport(e, args[1:])
+	case "pnl":
+		return historyPnL(e, args[1:])
+	}
+	return usageError("unknown history subcommand %q", args[0])
+}
+
+type recordOutput struct {
+	Address string    `json:"address"`
+	Time    time.Time `json:"time,omitempty"`
+	Value   float64   `json:"value"`
+	Error   string    `json:"error,omitempty"`
+}
+
+// historyRecord takes a snapshot of each wallet now. Run it from cron to keep
+// history while the GUI is closed.
+func historyRecord(e *env, args []string) error {
+	fs := newFlagSet("history record", "history record [-all] [WALLET...]")
+	all := fs.Bool("all", false, "include hidden wallets when no wallet is given")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	var infos []wallet.Info
+	if fs.NArg() == 0 {
+		stored, err := wallet.List(e.storage)
+		if err != nil {
+			return err
+		}
+		for _, info := range stored {
+			if !info.Hidden || *all {
+				infos = append(infos, info)
+			}
+		}
+	}
+	for _, ref := range fs.Args() {
+		info, err := e.findWallet(ref)
+		if err != nil {
+			return err
+		}
+		infos = append(infos, info)
+	}
+
+	store := e.history()
+	fetcher := e.fetcher(price.Config{})
+	results := make([]recordOutput, 0, len(infos))
+	failed := 0
+	for _, info := range infos {
+		result := recordOutput{Address: info.Address}
+		balances, err := fetcher.Balances(info.Address)
+		if err == nil {
+			snapshot := history.FromBalances(e.network.Name, info.Address, "cli", balances)
+			if err = store.Append(snapshot); err == nil {
+				result.Time, result.Value = snapshot.Time, snapshot.Value
+			}
+		}
+		if err != nil {
+			result.Error = err.Error()
+			failed++
+		}
+		results = append(results, result)
+	}
+	if err := printJSON(results); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d wallets were not recorded", failed, len(infos))
+	}
+	return nil
+}
+
+// historySnapshots loads the snapshots of the single wallet in args
+// over the range called rangeName.
+func historySnapshots(e *env, name string, args []string, rangeName string) ([]history.Snapshot, error) {
+	if len(args) != 1 {
+		return nil, usageError("%s needs exactly one wallet", name)
+	}
+	info, err := e.findWallet(args[0])
+	if err != nil {
+		return nil, err
+	}
+	since, err := history.RangeStart(rangeName, time.Now())
+	if err != nil {
+		return nil, usageError("%v", err)
+	}
+	return e.history().Load(e.network.Name, info.Address, since)
+}
+
+func historyExport(e *env, args []string) error {
+	fs := newFlagSet("history export", "history export [-range RANGE] [-o FILE] WALLET")
+	rangeName := fs.String("range", "all", rangeUsage)
+	output := fs.String("o", "", "write the CSV to FILE instead of stdout")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	snapshots, err := historySnapshots(e, "history export", fs.Args(), *rangeName)
+	if err != nil {
+		return err
+	}
+
+	var w io.Writer = os.Stdout
+	if *output != "" {
+		f, err := os.OpenFile(*output, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	return history.WriteCSV(w, snapshots)
+}
+
+func historyPnL(e *env, args []string) error {
+	fs := newFlagSet("history pnl", "history pnl [-range RANGE] WALLET")
+	rangeName := fs.String("range", "30d", rangeUsage)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	snapshots, err := historySnapshots(e, "history pnl", fs.Args(), *rangeName)
+	if err != nil {
+		return err
+	}
+	if len(snapshots) == 0 {
+		return fmt.Errorf("no snapshots of %s in range %s", fs.Arg(0), *rangeName)
+	}
+	return printJSON(history.ComputePnL(snapshots))
+}
@@ -0,0 +1,33 @@
+//go:build !js
+
+package main
+
+import (
+	"context"
+
+	"unruggable-go/internal/txinspect"
+)
+
+func runInspect(e *env, args []string) error {
+	fs := newFlagSet("inspect", "inspect SIGNATURE|TRANSACTION")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return usageError("inspect needs a signature or an encoded transaction")
+	}
+	input := fs.Arg(0)
+
+	// A signature is also valid base58, so decoding it as a transaction is
+	// attempted first and only a failure falls through to fetching.
+	tx, err := txinspect.Decode(input)
+	if err != nil {
+		if !txinspect.LooksLikeSignature(input) {
+			return err
+		}
+		if tx, err = txinspect.Fetch(context.Background(), e.rpcClient(), input); err != nil {
+			return err
+		}
+	}
+	return printJSON(txinspect.Summarize(tx))
+}
@@ -0,0 +1,160 @@
+//go:build !js
+
+// Command unruggable is a headless interface to the wallets of the Unruggable
+// app. It reads the same wallet storage and network profiles as the GUI and
+// prints JSON, so it can be scripted.
+//
+// Usage:
+//
+//	unruggable [global flags] <command> [flags] [args]
+//
+// Commands:
+//
+//	wallets list              list stored wallets
+//	wallets import FILE       import a base58 or solana-keygen key (- for stdin)
+//	wallets generate          create a new wallet
+//	balance WALLET...         SOL and token balances, summed over several wallets
+//	history record|export|pnl portfolio snapshots, CSV export and PnL
+//	send                      send SOL or an SPL token
+//	inspect SIG|TX            decode a transaction or fetch it by signature
+//	multisig info ADDRESS     show a Squads multisig
+//	multisig create           create a Squads multisig
+//	sign-file FILE            sign a serialized transaction or a message
+//	daemon -config FILE       run Calypso and conditional bots unattended
+//
+// Passwords are read from the terminal, or from stdin with -password-stdin.
+// Errors are printed to stderr as JSON and the exit status is non-zero:
+// 2 for usage errors, 1 for everything else.
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+)
+
+// errUsage marks errors caused by invalid arguments.
+var errUsage = errors.New("usage error")
+
+// usageError formats an error that exits with status 2.
+func usageError(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s", errUsage, fmt.Sprintf(format, args...))
+}
+
+type command struct {
+	name    string
+	summary string
+	run     func(env *env, args []string) error
+}
+
+var commands = []command{
+	{"wallets", "list, import or generate wallets", runWallets},
+	{"balance", "show SOL and token balances of a wallet", runBalance},
+	{"history", "record, export or summarize portfolio snapshots", runHistory},
+	{"send", "send SOL or an SPL token", runSend},
+	{"inspect", "decode a transaction or fetch it by signature", runInspect},
+	{"multisig", "show or create a Squads multisig", runMultisig},
+	{"sign-file", "sign a serialized transaction or message file", runSignFile},
+	{"daemon", "run Calypso and conditional bots unattended", runDaemon},
+}
+
+func main() {
+	os.Exit(run(os.Args[1:]))
+}
+
+func run(args []string) int {
+	global := flag.NewFlagSet("unruggable", flag.ContinueOnError)
+	global.SetOutput(os.Stderr)
+	var opts envOptions
+	global.StringVar(&opts.dataDir, "data-dir", "", "wallet storage directory (default: the GUI's)")
+	global.StringVar(&opts.network, "network", "", "network profile name (default: the GUI's active network)")
+	global.StringVar(&opts.rpcURL, "rpc", "", "override the RPC endpoint of the network")
+	global.StringVar(&opts.fee, "fee", "", "priority fee: low, medium, high or a percentile (default: the GUI's)")
+	verbose := global.Bool("v", false, "log progress to stderr")
+	global.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: unruggable [global flags] <command> [flags] [args]")
+		fmt.Fprintln(os.Stderr, "\nCommands:")
+		for _, c := range commands {
+			fmt.Fprintf(os.Stderr, "  %-10s %s\n", c.name, c.summary)
+		}
+		fmt.Fprintln(os.Stderr, "\nGlobal flags:")
+		global.PrintDefaults()
+	}
+	if err := global.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return 2
+	}
+
+	log.SetOutput(io.Discard)
+	if *verbose {
+		log.SetOutput(os.Stderr)
+	}
+
+	if global.NArg() == 0 {
+		global.Usage()
+		return 2
+	}
+	name, rest := global.Arg(0), global.Args()[1:]
+	for _, c := range commands {
+		if c.name != name {
+			continue
+		}
+		e, err := newEnv(opts)
+		if err == nil {
+			err = c.run(e, rest)
+		}
+		return report(err)
+	}
+	return report(usageError("unknown command %q", name))
+}
+
+// report prints err as JSON to stderr and returns the exit status.
+func report(err error) int {
+	if err == nil {
+		return 0
+	}
+	if errors.Is(err, flag.ErrHelp) {
+		return 0
+	}
+	json.NewEncoder(os.Stderr).Encode(map[string]string{"error": err.Error()})
+	if errors.Is(err, errUsage) {
+		return 2
+	}
+	return 1
+}
+
+// printJSON writes v to stdout as indented JSON.
+func printJSON(v interface{}) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// newFlagSet returns a flag set for a subcommand that reports parse errors
+// as usage errors instead of exiting.
+func newFlagSet(name, usage string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: unruggable %s\n", usage)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// parseFlags parses args into fs, wrapping failures as usage errors.
+func parseFlags(fs *flag.FlagSet, args []string) error {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return fmt.Errorf("%w: %v", errUsage, err)
+	}
+	return nil
+}
@@ -0,0 +1,198 @@
+//go:build !js
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/hogyzen12/squads-go/pkg/multisig"
+
+	"unruggable-go/internal/fee"
+	"unruggable-go/internal/squads"
+	"unruggable-go/internal/transfer"
+)
+
+type multisigMember struct {
+	Key         string `json:"key"`
+	Permissions uint8  `json:"permissions"`
+}
+
+type multisigOutput struct {
+	Address               string           `json:"address"`
+	Threshold             uint16           `json:"threshold"`
+	TimeLock              uint32           `json:"timeLock"`
+	DefaultVault          string           `json:"defaultVault"`
+	TransactionIndex      uint64           `json:"transactionIndex"`
+	StaleTransactionIndex uint64           `json:"staleTransactionIndex"`
+	Members               []multisigMember `json:"members"`
+}
+
+type multisigCreated struct {
+	Address   string `json:"address"`
+	Signature string `json:"signature"`
+	Explorer  string `json:"explorer"`
+	// PriorityFee is paid on top of the base fee, in lamports.
+	PriorityFee  uint64 `json:"priorityFee"`
+	ComputeUnits uint32 `json:"computeUnits"`
+}
+
+// memberFlags collects repeated -member KEY[:PERMISSIONS] flags.
+type memberFlags []multisig.Member
+
+func (m *memberFlags) String() string {
+	return fmt.Sprintf("%d members", len(*m))
+}
+
+func (m *memberFlags) Set(value string) error {
+	keyPart, permPart, hasPerm := strings.Cut(value, ":")
+	key, err := solana.PublicKeyFromBase58(keyPart)
+	if err != nil {
+		return fmt.Errorf("invalid member key %q: %v", keyPart, err)
+	}
+	perm := multisig.PermissionFull
+	if hasPerm {
+		if perm, err = parsePermissions(permPart); err != nil {
+			return err
+		}
+	}
+	*m = append(*m, multisig.Member{Key: key, Permissions: perm})
+	return nil
+}
+
+// parsePermissions accepts a permission name or a 0-7 bit mask.
+func parsePermissions(s string) (uint8, error) {
+	switch strings.ToLower(s) {
+	case "full":
+		return multisig.PermissionFull, nil
+	case "propose":
+		return multisig.PermissionPropose, nil
+	case "vote":
+		return multisig.PermissionVote, nil
+	case "execute":
+		return multisig.PermissionExecute, nil
+	}
+	n, err := strconv.ParseUint(s, 10, 8)
+	if err != nil || n > uint64(multisig.PermissionFull) {
+		return 0, fmt.Errorf("invalid permissions %q: use full, propose, vote, execute or 0-7", s)
+	}
+	return uint8(n), nil
+}
+
+func runMultisig(e *env, args []string) error {
+	if len(args) == 0 {
+		return usageError("multisig needs a subcommand: info or create")
+	}
+	switch args[0] {
+	case "info":
+		return multisigInfo(e, args[1:])
+	case "create":
+		return multisigCreate(e, args[1:])
+	}
+	return usageError("unknown multisig subcommand %q", args[0])
+}
+
+func multisigInfo(e *env, args []string) error {
+	fs := newFlagSet("multisig info", "multisig info ADDRESS")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return usageError("multisig info needs exactly one address")
+	}
+	addr, err := solana.PublicKeyFromBase58(fs.Arg(0))
+	if err != nil {
+		return usageError("invalid multisig address: %v", err)
+	}
+
+	info, err := multisig.FetchMultisigInfo(context.Background(), e.network.RPCURL, addr)
+	if err != nil {
+		return fmt.Errorf("error fetching multisig: %v", err)
+	}
+	out := multisigOutput{
+		Address:               info.Address.String(),
+		Threshold:             info.Threshold,
+		TimeLock:              info.TimeLock,
+		DefaultVault:          info.DefaultVault.String(),
+		TransactionIndex:      info.TransactionIndex,
+		StaleTransactionIndex: info.StaleTransactionIndex,
+		Members:               make([]multisigMember, len(info.Members)),
+	}
+	for i, m := range info.Members {
+		out.Members[i] = multisigMember{Key: m.Key.String(), Permissions: m.Permissions.Mask}
+	}
+	return printJSON(out)
+}
+
+func multisigCreate(e *env, args []string) error {
+	fs := newFlagSet("multisig create", "multisig create -from WALLET -member KEY[:PERMISSIONS]... -threshold N [-password-stdin]")
+	from := fs.String("from", "", "wallet paying for the multisig (address or label)")
+	var members memberFlags
+	fs.Var(&members, "member", "member key with optional permissions (full, propose, vote, execute or 0-7); repeatable")
+	threshold := fs.Uint("threshold", 1, "approvals needed to execute")
+	passwordStdin := fs.Bool("password-stdin", false, "read the password from stdin")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		return usageError("multisig create takes no arguments")
+	}
+	if len(members) == 0 {
+		return usageError("multisig create needs at least one -member")
+	}
+	if *threshold == 0 || *threshold > 0xffff {
+		return usageError("invalid threshold %d", *threshold)
+	}
+
+	info, err := e.findWallet(*from)
+	if err != nil {
+		return err
+	}
+	signer, err := e.unlock(info, *passwordStdin)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	client := e.rpcClient()
+	create, err := squads.NewCreate(client, signer.PublicKey(), members, uint16(*threshold))
+	if err != nil {
+		return fmt.Errorf("error creating multisig: %v", err)
+	}
+	instructions, budget, err := fee.Prepare(ctx, client, e.fee, signer.PublicKey(), []solana.Instruction{create.Instruction})
+	if err != nil {
+		return err
+	}
+	tx, err := transfer.NewTransaction(ctx, client, signer.PublicKey(), instructions)
+	if err != nil {
+		return err
+	}
+	err = signer.WithPrivateKey(func(key solana.PrivateKey) error {
+		return create.Sign(tx, key)
+	})
+	if err != nil {
+		return err
+	}
+	sig, err := transfer.Submit(ctx, client, "", tx, signer)
+	if err != nil {
+		return fmt.Errorf("error creating multisig: %v", err)
+	}
+
+	out := multisigCreated{
+		Address:      create.Multisig.String(),
+		Signature:    sig.String(),
+		Explorer:     e.network.ExplorerTxURL(sig.String()),
+		PriorityFee:  budget.PriorityFee(),
+		ComputeUnits: budget.Units,
+	}
+	waitCtx, cancel := context.WithTimeout(ctx, confirmTimeout)
+	defer cancel()
+	if _, err := transfer.WaitConfirmed(waitCtx, client, sig); err != nil {
+		printJSON(out)
+		return err
+	}
+	return printJSON(out)
+}
@@ -0,0 +1,71 @@
+//go:build !js
+
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// stdin is shared so a key and a password can be read from consecutive lines.
+var stdin = bufio.NewReader(os.Stdin)
+
+// readLine returns the next line of stdin without its line ending.
+func readLine() (string, error) {
+	line, err := stdin.ReadString('\n')
+	if err != nil && !(errors.Is(err, io.EOF) && line != "") {
+		if errors.Is(err, io.EOF) {
+			return "", errors.New("unexpected end of input")
+		}
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// readPassword reads a password from stdin, or prompts for it on the terminal
+// without echo.
+func readPassword(prompt string, fromStdin bool) (string, error) {
+	if fromStdin {
+		return readLine()
+	}
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return "", usageError("stdin is not a terminal; pass -password-stdin to read the password from it")
+	}
+	fmt.Fprint(os.Stderr, prompt)
+	password, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", fmt.Errorf("failed to read password: %v", err)
+	}
+	return string(password), nil
+}
+
+// readNewPassword reads the password for a new wallet. On a terminal it is
+// asked for twice.
+func readNewPassword(fromStdin bool) (string, error) {
+	password, err := readPassword("New wallet password: ", fromStdin)
+	if err != nil {
+		return "", err
+	}
+	if password == "" {
+		return "", errors.New("password cannot be empty")
+	}
+	if fromStdin {
+		return password, nil
+	}
+	confirm, err := readPassword("Repeat password: ", false)
+	if err != nil {
+		return "", err
+	}
+	if confirm != password {
+		return "", errors.New("passwords do not match")
+	}
+	return password, nil
+}
@@ -0,0 +1,116 @@
+//go:build !js
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+
+	"unruggable-go/internal/transfer"
+)
+
+// confirmTimeout bounds how long send -wait polls for finalization.
+const confirmTimeout = 60 * time.Second
+
+type sendOutput struct {
+	Signature string `json:"signature"`
+	Explorer  string `json:"explorer"`
+	Slot      uint64 `json:"slot,omitempty"`
+	// TransferFee is withheld from the amount by Token-2022 mints with a
+	// transfer fee, in whole tokens.
+	TransferFee float64 `json:"transferFee,omitempty"`
+	// PriorityFee is paid on top of the base fee, in lamports, for
+	// ComputeUnits at the -fee strategy's price.
+	PriorityFee  uint64 `json:"priorityFee"`
+	ComputeUnits uint32 `json:"computeUnits"`
+}
+
+func runSend(e *env, args []string) error {
+	fs := newFlagSet("send", "send -from WALLET -to ADDRESS -amount N [-token SOL|MINT] [-password-stdin] [-wait]")
+	from := fs.String("from", "", "sending wallet (address or label)")
+	to := fs.String("to", "", "recipient address")
+	amountFlag := fs.String("amount", "", "amount in whole tokens, e.g. 1.5")
+	tokenFlag := fs.String("token", "SOL", "SOL or the mint address of an SPL token")
+	passwordStdin := fs.Bool("password-stdin", false, "read the password from stdin")
+	wait := fs.Bool("wait", false, "wait until the transaction is finalized")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		return usageError("send takes no arguments")
+	}
+
+	info, err := e.findWallet(*from)
+	if err != nil {
+		return err
+	}
+	recipient, err := solana.PublicKeyFromBase58(*to)
+	if err != nil {
+		return usageError("invalid recipient address: %v", err)
+	}
+	amount, err := strconv.ParseFloat(*amountFlag, 64)
+	if err != nil || amount <= 0 {
+		return usageError("invalid amount %q", *amountFlag)
+	}
+
+	ctx := context.Background()
+	client := e.rpcClient()
+	params := transfer.Params{
+		From:   solana.MustPublicKeyFromBase58(info.Address),
+		To:     recipient,
+		Amount: amount,
+	}
+	var transferFee float64
+	if !strings.EqualFold(*tokenFlag, "SOL") {
+		if params.Mint, err = solana.PublicKeyFromBase58(*tokenFlag); err != nil {
+			return usageError("invalid token mint: %v", err)
+		}
+		mint, err := transfer.LoadMint(ctx, client, params.Mint)
+		if err != nil {
+			return err
+		}
+		if mint.NonTransferable {
+			return fmt.Errorf("token %s is non-transferable", params.Mint)
+		}
+		transferFee = mint.Tokens(mint.TransferFee.Fee(mint.BaseUnits(amount)))
+	}
+
+	signer, err := e.unlock(info, *passwordStdin)
+	if err != nil {
+		return err
+	}
+
+	tx, budget, err := transfer.Build(ctx, client, params, e.fee)
+	if err != nil {
+		return err
+	}
+	if err := signer.SignTransaction(tx); err != nil {
+		return fmt.Errorf("error signing transaction: %v", err)
+	}
+	sig, err := transfer.Submit(ctx, client, e.network.JitoURL, tx, signer)
+	if err != nil {
+		return err
+	}
+
+	out := sendOutput{
+		Signature:    sig.String(),
+		Explorer:     e.network.ExplorerTxURL(sig.String()),
+		TransferFee:  transferFee,
+		PriorityFee:  budget.PriorityFee(),
+		ComputeUnits: budget.Units,
+	}
+	if *wait {
+		waitCtx, cancel := context.WithTimeout(ctx, confirmTimeout)
+		defer cancel()
+		if out.Slot, err = transfer.WaitConfirmed(waitCtx, client, sig); err != nil {
+			printJSON(out)
+			return err
+		}
+	}
+	return printJSON(out)
+}
@@ -0,0 +1,124 @@
+//go:build !js
+
+package main
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gagliardetto/solana-go"
+
+	"unruggable-go/internal/txinspect"
+)
+
+type signFileOutput struct {
+	Signer      string `json:"signer"`
+	Signature   string `json:"signature"`
+	Transaction string `json:"transaction,omitempty"` // base64, with every signature so far
+	Complete    bool   `json:"complete,omitempty"`
+}
+
+func runSignFile(e *env, args []string) error {
+	fs := newFlagSet("sign-file", "sign-file -from WALLET [-message] [-out FILE] [-password-stdin] FILE")
+	from := fs.String("from", "", "signing wallet (address or label)")
+	message := fs.Bool("message", false, "sign the file contents as a raw message")
+	outPath := fs.String("out", "", "also write the signed transaction, base64 encoded, to this file")
+	passwordStdin := fs.Bool("password-stdin", false, "read the password from stdin")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return usageError("sign-file needs exactly one file")
+	}
+	if *message && *outPath != "" {
+		return usageError("-out only applies to transactions")
+	}
+
+	data, err := os.ReadFile(fs.Arg(0))
+	if err != nil {
+		return fmt.Errorf("failed to read file: %v", err)
+	}
+	info, err := e.findWallet(*from)
+	if err != nil {
+		return err
+	}
+
+	if *message {
+		signer, err := e.unlock(info, *passwordStdin)
+		if err != nil {
+			return err
+		}
+		sig, err := signer.SignMessage(data)
+		if err != nil {
+			return fmt.Errorf("error signing message: %v", err)
+		}
+		return printJSON(signFileOutput{Signer: info.Address, Signature: sig.String()})
+	}
+
+	tx, err := readTransaction(data)
+	if err != nil {
+		return err
+	}
+	pubKey := solana.MustPublicKeyFromBase58(info.Address)
+	if !tx.Message.IsSigner(pubKey) {
+		return fmt.Errorf("wallet %s is not a signer of this transaction", info.Address)
+	}
+
+	signer, err := e.unlock(info, *passwordStdin)
+	if err != nil {
+		return err
+	}
+	// PartialSign keeps the signatures of other signers already in the file.
+	var sig solana.Signature
+	err = signer.WithPrivateKey(func(key solana.PrivateKey) error {
+		sigs, err := tx.PartialSign(func(k solana.PublicKey) *solana.PrivateKey {
+			if k.Equals(pubKey) {
+				return &key
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		for i, k := range tx.Message.Signers() {
+			if k.Equals(pubKey) {
+				sig = sigs[i]
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("error signing transaction: %v", err)
+	}
+
+	signed, err := tx.MarshalBinary()
+	if err != nil {
+		return fmt.Errorf("failed to encode transaction: %v", err)
+	}
+	out := signFileOutput{
+		Signer:      info.Address,
+		Signature:   sig.String(),
+		Transaction: base64.StdEncoding.EncodeToString(signed),
+		Complete:    tx.VerifySignatures() == nil,
+	}
+	if *outPath != "" {
+		if err := os.WriteFile(*outPath, []byte(out.Transaction+"\n"), 0o600); err != nil {
+			return fmt.Errorf("failed to write %s: %v", *outPath, err)
+		}
+	}
+	return printJSON(out)
+}
+
+// readTransaction decodes a transaction file holding base58 or base64 text
+// or the raw wire format.
+func readTransaction(data []byte) (*solana.Transaction, error) {
+	if utf8.Valid(data) {
+		if tx, err := txinspect.Decode(strings.TrimSpace(string(data))); err == nil {
+			return tx, nil
+		}
+	}
+	return txinspect.DecodeBinary(data)
+}
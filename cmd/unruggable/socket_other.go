@@ -0,0 +1,11 @@
+//go:build !unix && !js
+
+package main
+
+import "net"
+
+// listenPrivate creates the unix socket at path. Without a umask, access is
+// limited by the permissions of the socket's directory.
+func listenPrivate(path string) (net.Listener, error) {
+	return net.Listen("unix", path)
+}
@@ -0,0 +1,17 @@
+//go:build unix
+
+package main
+
+import (
+	"net"
+	"syscall"
+)
+
+// listenPrivate creates the unix socket at path accessible only to the owner.
+// The socket is created under a restrictive umask, so there is no moment in
+// which other users could connect.
+func listenPrivate(path string) (net.Listener, error) {
+	previous := syscall.Umask(0o077)
+	defer syscall.Umask(previous)
+	return net.Listen("unix", path)
+}
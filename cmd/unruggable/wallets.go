@@ -0,0 +1,139 @@
+//go:build !js
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+
+	"unruggable-go/internal/keypair"
+	"unruggable-go/internal/storage"
+	"unruggable-go/internal/wallet"
+)
+
+func runWallets(e *env, args []string) error {
+	if len(args) == 0 {
+		return usageError("wallets needs a subcommand: list, import or generate")
+	}
+	switch args[0] {
+	case "list":
+		return walletsList(e, args[1:])
+	case "import":
+		return walletsImport(e, args[1:])
+	case "generate":
+		return walletsGenerate(e, args[1:])
+	}
+	return usageError("unknown wallets subcommand %q", args[0])
+}
+
+func walletsList(e *env, args []string) error {
+	fs := newFlagSet("wallets list", "wallets list [-all]")
+	all := fs.Bool("all", false, "include hidden wallets")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	infos, err := wallet.List(e.storage)
+	if err != nil {
+		return err
+	}
+	shown := make([]wallet.Info, 0, len(infos))
+	for _, info := range infos {
+		if info.Hidden && !*all {
+			continue
+		}
+		shown = append(shown, info)
+	}
+	return printJSON(shown)
+}
+
+func walletsImport(e *env, args []string) error {
+	fs := newFlagSet("wallets import", "wallets import [-label NAME] [-password-stdin] FILE|-")
+	label := fs.String("label", "", "wallet label")
+	passwordStdin := fs.Bool("password-stdin", false, "read the password from stdin (after the key when FILE is -)")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return usageError("wallets import needs exactly one key file")
+	}
+
+	var input string
+	if path := fs.Arg(0); path == "-" {
+		if *passwordStdin {
+			// The key and the password share stdin, one per line.
+			line, err := readLine()
+			if err != nil {
+				return fmt.Errorf("failed to read key: %v", err)
+			}
+			input = line
+		} else {
+			data, err := io.ReadAll(stdin)
+			if err != nil {
+				return fmt.Errorf("failed to read key: %v", err)
+			}
+			input = string(data)
+		}
+	} else {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read key file: %v", err)
+		}
+		input = string(data)
+	}
+
+	key, err := keypair.Parse(input)
+	if err != nil {
+		return err
+	}
+	address := key.PublicKey().String()
+	if existing, err := e.findWallet(address); err == nil && !existing.WatchOnly {
+		return fmt.Errorf("wallet %s already exists", address)
+	}
+
+	password, err := readNewPassword(*passwordStdin)
+	if err != nil {
+		return err
+	}
+	return saveWallet(e, key, password, *label, storage.OriginImported)
+}
+
+func walletsGenerate(e *env, args []string) error {
+	fs := newFlagSet("wallets generate", "wallets generate [-label NAME] [-password-stdin]")
+	label := fs.String("label", "", "wallet label")
+	passwordStdin := fs.Bool("password-stdin", false, "read the password from stdin")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		return usageError("wallets generate takes no arguments")
+	}
+
+	password, err := readNewPassword(*passwordStdin)
+	if err != nil {
+		return err
+	}
+	return saveWallet(e, solana.NewWallet().PrivateKey, password, *label, storage.OriginGenerated)
+}
+
+// saveWallet stores key and prints the resulting wallet.
+func saveWallet(e *env, key solana.PrivateKey, password, label string, origin storage.WalletOrigin) error {
+	meta := storage.WalletMetadata{
+		Label:     label,
+		CreatedAt: time.Now(),
+		Origin:    origin,
+	}
+	if err := wallet.Save(e.storage, key, password, meta); err != nil {
+		return fmt.Errorf("error saving wallet: %v", err)
+	}
+	return printJSON(wallet.Info{
+		Address:   key.PublicKey().String(),
+		Label:     meta.Label,
+		Origin:    meta.Origin,
+		CreatedAt: meta.CreatedAt,
+	})
+}
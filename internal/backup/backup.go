@@ -0,0 +1,403 @@
+// Package backup exports everything the app stores into one encrypted archive
+// and restores it item by item.
+package backup
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"unruggable-go/internal/keystore"
+	"unruggable-go/internal/storage"
+)
+
+// Format identifies backup archives once decrypted.
+const Format = "unruggable-backup"
+
+// Version is the current archive version.
+const Version = 1
+
+// FileExtension is the suggested extension for archive files.
+const FileExtension = ".unruggable-backup"
+
+// Wallet is a stored wallet together with its metadata.
+type Wallet struct {
+	Kind         storage.WalletKind      `json:"kind"`
+	EncryptedKey string                  `json:"encryptedKey,omitempty"`
+	Metadata     *storage.WalletMetadata `json:"metadata,omitempty"`
+}
+
+// Archive is the plaintext content of a backup. Wallet keys, seeds and share
+// secrets stay in their own keystore envelopes inside it.
+type Archive struct {
+	Format    string            `json:"format"`
+	Version   int               `json:"version"`
+	CreatedAt time.Time         `json:"createdAt"`
+	Wallets   map[string]Wallet `json:"wallets"`
+	Seeds     map[string]string `json:"seeds"`
+	Shares    map[string]string `json:"shares"`
+	Configs   map[string]string `json:"configs"`
+	Settings  map[string]string `json:"settings"`
+}
+
+// Collect reads everything in s into an archive. settings holds the app
+// preferences to include. Wallets still in the legacy key format cannot be
+// restored, so Collect refuses them until they are upgraded, which happens
+// when they are unlocked or re-encrypted with the master password.
+func Collect(s storage.WalletStorage, settings map[string]string) (*Archive, error) {
+	archive := &Archive{
+		Format:    Format,
+		Version:   Version,
+		CreatedAt: time.Now().UTC(),
+		Wallets:   make(map[string]Wallet),
+		Settings:  settings,
+	}
+
+	wallets, err := s.LoadWallets()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load wallets: %v", err)
+	}
+	metadata, err := s.LoadMetadata()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load wallet metadata: %v", err)
+	}
+	var legacy []string
+	for pubKey, stored := range wallets {
+		if !stored.WatchOnly() && keystore.IsLegacy(stored.EncryptedKey) {
+			legacy = append(legacy, pubKey)
+			continue
+		}
+		wallet := Wallet{Kind: stored.Kind, EncryptedKey: stored.EncryptedKey}
+		if meta, ok := metadata[pubKey]; ok {
+			wallet.Metadata = &meta
+		}
+		archive.Wallets[pubKey] = wallet
+	}
+	if len(legacy) > 0 {
+		sort.Strings(legacy)
+		return nil, fmt.Errorf("%d wallet(s) still use the legacy key format and could not be restored from a backup: %s. "+
+			"Unlock each once, or change the master password, to upgrade them before exporting",
+			len(legacy), strings.Join(legacy, ", "))
+	}
+
+	if archive.Seeds, err = s.LoadSeeds(); err != nil {
+		return nil, fmt.Errorf("failed to load seeds: %v", err)
+	}
+	if archive.Shares, err = s.LoadShares(); err != nil {
+		return nil, fmt.Errorf("failed to load key shares: %v", err)
+	}
+	if archive.Configs, err = s.LoadConfigs(); err != nil {
+		return nil, fmt.Errorf("failed to load configurations: %v", err)
+	}
+	return archive, nil
+}
+
+// Seal encrypts the archive under password. AES-GCM authenticates the whole
+// archive, so any modification is detected when it is opened.
+func Seal(archive *Archive, password string) ([]byte, error) {
+	if password == "" {
+		return nil, fmt.Errorf("password cannot be empty")
+	}
+	plaintext, err := json.Marshal(archive)
+	if err != nil {
+		return nil, err
+	}
+	sealed, err := keystore.Seal(plaintext, password)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(sealed), nil
+}
+
+// Open decrypts and validates an archive written by Seal.
+func Open(data []byte, password string) (*Archive, error) {
+	env, err := keystore.Parse(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("not a backup archive: %v", err)
+	}
+	plaintext, err := env.Decrypt(password)
+	if err != nil {
+		return nil, err
+	}
+
+	var archive Archive
+	if err := json.Unmarshal(plaintext, &archive); err != nil {
+		return nil, fmt.Errorf("corrupted backup archive: %v", err)
+	}
+	if archive.Format != Format {
+		return nil, fmt.Errorf("not a backup archive")
+	}
+	if archive.Version > Version {
+		return nil, fmt.Errorf("backup archive version %d is newer than supported version %d", archive.Version, Version)
+	}
+	return &archive, nil
+}
+
+// ItemKind is the category of a restorable item.
+type ItemKind string
+
+const (
+	KindWallet  ItemKind = "wallet"
+	KindSeed    ItemKind = "seed"
+	KindShare   ItemKind = "share"
+	KindConfig  ItemKind = "config"
+	KindSetting ItemKind = "setting"
+)
+
+// Status compares an archive item with what is currently stored.
+type Status string
+
+const (
+	StatusNew       Status = "new"
+	StatusIdentical Status = "identical"
+	StatusConflict  Status = "conflict"
+)
+
+// Resolution is what restore does with an item.
+type Resolution string
+
+const (
+	// ResolveSkip leaves the current item untouched.
+	ResolveSkip Resolution = "skip"
+	// ResolveMerge keeps the current item and fills in what it is missing
+	// from the archive.
+	ResolveMerge Resolution = "merge"
+	// ResolveOverwrite replaces the current item with the archive's.
+	ResolveOverwrite Resolution = "overwrite"
+)
+
+// Item is one entry of a restore plan.
+type Item struct {
+	Kind       ItemKind
+	ID         string
+	Status     Status
+	Resolution Resolution
+}
+
+// Plan compares archive with the current state without changing anything.
+// New items default to being added, conflicts to being skipped.
+func Plan(archive *Archive, s storage.WalletStorage, settings map[string]string) ([]Item, error) {
+	wallets, err := s.LoadWallets()
+	if err != nil {
+		return nil, err
+	}
+	metadata, err := s.LoadMetadata()
+	if err != nil {
+		return nil, err
+	}
+	seeds, err := s.LoadSeeds()
+	if err != nil {
+		return nil, err
+	}
+	shares, err := s.LoadShares()
+	if err != nil {
+		return nil, err
+	}
+	configs, err := s.LoadConfigs()
+	if err != nil {
+		return nil, err
+	}
+
+	var items []Item
+	for pubKey, wallet := range archive.Wallets {
+		current, exists := wallets[pubKey]
+		var status Status
+		switch {
+		case !exists:
+			status = StatusNew
+		case current.Kind == wallet.Kind && current.EncryptedKey == wallet.EncryptedKey &&
+			metadataEqual(metadata[pubKey], wallet.Metadata):
+			status = StatusIdentical
+		default:
+			status = StatusConflict
+		}
+		items = append(items, newItem(KindWallet, pubKey, status))
+	}
+	items = append(items, planStrings(KindSeed, archive.Seeds, seeds)...)
+	items = append(items, planStrings(KindShare, archive.Shares, shares)...)
+	items = append(items, planStrings(KindConfig, archive.Configs, configs)...)
+	items = append(items, planStrings(KindSetting, archive.Settings, settings)...)
+
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].Kind != items[j].Kind {
+			return items[i].Kind < items[j].Kind
+		}
+		return items[i].ID < items[j].ID
+	})
+	return items, nil
+}
+
+func planStrings(kind ItemKind, archived, current map[string]string) []Item {
+	var items []Item
+	for id, value := range archived {
+		existing, exists := current[id]
+		switch {
+		case !exists:
+			items = append(items, newItem(kind, id, StatusNew))
+		case existing == value:
+			items = append(items, newItem(kind, id, StatusIdentical))
+		default:
+			items = append(items, newItem(kind, id, StatusConflict))
+		}
+	}
+	return items
+}
+
+func newItem(kind ItemKind, id string, status Status) Item {
+	resolution := ResolveSkip
+	if status == StatusNew {
+		resolution = ResolveOverwrite
+	}
+	return Item{Kind: kind, ID: id, Status: status, Resolution: resolution}
+}
+
+func metadataEqual(current storage.WalletMetadata, archived *storage.WalletMetadata) bool {
+	if archived == nil {
+		return current == storage.WalletMetadata{}
+	}
+	return current.Label == archived.Label && current.Color == archived.Color &&
+		current.CreatedAt.Equal(archived.CreatedAt) && current.Origin == archived.Origin &&
+		current.Hidden == archived.Hidden && current.SeedID == archived.SeedID &&
+		current.DerivationPath == archived.DerivationPath
+}
+
+// SettingsStore reads and writes app preferences during restore.
+type SettingsStore interface {
+	Setting(key string) (string, bool)
+	SetSetting(key, value string) error
+}
+
+// Failure is an item Apply could not restore.
+type Failure struct {
+	Item Item
+	Err  error
+}
+
+// Apply restores the items of plan according to their resolution. An item
+// that fails is reported and the rest are still restored; the error is only
+// for failing to read the current state.
+func Apply(archive *Archive, plan []Item, s storage.WalletStorage, settings SettingsStore) (applied []Item, failures []Failure, err error) {
+	wallets, err := s.LoadWallets()
+	if err != nil {
+		return nil, nil, err
+	}
+	metadata, err := s.LoadMetadata()
+	if err != nil {
+		return nil, nil, err
+	}
+	configs, err := s.LoadConfigs()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, item := range plan {
+		if item.Resolution == ResolveSkip || item.Status == StatusIdentical {
+			continue
+		}
+		overwrite := item.Status == StatusNew || item.Resolution == ResolveOverwrite
+
+		var err error
+		switch item.Kind {
+		case KindWallet:
+			err = applyWallet(s, item.ID, archive.Wallets[item.ID], wallets[item.ID], metadata[item.ID], overwrite)
+		case KindSeed:
+			if overwrite {
+				err = s.SaveSeed(item.ID, archive.Seeds[item.ID])
+			}
+		case KindShare:
+			if overwrite {
+				err = s.SaveShare(item.ID, archive.Shares[item.ID])
+			}
+		case KindConfig:
+			value := archive.Configs[item.ID]
+			if !overwrite {
+				value, err = mergeJSON(configs[item.ID], value)
+			}
+			if err == nil {
+				err = s.SaveConfig(item.ID, value)
+			}
+		case KindSetting:
+			_, exists := settings.Setting(item.ID)
+			if overwrite || !exists {
+				err = settings.SetSetting(item.ID, archive.Settings[item.ID])
+			}
+		default:
+			err = fmt.Errorf("unknown item kind %q", item.Kind)
+		}
+		if err != nil {
+			failures = append(failures, Failure{Item: item, Err: err})
+			continue
+		}
+		applied = append(applied, item)
+	}
+	return applied, failures, nil
+}
+
+// applyWallet writes a wallet. Merging keeps the current key and fills empty
+// metadata fields from the archive. A watch-only entry never replaces a stored
+// key: the key would stay and keep taking precedence, so the wallet has to be
+// deleted first.
+func applyWallet(s storage.WalletStorage, pubKey string, wallet Wallet, stored storage.StoredWallet, current storage.WalletMetadata, overwrite bool) error {
+	if overwrite {
+		var err error
+		if wallet.Kind == storage.KindWatchOnly {
+			if stored.Kind == storage.KindKeypair {
+				return fmt.Errorf("the backup has %s as watch-only but its key is stored here; delete the wallet first to restore it as watch-only", pubKey)
+			}
+			err = s.SaveWatchOnly(pubKey)
+		} else {
+			err = s.SaveWallet(pubKey, wallet.EncryptedKey)
+		}
+		if err != nil {
+			return err
+		}
+		if wallet.Metadata != nil {
+			return s.SaveMetadata(pubKey, *wallet.Metadata)
+		}
+		return nil
+	}
+
+	if wallet.Metadata == nil {
+		return nil
+	}
+	merged := current
+	archived := *wallet.Metadata
+	if merged.Label == "" {
+		merged.Label = archived.Label
+	}
+	if merged.Color == "" {
+		merged.Color = archived.Color
+	}
+	if merged.CreatedAt.IsZero() {
+		merged.CreatedAt = archived.CreatedAt
+	}
+	if merged.Origin == "" {
+		merged.Origin = archived.Origin
+	}
+	if merged.SeedID == "" {
+		merged.SeedID = archived.SeedID
+		merged.DerivationPath = archived.DerivationPath
+	}
+	return s.SaveMetadata(pubKey, merged)
+}
+
+// mergeJSON adds the top-level fields of archived that current lacks. Values
+// that are not JSON objects cannot be merged and keep the current value.
+func mergeJSON(current, archived string) (string, error) {
+	var cur, arc map[string]json.RawMessage
+	if json.Unmarshal([]byte(current), &cur) != nil || json.Unmarshal([]byte(archived), &arc) != nil || cur == nil {
+		return current, nil
+	}
+	for key, value := range arc {
+		if _, exists := cur[key]; !exists {
+			cur[key] = value
+		}
+	}
+	merged, err := json.Marshal(cur)
+	if err != nil {
+		return "", err
+	}
+	return string(merged), nil
+}
@@ -0,0 +1,300 @@
+//go:build !js
+
+package backup
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"unruggable-go/internal/keystore"
+	"unruggable-go/internal/storage"
+)
+
+// settingsMap is a SettingsStore backed by a map.
+type settingsMap map[string]string
+
+func (m settingsMap) Setting(key string) (string, bool) {
+	value, ok := m[key]
+	return value, ok
+}
+
+func (m settingsMap) SetSetting(key, value string) error {
+	m[key] = value
+	return nil
+}
+
+func sealKey(t *testing.T, plaintext string) string {
+	t.Helper()
+	sealed, err := keystore.Seal([]byte(plaintext), "master")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sealed
+}
+
+func testArchive(t *testing.T) *Archive {
+	t.Helper()
+	return &Archive{
+		Format:    Format,
+		Version:   Version,
+		CreatedAt: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Wallets: map[string]Wallet{
+			"Keypair1": {
+				Kind:         storage.KindKeypair,
+				EncryptedKey: sealKey(t, "archived key"),
+				Metadata:     &storage.WalletMetadata{Label: "Archived", Color: "#ff0000", Origin: storage.OriginImported},
+			},
+			"Watch1": {Kind: storage.KindWatchOnly, Metadata: &storage.WalletMetadata{Label: "Watched"}},
+		},
+		Seeds:    map[string]string{"seed1": sealKey(t, "archived seed")},
+		Shares:   map[string]string{},
+		Configs:  map[string]string{"bots": `{"a":1,"b":2}`},
+		Settings: map[string]string{"theme": "dark"},
+	}
+}
+
+// find returns the item of plan with kind and id.
+func find(t *testing.T, plan []Item, kind ItemKind, id string) *Item {
+	t.Helper()
+	for i := range plan {
+		if plan[i].Kind == kind && plan[i].ID == id {
+			return &plan[i]
+		}
+	}
+	t.Fatalf("plan has no %s %q", kind, id)
+	return nil
+}
+
+func TestSealOpenRoundTrip(t *testing.T) {
+	archive := testArchive(t)
+	sealed, err := Seal(archive, "backup password")
+	if err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+	if strings.Contains(string(sealed), "Archived") {
+		t.Error("sealed archive contains plaintext metadata")
+	}
+
+	opened, err := Open(sealed, "backup password")
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if !opened.CreatedAt.Equal(archive.CreatedAt) || len(opened.Wallets) != len(archive.Wallets) {
+		t.Fatalf("Open = %+v, want %+v", opened, archive)
+	}
+	for pubKey, want := range archive.Wallets {
+		got := opened.Wallets[pubKey]
+		if got.Kind != want.Kind || got.EncryptedKey != want.EncryptedKey || !metadataEqual(*got.Metadata, want.Metadata) {
+			t.Errorf("wallet %s = %+v, want %+v", pubKey, got, want)
+		}
+	}
+	if opened.Seeds["seed1"] != archive.Seeds["seed1"] || opened.Configs["bots"] != archive.Configs["bots"] ||
+		opened.Settings["theme"] != "dark" {
+		t.Errorf("Open lost seeds, configs or settings: %+v", opened)
+	}
+}
+
+func TestOpenWrongPassword(t *testing.T) {
+	sealed, err := Seal(testArchive(t), "right")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Open(sealed, "wrong"); !errors.Is(err, keystore.ErrInvalidPassword) {
+		t.Errorf("Open with wrong password = %v, want ErrInvalidPassword", err)
+	}
+	if _, err := Seal(testArchive(t), ""); err == nil {
+		t.Error("Seal accepted an empty password")
+	}
+}
+
+func TestOpenRejectsOtherFormats(t *testing.T) {
+	sealed, err := keystore.Seal([]byte(`{"format":"something-else","version":1}`), "pw")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Open([]byte(sealed), "pw"); err == nil {
+		t.Error("Open accepted an envelope that is not a backup archive")
+	}
+	if _, err := Open([]byte("not an envelope"), "pw"); err == nil {
+		t.Error("Open accepted garbage")
+	}
+}
+
+func TestPlanDefaults(t *testing.T) {
+	s := storage.NewFileWalletStorage(t.TempDir())
+	archive := testArchive(t)
+	if err := s.SaveSeed("seed1", archive.Seeds["seed1"]); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SaveConfig("bots", `{"a":0}`); err != nil {
+		t.Fatal(err)
+	}
+
+	plan, err := Plan(archive, s, map[string]string{})
+	if err != nil {
+		t.Fatalf("Plan: %v", err)
+	}
+	tests := []struct {
+		kind       ItemKind
+		id         string
+		status     Status
+		resolution Resolution
+	}{
+		{KindWallet, "Keypair1", StatusNew, ResolveOverwrite},
+		{KindWallet, "Watch1", StatusNew, ResolveOverwrite},
+		{KindSeed, "seed1", StatusIdentical, ResolveSkip},
+		{KindConfig, "bots", StatusConflict, ResolveSkip},
+		{KindSetting, "theme", StatusNew, ResolveOverwrite},
+	}
+	for _, tt := range tests {
+		item := find(t, plan, tt.kind, tt.id)
+		if item.Status != tt.status || item.Resolution != tt.resolution {
+			t.Errorf("%s %s = %s/%s, want %s/%s", tt.kind, tt.id, item.Status, item.Resolution, tt.status, tt.resolution)
+		}
+	}
+}
+
+// conflicting stores a keypair, a config and a setting that all differ from
+// testArchive, and returns the plan for restoring it.
+func conflicting(t *testing.T, archive *Archive) (storage.WalletStorage, settingsMap, []Item) {
+	t.Helper()
+	s := storage.NewFileWalletStorage(t.TempDir())
+	if err := s.SaveWallet("Keypair1", sealKey(t, "current key")); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SaveMetadata("Keypair1", storage.WalletMetadata{Label: "Current"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SaveConfig("bots", `{"a":0}`); err != nil {
+		t.Fatal(err)
+	}
+	settings := settingsMap{"theme": "light"}
+
+	plan, err := Plan(archive, s, settings)
+	if err != nil {
+		t.Fatalf("Plan: %v", err)
+	}
+	for _, item := range []*Item{find(t, plan, KindWallet, "Keypair1"), find(t, plan, KindConfig, "bots"), find(t, plan, KindSetting, "theme")} {
+		if item.Status != StatusConflict {
+			t.Fatalf("%s %s status = %s, want conflict", item.Kind, item.ID, item.Status)
+		}
+	}
+	return s, settings, plan
+}
+
+// resolve sets the resolution of every conflict in plan.
+func resolve(plan []Item, resolution Resolution) {
+	for i := range plan {
+		if plan[i].Status == StatusConflict {
+			plan[i].Resolution = resolution
+		}
+	}
+}
+
+func TestApplySkip(t *testing.T) {
+	archive := testArchive(t)
+	s, settings, plan := conflicting(t, archive)
+
+	applied, failures, err := Apply(archive, plan, s, settings)
+	if err != nil || len(failures) > 0 {
+		t.Fatalf("Apply: %v, failures %v", err, failures)
+	}
+	for _, item := range applied {
+		if item.Status == StatusConflict {
+			t.Errorf("skipped conflict %s %s reported as applied", item.Kind, item.ID)
+		}
+	}
+
+	wallets, _ := s.LoadWallets()
+	if key, _, _ := keystore.Open(wallets["Keypair1"].EncryptedKey, "master"); string(key) != "current key" {
+		t.Errorf("skip replaced the key with %q", key)
+	}
+	if !wallets["Watch1"].WatchOnly() {
+		t.Error("new watch-only wallet was not restored")
+	}
+	configs, _ := s.LoadConfigs()
+	if configs["bots"] != `{"a":0}` || settings["theme"] != "light" {
+		t.Errorf("skip changed config %q or setting %q", configs["bots"], settings["theme"])
+	}
+}
+
+func TestApplyMerge(t *testing.T) {
+	archive := testArchive(t)
+	s, settings, plan := conflicting(t, archive)
+	resolve(plan, ResolveMerge)
+
+	if _, failures, err := Apply(archive, plan, s, settings); err != nil || len(failures) > 0 {
+		t.Fatalf("Apply: %v, failures %v", err, failures)
+	}
+
+	wallets, _ := s.LoadWallets()
+	if key, _, _ := keystore.Open(wallets["Keypair1"].EncryptedKey, "master"); string(key) != "current key" {
+		t.Errorf("merge replaced the key with %q", key)
+	}
+	metadata, _ := s.LoadMetadata()
+	if meta := metadata["Keypair1"]; meta.Label != "Current" || meta.Color != "#ff0000" || meta.Origin != storage.OriginImported {
+		t.Errorf("merged metadata = %+v, want current label with archived color and origin", meta)
+	}
+	configs, _ := s.LoadConfigs()
+	if configs["bots"] != `{"a":0,"b":2}` {
+		t.Errorf("merged config = %s, want current fields plus missing archived ones", configs["bots"])
+	}
+	if settings["theme"] != "light" {
+		t.Errorf("merge replaced existing setting with %q", settings["theme"])
+	}
+}
+
+func TestApplyOverwrite(t *testing.T) {
+	archive := testArchive(t)
+	s, settings, plan := conflicting(t, archive)
+	resolve(plan, ResolveOverwrite)
+
+	if _, failures, err := Apply(archive, plan, s, settings); err != nil || len(failures) > 0 {
+		t.Fatalf("Apply: %v, failures %v", err, failures)
+	}
+
+	wallets, _ := s.LoadWallets()
+	if key, _, _ := keystore.Open(wallets["Keypair1"].EncryptedKey, "master"); string(key) != "archived key" {
+		t.Errorf("overwrite kept key %q", key)
+	}
+	metadata, _ := s.LoadMetadata()
+	if !metadataEqual(metadata["Keypair1"], archive.Wallets["Keypair1"].Metadata) {
+		t.Errorf("overwritten metadata = %+v", metadata["Keypair1"])
+	}
+	configs, _ := s.LoadConfigs()
+	if configs["bots"] != archive.Configs["bots"] || settings["theme"] != "dark" {
+		t.Errorf("overwrite left config %q and setting %q", configs["bots"], settings["theme"])
+	}
+}
+
+func TestApplyWatchOnlyDoesNotOverwriteKeypair(t *testing.T) {
+	archive := testArchive(t)
+	s := storage.NewFileWalletStorage(t.TempDir())
+	if err := s.SaveWallet("Watch1", sealKey(t, "current key")); err != nil {
+		t.Fatal(err)
+	}
+
+	plan, err := Plan(archive, s, settingsMap{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resolve(plan, ResolveOverwrite)
+	applied, failures, err := Apply(archive, plan, s, settingsMap{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(failures) != 1 || failures[0].Item.ID != "Watch1" {
+		t.Fatalf("failures = %v, want only Watch1", failures)
+	}
+	for _, item := range applied {
+		if item.ID == "Watch1" {
+			t.Error("Watch1 reported as applied")
+		}
+	}
+	wallets, _ := s.LoadWallets()
+	if wallets["Watch1"].WatchOnly() {
+		t.Error("Watch1 lost its key")
+	}
+}
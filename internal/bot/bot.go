@@ -0,0 +1,106 @@
+// Package bot runs the Calypso rebalancer and the conditional order bot
+// without any UI. The GUI screens and the headless daemon drive the same
+// engines and only differ in where the log lines go.
+package bot
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"unruggable-go/internal/session"
+)
+
+// apiClient is used for quote and swap requests.
+var apiClient = &http.Client{Timeout: 30 * time.Second}
+
+// Status is a snapshot of a bot's progress.
+type Status struct {
+	Running   bool      `json:"running"`
+	Cycles    int       `json:"cycles"`
+	LastCycle time.Time `json:"lastCycle,omitempty"`
+	LastError string    `json:"lastError,omitempty"`
+}
+
+// Runner is implemented by both bots.
+type Runner interface {
+	// Run checks on every interval until ctx is cancelled. A cycle in
+	// progress is allowed to finish.
+	Run(ctx context.Context) error
+	Status() Status
+}
+
+// tracker records the Status of a bot.
+type tracker struct {
+	mu     sync.Mutex
+	status Status
+}
+
+func (t *tracker) Status() Status {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.status
+}
+
+func (t *tracker) setRunning(running bool) {
+	t.mu.Lock()
+	t.status.Running = running
+	t.mu.Unlock()
+}
+
+func (t *tracker) recordCycle(err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.status.Cycles++
+	t.status.LastCycle = time.Now()
+	t.status.LastError = ""
+	if err != nil {
+		t.status.LastError = err.Error()
+	}
+}
+
+// loop calls cycle every interval until ctx is done. Errors are logged and
+// recorded but do not stop the bot, except a locked wallet, which loop
+// returns.
+func (t *tracker) loop(ctx context.Context, interval time.Duration, logf func(string), cycle func(context.Context) error) error {
+	t.setRunning(true)
+	defer t.setRunning(false)
+
+	for {
+		err := cycle(ctx)
+		if err != nil {
+			logf(err.Error())
+		}
+		t.recordCycle(err)
+		if errors.Is(err, session.ErrLocked) {
+			// Nothing can be signed until the wallet is unlocked again.
+			return err
+		}
+
+		if !sleep(ctx, interval) {
+			return nil
+		}
+	}
+}
+
+// sleep waits for d and reports false if ctx ended first.
+func sleep(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// logTo returns log, or a no-op if it is nil.
+func logTo(log func(string)) func(string) {
+	if log == nil {
+		return func(string) {}
+	}
+	return log
+}
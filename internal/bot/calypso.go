@@ -0,0 +1,643 @@
+package bot
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/shopspring/decimal"
+
+	"unruggable-go/internal/fee"
+	"unruggable-go/internal/history"
+	"unruggable-go/internal/network"
+	"unruggable-go/internal/price"
+	"unruggable-go/internal/session"
+	"unruggable-go/internal/transfer"
+)
+
+// Asset is a token managed by the Calypso rebalancer.
+type Asset struct {
+	Mint       string
+	Decimals   int
+	Allocation decimal.Decimal
+}
+
+// DefaultCalypsoAssets returns the assets Calypso trades with their default
+// target allocations.
+func DefaultCalypsoAssets() map[string]Asset {
+	return map[string]Asset{
+		"USDC": {"EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v", 6, decimal.NewFromFloat(0.2)},
+		"JTO":  {"jtojtomepa8beP8AuQc6eXt5FriJwfFMwQx2v2f9mCL", 9, decimal.NewFromFloat(0.0)},
+		"SOL":  {"So11111111111111111111111111111111111111112", 9, decimal.NewFromFloat(0.4)},
+		"JUP":  {"JUPyiwrYJFskUPiHa7hkeR8VUtAeFoSYbKedZNsDvCN", 6, decimal.NewFromFloat(0.1)},
+		"JLP":  {"27G8MtK7VtTcCHkpASjSDdkWWYfoqT6ggEuKidVJidD4", 6, decimal.NewFromFloat(0.3)},
+	}
+}
+
+// Calypso defaults.
+const (
+	CalypsoCheckInterval = 60 // seconds
+	CalypsoStashAddress  = "StAshdD7TkoNrWqsrbPTwRjCdqaCfMgfVCwKpvaGhuC"
+)
+
+var (
+	CalypsoRebalanceThreshold = decimal.NewFromFloat(0.0042)
+	CalypsoStashThreshold     = decimal.NewFromFloat(10)
+	CalypsoStashAmount        = decimal.NewFromFloat(1)
+)
+
+// minTrade is the smallest rebalance amount worth a swap.
+var minTrade = decimal.NewFromFloat(0.01)
+
+// calypsoBaseline names the stash baseline in the history store.
+const calypsoBaseline = "calypso"
+
+// CalypsoConfig is the user-editable part of the rebalancer. The GUI saves it
+// under the "calypso" config name and the daemon reads it from its config file.
+type CalypsoConfig struct {
+	CheckInterval      int                        `json:"checkInterval"`
+	RebalanceThreshold decimal.Decimal            `json:"rebalanceThreshold"`
+	StashThreshold     decimal.Decimal            `json:"stashThreshold"`
+	StashAmount        decimal.Decimal            `json:"stashAmount"`
+	StashAddress       string                     `json:"stashAddress,omitempty"`
+	Allocations        map[string]decimal.Decimal `json:"allocations"`
+	// Prices selects the price sources trusted for rebalancing.
+	Prices price.Config `json:"prices,omitempty"`
+}
+
+// DefaultCalypsoConfig returns the settings a new installation starts with.
+func DefaultCalypsoConfig() CalypsoConfig {
+	config := CalypsoConfig{
+		CheckInterval:      CalypsoCheckInterval,
+		RebalanceThreshold: CalypsoRebalanceThreshold,
+		StashThreshold:     CalypsoStashThreshold,
+		StashAmount:        CalypsoStashAmount,
+		Allocations:        make(map[string]decimal.Decimal),
+	}
+	for asset, details := range DefaultCalypsoAssets() {
+		config.Allocations[asset] = details.Allocation
+	}
+	return config
+}
+
+// Validate checks that the allocations are known assets and add up to 100%.
+func (c CalypsoConfig) Validate() error {
+	assets := DefaultCalypsoAssets()
+	total := decimal.Zero
+	for asset, allocation := range c.Allocations {
+		if _, ok := assets[asset]; !ok {
+			return fmt.Errorf("unknown asset %q", asset)
+		}
+		if allocation.IsNegative() {
+			return fmt.Errorf("allocation of %s is negative", asset)
+		}
+		total = total.Add(allocation)
+	}
+	if !total.Equal(decimal.NewFromInt(1)) {
+		return fmt.Errorf("allocations add up to %s%%, must be 100%%", total.Mul(decimal.NewFromInt(100)).String())
+	}
+	if c.CheckInterval < 0 {
+		return errors.New("check interval cannot be negative")
+	}
+	if c.StashAddress != "" {
+		if _, err := solana.PublicKeyFromBase58(c.StashAddress); err != nil {
+			return fmt.Errorf("invalid stash address: %v", err)
+		}
+	}
+	return c.Prices.Validate()
+}
+
+// Calypso keeps a wallet at its target allocations, rebalancing through
+// Jupiter and stashing profits once the portfolio moves by StashThreshold.
+type Calypso struct {
+	Config  CalypsoConfig
+	Network network.Profile
+	Client  *rpc.Client
+	// RPC carries the DAS requests the solana-go client does not cover.
+	RPC    *http.Client
+	Signer *session.Signer
+	Log    func(string)
+	// OnSubmit, if set, is told about every bundle submission.
+	OnSubmit func(bundleID string, err error)
+	// History, if set, keeps the stash baseline across restarts. Without it
+	// the baseline is the portfolio value of the first cycle.
+	History *history.Store
+	// Fee prices the compute units of the swaps.
+	Fee fee.Strategy
+
+	tracker
+	assets                map[string]Asset
+	prices                price.Source
+	lastStashValue        *decimal.Decimal
+	initialPortfolioValue *decimal.Decimal
+}
+
+// Run rebalances every Config.CheckInterval seconds until ctx is cancelled.
+func (c *Calypso) Run(ctx context.Context) error {
+	if err := c.Config.Validate(); err != nil {
+		return err
+	}
+	c.assets = DefaultCalypsoAssets()
+	for asset, details := range c.assets {
+		details.Allocation = c.Config.Allocations[asset]
+		c.assets[asset] = details
+	}
+	if c.RPC == nil {
+		c.RPC = http.DefaultClient
+	}
+	if _, err := c.Network.DASEndpoint(); err != nil {
+		return fmt.Errorf("Calypso reads balances through DAS: %v", err)
+	}
+	prices, err := c.Config.Prices.Build(c.Network, c.Client)
+	if err != nil {
+		return err
+	}
+	prices.Log = c.log
+	c.prices = prices
+
+	interval := time.Duration(c.Config.CheckInterval) * time.Second
+	if interval <= 0 {
+		interval = CalypsoCheckInterval * time.Second
+	}
+	c.log(fmt.Sprintf("Bot started on %s.", c.Network.Name))
+	defer c.log("Bot stopped.")
+	return c.loop(ctx, interval, c.log, c.cycle)
+}
+
+func (c *Calypso) log(message string) {
+	logTo(c.Log)(message)
+}
+
+func (c *Calypso) cycle(ctx context.Context) error {
+	c.log("Starting portfolio check...")
+
+	if c.Signer == nil {
+		return errors.New("no wallet loaded; select a wallet and unlock it before starting the bot")
+	}
+	if !c.Signer.Unlocked() {
+		return fmt.Errorf("%w; unlock it again to resume trading", session.ErrLocked)
+	}
+	walletAddress := c.Signer.PublicKey().String()
+	c.log(fmt.Sprintf("Wallet address: %s", walletAddress))
+
+	balances, err := c.getWalletBalances(ctx, walletAddress)
+	if err != nil {
+		return fmt.Errorf("failed to get wallet balances: %v", err)
+	}
+	prices, err := c.getPrices(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get prices: %v", err)
+	}
+
+	totalValue := c.portfolioValue(balances, prices)
+	c.log(fmt.Sprintf("Total portfolio value: $%s", totalValue.StringFixed(2)))
+	if totalValue.IsZero() {
+		return errors.New("portfolio is empty")
+	}
+
+	if c.initialPortfolioValue == nil {
+		c.restoreBaseline(walletAddress)
+	}
+	if c.initialPortfolioValue == nil {
+		c.initialPortfolioValue = &totalValue
+		c.log(fmt.Sprintf("Initialized initial portfolio value to: $%s", totalValue.StringFixed(2)))
+		c.saveBaseline(walletAddress, totalValue)
+	}
+	delta := totalValue.Sub(*c.initialPortfolioValue)
+	c.log(fmt.Sprintf("Current DELTA: $%s", delta.StringFixed(2)))
+
+	c.printPortfolio(balances, prices, totalValue)
+	rebalanceAmounts := c.rebalanceAmounts(balances, prices, totalValue)
+
+	// Check for stashing first, independent of rebalancing needs
+	var opErr error
+	switch {
+	case c.lastStashValue != nil && delta.Abs().GreaterThanOrEqual(c.Config.StashThreshold):
+		c.log("Stashing threshold reached. Executing stash operation.")
+		opErr = c.stashAndRebalance(ctx, rebalanceAmounts, prices, delta)
+	case c.needsRebalance(balances, prices, totalValue):
+		c.log("Rebalancing needed. Executing rebalance operation.")
+		opErr = c.rebalance(ctx, rebalanceAmounts, prices)
+	default:
+		c.log("Portfolio is balanced and no stashing needed.")
+	}
+
+	if c.lastStashValue == nil {
+		c.lastStashValue = &totalValue
+		c.log(fmt.Sprintf("Initialized last stash value to: $%s", totalValue.StringFixed(2)))
+	}
+	return opErr
+}
+
+// dasAssetsResponse is the part of a getAssetsByOwner response Calypso reads.
+type dasAssetsResponse struct {
+	Result struct {
+		Items []struct {
+			TokenInfo struct {
+				Symbol   string          `json:"symbol"`
+				Decimals int             `json:"decimals"`
+				Balance  json.RawMessage `json:"balance"`
+			} `json:"token_info"`
+		} `json:"items"`
+		NativeBalance struct {
+			Lamports int64 `json:"lamports"`
+		} `json:"nativeBalance"`
+	} `json:"result"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// getWalletBalances returns the wallet's balances by token symbol.
+func (c *Calypso) getWalletBalances(ctx context.Context, walletAddress string) (map[string]decimal.Decimal, error) {
+	endpoint, err := c.Network.DASEndpoint()
+	if err != nil {
+		return nil, err
+	}
+	requestBody, err := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      "calypso",
+		"method":  "getAssetsByOwner",
+		"params": map[string]interface{}{
+			"ownerAddress": walletAddress,
+			"page":         1,
+			"limit":        1000,
+			"displayOptions": map[string]bool{
+				"showFungible":      true,
+				"showNativeBalance": true,
+			},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %v", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(requestBody))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := c.RPC.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	bodyBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %v", err)
+	}
+	var response dasAssetsResponse
+	if err := json.Unmarshal(bodyBytes, &response); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %v", err)
+	}
+	if response.Error != nil {
+		return nil, fmt.Errorf("RPC error: %s", response.Error.Message)
+	}
+
+	balances := make(map[string]decimal.Decimal)
+	for _, item := range response.Result.Items {
+		info := item.TokenInfo
+		var balance decimal.Decimal
+		var balanceStr string
+		if err := json.Unmarshal(info.Balance, &balanceStr); err == nil {
+			if balance, err = decimal.NewFromString(balanceStr); err != nil {
+				c.log(fmt.Sprintf("Failed to parse balance for %s: %v", info.Symbol, err))
+				continue
+			}
+		} else {
+			var balanceNum float64
+			if err := json.Unmarshal(info.Balance, &balanceNum); err != nil {
+				c.log(fmt.Sprintf("Failed to parse balance for %s: %v", info.Symbol, err))
+				continue
+			}
+			balance = decimal.NewFromFloat(balanceNum)
+		}
+		balances[info.Symbol] = balance.Div(decimal.New(1, int32(info.Decimals)))
+	}
+
+	balances["SOL"] = decimal.NewFromInt(response.Result.NativeBalance.Lamports).Div(decimal.New(1, 9))
+	return balances, nil
+}
+
+// getPrices returns USD prices by symbol from the trusted price sources.
+// USDC is taken as $1.
+func (c *Calypso) getPrices(ctx context.Context) (map[string]decimal.Decimal, error) {
+	var mints []string
+	for asset, details := range c.assets {
+		if asset != "USDC" {
+			mints = append(mints, details.Mint)
+		}
+	}
+	quotes, err := c.prices.Prices(ctx, mints)
+	if err != nil {
+		return nil, err
+	}
+
+	prices := map[string]decimal.Decimal{"USDC": decimal.NewFromInt(1)}
+	for asset, details := range c.assets {
+		if asset == "USDC" {
+			continue
+		}
+		quote, ok := quotes[details.Mint]
+		if !ok {
+			return nil, fmt.Errorf("no price for %s", asset)
+		}
+		prices[asset] = decimal.NewFromFloat(quote.Price)
+	}
+	return prices, nil
+}
+
+// portfolioValue returns the USD value of the managed assets.
+func (c *Calypso) portfolioValue(balances, prices map[string]decimal.Decimal) decimal.Decimal {
+	total := decimal.Zero
+	for asset := range c.assets {
+		total = total.Add(balances[asset].Mul(prices[asset]))
+	}
+	return total
+}
+
+// rebalanceAmounts returns how much of each asset to buy (positive) or sell
+// (negative) to reach the targets.
+func (c *Calypso) rebalanceAmounts(balances, prices map[string]decimal.Decimal, totalValue decimal.Decimal) map[string]decimal.Decimal {
+	amounts := make(map[string]decimal.Decimal)
+	for asset, details := range c.assets {
+		targetAmount := totalValue.Mul(details.Allocation).Div(prices[asset])
+		amounts[asset] = targetAmount.Sub(balances[asset]).Round(6)
+	}
+	return amounts
+}
+
+func (c *Calypso) needsRebalance(balances, prices map[string]decimal.Decimal, totalValue decimal.Decimal) bool {
+	for asset, details := range c.assets {
+		current := balances[asset].Mul(prices[asset]).Div(totalValue)
+		if current.Sub(details.Allocation).Abs().GreaterThan(c.Config.RebalanceThreshold) {
+			return true
+		}
+	}
+	return false
+}
+
+// trade is one swap against USDC.
+type trade struct {
+	From, To             string
+	Value                decimal.Decimal // in USD
+	FromAmount, ToAmount decimal.Decimal
+}
+
+func (c *Calypso) trades(rebalanceAmounts, prices map[string]decimal.Decimal) []trade {
+	var trades []trade
+	for _, asset := range sortedKeys(rebalanceAmounts) {
+		amount := rebalanceAmounts[asset]
+		if asset == "USDC" || amount.Abs().LessThanOrEqual(minTrade) {
+			continue
+		}
+		value := amount.Abs().Mul(prices[asset])
+		if amount.IsPositive() {
+			trades = append(trades, trade{From: "USDC", To: asset, Value: value, FromAmount: value, ToAmount: amount})
+		} else {
+			trades = append(trades, trade{From: asset, To: "USDC", Value: value, FromAmount: amount.Abs(), ToAmount: value})
+		}
+	}
+	return trades
+}
+
+func (c *Calypso) printTrades(trades []trade) {
+	c.log("Executing the following trades:")
+	c.log(strings.Repeat("-", 70))
+	c.log("From   To     From Amount      To Amount      Value ($)")
+	c.log(strings.Repeat("-", 70))
+	for _, t := range trades {
+		c.log(fmt.Sprintf("%-6s %-6s %15s %15s %12s",
+			t.From, t.To,
+			t.FromAmount.StringFixed(6),
+			t.ToAmount.StringFixed(6),
+			t.Value.StringFixed(2)))
+	}
+	c.log(strings.Repeat("-", 70))
+}
+
+func (c *Calypso) printPortfolio(balances, prices map[string]decimal.Decimal, totalValue decimal.Decimal) {
+	hundred := decimal.NewFromInt(100)
+	c.log("Current Portfolio:")
+	c.log("Asset  Balance      Value ($)   Allocation  Target")
+	c.log(strings.Repeat("-", 57))
+	for _, asset := range sortedKeys(c.assets) {
+		balance := balances[asset]
+		value := balance.Mul(prices[asset])
+		c.log(fmt.Sprintf("%-6s %12s %12s %11s%% %8s%%",
+			asset,
+			balance.StringFixed(3),
+			value.StringFixed(2),
+			value.Div(totalValue).Mul(hundred).StringFixed(2),
+			c.assets[asset].Allocation.Mul(hundred).StringFixed(2)))
+	}
+	c.log(strings.Repeat("-", 57))
+	c.log(fmt.Sprintf("%-6s %12s %12s %11s %8s", "Total", "", totalValue.StringFixed(2), "100.00%", "100.00%"))
+}
+
+func (c *Calypso) stashAndRebalance(ctx context.Context, rebalanceAmounts, prices map[string]decimal.Decimal, delta decimal.Decimal) error {
+	stashAmount := c.Config.StashAmount
+	doubleStash := delta.GreaterThanOrEqual(c.Config.StashThreshold.Mul(decimal.NewFromInt(2)))
+	if doubleStash {
+		stashAmount = stashAmount.Mul(decimal.NewFromInt(2))
+		c.log("Double stash threshold reached.")
+	}
+	c.log(fmt.Sprintf("Stashing $%s USDC to %s", stashAmount.String(), c.stashAddress()))
+
+	if trades := c.trades(rebalanceAmounts, prices); len(trades) > 0 {
+		c.printTrades(trades)
+	} else {
+		c.log("No trades needed for rebalancing after stash.")
+	}
+
+	stashTx, err := transfer.BuildTip(ctx, c.Client, c.Signer)
+	if err != nil {
+		return fmt.Errorf("failed to create stash transaction: %v", err)
+	}
+	swaps, err := c.swapTransactions(ctx, rebalanceAmounts, prices)
+	if err != nil {
+		return fmt.Errorf("failed to create rebalance transactions: %v", err)
+	}
+	if err := c.submit(ctx, append(swaps, stashTx)); err != nil {
+		return err
+	}
+	c.log(fmt.Sprintf("Stashed $%s to %s", stashAmount.String(), c.stashAddress()))
+	c.log(fmt.Sprintf("Processed %d swap(s) and 1 stash transaction.", len(swaps)))
+
+	c.verify(ctx, doubleStash)
+	return nil
+}
+
+func (c *Calypso) rebalance(ctx context.Context, rebalanceAmounts, prices map[string]decimal.Decimal) error {
+	trades := c.trades(rebalanceAmounts, prices)
+	if len(trades) == 0 {
+		c.log("No trades needed for rebalancing.")
+		return nil
+	}
+	c.printTrades(trades)
+
+	swaps, err := c.swapTransactions(ctx, rebalanceAmounts, prices)
+	if err != nil {
+		return fmt.Errorf("failed to create rebalance transactions: %v", err)
+	}
+	tipTx, err := transfer.BuildTip(ctx, c.Client, c.Signer)
+	if err != nil {
+		return fmt.Errorf("failed to create tip transaction: %v", err)
+	}
+	if err := c.submit(ctx, append(swaps, tipTx)); err != nil {
+		return err
+	}
+	c.log(fmt.Sprintf("Processed %d swap(s) and 1 tip transaction.", len(swaps)))
+
+	c.verify(ctx, false)
+	return nil
+}
+
+func (c *Calypso) stashAddress() string {
+	if c.Config.StashAddress != "" {
+		return c.Config.StashAddress
+	}
+	return CalypsoStashAddress
+}
+
+// submit sends txs as one Jito bundle.
+func (c *Calypso) submit(ctx context.Context, txs []*solana.Transaction) error {
+	if c.Network.JitoURL == "" {
+		return fmt.Errorf("Jito bundles are not available on network %s", c.Network.Name)
+	}
+	bundleID, err := transfer.SendBundle(ctx, c.Network.JitoURL, txs)
+	if c.OnSubmit != nil {
+		c.OnSubmit(bundleID, err)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to send transaction bundle: %v", err)
+	}
+	c.log(fmt.Sprintf("Bundle submitted with ID: %s", bundleID))
+	return nil
+}
+
+func (c *Calypso) swapTransactions(ctx context.Context, rebalanceAmounts, prices map[string]decimal.Decimal) ([]*solana.Transaction, error) {
+	var txs []*solana.Transaction
+	for _, asset := range sortedKeys(rebalanceAmounts) {
+		amount := rebalanceAmounts[asset]
+		if asset == "USDC" || amount.Abs().LessThanOrEqual(minTrade) {
+			continue
+		}
+		var tx *solana.Transaction
+		var err error
+		if amount.IsPositive() {
+			tx, err = c.swapTransaction(ctx, "USDC", asset, amount.Mul(prices[asset]).Round(6))
+		} else {
+			tx, err = c.swapTransaction(ctx, asset, "USDC", amount.Abs())
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to create swap transaction for %s: %v", asset, err)
+		}
+		txs = append(txs, tx)
+	}
+	return txs, nil
+}
+
+func (c *Calypso) swapTransaction(ctx context.Context, inputAsset, outputAsset string, amount decimal.Decimal) (*solana.Transaction, error) {
+	input, output := c.assets[inputAsset], c.assets[outputAsset]
+	baseUnits := amount.Mul(decimal.New(1, int32(input.Decimals))).IntPart()
+	c.log(fmt.Sprintf("Swapping %s %s to %s...", amount.String(), inputAsset, outputAsset))
+
+	swap, err := getSwapInstructions(ctx, c.Network, c.Signer.PublicKey(), input.Mint, output.Mint, baseUnits, swapOptions{
+		SlippageBps:      100,
+		DirectRoutesOnly: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	tx, budget, err := buildSwapTransaction(ctx, c.Client, c.Signer, swap, c.Fee)
+	if err != nil {
+		return nil, err
+	}
+	c.log(budget.String())
+	logSimulation(ctx, c.Client, tx, c.Signer.PublicKey(), c.log)
+	return tx, nil
+}
+
+// verify re-reads the portfolio after an operation and resets the stash
+// baseline if it landed.
+func (c *Calypso) verify(ctx context.Context, doubleStash bool) {
+	c.log("Waiting for 15 seconds before verifying the transactions...")
+	if !sleep(ctx, 15*time.Second) {
+		c.log("Shutting down before the transactions could be verified.")
+		return
+	}
+
+	balances, err := c.getWalletBalances(ctx, c.Signer.PublicKey().String())
+	if err != nil {
+		c.log(fmt.Sprintf("Failed to get updated balances: %v", err))
+		return
+	}
+	prices, err := c.getPrices(ctx)
+	if err != nil {
+		c.log(fmt.Sprintf("Failed to get updated prices: %v", err))
+		return
+	}
+
+	total := c.portfolioValue(balances, prices)
+	c.log("Updated portfolio after operation:")
+	c.printPortfolio(balances, prices, total)
+
+	if c.needsRebalance(balances, prices, total) {
+		c.log("Operation may not have been fully successful. Please check the updated portfolio.")
+		return
+	}
+	c.log("Operation was successful.")
+	c.lastStashValue = &total
+	c.initialPortfolioValue = &total
+	c.log(fmt.Sprintf("Reset stash baseline to: $%s", total.StringFixed(2)))
+	c.saveBaseline(c.Signer.PublicKey().String(), total)
+	if doubleStash {
+		c.log("Double stash completed.")
+	}
+}
+
+// restoreBaseline resumes from the stash baseline saved by a previous run.
+func (c *Calypso) restoreBaseline(walletAddress string) {
+	if c.History == nil {
+		return
+	}
+	baseline, ok := c.History.Baseline(c.Network.Name, walletAddress, calypsoBaseline)
+	if !ok {
+		return
+	}
+	value := decimal.NewFromFloat(baseline.Value)
+	c.initialPortfolioValue = &value
+	c.lastStashValue = &value
+	c.log(fmt.Sprintf("Restored stash baseline of $%s from %s", value.StringFixed(2), baseline.Time.Local().Format(time.RFC822)))
+}
+
+// saveBaseline keeps value as the stash baseline for the next run.
+func (c *Calypso) saveBaseline(walletAddress string, value decimal.Decimal) {
+	if c.History == nil {
+		return
+	}
+	if err := c.History.SetBaseline(c.Network.Name, walletAddress, calypsoBaseline, value.InexactFloat64()); err != nil {
+		c.log(fmt.Sprintf("Failed to save stash baseline: %v", err))
+	}
+}
+
+// sortedKeys returns the keys of m in order, for stable logs and trades.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
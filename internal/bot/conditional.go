@@ -0,0 +1,454 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/programs/system"
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/shopspring/decimal"
+
+	"unruggable-go/internal/fee"
+	"unruggable-go/internal/network"
+	"unruggable-go/internal/price"
+	"unruggable-go/internal/session"
+	"unruggable-go/internal/transfer"
+)
+
+// ConditionalCheckInterval is how often conditions are checked by default.
+const ConditionalCheckInterval = 60 * time.Second
+
+// ConditionOperators are the comparisons a condition can use. Their short
+// forms (">", "<", "=", ">=", "<=") are accepted too.
+var ConditionOperators = []string{"Greater Than (>)", "Less Than (<)", "Equal To (=)", "Greater Than or Equal (>=)", "Less Than or Equal (<=)"}
+
+// ActionTypes are the actions a triggered condition can take.
+var ActionTypes = []string{"Buy", "Sell", "Send"}
+
+// PriceAsset is an asset whose price can be monitored.
+type PriceAsset struct {
+	Symbol    string
+	TokenMint string
+	Decimals  int
+}
+
+// TradingPair is a base/quote pair a condition can trade.
+type TradingPair struct {
+	BaseSymbol    string
+	QuoteSymbol   string
+	BaseMint      string
+	QuoteMint     string
+	BaseDecimals  int
+	QuoteDecimals int
+}
+
+// MonitoredAssets are the assets conditions can watch.
+var MonitoredAssets = map[string]PriceAsset{
+	"SOL":  {"SOL", "So11111111111111111111111111111111111111112", 9},
+	"JUP":  {"JUP", "JUPyiwrYJFskUPiHa7hkeR8VUtAeFoSYbKedZNsDvCN", 6},
+	"JTO":  {"JTO", "jtojtomepa8beP8AuQc6eXt5FriJwfFMwQx2v2f9mCL", 9},
+	"USDC": {"USDC", "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v", 6},
+}
+
+// TradingPairs are the pairs conditions can trade.
+var TradingPairs = map[string]TradingPair{
+	"SOL/USDC": {"SOL", "USDC", "So11111111111111111111111111111111111111112", "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v", 9, 6},
+	"JUP/USDC": {"JUP", "USDC", "JUPyiwrYJFskUPiHa7hkeR8VUtAeFoSYbKedZNsDvCN", "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v", 6, 6},
+	"JTO/USDC": {"JTO", "USDC", "jtojtomepa8beP8AuQc6eXt5FriJwfFMwQx2v2f9mCL", "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v", 9, 6},
+	"USDC/SOL": {"USDC", "SOL", "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v", "So11111111111111111111111111111111111111112", 6, 9},
+	"USDC/JUP": {"USDC", "JUP", "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v", "JUPyiwrYJFskUPiHa7hkeR8VUtAeFoSYbKedZNsDvCN", 6, 6},
+	"USDC/JTO": {"USDC", "JTO", "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v", "jtojtomepa8beP8AuQc6eXt5FriJwfFMwQx2v2f9mCL", 6, 9},
+}
+
+// PriceCondition triggers when the price of Asset compares to Price.
+type PriceCondition struct {
+	Asset     string
+	Operator  string
+	Price     decimal.Decimal
+	Triggered bool
+}
+
+// TradeAction is what happens when the condition triggers.
+type TradeAction struct {
+	Type     string
+	Pair     string
+	Amount   decimal.Decimal
+	Executed bool
+}
+
+// ConditionalTrade pairs a condition with its action.
+type ConditionalTrade struct {
+	ID         string
+	Condition  PriceCondition
+	Action     TradeAction
+	Active     bool
+	CreatedAt  time.Time
+	ExecutedAt *time.Time
+}
+
+// Validate checks that the trade refers to known assets, pairs and operators.
+func (t *ConditionalTrade) Validate() error {
+	if _, ok := MonitoredAssets[t.Condition.Asset]; !ok {
+		return fmt.Errorf("trade %s: unknown asset %q", t.ID, t.Condition.Asset)
+	}
+	if !isShortOperator(ShortOperator(t.Condition.Operator)) {
+		return fmt.Errorf("trade %s: unknown operator %q", t.ID, t.Condition.Operator)
+	}
+	switch t.Action.Type {
+	case "Buy", "Sell":
+		if _, ok := TradingPairs[t.Action.Pair]; !ok {
+			return fmt.Errorf("trade %s: unknown trading pair %q", t.ID, t.Action.Pair)
+		}
+	case "Send":
+	default:
+		return fmt.Errorf("trade %s: unknown action %q", t.ID, t.Action.Type)
+	}
+	if !t.Action.Amount.IsPositive() {
+		return fmt.Errorf("trade %s: amount must be positive", t.ID)
+	}
+	return nil
+}
+
+// ShortOperator converts an operator's display text to its symbol.
+func ShortOperator(operator string) string {
+	switch operator {
+	case "Greater Than (>)":
+		return ">"
+	case "Less Than (<)":
+		return "<"
+	case "Equal To (=)":
+		return "="
+	case "Greater Than or Equal (>=)":
+		return ">="
+	case "Less Than or Equal (<=)":
+		return "<="
+	default:
+		return operator
+	}
+}
+
+func isShortOperator(operator string) bool {
+	switch operator {
+	case ">", "<", "=", ">=", "<=":
+		return true
+	}
+	return false
+}
+
+// Conditional watches prices and runs each trade's action once its
+// condition is met.
+type Conditional struct {
+	Network  network.Profile
+	Client   *rpc.Client
+	Signer   *session.Signer
+	Interval time.Duration // ConditionalCheckInterval if zero
+	// Prices selects the price sources conditions are checked against.
+	Prices price.Config
+	// Fee prices the compute units of the swaps.
+	Fee fee.Strategy
+	Log func(string)
+	// OnSubmit, if set, is told about every bundle or transaction sent.
+	OnSubmit func(id string, err error)
+	// OnChange, if set, is called after a trade was triggered or executed so
+	// the caller can save and redisplay the trades.
+	OnChange func()
+
+	tracker
+	mu     sync.Mutex
+	trades []*ConditionalTrade
+	prices price.Source
+}
+
+// SetTrades replaces the trades.
+func (c *Conditional) SetTrades(trades []*ConditionalTrade) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.trades = append([]*ConditionalTrade(nil), trades...)
+}
+
+// Trades returns the trades in the order they were added.
+func (c *Conditional) Trades() []*ConditionalTrade {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]*ConditionalTrade(nil), c.trades...)
+}
+
+// Add appends trade. It takes effect on the next check.
+func (c *Conditional) Add(trade *ConditionalTrade) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.trades = append(c.trades, trade)
+}
+
+// Remove deletes the trade with the given ID.
+func (c *Conditional) Remove(id string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i, trade := range c.trades {
+		if trade.ID == id {
+			c.trades = append(c.trades[:i], c.trades[i+1:]...)
+			return
+		}
+	}
+}
+
+// Run checks the conditions every Interval until ctx is cancelled.
+func (c *Conditional) Run(ctx context.Context) error {
+	if c.Signer == nil || !c.Signer.Unlocked() {
+		return fmt.Errorf("no unlocked wallet")
+	}
+	prices, err := c.Prices.Build(c.Network, c.Client)
+	if err != nil {
+		return err
+	}
+	prices.Log = c.log
+	c.prices = prices
+
+	interval := c.Interval
+	if interval <= 0 {
+		interval = ConditionalCheckInterval
+	}
+	c.log(fmt.Sprintf("Bot started on %s. Monitoring price conditions...", c.Network.Name))
+	defer c.log("Bot stopped.")
+	return c.loop(ctx, interval, c.log, c.checkConditions)
+}
+
+func (c *Conditional) log(message string) {
+	logTo(c.Log)(message)
+}
+
+func (c *Conditional) changed() {
+	if c.OnChange != nil {
+		c.OnChange()
+	}
+}
+
+// checkConditions runs the actions of every pending trade whose condition
+// holds at the current prices.
+func (c *Conditional) checkConditions(ctx context.Context) error {
+	if !c.Signer.Unlocked() {
+		return fmt.Errorf("%w; unlock it again to resume trading", session.ErrLocked)
+	}
+
+	prices, err := c.getPrices(ctx)
+	if err != nil {
+		return fmt.Errorf("error fetching prices: %v", err)
+	}
+	var quotes []string
+	for _, asset := range sortedKeys(prices) {
+		quotes = append(quotes, fmt.Sprintf("%s $%s", asset, prices[asset].String()))
+	}
+	c.log("Current prices: " + strings.Join(quotes, ", "))
+
+	for _, trade := range c.Trades() {
+		if !trade.Active || trade.Action.Executed || trade.Condition.Triggered {
+			continue
+		}
+		price, ok := prices[trade.Condition.Asset]
+		if !ok {
+			c.log(fmt.Sprintf("No price data available for %s", trade.Condition.Asset))
+			continue
+		}
+		if !evaluateCondition(trade.Condition, price) {
+			continue
+		}
+
+		c.log(fmt.Sprintf("Condition triggered for %s: %s price %s $%s (Current: $%s)",
+			trade.ID,
+			trade.Condition.Asset,
+			ShortOperator(trade.Condition.Operator),
+			trade.Condition.Price.String(),
+			price.String()))
+		trade.Condition.Triggered = true
+		c.changed()
+
+		if err := c.executeAction(ctx, trade); err != nil {
+			c.log(fmt.Sprintf("Action for condition %s failed: %v", trade.ID, err))
+			continue
+		}
+		trade.Action.Executed = true
+		now := time.Now()
+		trade.ExecutedAt = &now
+		c.log(fmt.Sprintf("Action executed successfully for condition %s", trade.ID))
+		c.changed()
+	}
+	return nil
+}
+
+func evaluateCondition(condition PriceCondition, currentPrice decimal.Decimal) bool {
+	switch ShortOperator(condition.Operator) {
+	case ">":
+		return currentPrice.GreaterThan(condition.Price)
+	case "<":
+		return currentPrice.LessThan(condition.Price)
+	case "=":
+		return currentPrice.Equal(condition.Price)
+	case ">=":
+		return currentPrice.GreaterThanOrEqual(condition.Price)
+	case "<=":
+		return currentPrice.LessThanOrEqual(condition.Price)
+	default:
+		return false
+	}
+}
+
+// executeAction submits the trade's swap, bundled with a tip when Jito is
+// available and as a plain transaction otherwise.
+func (c *Conditional) executeAction(ctx context.Context, trade *ConditionalTrade) error {
+	c.log(fmt.Sprintf("Executing action for condition %s: %s %s %s",
+		trade.ID, trade.Action.Type, trade.Action.Amount.String(), trade.Action.Pair))
+
+	var swapTx *solana.Transaction
+	var err error
+	switch trade.Action.Type {
+	case "Buy", "Sell":
+		swapTx, err = c.swapTransaction(ctx, trade)
+	case "Send":
+		err = fmt.Errorf("send actions are not implemented yet")
+	default:
+		err = fmt.Errorf("unsupported action type: %s", trade.Action.Type)
+	}
+	if err != nil {
+		return err
+	}
+
+	if c.Network.JitoURL != "" {
+		tipTx, err := c.tipTransaction(ctx)
+		if err == nil {
+			bundleID, err := transfer.SendBundle(ctx, c.Network.JitoURL, []*solana.Transaction{swapTx, tipTx})
+			if err == nil {
+				c.submitted(bundleID, nil)
+				c.log(fmt.Sprintf("Bundle sent successfully with ID: %s", bundleID))
+				return nil
+			}
+			c.log(fmt.Sprintf("Failed to send bundle: %v", err))
+		} else {
+			c.log(fmt.Sprintf("Error creating tip transaction: %v", err))
+		}
+		c.log("Falling back to sending the transaction alone...")
+	}
+
+	maxRetries := uint(5)
+	sig, err := c.Client.SendTransactionWithOpts(ctx, swapTx, rpc.TransactionOpts{
+		SkipPreflight:       true,
+		PreflightCommitment: rpc.CommitmentConfirmed,
+		MaxRetries:          &maxRetries,
+	})
+	if err != nil {
+		c.submitted(swapTx.Signatures[0].String(), err)
+		return fmt.Errorf("error sending transaction: %v", err)
+	}
+	c.submitted(sig.String(), nil)
+	c.log(fmt.Sprintf("Transaction sent with signature: %s", sig))
+	return nil
+}
+
+func (c *Conditional) submitted(id string, err error) {
+	if c.OnSubmit != nil {
+		c.OnSubmit(id, err)
+	}
+}
+
+// swapTransaction builds the Jupiter swap of a Buy or Sell action. Buy
+// amounts are in the quote currency and Sell amounts in the base currency.
+func (c *Conditional) swapTransaction(ctx context.Context, trade *ConditionalTrade) (*solana.Transaction, error) {
+	pair, ok := TradingPairs[trade.Action.Pair]
+	if !ok {
+		return nil, fmt.Errorf("unsupported trading pair: %s", trade.Action.Pair)
+	}
+
+	inputMint, outputMint, decimals := pair.QuoteMint, pair.BaseMint, pair.QuoteDecimals
+	if trade.Action.Type == "Sell" {
+		inputMint, outputMint, decimals = pair.BaseMint, pair.QuoteMint, pair.BaseDecimals
+	}
+	amount := trade.Action.Amount.Mul(decimal.New(1, int32(decimals))).IntPart()
+
+	swap, err := getSwapInstructions(ctx, c.Network, c.Signer.PublicKey(), inputMint, outputMint, amount, swapOptions{
+		SlippageBps:      100,
+		NoSharedAccounts: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error getting swap instructions: %v", err)
+	}
+	tx, budget, err := buildSwapTransaction(ctx, c.Client, c.Signer, swap, c.Fee)
+	if err != nil {
+		return nil, err
+	}
+	c.log(budget.String())
+	logSimulation(ctx, c.Client, tx, c.Signer.PublicKey(), c.log)
+	c.log(fmt.Sprintf("Swap transaction signed: %s", tx.Signatures[0]))
+	return tx, nil
+}
+
+// jitoTipAccounts are Jito's tip accounts. One is picked at random per
+// bundle to reduce contention.
+var jitoTipAccounts = []string{
+	"96gYZGLnJYVFmbjzopPSU6QiEV5fGqZNyN9nmNhvrZU5",
+	"Gd6dV4ESSHcQAMWJpZ9mX6mGCQ1jNEKRDz3Q58wp3Wx3",
+	"DttWaMuVvTiduZRnguLF7jNxTgiMBZ1hyAumKUiL2KRL",
+	"4nh6f8rJjbP5eYbCvQ3rd5EVFFmZ4nYvRB34mFAEenHJ",
+	"3AVi9Tg9Uo68tJfuvoKvqKNWKkC5wPdSSdeBnizKzYCN",
+	"EZknHQUZ6YByhGxXia8MKKhu39LzjVV7NLd5xuVwmz7R",
+	"6SCu87GnSgHgxNsUoVjdLnkgJoM5VLUiitKUa7xHKbys",
+	"JC4sWJYkueeRYgwwMvCTi4M311ZWmJKptZ1T8p7vBqKr",
+}
+
+const (
+	conditionalJitoTip       = 5_000_000 // 0.005 SOL, so the bundle gets processed
+	conditionalUnruggableTip = 1_000_000 // 0.001 SOL
+)
+
+// tipTransaction tips a Jito tip account and the Unruggable account.
+func (c *Conditional) tipTransaction(ctx context.Context) (*solana.Transaction, error) {
+	recent, err := c.Client.GetLatestBlockhash(ctx, rpc.CommitmentFinalized)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get latest blockhash: %v", err)
+	}
+
+	payer := c.Signer.PublicKey()
+	tipAccount := solana.MustPublicKeyFromBase58(jitoTipAccounts[rand.Intn(len(jitoTipAccounts))])
+	tx, err := solana.NewTransaction(
+		[]solana.Instruction{
+			system.NewTransferInstruction(conditionalJitoTip, payer, tipAccount).Build(),
+			system.NewTransferInstruction(conditionalUnruggableTip, payer,
+				solana.MustPublicKeyFromBase58("juLesoSmdTcRtzjCzYzRoHrnF8GhVu6KCV7uxq7nJGp")).Build(),
+		},
+		recent.Value.Blockhash,
+		solana.TransactionPayer(payer),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build tip transaction: %v", err)
+	}
+	if err := c.Signer.SignTransaction(tx); err != nil {
+		return nil, fmt.Errorf("failed to sign tip transaction: %v", err)
+	}
+	return tx, nil
+}
+
+// getPrices fetches USD prices of the monitored assets from the trusted
+// price sources. USDC is taken as $1.
+func (c *Conditional) getPrices(ctx context.Context) (map[string]decimal.Decimal, error) {
+	var mints []string
+	for symbol, asset := range MonitoredAssets {
+		if symbol != "USDC" {
+			mints = append(mints, asset.TokenMint)
+		}
+	}
+	quotes, err := c.prices.Prices(ctx, mints)
+	if err != nil {
+		return nil, err
+	}
+
+	prices := map[string]decimal.Decimal{"USDC": decimal.NewFromInt(1)}
+	for symbol, asset := range MonitoredAssets {
+		if quote, ok := quotes[asset.TokenMint]; ok && symbol != "USDC" {
+			prices[symbol] = decimal.NewFromFloat(quote.Price)
+		}
+	}
+	return prices, nil
+}
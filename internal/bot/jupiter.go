@@ -0,0 +1,199 @@
+package bot
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+
+	"unruggable-go/internal/fee"
+	"unruggable-go/internal/network"
+	"unruggable-go/internal/simulate"
+	"unruggable-go/internal/transfer"
+)
+
+// swapOptions tune the Jupiter quote and swap requests.
+type swapOptions struct {
+	SlippageBps      int
+	DirectRoutesOnly bool
+	// NoSharedAccounts avoids Jupiter's shared accounts, which some routes
+	// reject.
+	NoSharedAccounts bool
+}
+
+// swapInstructions is the response of Jupiter's swap-instructions endpoint.
+type swapInstructions struct {
+	Error              string               `json:"error"`
+	SetupInstructions  []jupiterInstruction `json:"setupInstructions"`
+	SwapInstruction    *jupiterInstruction  `json:"swapInstruction"`
+	CleanupInstruction *jupiterInstruction  `json:"cleanupInstruction"`
+}
+
+type jupiterInstruction struct {
+	ProgramID string `json:"programId"`
+	Accounts  []struct {
+		Pubkey     string `json:"pubkey"`
+		IsSigner   bool   `json:"isSigner"`
+		IsWritable bool   `json:"isWritable"`
+	} `json:"accounts"`
+	Data string `json:"data"` // base64
+}
+
+// getSwapInstructions asks Jupiter for the instructions swapping amount base
+// units of inputMint into outputMint for owner.
+func getSwapInstructions(ctx context.Context, profile network.Profile, owner solana.PublicKey, inputMint, outputMint string, amount int64, opts swapOptions) (*swapInstructions, error) {
+	if profile.JupiterQuoteURL == "" || profile.JupiterSwapURL == "" {
+		return nil, fmt.Errorf("no Jupiter endpoints configured for network %s", profile.Name)
+	}
+
+	quoteURL := fmt.Sprintf("%s?inputMint=%s&outputMint=%s&amount=%d&slippageBps=%d&onlyDirectRoutes=%t",
+		profile.JupiterQuoteURL, inputMint, outputMint, amount, opts.SlippageBps, opts.DirectRoutesOnly)
+	var quote map[string]interface{}
+	if err := jupiterRequest(ctx, http.MethodGet, quoteURL, nil, &quote); err != nil {
+		return nil, fmt.Errorf("failed to get Jupiter quote: %v", err)
+	}
+	if msg, ok := quote["error"]; ok {
+		return nil, fmt.Errorf("Jupiter quote error: %v", msg)
+	}
+
+	swapBody := map[string]interface{}{
+		"userPublicKey":    owner.String(),
+		"quoteResponse":    quote,
+		"wrapAndUnwrapSol": true,
+	}
+	if opts.NoSharedAccounts {
+		swapBody["useSharedAccounts"] = false
+	}
+	body, err := json.Marshal(swapBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode swap body: %v", err)
+	}
+
+	var swap swapInstructions
+	if err := jupiterRequest(ctx, http.MethodPost, profile.JupiterSwapURL, body, &swap); err != nil {
+		return nil, fmt.Errorf("failed to get swap instructions: %v", err)
+	}
+	if swap.Error != "" {
+		return nil, fmt.Errorf("Jupiter API error: %s", swap.Error)
+	}
+	if swap.SwapInstruction == nil {
+		return nil, fmt.Errorf("Jupiter returned no swap instruction")
+	}
+	return &swap, nil
+}
+
+func jupiterRequest(ctx context.Context, method, url string, body []byte, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := apiClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %v", err)
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("failed to decode response (status %d): %v", resp.StatusCode, err)
+	}
+	return nil
+}
+
+// buildSwapTransaction assembles and signs a transaction from Jupiter's
+// instructions. Jupiter's compute budget is replaced by one for strategy.
+func buildSwapTransaction(ctx context.Context, client *rpc.Client, signer transfer.Signer, swap *swapInstructions, strategy fee.Strategy) (*solana.Transaction, fee.Budget, error) {
+	var instructions []solana.Instruction
+	add := func(kind string, inst jupiterInstruction) error {
+		built, err := inst.build()
+		if err != nil {
+			return fmt.Errorf("invalid %s instruction: %v", kind, err)
+		}
+		instructions = append(instructions, built)
+		return nil
+	}
+	for _, inst := range swap.SetupInstructions {
+		if err := add("setup", inst); err != nil {
+			return nil, fee.Budget{}, err
+		}
+	}
+	if err := add("swap", *swap.SwapInstruction); err != nil {
+		return nil, fee.Budget{}, err
+	}
+	if swap.CleanupInstruction != nil {
+		if err := add("cleanup", *swap.CleanupInstruction); err != nil {
+			return nil, fee.Budget{}, err
+		}
+	}
+
+	instructions, budget, err := fee.Prepare(ctx, client, strategy, signer.PublicKey(), instructions)
+	if err != nil {
+		return nil, fee.Budget{}, err
+	}
+	tx, err := transfer.NewTransaction(ctx, client, signer.PublicKey(), instructions)
+	if err != nil {
+		return nil, fee.Budget{}, err
+	}
+	if err := signer.SignTransaction(tx); err != nil {
+		return nil, fee.Budget{}, fmt.Errorf("failed to sign transaction: %v", err)
+	}
+	return tx, budget, nil
+}
+
+// logSimulation simulates tx and logs what it would change for owner. The
+// transaction is sent regardless: a swap bundled after another may spend
+// what the earlier one receives, which a lone simulation cannot see.
+func logSimulation(ctx context.Context, client *rpc.Client, tx *solana.Transaction, owner solana.PublicKey, log func(string)) {
+	preview, err := simulate.Run(ctx, client, tx, owner)
+	if err != nil {
+		log(fmt.Sprintf("Simulation unavailable: %v", err))
+		return
+	}
+	log("Simulation: " + preview.String())
+	if preview.Failed() {
+		// The reason is at the end of the program logs.
+		logs := preview.Logs
+		if len(logs) > 10 {
+			logs = logs[len(logs)-10:]
+		}
+		for _, line := range logs {
+			log("  " + line)
+		}
+	}
+}
+
+func (inst jupiterInstruction) build() (solana.Instruction, error) {
+	programID, err := solana.PublicKeyFromBase58(inst.ProgramID)
+	if err != nil {
+		return nil, fmt.Errorf("program id: %v", err)
+	}
+	accounts := make(solana.AccountMetaSlice, len(inst.Accounts))
+	for i, acc := range inst.Accounts {
+		pubkey, err := solana.PublicKeyFromBase58(acc.Pubkey)
+		if err != nil {
+			return nil, fmt.Errorf("account %d: %v", i, err)
+		}
+		accounts[i] = &solana.AccountMeta{
+			PublicKey:  pubkey,
+			IsSigner:   acc.IsSigner,
+			IsWritable: acc.IsWritable,
+		}
+	}
+	data, err := base64.StdEncoding.DecodeString(inst.Data)
+	if err != nil {
+		return nil, fmt.Errorf("data: %v", err)
+	}
+	return solana.NewInstruction(programID, accounts, data), nil
+}
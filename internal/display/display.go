@@ -0,0 +1,33 @@
+// Package display renders addresses and amounts for people to read.
+package display
+
+import (
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// solDecimals is the number of decimal places of SOL.
+const solDecimals = 9
+
+// Address shortens a base58 address or signature to its first and last four
+// characters.
+func Address(s string) string {
+	if len(s) <= 8 {
+		return s
+	}
+	return s[:4] + "..." + s[len(s)-4:]
+}
+
+// Lamports returns lamports as SOL, exactly and without trailing zeros.
+func Lamports(lamports int64) string {
+	return decimal.NewFromInt(lamports).Shift(-solDecimals).String()
+}
+
+// Signed prefixes amount with a plus sign unless it is negative.
+func Signed(amount string) string {
+	if strings.HasPrefix(amount, "-") {
+		return amount
+	}
+	return "+" + amount
+}
@@ -0,0 +1,53 @@
+// Package events is a small typed publish/subscribe bus.
+package events
+
+import "sync"
+
+// Topic delivers events of one type to its subscribers. The zero value is
+// ready to use.
+type Topic[T any] struct {
+	mu     sync.Mutex
+	nextID int
+	subs   []subscriber[T]
+}
+
+type subscriber[T any] struct {
+	id int
+	fn func(T)
+}
+
+// Subscribe registers fn and returns a function that removes it again.
+func (t *Topic[T]) Subscribe(fn func(T)) (unsubscribe func()) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.nextID++
+	id := t.nextID
+	t.subs = append(t.subs, subscriber[T]{id: id, fn: fn})
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			for i, s := range t.subs {
+				if s.id == id {
+					t.subs = append(t.subs[:i:i], t.subs[i+1:]...)
+					return
+				}
+			}
+		})
+	}
+}
+
+// Publish calls every subscriber with event, in subscription order, on the
+// caller's goroutine. Subscribers may subscribe or unsubscribe while handling
+// an event; the change takes effect from the next Publish.
+func (t *Topic[T]) Publish(event T) {
+	t.mu.Lock()
+	subs := append([]subscriber[T](nil), t.subs...)
+	t.mu.Unlock()
+
+	for _, s := range subs {
+		s.fn(event)
+	}
+}
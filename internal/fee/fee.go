@@ -0,0 +1,255 @@
+// Package fee sets the compute budget of transactions: the unit limit is
+// sized by simulating the transaction and the unit price is a percentile of
+// the recent prioritization fees paid for the accounts it writes to.
+package fee
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gagliardetto/solana-go"
+	computebudget "github.com/gagliardetto/solana-go/programs/compute-budget"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+// PreferenceKey is where the GUI stores the chosen strategy, as its String
+// form. The CLI reads the same key.
+const PreferenceKey = "priorityFee"
+
+const (
+	// MaxUnits is the most compute units a transaction can request.
+	MaxUnits = 1_400_000
+	// fallbackUnits are requested per instruction when simulation fails,
+	// matching the runtime's default limit.
+	fallbackUnits = 200_000
+	// MaxMicroLamports caps the unit price, so a spike in recent fees cannot
+	// cost more than about 0.007 SOL for a full-size transaction.
+	MaxMicroLamports = 5_000_000
+	// maxFeeAccounts is how many accounts getRecentPrioritizationFees takes.
+	maxFeeAccounts = 128
+)
+
+// Level names a preset percentile of recent prioritization fees.
+type Level string
+
+const (
+	Low    Level = "low"
+	Medium Level = "medium"
+	High   Level = "high"
+	Custom Level = "custom"
+)
+
+// Levels lists the levels in the order they are offered.
+var Levels = []Level{Low, Medium, High, Custom}
+
+var percentiles = map[Level]int{Low: 25, Medium: 50, High: 75}
+
+// Strategy picks the unit price. The zero value is Medium.
+type Strategy struct {
+	Level Level
+	// Percentile of recent fees to pay, 0-100. Used by Custom only.
+	Percentile int
+}
+
+// Default is the strategy used when none was chosen.
+var Default = Strategy{Level: Medium}
+
+// ParseStrategy reads "low", "medium", "high" or a percentile such as "90".
+// An empty string gives Default.
+func ParseStrategy(s string) (Strategy, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	switch Level(s) {
+	case "":
+		return Default, nil
+	case Low, Medium, High:
+		return Strategy{Level: Level(s)}, nil
+	}
+	p, err := strconv.Atoi(strings.TrimPrefix(s, "p"))
+	if err != nil || p < 0 || p > 100 {
+		return Strategy{}, fmt.Errorf("invalid priority fee %q: use low, medium, high or a percentile from 0 to 100", s)
+	}
+	return Strategy{Level: Custom, Percentile: p}, nil
+}
+
+// String returns the level name, or "pN" for a custom percentile.
+func (s Strategy) String() string {
+	switch s.Level {
+	case "":
+		return string(Medium)
+	case Custom:
+		return fmt.Sprintf("p%d", s.Percentile)
+	}
+	return string(s.Level)
+}
+
+func (s Strategy) percentile() int {
+	if s.Level == Custom {
+		return s.Percentile
+	}
+	if p, ok := percentiles[s.Level]; ok {
+		return p
+	}
+	return percentiles[Medium]
+}
+
+// Budget is the compute budget chosen for a transaction.
+type Budget struct {
+	Strategy      Strategy
+	Units         uint32
+	MicroLamports uint64 // price per compute unit
+	// Simulated is false when the unit limit is a fallback because the
+	// transaction could not be simulated.
+	Simulated bool
+}
+
+// PriorityFee returns the lamports paid on top of the base fee.
+func (b Budget) PriorityFee() uint64 {
+	return (uint64(b.Units)*b.MicroLamports + 999_999) / 1_000_000
+}
+
+// String describes the budget for confirmation dialogs and logs. Budgets read
+// from a transaction have no strategy.
+func (b Budget) String() string {
+	text := fmt.Sprintf("Priority fee: %s SOL (", strconv.FormatFloat(float64(b.PriorityFee())/float64(solana.LAMPORTS_PER_SOL), 'f', -1, 64))
+	if b.Strategy.Level != "" {
+		text += b.Strategy.String() + ", "
+	}
+	text += fmt.Sprintf("%d µlamports/CU × %d CU)", b.MicroLamports, b.Units)
+	if b.Strategy.Level != "" && !b.Simulated {
+		text += ", unit limit not simulated"
+	}
+	return text
+}
+
+// Instructions returns the ComputeBudget instructions setting b.
+func (b Budget) Instructions() []solana.Instruction {
+	return []solana.Instruction{
+		computebudget.NewSetComputeUnitLimitInstruction(b.Units).Build(),
+		computebudget.NewSetComputeUnitPriceInstruction(b.MicroLamports).Build(),
+	}
+}
+
+// Prepare returns instructions, paid by payer, preceded by ComputeBudget
+// instructions for strategy. Compute budget instructions already present,
+// e.g. from Jupiter, are replaced.
+func Prepare(ctx context.Context, client *rpc.Client, strategy Strategy, payer solana.PublicKey, instructions []solana.Instruction) ([]solana.Instruction, Budget, error) {
+	if strategy.Level == "" {
+		strategy = Default
+	}
+	var body []solana.Instruction
+	for _, instruction := range instructions {
+		if !instruction.ProgramID().Equals(solana.ComputeBudget) {
+			body = append(body, instruction)
+		}
+	}
+
+	price, err := EstimatePrice(ctx, client, strategy, writableAccounts(payer, body))
+	if err != nil {
+		return nil, Budget{}, err
+	}
+	budget := Budget{Strategy: strategy, MicroLamports: price}
+
+	units, err := simulateUnits(ctx, client, payer, append(Budget{Units: MaxUnits, MicroLamports: price}.Instructions(), body...))
+	if err != nil {
+		log.Printf("Sizing compute budget: %v", err)
+		budget.Units = uint32(min(fallbackUnits*len(body), MaxUnits))
+	} else {
+		budget.Units = uint32(min(units+units/10, MaxUnits))
+		budget.Simulated = true
+	}
+	return append(budget.Instructions(), body...), budget, nil
+}
+
+// FromTransaction returns the compute budget tx requests. Without a
+// SetComputeUnitLimit instruction the runtime's default limit applies.
+func FromTransaction(tx *solana.Transaction) Budget {
+	var budget Budget
+	limitSet, instructions := false, 0
+	for _, inst := range tx.Message.Instructions {
+		program, err := tx.ResolveProgramIDIndex(inst.ProgramIDIndex)
+		if err != nil || !program.Equals(solana.ComputeBudget) {
+			instructions++
+			continue
+		}
+		data := inst.Data
+		switch {
+		case len(data) >= 5 && data[0] == computebudget.Instruction_SetComputeUnitLimit:
+			budget.Units = binary.LittleEndian.Uint32(data[1:])
+			limitSet = true
+		case len(data) >= 9 && data[0] == computebudget.Instruction_SetComputeUnitPrice:
+			budget.MicroLamports = binary.LittleEndian.Uint64(data[1:])
+		}
+	}
+	if !limitSet {
+		budget.Units = uint32(min(fallbackUnits*instructions, MaxUnits))
+	}
+	return budget
+}
+
+// EstimatePrice returns the unit price in micro-lamports at the strategy's
+// percentile of the fees recently paid for accounts, capped at
+// MaxMicroLamports.
+func EstimatePrice(ctx context.Context, client *rpc.Client, strategy Strategy, accounts []solana.PublicKey) (uint64, error) {
+	if len(accounts) > maxFeeAccounts {
+		accounts = accounts[:maxFeeAccounts]
+	}
+	recent, err := client.GetRecentPrioritizationFees(ctx, accounts)
+	if err != nil {
+		return 0, fmt.Errorf("error getting recent prioritization fees: %v", err)
+	}
+	if len(recent) == 0 {
+		return 0, nil
+	}
+	fees := make([]uint64, len(recent))
+	for i, r := range recent {
+		fees[i] = r.PrioritizationFee
+	}
+	sort.Slice(fees, func(i, j int) bool { return fees[i] < fees[j] })
+	price := fees[(len(fees)-1)*strategy.percentile()/100]
+	return min(price, MaxMicroLamports), nil
+}
+
+// simulateUnits returns the compute units instructions consume.
+func simulateUnits(ctx context.Context, client *rpc.Client, payer solana.PublicKey, instructions []solana.Instruction) (int, error) {
+	// The blockhash is replaced by the node and signatures are not checked,
+	// but their slots must be present.
+	tx, err := solana.NewTransaction(instructions, solana.Hash{}, solana.TransactionPayer(payer))
+	if err != nil {
+		return 0, fmt.Errorf("error creating simulation transaction: %v", err)
+	}
+	tx.Signatures = make([]solana.Signature, tx.Message.Header.NumRequiredSignatures)
+	result, err := client.SimulateTransactionWithOpts(ctx, tx, &rpc.SimulateTransactionOpts{
+		Commitment:             rpc.CommitmentProcessed,
+		ReplaceRecentBlockhash: true,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("error simulating transaction: %v", err)
+	}
+	if result.Value.Err != nil {
+		return 0, fmt.Errorf("simulation failed: %v", result.Value.Err)
+	}
+	if result.Value.UnitsConsumed == nil {
+		return 0, fmt.Errorf("simulation reported no compute units")
+	}
+	return int(*result.Value.UnitsConsumed), nil
+}
+
+// writableAccounts returns payer and every account instructions write to.
+func writableAccounts(payer solana.PublicKey, instructions []solana.Instruction) []solana.PublicKey {
+	seen := map[solana.PublicKey]bool{payer: true}
+	accounts := []solana.PublicKey{payer}
+	for _, instruction := range instructions {
+		for _, meta := range instruction.Accounts() {
+			if meta.IsWritable && !seen[meta.PublicKey] {
+				seen[meta.PublicKey] = true
+				accounts = append(accounts, meta.PublicKey)
+			}
+		}
+	}
+	return accounts
+}
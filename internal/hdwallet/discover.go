@@ -0,0 +1,103 @@
+package hdwallet
+
+import (
+	"context"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+// DefaultGap is how many consecutive unused accounts end a scan.
+const DefaultGap = 5
+
+// Account is a keypair derived from a seed.
+type Account struct {
+	Scheme     PathScheme
+	Index      uint32
+	Path       string
+	PrivateKey solana.PrivateKey
+}
+
+// PublicKey returns the account address.
+func (a Account) PublicKey() solana.PublicKey {
+	return a.PrivateKey.PublicKey()
+}
+
+// ActivityFunc reports whether an address has been used on chain.
+type ActivityFunc func(ctx context.Context, pubKey solana.PublicKey) (bool, error)
+
+// RPCActivity treats an address as used if it has any transaction history or a balance.
+func RPCActivity(client *rpc.Client) ActivityFunc {
+	return func(ctx context.Context, pubKey solana.PublicKey) (bool, error) {
+		limit := 1
+		sigs, err := client.GetSignaturesForAddressWithOpts(ctx, pubKey, &rpc.GetSignaturesForAddressOpts{
+			Limit: &limit,
+		})
+		if err != nil {
+			return false, err
+		}
+		if len(sigs) > 0 {
+			return true, nil
+		}
+
+		balance, err := client.GetBalance(ctx, pubKey, rpc.CommitmentConfirmed)
+		if err != nil {
+			return false, err
+		}
+		return balance.Value > 0, nil
+	}
+}
+
+// DeriveAccount derives account n of scheme.
+func DeriveAccount(seed []byte, scheme PathScheme, n uint32) (Account, error) {
+	path := scheme.Path(n)
+	key, err := Derive(seed, path)
+	if err != nil {
+		return Account{}, err
+	}
+	return Account{Scheme: scheme, Index: n, Path: path, PrivateKey: key}, nil
+}
+
+// Discover derives accounts along each scheme and returns the ones with on-chain
+// activity. Indexed schemes are scanned until gap consecutive unused accounts.
+func Discover(ctx context.Context, seed []byte, schemes []PathScheme, gap int, active ActivityFunc) ([]Account, error) {
+	if gap <= 0 {
+		gap = DefaultGap
+	}
+
+	var found []Account
+	seen := make(map[solana.PublicKey]bool)
+
+	for _, scheme := range schemes {
+		unused := 0
+		for n := uint32(0); unused < gap; n++ {
+			if err := ctx.Err(); err != nil {
+				return found, err
+			}
+
+			account, err := DeriveAccount(seed, scheme, n)
+			if err != nil {
+				return found, err
+			}
+
+			used, err := active(ctx, account.PublicKey())
+			if err != nil {
+				return found, err
+			}
+
+			if used && !seen[account.PublicKey()] {
+				seen[account.PublicKey()] = true
+				found = append(found, account)
+				unused = 0
+			} else {
+				unused++
+			}
+
+			if !scheme.Indexed() {
+				break
+			}
+		}
+	}
+
+	return found, nil
+}
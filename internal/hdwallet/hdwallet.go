@@ -0,0 +1,155 @@
+// Package hdwallet implements BIP39 mnemonics and SLIP-0010 ed25519 key
+// derivation along the Solana derivation paths used by common wallets.
+package hdwallet
+
+import (
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/tyler-smith/go-bip39"
+)
+
+const hardenedOffset = 0x80000000
+
+// PathScheme is a derivation path template. "{n}" is replaced by the account index.
+type PathScheme struct {
+	Name     string
+	Template string
+}
+
+// Path returns the concrete derivation path for account index n.
+func (p PathScheme) Path(n uint32) string {
+	return strings.ReplaceAll(p.Template, "{n}", strconv.FormatUint(uint64(n), 10))
+}
+
+// Indexed reports whether the scheme yields more than one account.
+func (p PathScheme) Indexed() bool {
+	return strings.Contains(p.Template, "{n}")
+}
+
+var (
+	// SchemeStandard is used by Phantom, Solflare and Backpack.
+	SchemeStandard = PathScheme{"Standard (m/44'/501'/n'/0')", "m/44'/501'/{n}'/0'"}
+	// SchemeLegacy is used by older Solflare and Ledger Live accounts.
+	SchemeLegacy = PathScheme{"Legacy (m/44'/501'/n')", "m/44'/501'/{n}'"}
+	// SchemeCLI is the solana-keygen default when a derivation path is requested.
+	SchemeCLI = PathScheme{"Solana CLI (m/44'/501')", "m/44'/501'"}
+)
+
+// Schemes lists the supported derivation path schemes, most common first.
+var Schemes = []PathScheme{SchemeStandard, SchemeLegacy, SchemeCLI}
+
+// Secret is the plaintext that is encrypted and stored for a seed.
+type Secret struct {
+	Mnemonic   string `json:"mnemonic"`
+	Passphrase string `json:"passphrase,omitempty"`
+}
+
+// Seed derives the BIP39 seed for the secret.
+func (s Secret) Seed() ([]byte, error) {
+	return Seed(s.Mnemonic, s.Passphrase)
+}
+
+// NewMnemonic returns a fresh BIP39 mnemonic with the given number of words (12 or 24).
+func NewMnemonic(words int) (string, error) {
+	var bits int
+	switch words {
+	case 12:
+		bits = 128
+	case 24:
+		bits = 256
+	default:
+		return "", fmt.Errorf("unsupported mnemonic length %d", words)
+	}
+
+	entropy, err := bip39.NewEntropy(bits)
+	if err != nil {
+		return "", err
+	}
+	return bip39.NewMnemonic(entropy)
+}
+
+// NormalizeMnemonic collapses whitespace and lowercases the words.
+func NormalizeMnemonic(mnemonic string) string {
+	return strings.Join(strings.Fields(strings.ToLower(mnemonic)), " ")
+}
+
+// ValidateMnemonic checks the word list and checksum of a mnemonic.
+func ValidateMnemonic(mnemonic string) error {
+	if _, err := bip39.MnemonicToByteArray(NormalizeMnemonic(mnemonic)); err != nil {
+		return fmt.Errorf("invalid seed phrase: %v", err)
+	}
+	return nil
+}
+
+// Seed derives the 64-byte BIP39 seed from a mnemonic and optional passphrase.
+func Seed(mnemonic, passphrase string) ([]byte, error) {
+	normalized := NormalizeMnemonic(mnemonic)
+	if err := ValidateMnemonic(normalized); err != nil {
+		return nil, err
+	}
+	return bip39.NewSeed(normalized, passphrase), nil
+}
+
+// Fingerprint returns a short, stable identifier for a seed. It does not
+// reveal the seed and differs for every BIP39 passphrase.
+func Fingerprint(seed []byte) string {
+	sum := sha256.Sum256(seed)
+	return hex.EncodeToString(sum[:8])
+}
+
+// Derive derives the Solana keypair at path from seed using SLIP-0010 ed25519.
+func Derive(seed []byte, path string) (solana.PrivateKey, error) {
+	indexes, err := parsePath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	mac := hmac.New(sha512.New, []byte("ed25519 seed"))
+	mac.Write(seed)
+	sum := mac.Sum(nil)
+	key, chainCode := sum[:32], sum[32:]
+
+	for _, index := range indexes {
+		data := make([]byte, 0, 37)
+		data = append(data, 0x00)
+		data = append(data, key...)
+		data = binary.BigEndian.AppendUint32(data, index)
+
+		mac = hmac.New(sha512.New, chainCode)
+		mac.Write(data)
+		sum = mac.Sum(nil)
+		key, chainCode = sum[:32], sum[32:]
+	}
+
+	return solana.PrivateKey(ed25519.NewKeyFromSeed(key)), nil
+}
+
+// parsePath parses an all-hardened path such as m/44'/501'/0'/0'.
+func parsePath(path string) ([]uint32, error) {
+	parts := strings.Split(strings.TrimSpace(path), "/")
+	if len(parts) == 0 || parts[0] != "m" {
+		return nil, fmt.Errorf("invalid derivation path %q", path)
+	}
+
+	var indexes []uint32
+	for _, part := range parts[1:] {
+		if !strings.HasSuffix(part, "'") && !strings.HasSuffix(part, "h") {
+			return nil, fmt.Errorf("ed25519 derivation requires hardened indexes: %q", path)
+		}
+		n, err := strconv.ParseUint(part[:len(part)-1], 10, 31)
+		if err != nil {
+			return nil, fmt.Errorf("invalid derivation path %q: %v", path, err)
+		}
+		indexes = append(indexes, uint32(n)+hardenedOffset)
+	}
+	return indexes, nil
+}
@@ -0,0 +1,100 @@
+package hdwallet
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+// SLIP-0010 test vector 1 for ed25519. Public keys are listed without the
+// 0x00 prefix the spec adds.
+func TestDeriveSLIP10Vector1(t *testing.T) {
+	seed, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+	for _, tc := range []struct {
+		path, private, public string
+	}{
+		{"m",
+			"2b4be7f19ee27bbf30c667b642d5f4aa69fd169872f8fc3059c08ebae2eb19e7",
+			"a4b2856bfec510abab89753fac1ac0e1112364e7d250545963f135f2a33188ed"},
+		{"m/0'",
+			"68e0fe46dfb67e368c75379acec591dad19df3cde26e63b93a8e704f1dade7a3",
+			"8c8a13df77a28f3445213a0f432fde644acaa215fc72dcdf300d5efaa85d350c"},
+		{"m/0'/1'",
+			"b1d0bad404bf35da785a64ca1ac54b2617211d2777696fbffaf208f746ae84f2",
+			"1932a5270f335bed617d5b935c80aedb1a35bd9fc1e31acafd5372c30f5c1187"},
+		{"m/0'/1'/2'",
+			"92a5b23c0b8a99e37d07df3fb9966917f5d06e02ddbd909c7e184371463e9fc9",
+			"ae98736566d30ed0e9d2f4486a64bc95740d89c7db33f52121f8ea8f76ff0fc1"},
+		{"m/0'/1'/2'/2'",
+			"30d1dc7e5fc04c31219ab25a27ae00b50f6fd66622f6e9c913253d6511d1e662",
+			"8abae2d66361c879b900d204ad2cc4984fa2aa344dd7ddc46007329ac76c429c"},
+		{"m/0h/1h/2h/2h/1000000000h",
+			"8f94d394a8e8fd6b1bc2f3f49f5c47e385281d5c17e65324b0f62483e37e8793",
+			"3c24da049451555d51a7014a37337aa4e12d41e485abccfa46b47dfb2af54b7a"},
+	} {
+		key, err := Derive(seed, tc.path)
+		if err != nil {
+			t.Fatalf("Derive(%s): %v", tc.path, err)
+		}
+		if got := hex.EncodeToString(key[:32]); got != tc.private {
+			t.Errorf("Derive(%s) private = %s, want %s", tc.path, got, tc.private)
+		}
+		public := key.PublicKey()
+		if got := hex.EncodeToString(public[:]); got != tc.public {
+			t.Errorf("Derive(%s) public = %s, want %s", tc.path, got, tc.public)
+		}
+	}
+}
+
+const testMnemonic = "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+
+// BIP39 reference vector: the mnemonic for all-zero entropy with passphrase
+// "TREZOR".
+func TestSeedBIP39Vector(t *testing.T) {
+	seed, err := Seed(testMnemonic, "TREZOR")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "c55257c360c07c72029aebc1b53c05ed0362ada38ead3e3e9efa3708e53495531f09a6987599d18264c1e1c92f2cf141630c7a3c4ab7c81b2f001698e7463b04"
+	if got := hex.EncodeToString(seed); got != want {
+		t.Errorf("Seed = %s, want %s", got, want)
+	}
+}
+
+// The first account of the standard scheme matches what Phantom, Solflare
+// and solana-keygen derive for the same phrase.
+func TestDeriveStandardAccount(t *testing.T) {
+	seed, err := Seed("  Abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon ABOUT ", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := SchemeStandard.Path(0)
+	if path != "m/44'/501'/0'/0'" {
+		t.Fatalf("SchemeStandard.Path(0) = %s", path)
+	}
+	key, err := Derive(seed, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := key.PublicKey().String(), "HAgk14JpMQLgt6rVgv7cBQFJWFto5Dqxi472uT3DKpqk"; got != want {
+		t.Errorf("account 0 = %s, want %s", got, want)
+	}
+}
+
+func TestDeriveRejectsInvalidPaths(t *testing.T) {
+	seed := make([]byte, 64)
+	for _, path := range []string{"", "44'/501'", "m/44'/501", "m/44'/x'", "m/2147483648'"} {
+		if _, err := Derive(seed, path); err == nil {
+			t.Errorf("Derive(%q) succeeded, want an error", path)
+		}
+	}
+}
+
+func TestValidateMnemonic(t *testing.T) {
+	if err := ValidateMnemonic(testMnemonic); err != nil {
+		t.Errorf("valid mnemonic rejected: %v", err)
+	}
+	bad := "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon"
+	if err := ValidateMnemonic(bad); err == nil {
+		t.Error("mnemonic with a bad checksum accepted")
+	}
+}
@@ -0,0 +1,50 @@
+package history
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// csvHeader names the columns WriteCSV writes.
+var csvHeader = []string{"time", "network", "wallet", "symbol", "mint", "balance", "price_usd", "value_usd"}
+
+// WriteCSV writes snapshots to w with one row per token of each snapshot.
+func WriteCSV(w io.Writer, snapshots []Snapshot) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvHeader); err != nil {
+		return err
+	}
+	for _, snapshot := range snapshots {
+		when := snapshot.Time.UTC().Format(time.RFC3339)
+		for _, h := range snapshot.Holdings {
+			err := writer.Write([]string{
+				when,
+				csvText(snapshot.Network),
+				snapshot.Wallet,
+				csvText(h.Symbol),
+				h.Mint,
+				strconv.FormatFloat(h.Balance, 'f', -1, 64),
+				strconv.FormatFloat(h.Price, 'f', -1, 64),
+				strconv.FormatFloat(h.Value, 'f', 2, 64),
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// csvText neutralises text a spreadsheet would run as a formula. Token
+// symbols are chosen by whoever created the mint, so a leading =, +, -, @,
+// tab or carriage return is escaped with a quote.
+func csvText(s string) string {
+	if s != "" && strings.ContainsAny(s[:1], "=+-@\t\r") {
+		return "'" + s
+	}
+	return s
+}
@@ -0,0 +1,258 @@
+// Package history keeps a local record of wallet snapshots: what each wallet
+// held, at which prices and for how much in USD. Snapshots are appended to one
+// JSON lines file per network and wallet under the store directory.
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"unruggable-go/internal/network"
+	"unruggable-go/internal/portfolio"
+)
+
+// Dir is the directory of the store in the storage root.
+const Dir = "history"
+
+// Interval is the least time Record leaves between two snapshots of a wallet.
+const Interval = 15 * time.Minute
+
+// Holding is one token of a snapshot. SOL is recorded under
+// portfolio.SOLMint.
+type Holding struct {
+	Mint    string  `json:"mint"`
+	Symbol  string  `json:"symbol"`
+	Balance float64 `json:"balance"`
+	Price   float64 `json:"price"` // USD, zero if unknown
+	Value   float64 `json:"value"` // USD
+}
+
+// Snapshot is the state of a wallet at one time.
+type Snapshot struct {
+	Time     time.Time `json:"time"`
+	Wallet   string    `json:"wallet"`
+	Network  string    `json:"network"`
+	Value    float64   `json:"value"` // total USD
+	Holdings []Holding `json:"holdings"`
+	// Source names what recorded the snapshot, e.g. "app" or "calypso".
+	Source string `json:"source,omitempty"`
+}
+
+// FromBalances turns fetched balances into a snapshot taken now. Unverified
+// tokens without a price are left out; they are mostly spam and add no value.
+func FromBalances(network, wallet, source string, b *portfolio.Balances) Snapshot {
+	snapshot := Snapshot{Time: time.Now().UTC(), Wallet: wallet, Network: network, Source: source}
+	solPrice := 0.0
+	if b.SolBalance > 0 {
+		solPrice = b.SolBalanceUSD / b.SolBalance
+	}
+	snapshot.add(Holding{Mint: portfolio.SOLMint, Symbol: "SOL", Balance: b.SolBalance, Price: solPrice, Value: b.SolBalanceUSD})
+	for _, h := range b.Assets {
+		if !h.Verified && h.USDPrice == 0 {
+			continue
+		}
+		snapshot.add(Holding{Mint: h.Address, Symbol: h.Symbol, Balance: h.Balance, Price: h.USDPrice, Value: h.USDBalance})
+	}
+	return snapshot
+}
+
+func (s *Snapshot) add(h Holding) {
+	s.Holdings = append(s.Holdings, h)
+	s.Value += h.Value
+}
+
+// NewSnapshot builds a snapshot taken now from holdings, adding up Value.
+func NewSnapshot(network, wallet, source string, holdings []Holding) Snapshot {
+	snapshot := Snapshot{Time: time.Now().UTC(), Wallet: wallet, Network: network, Source: source}
+	for _, h := range holdings {
+		snapshot.add(h)
+	}
+	return snapshot
+}
+
+// Store reads and appends snapshots under a directory.
+type Store struct {
+	dir  string
+	mu   sync.Mutex
+	last map[string]time.Time // newest snapshot per file
+}
+
+// Open returns the store kept in dir. The directory is created on the first
+// write.
+func Open(dir string) *Store {
+	return &Store{dir: dir, last: make(map[string]time.Time)}
+}
+
+func (s *Store) path(networkName, wallet, ext string) (string, error) {
+	if wallet == "" || strings.ContainsAny(wallet, `/\.`) {
+		return "", fmt.Errorf("invalid wallet %q", wallet)
+	}
+	return filepath.Join(s.dir, network.FileName(networkName), wallet+ext), nil
+}
+
+// Append records snapshot.
+func (s *Store) Append(snapshot Snapshot) error {
+	path, err := s.path(snapshot.Network, snapshot.Wallet, ".jsonl")
+	if err != nil {
+		return err
+	}
+	line, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("cannot create history directory: %v", err)
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("cannot open history: %v", err)
+	}
+	defer f.Close()
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("cannot write history: %v", err)
+	}
+	if snapshot.Time.After(s.last[path]) {
+		s.last[path] = snapshot.Time
+	}
+	return nil
+}
+
+// Record appends snapshot unless the wallet already has one taken less than
+// Interval earlier, and reports whether it did. It suits callers that see
+// balances more often than history needs them.
+func (s *Store) Record(snapshot Snapshot) (bool, error) {
+	path, err := s.path(snapshot.Network, snapshot.Wallet, ".jsonl")
+	if err != nil {
+		return false, err
+	}
+	s.mu.Lock()
+	last, ok := s.last[path]
+	s.mu.Unlock()
+	if !ok {
+		recent, err := s.Load(snapshot.Network, snapshot.Wallet, snapshot.Time.Add(-Interval))
+		if err != nil {
+			return false, err
+		}
+		if len(recent) > 0 {
+			last = recent[len(recent)-1].Time
+		}
+	}
+	if snapshot.Time.Sub(last) < Interval {
+		return false, nil
+	}
+	if err := s.Append(snapshot); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Load returns the snapshots of wallet on network taken at or after since,
+// oldest first. A wallet without history has none. Damaged lines are skipped.
+func (s *Store) Load(network, wallet string, since time.Time) ([]Snapshot, error) {
+	path, err := s.path(network, wallet, ".jsonl")
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot open history: %v", err)
+	}
+	defer f.Close()
+
+	var snapshots []Snapshot
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		var snapshot Snapshot
+		if err := json.Unmarshal(scanner.Bytes(), &snapshot); err != nil {
+			continue
+		}
+		if !snapshot.Time.Before(since) {
+			snapshots = append(snapshots, snapshot)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("cannot read history: %v", err)
+	}
+	return snapshots, nil
+}
+
+// Baseline is a reference value a bot measures its gains against.
+type Baseline struct {
+	Value float64   `json:"value"`
+	Time  time.Time `json:"time"`
+}
+
+// Baseline returns the baseline saved under name for wallet on network.
+func (s *Store) Baseline(network, wallet, name string) (Baseline, bool) {
+	baselines, err := s.baselines(network, wallet)
+	if err != nil {
+		return Baseline{}, false
+	}
+	baseline, ok := baselines[name]
+	return baseline, ok
+}
+
+// SetBaseline saves value as the baseline name of wallet on network.
+func (s *Store) SetBaseline(network, wallet, name string, value float64) error {
+	baselines, err := s.baselines(network, wallet)
+	if err != nil {
+		return err
+	}
+	baselines[name] = Baseline{Value: value, Time: time.Now().UTC()}
+	data, err := json.Marshal(baselines)
+	if err != nil {
+		return err
+	}
+	path, err := s.path(network, wallet, ".baselines.json")
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("cannot create history directory: %v", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("cannot save baseline: %v", err)
+	}
+	return os.Rename(tmp, path)
+}
+
+func (s *Store) baselines(network, wallet string) (map[string]Baseline, error) {
+	path, err := s.path(network, wallet, ".baselines.json")
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	baselines := make(map[string]Baseline)
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return baselines, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot read baselines: %v", err)
+	}
+	if err := json.Unmarshal(data, &baselines); err != nil {
+		return nil, fmt.Errorf("invalid baselines: %v", err)
+	}
+	return baselines, nil
+}
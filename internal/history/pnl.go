@@ -0,0 +1,146 @@
+package history
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Ranges are the names RangeStart accepts, shortest first.
+var Ranges = []string{"24h", "7d", "30d", "90d", "all"}
+
+// RangeStart returns when the range name ending at now begins. "all" starts
+// at the zero time.
+func RangeStart(name string, now time.Time) (time.Time, error) {
+	switch strings.ToLower(name) {
+	case "24h":
+		return now.Add(-24 * time.Hour), nil
+	case "7d":
+		return now.AddDate(0, 0, -7), nil
+	case "30d":
+		return now.AddDate(0, 0, -30), nil
+	case "90d":
+		return now.AddDate(0, 0, -90), nil
+	case "all", "":
+		return time.Time{}, nil
+	}
+	return time.Time{}, fmt.Errorf("unknown range %q (want one of %s)", name, strings.Join(Ranges, ", "))
+}
+
+// TokenPnL is the profit and loss of one token over a range.
+type TokenPnL struct {
+	Mint    string  `json:"mint"`
+	Symbol  string  `json:"symbol"`
+	Balance float64 `json:"balance"`
+	Price   float64 `json:"price"`
+	Value   float64 `json:"value"`
+	// CostBasis is what the balance held at the end cost, at average cost.
+	CostBasis  float64 `json:"costBasis"`
+	Realized   float64 `json:"realized"`
+	Unrealized float64 `json:"unrealized"`
+}
+
+// PnL is the profit and loss of a wallet over a range.
+type PnL struct {
+	Start      time.Time  `json:"start"`
+	End        time.Time  `json:"end"`
+	StartValue float64    `json:"startValue"`
+	EndValue   float64    `json:"endValue"`
+	Realized   float64    `json:"realized"`
+	Unrealized float64    `json:"unrealized"`
+	Tokens     []TokenPnL `json:"tokens"`
+}
+
+// ComputePnL works out profit and loss from snapshots, oldest first. The
+// first snapshot opens every position at its price then. After that a
+// balance increase is treated as a buy and a decrease as a sale at the price
+// of the snapshot that saw it, and positions are kept at average cost. Since
+// only snapshots are known, transfers in and out count as buys and sales too.
+func ComputePnL(snapshots []Snapshot) PnL {
+	var result PnL
+	if len(snapshots) == 0 {
+		return result
+	}
+	first, last := snapshots[0], snapshots[len(snapshots)-1]
+	result.Start, result.End = first.Time, last.Time
+	result.StartValue, result.EndValue = first.Value, last.Value
+
+	positions := make(map[string]*position)
+	for i, snapshot := range snapshots {
+		seen := make(map[string]bool, len(snapshot.Holdings))
+		for _, h := range snapshot.Holdings {
+			seen[h.Mint] = true
+			p := positions[h.Mint]
+			if p == nil {
+				p = &position{}
+				positions[h.Mint] = p
+			}
+			p.symbol = h.Symbol
+			if h.Price > 0 {
+				p.price = h.Price
+			}
+			if i == 0 {
+				p.balance, p.cost = h.Balance, h.Balance*p.price
+				continue
+			}
+			p.trade(h.Balance-p.balance, p.price)
+		}
+		// A token missing from a snapshot was sold or sent away entirely.
+		for mint, p := range positions {
+			if !seen[mint] && p.balance != 0 {
+				p.trade(-p.balance, p.price)
+			}
+		}
+	}
+
+	for mint, p := range positions {
+		token := TokenPnL{
+			Mint:      mint,
+			Symbol:    p.symbol,
+			Balance:   p.balance,
+			Price:     p.price,
+			Value:     p.balance * p.price,
+			CostBasis: p.cost,
+			Realized:  p.realized,
+		}
+		token.Unrealized = token.Value - token.CostBasis
+		if token.Balance == 0 && token.Realized == 0 {
+			continue
+		}
+		result.Realized += token.Realized
+		result.Unrealized += token.Unrealized
+		result.Tokens = append(result.Tokens, token)
+	}
+	sort.Slice(result.Tokens, func(i, j int) bool {
+		return result.Tokens[i].Value > result.Tokens[j].Value
+	})
+	return result
+}
+
+// position is a token held at average cost.
+type position struct {
+	symbol   string
+	balance  float64
+	cost     float64
+	price    float64 // last known
+	realized float64
+}
+
+// trade applies a balance change of delta at price to the position.
+func (p *position) trade(delta, price float64) {
+	switch {
+	case delta > 0:
+		p.balance += delta
+		p.cost += delta * price
+	case delta < 0 && p.balance > 0:
+		sold := -delta
+		if sold > p.balance {
+			sold = p.balance
+		}
+		average := p.cost / p.balance
+		p.realized += sold * (price - average)
+		p.cost -= sold * average
+		p.balance -= sold
+	}
+}
@@ -0,0 +1,92 @@
+// Package keypair parses and formats Solana private keys in the formats used
+// by other wallets and the Solana CLI.
+package keypair
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+// Parse accepts a base58 private key or a solana-keygen JSON byte array.
+func Parse(input string) (solana.PrivateKey, error) {
+	input = strings.TrimSpace(input)
+	if input == "" {
+		return nil, fmt.Errorf("empty private key")
+	}
+	if strings.HasPrefix(input, "[") {
+		return ParseJSON([]byte(input))
+	}
+
+	key, err := solana.PrivateKeyFromBase58(input)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base58 private key: %v", err)
+	}
+	if err := Validate(key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// ParseJSON decodes a solana-keygen keypair file: a JSON array of 64 bytes.
+func ParseJSON(data []byte) (solana.PrivateKey, error) {
+	var raw []int
+	if err := json.Unmarshal(bytes.TrimSpace(data), &raw); err != nil {
+		return nil, fmt.Errorf("invalid keypair JSON: %v", err)
+	}
+	if len(raw) != ed25519.PrivateKeySize {
+		return nil, fmt.Errorf("keypair must contain %d bytes, got %d", ed25519.PrivateKeySize, len(raw))
+	}
+
+	key := make([]byte, len(raw))
+	for i, b := range raw {
+		if b < 0 || b > 255 {
+			return nil, fmt.Errorf("keypair byte %d out of range: %d", i, b)
+		}
+		key[i] = byte(b)
+	}
+
+	privateKey := solana.PrivateKey(key)
+	if err := Validate(privateKey); err != nil {
+		return nil, err
+	}
+	return privateKey, nil
+}
+
+// ReadFile reads and validates a solana-keygen keypair file.
+func ReadFile(path string) (solana.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read keypair file: %v", err)
+	}
+	return ParseJSON(data)
+}
+
+// MarshalJSON encodes key in the solana-keygen format.
+func MarshalJSON(key solana.PrivateKey) ([]byte, error) {
+	if err := Validate(key); err != nil {
+		return nil, err
+	}
+	raw := make([]int, len(key))
+	for i, b := range key {
+		raw[i] = int(b)
+	}
+	return json.Marshal(raw)
+}
+
+// Validate checks that the public half of key matches the one derived from its secret.
+func Validate(key solana.PrivateKey) error {
+	if len(key) != ed25519.PrivateKeySize {
+		return fmt.Errorf("private key must be %d bytes, got %d", ed25519.PrivateKeySize, len(key))
+	}
+	derived := ed25519.NewKeyFromSeed(key[:ed25519.SeedSize])
+	if !bytes.Equal(derived[ed25519.SeedSize:], key[ed25519.SeedSize:]) {
+		return fmt.Errorf("secret key does not match its public key")
+	}
+	return nil
+}
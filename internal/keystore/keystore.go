@@ -0,0 +1,276 @@
+// Package keystore implements the versioned, self-describing envelope used to
+// encrypt wallet keys and key shares at rest.
+package keystore
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/scrypt"
+)
+
+// Version is the current envelope format version.
+const Version = 1
+
+const (
+	KDFArgon2id = "argon2id"
+	KDFScrypt   = "scrypt"
+
+	CipherAES256GCM = "aes-256-gcm"
+
+	keyLen  = 32
+	saltLen = 16
+)
+
+// Default Argon2id parameters (RFC 9106 second recommended option).
+const (
+	defaultArgonTime    = 3
+	defaultArgonMemory  = 64 * 1024 // KiB
+	defaultArgonThreads = 4
+)
+
+// ErrInvalidPassword is returned when the envelope cannot be opened with the given password.
+var ErrInvalidPassword = errors.New("invalid password or corrupted keystore")
+
+// KDFParams describes how the encryption key was derived from the password.
+type KDFParams struct {
+	Name string `json:"name"`
+	Salt string `json:"salt"` // hex
+
+	// Argon2id
+	Time    uint32 `json:"time,omitempty"`
+	Memory  uint32 `json:"memory,omitempty"` // KiB
+	Threads uint8  `json:"threads,omitempty"`
+
+	// scrypt
+	N int `json:"n,omitempty"`
+	R int `json:"r,omitempty"`
+	P int `json:"p,omitempty"`
+}
+
+// Envelope is the on-disk representation of an encrypted secret.
+type Envelope struct {
+	Version    int       `json:"version"`
+	Cipher     string    `json:"cipher"`
+	KDF        KDFParams `json:"kdf"`
+	Nonce      string    `json:"nonce"`      // hex
+	Ciphertext string    `json:"ciphertext"` // hex
+}
+
+// Encrypt seals plaintext under a key derived from password with fresh
+// Argon2id salt and AES-GCM nonce.
+func Encrypt(plaintext []byte, password string) (*Envelope, error) {
+	salt := make([]byte, saltLen)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return nil, err
+	}
+
+	params := KDFParams{
+		Name:    KDFArgon2id,
+		Salt:    hex.EncodeToString(salt),
+		Time:    defaultArgonTime,
+		Memory:  defaultArgonMemory,
+		Threads: defaultArgonThreads,
+	}
+
+	key, err := deriveKey(password, params)
+	if err != nil {
+		return nil, err
+	}
+
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+
+	return &Envelope{
+		Version:    Version,
+		Cipher:     CipherAES256GCM,
+		KDF:        params,
+		Nonce:      hex.EncodeToString(nonce),
+		Ciphertext: hex.EncodeToString(gcm.Seal(nil, nonce, plaintext, nil)),
+	}, nil
+}
+
+// Decrypt opens the envelope with password.
+func (e *Envelope) Decrypt(password string) ([]byte, error) {
+	if e.Version != Version {
+		return nil, fmt.Errorf("unsupported keystore version %d", e.Version)
+	}
+	if e.Cipher != CipherAES256GCM {
+		return nil, fmt.Errorf("unsupported keystore cipher %q", e.Cipher)
+	}
+
+	key, err := deriveKey(password, e.KDF)
+	if err != nil {
+		return nil, err
+	}
+
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce, err := hex.DecodeString(e.Nonce)
+	if err != nil || len(nonce) != gcm.NonceSize() {
+		return nil, fmt.Errorf("invalid keystore nonce")
+	}
+	ciphertext, err := hex.DecodeString(e.Ciphertext)
+	if err != nil {
+		return nil, fmt.Errorf("invalid keystore ciphertext: %v", err)
+	}
+
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, ErrInvalidPassword
+	}
+	return plaintext, nil
+}
+
+// Marshal serializes the envelope to its JSON form.
+func (e *Envelope) Marshal() (string, error) {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// Parse decodes a serialized envelope.
+func Parse(data string) (*Envelope, error) {
+	var env Envelope
+	if err := json.Unmarshal([]byte(data), &env); err != nil {
+		return nil, fmt.Errorf("invalid keystore envelope: %v", err)
+	}
+	if env.Version == 0 || env.Ciphertext == "" {
+		return nil, fmt.Errorf("invalid keystore envelope: missing fields")
+	}
+	return &env, nil
+}
+
+// Seal encrypts plaintext and returns the serialized envelope.
+func Seal(plaintext []byte, password string) (string, error) {
+	env, err := Encrypt(plaintext, password)
+	if err != nil {
+		return "", err
+	}
+	return env.Marshal()
+}
+
+// Open decrypts data in either the current envelope format or the legacy
+// hex format. legacy reports whether data should be re-sealed.
+func Open(data, password string) (plaintext []byte, legacy bool, err error) {
+	if IsLegacy(data) {
+		plaintext, err = decryptLegacy(data, password)
+		return plaintext, true, err
+	}
+
+	env, err := Parse(data)
+	if err != nil {
+		return nil, false, err
+	}
+	plaintext, err = env.Decrypt(password)
+	return plaintext, false, err
+}
+
+// Reseal opens data with oldPassword and seals the plaintext again under
+// newPassword with a fresh salt and nonce. Legacy blobs are upgraded.
+func Reseal(data, oldPassword, newPassword string) (string, error) {
+	plaintext, _, err := Open(data, oldPassword)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		for i := range plaintext {
+			plaintext[i] = 0
+		}
+	}()
+	return Seal(plaintext, newPassword)
+}
+
+// IsLegacy reports whether data is a pre-envelope hex(nonce||ciphertext) blob.
+func IsLegacy(data string) bool {
+	data = strings.TrimSpace(data)
+	if data == "" || strings.HasPrefix(data, "{") {
+		return false
+	}
+	_, err := hex.DecodeString(data)
+	return err == nil
+}
+
+func deriveKey(password string, params KDFParams) ([]byte, error) {
+	salt, err := hex.DecodeString(params.Salt)
+	if err != nil || len(salt) == 0 {
+		return nil, fmt.Errorf("invalid keystore salt")
+	}
+
+	switch params.Name {
+	case KDFArgon2id:
+		if params.Time == 0 || params.Memory == 0 || params.Threads == 0 {
+			return nil, fmt.Errorf("invalid argon2id parameters")
+		}
+		return argon2.IDKey([]byte(password), salt, params.Time, params.Memory, params.Threads, keyLen), nil
+	case KDFScrypt:
+		return scrypt.Key([]byte(password), salt, params.N, params.R, params.P, keyLen)
+	default:
+		return nil, fmt.Errorf("unsupported keystore kdf %q", params.Name)
+	}
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// decryptLegacy opens blobs written before the envelope format, whose key was
+// the password repeated to 32 bytes and whose nonce prefixes the ciphertext.
+func decryptLegacy(data, password string) ([]byte, error) {
+	if password == "" {
+		return nil, ErrInvalidPassword
+	}
+
+	ciphertext, err := hex.DecodeString(strings.TrimSpace(data))
+	if err != nil {
+		return nil, err
+	}
+
+	gcm, err := newGCM([]byte(legacyPadKey(password)))
+	if err != nil {
+		return nil, err
+	}
+
+	nonceSize := gcm.NonceSize()
+	if len(ciphertext) < nonceSize {
+		return nil, fmt.Errorf("ciphertext too short")
+	}
+
+	nonce, ciphertext := ciphertext[:nonceSize], ciphertext[nonceSize:]
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, ErrInvalidPassword
+	}
+	return plaintext, nil
+}
+
+func legacyPadKey(key string) string {
+	for len(key) < 32 {
+		key += key
+	}
+	return key[:32]
+}
@@ -0,0 +1,179 @@
+package keystore
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"testing"
+)
+
+// sealLegacy produces a blob in the pre-envelope format: hex(nonce||ciphertext)
+// under the password repeated to 32 bytes.
+func sealLegacy(t *testing.T, plaintext []byte, password string) string {
+	t.Helper()
+	gcm, err := newGCM([]byte(legacyPadKey(password)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		t.Fatal(err)
+	}
+	return hex.EncodeToString(gcm.Seal(nonce, nonce, plaintext, nil))
+}
+
+func TestSealOpenRoundTrip(t *testing.T) {
+	plaintext := []byte("wallet secret key")
+	sealed, err := Seal(plaintext, "correct horse")
+	if err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+	if IsLegacy(sealed) {
+		t.Fatal("sealed envelope detected as legacy")
+	}
+
+	env, err := Parse(sealed)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if env.Version != Version || env.Cipher != CipherAES256GCM || env.KDF.Name != KDFArgon2id {
+		t.Fatalf("unexpected envelope header: %+v", env)
+	}
+
+	opened, legacy, err := Open(sealed, "correct horse")
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if legacy {
+		t.Error("Open reported an envelope as legacy")
+	}
+	if !bytes.Equal(opened, plaintext) {
+		t.Errorf("Open = %q, want %q", opened, plaintext)
+	}
+}
+
+func TestSealUsesFreshSaltAndNonce(t *testing.T) {
+	a, err := Encrypt([]byte("same"), "password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := Encrypt([]byte("same"), "password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.KDF.Salt == b.KDF.Salt || a.Nonce == b.Nonce || a.Ciphertext == b.Ciphertext {
+		t.Error("two seals of the same plaintext share salt, nonce or ciphertext")
+	}
+}
+
+func TestOpenWrongPassword(t *testing.T) {
+	sealed, err := Seal([]byte("secret"), "right")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := Open(sealed, "wrong"); !errors.Is(err, ErrInvalidPassword) {
+		t.Errorf("Open with wrong password = %v, want ErrInvalidPassword", err)
+	}
+
+	legacy := sealLegacy(t, []byte("secret"), "right")
+	if _, _, err := Open(legacy, "wrong"); !errors.Is(err, ErrInvalidPassword) {
+		t.Errorf("Open legacy with wrong password = %v, want ErrInvalidPassword", err)
+	}
+}
+
+func TestOpenTamperedEnvelope(t *testing.T) {
+	env, err := Encrypt([]byte("secret"), "password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ciphertext, _ := hex.DecodeString(env.Ciphertext)
+	ciphertext[0] ^= 1
+	env.Ciphertext = hex.EncodeToString(ciphertext)
+	if _, err := env.Decrypt("password"); !errors.Is(err, ErrInvalidPassword) {
+		t.Errorf("Decrypt of tampered ciphertext = %v, want ErrInvalidPassword", err)
+	}
+}
+
+func TestParseRejectsUnsupported(t *testing.T) {
+	for name, data := range map[string]string{
+		"not json":        "{",
+		"missing fields":  `{"cipher":"aes-256-gcm"}`,
+		"unknown version": `{"version":99,"cipher":"aes-256-gcm","kdf":{"name":"argon2id","salt":"00"},"nonce":"00","ciphertext":"00"}`,
+	} {
+		env, err := Parse(data)
+		if err == nil {
+			_, err = env.Decrypt("password")
+		}
+		if err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestLegacyMigration(t *testing.T) {
+	plaintext := []byte("legacy wallet key")
+	legacy := sealLegacy(t, plaintext, "password")
+	if !IsLegacy(legacy) {
+		t.Fatal("legacy blob not detected")
+	}
+
+	opened, isLegacy, err := Open(legacy, "password")
+	if err != nil {
+		t.Fatalf("Open legacy: %v", err)
+	}
+	if !isLegacy {
+		t.Error("Open did not report the blob as legacy")
+	}
+	if !bytes.Equal(opened, plaintext) {
+		t.Errorf("Open legacy = %q, want %q", opened, plaintext)
+	}
+
+	// Migration re-seals under the same password into the current envelope.
+	migrated, err := Reseal(legacy, "password", "password")
+	if err != nil {
+		t.Fatalf("Reseal: %v", err)
+	}
+	if IsLegacy(migrated) {
+		t.Fatal("Reseal kept the legacy format")
+	}
+	opened, isLegacy, err = Open(migrated, "password")
+	if err != nil || isLegacy || !bytes.Equal(opened, plaintext) {
+		t.Errorf("Open migrated = %q, %v, %v", opened, isLegacy, err)
+	}
+}
+
+func TestResealChangesPassword(t *testing.T) {
+	sealed, err := Seal([]byte("secret"), "old")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resealed, err := Reseal(sealed, "old", "new")
+	if err != nil {
+		t.Fatalf("Reseal: %v", err)
+	}
+	if _, _, err := Open(resealed, "old"); !errors.Is(err, ErrInvalidPassword) {
+		t.Errorf("old password still opens the resealed envelope: %v", err)
+	}
+	if opened, _, err := Open(resealed, "new"); err != nil || string(opened) != "secret" {
+		t.Errorf("Open with new password = %q, %v", opened, err)
+	}
+	if _, err := Reseal(sealed, "wrong", "new"); !errors.Is(err, ErrInvalidPassword) {
+		t.Errorf("Reseal with wrong password = %v, want ErrInvalidPassword", err)
+	}
+}
+
+func TestIsLegacy(t *testing.T) {
+	for data, want := range map[string]bool{
+		"":              false,
+		`{"version":1}`: false,
+		"00ff":          true,
+		"  0a0b  ":      true,
+		"not hex":       false,
+		"zz":            false,
+	} {
+		if got := IsLegacy(data); got != want {
+			t.Errorf("IsLegacy(%q) = %v, want %v", data, got, want)
+		}
+	}
+}
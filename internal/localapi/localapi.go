@@ -0,0 +1,458 @@
+// Package localapi serves wallet operations to scripts on the same machine as
+// JSON-RPC 2.0 over HTTP. Every request must carry the bearer token, and
+// transactions are only signed after the Approve hook accepted them.
+package localapi
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/gorilla/mux"
+
+	"unruggable-go/internal/fee"
+	"unruggable-go/internal/network"
+	"unruggable-go/internal/portfolio"
+	"unruggable-go/internal/session"
+	"unruggable-go/internal/transfer"
+	"unruggable-go/internal/txinspect"
+)
+
+// DefaultPort is the port the API listens on unless configured otherwise.
+const DefaultPort = 8765
+
+// requestTimeout bounds a call, including the time the user takes to approve
+// a signature.
+const requestTimeout = 2 * time.Minute
+
+// maxRequestSize limits request bodies.
+const maxRequestSize = 1 << 20
+
+// JSON-RPC error codes. Codes above -32000 are specific to this API.
+const (
+	codeParseError     = -32700
+	codeInvalidRequest = -32600
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+	codeServerError    = -32000
+	codeNoWallet       = -32001
+	codeRejected       = -32002
+	codeWatchOnly      = -32003
+)
+
+// ErrRejected is returned by Approve when the user declined to sign.
+var ErrRejected = errors.New("signature request rejected by the user")
+
+// Outcomes recorded in Entry.Status.
+const (
+	StatusOK           = "ok"
+	StatusError        = "error"
+	StatusRejected     = "rejected"
+	StatusUnauthorized = "unauthorized"
+)
+
+// SignRequest is shown to the user before a transaction is signed.
+type SignRequest struct {
+	Wallet      string
+	Transaction *solana.Transaction
+	Summary     txinspect.Summary
+}
+
+// Entry records one API call.
+type Entry struct {
+	Time     time.Time     `json:"time"`
+	Remote   string        `json:"remote"`
+	Method   string        `json:"method"`
+	Wallet   string        `json:"wallet,omitempty"`
+	Status   string        `json:"status"`
+	Error    string        `json:"error,omitempty"`
+	Duration time.Duration `json:"duration"`
+}
+
+// Server answers API calls for the wallet selected in the app. The hooks
+// connect it to the app's state; all of them are required.
+type Server struct {
+	Token string
+
+	// Wallet returns the selected wallet, or "" if none is selected.
+	Wallet  func() string
+	Network func() network.Profile
+	Client  func() *rpc.Client
+	// Fee returns the priority fee strategy of transactions the API builds.
+	// fee.Default is used if nil.
+	Fee func() fee.Strategy
+	// Balances fetches the balances of a wallet.
+	Balances func(ctx context.Context, address string) (*portfolio.Balances, error)
+	// WatchOnly, if set, reports wallets without a private key. Signing
+	// requests for them are refused without asking the user.
+	WatchOnly func(wallet string) bool
+	// Approve asks the user to sign req and blocks until they decide or ctx
+	// ends. It returns an unlocked signer for req.Wallet, or ErrRejected.
+	Approve func(ctx context.Context, req SignRequest) (*session.Signer, error)
+	// Log, if set, receives every call, including rejected ones.
+	Log func(Entry)
+
+	server *http.Server
+}
+
+// NewToken returns a random bearer token.
+func NewToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate token: %v", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// Start listens on the loopback interface at port and serves in the
+// background until Close.
+func (s *Server) Start(port int) error {
+	if s.Token == "" {
+		return errors.New("no API token set")
+	}
+	listener, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", port))
+	if err != nil {
+		return fmt.Errorf("cannot listen on port %d: %v", port, err)
+	}
+
+	router := mux.NewRouter()
+	router.HandleFunc("/", s.handle).Methods("POST")
+	s.server = &http.Server{Handler: router, ReadHeaderTimeout: 10 * time.Second}
+	go s.server.Serve(listener)
+	return nil
+}
+
+// Close stops the server. Calls waiting for approval are cancelled.
+func (s *Server) Close() error {
+	if s.server == nil {
+		return nil
+	}
+	return s.server.Close()
+}
+
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params"`
+}
+
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *rpcError) Error() string {
+	return e.Message
+}
+
+func newError(code int, format string, args ...interface{}) *rpcError {
+	return &rpcError{Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	entry := Entry{Time: time.Now(), Remote: r.RemoteAddr}
+	defer func() {
+		entry.Duration = time.Since(entry.Time)
+		if s.Log != nil {
+			s.Log(entry)
+		}
+	}()
+
+	if !loopbackHost(r.Host) {
+		entry.Status = StatusUnauthorized
+		http.Error(w, "requests must be addressed to localhost", http.StatusForbidden)
+		return
+	}
+	if !s.authorized(r) {
+		entry.Status = StatusUnauthorized
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		http.Error(w, "invalid or missing bearer token", http.StatusUnauthorized)
+		return
+	}
+
+	var req request
+	resp := response{JSONRPC: "2.0"}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestSize)).Decode(&req); err != nil {
+		resp.Error = newError(codeParseError, "invalid JSON: %v", err)
+	} else if req.JSONRPC != "2.0" || req.Method == "" {
+		resp.ID = req.ID
+		resp.Error = newError(codeInvalidRequest, "not a JSON-RPC 2.0 request")
+	} else {
+		resp.ID = req.ID
+		entry.Method = req.Method
+		entry.Wallet = s.Wallet()
+
+		ctx, cancel := context.WithTimeout(r.Context(), requestTimeout)
+		defer cancel()
+		result, err := s.call(ctx, entry.Wallet, req)
+		if err != nil {
+			var rpcErr *rpcError
+			if !errors.As(err, &rpcErr) {
+				rpcErr = newError(codeServerError, "%v", err)
+			}
+			resp.Error = rpcErr
+		} else {
+			resp.Result = result
+		}
+	}
+
+	switch {
+	case resp.Error == nil:
+		entry.Status = StatusOK
+	case resp.Error.Code == codeRejected:
+		entry.Status = StatusRejected
+	default:
+		entry.Status = StatusError
+		entry.Error = resp.Error.Message
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// loopbackHost reports whether the Host header names the loopback interface.
+// A web page that rebinds its own domain to 127.0.0.1 still sends that
+// domain, so its requests are refused before the token is even checked.
+func loopbackHost(host string) bool {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.Trim(host, "[]")
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+func (s *Server) authorized(r *http.Request) bool {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return ok && subtle.ConstantTimeCompare([]byte(token), []byte(s.Token)) == 1
+}
+
+func (s *Server) call(ctx context.Context, wallet string, req request) (interface{}, error) {
+	switch req.Method {
+	case "getWallet":
+		return walletResult{Address: wallet, Network: s.Network().Name}, nil
+	case "getWalletBalances":
+		if wallet == "" {
+			return nil, newError(codeNoWallet, "no wallet selected")
+		}
+		return s.Balances(ctx, wallet)
+	case "createTransferTransaction":
+		var params transferParams
+		if err := decodeParams(req.Params, &params); err != nil {
+			return nil, err
+		}
+		return s.createTransfer(ctx, wallet, params)
+	case "decodeTransaction":
+		var params transactionParams
+		if err := decodeParams(req.Params, &params); err != nil {
+			return nil, err
+		}
+		tx, err := decodeTransaction(params.Transaction)
+		if err != nil {
+			return nil, err
+		}
+		return txinspect.Summarize(tx), nil
+	case "signTransaction":
+		var params transactionParams
+		if err := decodeParams(req.Params, &params); err != nil {
+			return nil, err
+		}
+		return s.sign(ctx, wallet, params)
+	default:
+		return nil, newError(codeMethodNotFound, "unknown method %q", req.Method)
+	}
+}
+
+// decodeParams accepts params as an object or as an array holding one.
+func decodeParams(raw json.RawMessage, v interface{}) error {
+	var list []json.RawMessage
+	if err := json.Unmarshal(raw, &list); err == nil {
+		if len(list) != 1 {
+			return newError(codeInvalidParams, "expected one parameter object")
+		}
+		raw = list[0]
+	}
+	if err := json.Unmarshal(raw, v); err != nil {
+		return newError(codeInvalidParams, "invalid params: %v", err)
+	}
+	return nil
+}
+
+type walletResult struct {
+	Address string `json:"address"`
+	Network string `json:"network"`
+}
+
+type transferParams struct {
+	To     string  `json:"to"`
+	Amount float64 `json:"amount"` // in whole tokens
+	Mint   string  `json:"mint,omitempty"`
+	// PriorityFee overrides the app's strategy: low, medium, high or a
+	// percentile.
+	PriorityFee string `json:"priorityFee,omitempty"`
+}
+
+type transactionParams struct {
+	// Transaction is base64 or base58 encoded.
+	Transaction string `json:"transaction"`
+}
+
+type transactionResult struct {
+	Transaction string            `json:"transaction"` // base64
+	Signature   string            `json:"signature,omitempty"`
+	Summary     txinspect.Summary `json:"summary"`
+}
+
+func (s *Server) createTransfer(ctx context.Context, wallet string, params transferParams) (*transactionResult, error) {
+	if wallet == "" {
+		return nil, newError(codeNoWallet, "no wallet selected")
+	}
+	to, err := solana.PublicKeyFromBase58(params.To)
+	if err != nil {
+		return nil, newError(codeInvalidParams, "invalid recipient address: %v", err)
+	}
+	if params.Amount <= 0 {
+		return nil, newError(codeInvalidParams, "amount must be positive")
+	}
+
+	client := s.Client()
+	p := transfer.Params{
+		From:   solana.MustPublicKeyFromBase58(wallet),
+		To:     to,
+		Amount: params.Amount,
+	}
+	if params.Mint != "" && !strings.EqualFold(params.Mint, "SOL") {
+		if p.Mint, err = solana.PublicKeyFromBase58(params.Mint); err != nil {
+			return nil, newError(codeInvalidParams, "invalid mint: %v", err)
+		}
+	}
+
+	strategy := fee.Default
+	if s.Fee != nil {
+		strategy = s.Fee()
+	}
+	if params.PriorityFee != "" {
+		if strategy, err = fee.ParseStrategy(params.PriorityFee); err != nil {
+			return nil, newError(codeInvalidParams, "%v", err)
+		}
+	}
+
+	tx, _, err := transfer.Build(ctx, client, p, strategy)
+	if err != nil {
+		return nil, err
+	}
+	return encodeResult(tx, "")
+}
+
+func (s *Server) sign(ctx context.Context, wallet string, params transactionParams) (*transactionResult, error) {
+	if wallet == "" {
+		return nil, newError(codeNoWallet, "no wallet selected")
+	}
+	if s.WatchOnly != nil && s.WatchOnly(wallet) {
+		return nil, newError(codeWatchOnly, "wallet %s is watch-only and cannot sign", wallet)
+	}
+	tx, err := decodeTransaction(params.Transaction)
+	if err != nil {
+		return nil, err
+	}
+	pubKey := solana.MustPublicKeyFromBase58(wallet)
+	if !tx.Message.IsSigner(pubKey) {
+		return nil, newError(codeInvalidParams, "wallet %s is not a signer of this transaction", wallet)
+	}
+
+	signer, err := s.Approve(ctx, SignRequest{Wallet: wallet, Transaction: tx, Summary: txinspect.Summarize(tx)})
+	if errors.Is(err, ErrRejected) {
+		return nil, newError(codeRejected, "%v", err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if signer.WalletID() != wallet {
+		return nil, fmt.Errorf("selected wallet changed while waiting for approval")
+	}
+
+	// PartialSign keeps the signatures of other signers already present.
+	var sig solana.Signature
+	err = signer.WithPrivateKey(func(key solana.PrivateKey) error {
+		sigs, err := tx.PartialSign(func(k solana.PublicKey) *solana.PrivateKey {
+			if k.Equals(pubKey) {
+				return &key
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		for i, k := range tx.Message.Signers() {
+			if k.Equals(pubKey) {
+				sig = sigs[i]
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign transaction: %v", err)
+	}
+	return encodeResult(tx, sig.String())
+}
+
+func decodeTransaction(encoded string) (*solana.Transaction, error) {
+	if encoded == "" {
+		return nil, newError(codeInvalidParams, "missing transaction")
+	}
+	tx, err := txinspect.Decode(encoded)
+	if err != nil {
+		return nil, newError(codeInvalidParams, "%v", err)
+	}
+	return tx, nil
+}
+
+func encodeResult(tx *solana.Transaction, signature string) (*transactionResult, error) {
+	data, err := tx.MarshalBinary()
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode transaction: %v", err)
+	}
+	return &transactionResult{
+		Transaction: base64.StdEncoding.EncodeToString(data),
+		Signature:   signature,
+		Summary:     txinspect.Summarize(tx),
+	}, nil
+}
+
+// AppendLog appends entry to the file at path as one line of JSON.
+func AppendLog(path string, entry Entry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.Write(append(data, '\n'))
+	return err
+}
@@ -0,0 +1,208 @@
+package localapi
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/programs/system"
+
+	"unruggable-go/internal/network"
+	"unruggable-go/internal/session"
+)
+
+const testToken = "test-token"
+
+// testServer returns a server for wallet whose Approve hook answers with
+// approve and counts its calls.
+func testServer(wallet solana.PrivateKey, approve func() (*session.Signer, error), approvals *int) *Server {
+	return &Server{
+		Token:   testToken,
+		Wallet:  func() string { return wallet.PublicKey().String() },
+		Network: func() network.Profile { return network.Default() },
+		Approve: func(ctx context.Context, req SignRequest) (*session.Signer, error) {
+			*approvals++
+			return approve()
+		},
+	}
+}
+
+// post sends a JSON-RPC call to s as a script on the same machine would.
+func post(t *testing.T, s *Server, body string, modify func(*http.Request)) *httptest.ResponseRecorder {
+	t.Helper()
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	r.Host = "127.0.0.1:8765"
+	r.Header.Set("Authorization", "Bearer "+testToken)
+	if modify != nil {
+		modify(r)
+	}
+	w := httptest.NewRecorder()
+	s.handle(w, r)
+	return w
+}
+
+func decode(t *testing.T, w *httptest.ResponseRecorder) (transactionResult, *rpcError) {
+	t.Helper()
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body)
+	}
+	var resp struct {
+		Result transactionResult `json:"result"`
+		Error  *rpcError         `json:"error"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("invalid response %s: %v", w.Body, err)
+	}
+	return resp.Result, resp.Error
+}
+
+// transferFrom builds an unsigned transaction paid for and signed by payer.
+func transferFrom(t *testing.T, payer solana.PublicKey) string {
+	t.Helper()
+	tx, err := solana.NewTransaction(
+		[]solana.Instruction{system.NewTransferInstruction(1, payer, solana.NewWallet().PublicKey()).Build()},
+		solana.Hash{},
+		solana.TransactionPayer(payer),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := tx.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return base64.StdEncoding.EncodeToString(data)
+}
+
+func signCall(tx string) string {
+	return `{"jsonrpc":"2.0","id":1,"method":"signTransaction","params":{"transaction":"` + tx + `"}}`
+}
+
+func TestRejectsBadToken(t *testing.T) {
+	wallet := solana.NewWallet().PrivateKey
+	var approvals int
+	s := testServer(wallet, nil, &approvals)
+	var logged []Entry
+	s.Log = func(e Entry) { logged = append(logged, e) }
+
+	tests := map[string]string{
+		"missing":    "",
+		"wrong":      "Bearer other-token",
+		"not bearer": "Basic " + testToken,
+	}
+	for name, header := range tests {
+		t.Run(name, func(t *testing.T) {
+			w := post(t, s, `{"jsonrpc":"2.0","id":1,"method":"getWallet"}`, func(r *http.Request) {
+				r.Header.Set("Authorization", header)
+			})
+			if w.Code != http.StatusUnauthorized {
+				t.Errorf("status = %d, want 401", w.Code)
+			}
+			if strings.Contains(w.Body.String(), wallet.PublicKey().String()) {
+				t.Error("unauthorized response reveals the wallet")
+			}
+		})
+	}
+	for _, e := range logged {
+		if e.Status != StatusUnauthorized {
+			t.Errorf("logged status %q, want %q", e.Status, StatusUnauthorized)
+		}
+	}
+}
+
+func TestRejectsNonLoopbackHost(t *testing.T) {
+	var approvals int
+	s := testServer(solana.NewWallet().PrivateKey, nil, &approvals)
+	for _, host := range []string{"evil.example:8765", "192.168.1.10:8765", "localhost.evil.example"} {
+		w := post(t, s, `{"jsonrpc":"2.0","id":1,"method":"getWallet"}`, func(r *http.Request) { r.Host = host })
+		if w.Code != http.StatusForbidden {
+			t.Errorf("Host %s: status = %d, want 403", host, w.Code)
+		}
+	}
+	for _, host := range []string{"localhost:8765", "127.0.0.1", "[::1]:8765"} {
+		w := post(t, s, `{"jsonrpc":"2.0","id":1,"method":"getWallet"}`, func(r *http.Request) { r.Host = host })
+		if w.Code != http.StatusOK {
+			t.Errorf("Host %s: status = %d, want 200", host, w.Code)
+		}
+	}
+}
+
+func TestRejectsOversizedBody(t *testing.T) {
+	var approvals int
+	s := testServer(solana.NewWallet().PrivateKey, nil, &approvals)
+	body := `{"jsonrpc":"2.0","id":1,"method":"decodeTransaction","params":{"transaction":"` +
+		strings.Repeat("A", maxRequestSize) + `"}}`
+	_, rpcErr := decode(t, post(t, s, body, nil))
+	if rpcErr == nil || rpcErr.Code != codeParseError {
+		t.Errorf("error = %+v, want parse error", rpcErr)
+	}
+}
+
+func TestSignTransaction(t *testing.T) {
+	wallet := solana.NewWallet().PrivateKey
+	signer := func() (*session.Signer, error) {
+		// Unlock zeroes the key it is given.
+		key := append(solana.PrivateKey(nil), wallet...)
+		return session.New(time.Minute).Unlock(wallet.PublicKey().String(), key), nil
+	}
+	rejected := func() (*session.Signer, error) { return nil, ErrRejected }
+
+	tests := []struct {
+		name          string
+		tx            string
+		watchOnly     bool
+		approve       func() (*session.Signer, error)
+		wantCode      int
+		wantApprovals int
+	}{
+		{"signed", transferFrom(t, wallet.PublicKey()), false, signer, 0, 1},
+		{"watch-only", transferFrom(t, wallet.PublicKey()), true, signer, codeWatchOnly, 0},
+		{"not a signer", transferFrom(t, solana.NewWallet().PublicKey()), false, signer, codeInvalidParams, 0},
+		{"rejected", transferFrom(t, wallet.PublicKey()), false, rejected, codeRejected, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var approvals int
+			s := testServer(wallet, tt.approve, &approvals)
+			s.WatchOnly = func(string) bool { return tt.watchOnly }
+
+			result, rpcErr := decode(t, post(t, s, signCall(tt.tx), nil))
+			if approvals != tt.wantApprovals {
+				t.Errorf("Approve called %d times, want %d", approvals, tt.wantApprovals)
+			}
+			if tt.wantCode != 0 {
+				if rpcErr == nil || rpcErr.Code != tt.wantCode {
+					t.Fatalf("error = %+v, want code %d", rpcErr, tt.wantCode)
+				}
+				if result.Signature != "" || result.Transaction != "" {
+					t.Errorf("failed call returned a transaction: %+v", result)
+				}
+				return
+			}
+			if rpcErr != nil {
+				t.Fatalf("signTransaction: %v", rpcErr)
+			}
+
+			data, err := base64.StdEncoding.DecodeString(result.Transaction)
+			if err != nil {
+				t.Fatal(err)
+			}
+			tx, err := solana.TransactionFromBytes(data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := tx.VerifySignatures(); err != nil {
+				t.Errorf("returned transaction does not verify: %v", err)
+			}
+			if result.Signature != tx.Signatures[0].String() {
+				t.Errorf("signature = %s, want %s", result.Signature, tx.Signatures[0])
+			}
+		})
+	}
+}
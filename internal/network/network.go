@@ -0,0 +1,211 @@
+// Package network defines the named sets of endpoints the app talks to.
+package network
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Profile bundles every endpoint used for one Solana cluster.
+type Profile struct {
+	Name    string `json:"name"`
+	Cluster string `json:"cluster"` // mainnet-beta, devnet, testnet or custom
+	RPCURL  string `json:"rpcUrl"`
+	// ExtraRPCURLs are further HTTP endpoints for the same cluster. Requests
+	// are spread over RPCURL and these, failing over between them.
+	ExtraRPCURLs []string `json:"extraRpcUrls,omitempty"`
+	WSURL        string   `json:"wsUrl"`
+	// JitoURL is the bundle endpoint. Empty disables Jito bundles.
+	JitoURL          string `json:"jitoUrl,omitempty"`
+	JupiterQuoteURL  string `json:"jupiterQuoteUrl,omitempty"`
+	JupiterSwapURL   string `json:"jupiterSwapUrl,omitempty"`
+	JupiterPriceURL  string `json:"jupiterPriceUrl,omitempty"`
+	JupiterTokensURL string `json:"jupiterTokensUrl,omitempty"`
+	PythURL          string `json:"pythUrl,omitempty"`
+	// DASURL serves the Digital Asset Standard methods such as
+	// getAssetsByOwner, which public RPC nodes do not. Empty uses RPCURL,
+	// except on the builtin profiles, which have no DAS endpoint.
+	DASURL string `json:"dasUrl,omitempty"`
+	// Builtin profiles ship with the app and cannot be edited or deleted.
+	Builtin bool `json:"-"`
+}
+
+// Profile names of the built-in networks.
+const (
+	Mainnet  = "mainnet"
+	Devnet   = "devnet"
+	Testnet  = "testnet"
+	Localnet = "localnet"
+)
+
+// Preferences keys under which the GUI stores the user's own profiles (as a
+// JSON array) and the name of the active one. The CLI reads the same keys.
+const (
+	ProfilesPreferenceKey = "networkProfiles"
+	ActivePreferenceKey   = "activeNetwork"
+)
+
+// Shared off-chain services. Jupiter and Pyth only serve mainnet data.
+const (
+	jitoMainnetURL   = "https://mainnet.block-engine.jito.wtf/api/v1/bundles"
+	jupiterQuoteURL  = "https://quote-api.jup.ag/v6/quote"
+	jupiterSwapURL   = "https://quote-api.jup.ag/v6/swap-instructions"
+	jupiterPriceURL  = "https://api.jup.ag/price/v2"
+	jupiterTokensURL = "https://api.jup.ag/tokens/v1/tagged/verified"
+	pythURL          = "https://hermes.pyth.network/v2/updates/price/latest"
+)
+
+// Builtin returns the profiles that ship with the app.
+func Builtin() []Profile {
+	services := func(p Profile) Profile {
+		p.JupiterQuoteURL = jupiterQuoteURL
+		p.JupiterSwapURL = jupiterSwapURL
+		p.JupiterPriceURL = jupiterPriceURL
+		p.JupiterTokensURL = jupiterTokensURL
+		p.PythURL = pythURL
+		p.Builtin = true
+		return p
+	}
+
+	return []Profile{
+		services(Profile{
+			Name:    Mainnet,
+			Cluster: "mainnet-beta",
+			RPCURL:  "https://api.mainnet-beta.solana.com",
+			WSURL:   "wss://api.mainnet-beta.solana.com",
+			JitoURL: jitoMainnetURL,
+		}),
+		services(Profile{
+			Name:    Devnet,
+			Cluster: "devnet",
+			RPCURL:  "https://api.devnet.solana.com",
+			WSURL:   "wss://api.devnet.solana.com",
+		}),
+		services(Profile{
+			Name:    Testnet,
+			Cluster: "testnet",
+			RPCURL:  "https://api.testnet.solana.com",
+			WSURL:   "wss://api.testnet.solana.com",
+		}),
+		services(Profile{
+			Name:    Localnet,
+			Cluster: "custom",
+			RPCURL:  "http://127.0.0.1:8899",
+			WSURL:   "ws://127.0.0.1:8900",
+		}),
+	}
+}
+
+// Default is the profile used until the user picks another.
+func Default() Profile {
+	return Builtin()[0]
+}
+
+// Validate checks that the profile has a name and well-formed endpoints.
+func (p Profile) Validate() error {
+	if strings.TrimSpace(p.Name) == "" {
+		return fmt.Errorf("profile name cannot be empty")
+	}
+	if err := checkURL("RPC", p.RPCURL, true, "http", "https"); err != nil {
+		return err
+	}
+	for _, extra := range p.ExtraRPCURLs {
+		if err := checkURL("RPC", extra, true, "http", "https"); err != nil {
+			return err
+		}
+	}
+	if err := checkURL("WebSocket", p.WSURL, false, "ws", "wss"); err != nil {
+		return err
+	}
+	optional := map[string]string{
+		"Jito":           p.JitoURL,
+		"Jupiter quote":  p.JupiterQuoteURL,
+		"Jupiter swap":   p.JupiterSwapURL,
+		"Jupiter price":  p.JupiterPriceURL,
+		"Jupiter tokens": p.JupiterTokensURL,
+		"Pyth":           p.PythURL,
+		"DAS":            p.DASURL,
+	}
+	for name, value := range optional {
+		if err := checkURL(name, value, false, "http", "https"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FileName reduces a profile name to lowercase letters, digits and dashes
+// for use in file and directory names.
+func FileName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '-'
+	}, strings.ToLower(name))
+}
+
+// RPCURLs returns the primary RPC endpoint followed by the extra ones.
+func (p Profile) RPCURLs() []string {
+	return append([]string{p.RPCURL}, p.ExtraRPCURLs...)
+}
+
+// DASEndpoint returns the endpoint for DAS requests, falling back to the
+// primary RPC endpoint. DAS needs a provider account, so the builtin profiles
+// have none and the user has to add a profile with one.
+func (p Profile) DASEndpoint() (string, error) {
+	switch {
+	case p.DASURL != "":
+		return p.DASURL, nil
+	case p.Builtin:
+		return "", fmt.Errorf("the %s network has no DAS endpoint; add a network profile with a DAS URL from your RPC provider", p.Name)
+	}
+	return p.RPCURL, nil
+}
+
+// WebSocketURL returns the WS endpoint, deriving it from the RPC URL if unset.
+func (p Profile) WebSocketURL() string {
+	if p.WSURL != "" {
+		return p.WSURL
+	}
+	switch {
+	case strings.HasPrefix(p.RPCURL, "https://"):
+		return "wss://" + strings.TrimPrefix(p.RPCURL, "https://")
+	case strings.HasPrefix(p.RPCURL, "http://"):
+		return "ws://" + strings.TrimPrefix(p.RPCURL, "http://")
+	}
+	return p.RPCURL
+}
+
+// ExplorerTxURL links to a transaction on the Solana explorer for this cluster.
+func (p Profile) ExplorerTxURL(signature string) string {
+	link := "https://explorer.solana.com/tx/" + signature
+	switch p.Cluster {
+	case "", "mainnet-beta":
+		return link
+	case "custom":
+		return link + "?cluster=custom&customUrl=" + url.QueryEscape(p.RPCURL)
+	default:
+		return link + "?cluster=" + p.Cluster
+	}
+}
+
+func checkURL(name, value string, required bool, schemes ...string) error {
+	if value == "" {
+		if required {
+			return fmt.Errorf("%s URL is required", name)
+		}
+		return nil
+	}
+	u, err := url.Parse(value)
+	if err != nil || u.Host == "" {
+		return fmt.Errorf("invalid %s URL %q", name, value)
+	}
+	for _, scheme := range schemes {
+		if u.Scheme == scheme {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s URL must use %s", name, strings.Join(schemes, " or "))
+}
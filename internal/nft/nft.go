@@ -0,0 +1,351 @@
+// Package nft lists the collectibles of a wallet through the DAS API
+// (getAssetsByOwner) and sends them: standard NFTs as token transfers and
+// compressed NFTs through Bubblegum.
+package nft
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"unruggable-go/internal/display"
+)
+
+// pageLimit is the largest getAssetsByOwner page; maxPages bounds a listing.
+const (
+	pageLimit = 1000
+	maxPages  = 10
+)
+
+// maxImageSize is the largest image the cache downloads.
+const maxImageSize = 5 << 20
+
+// imageClient downloads previews.
+var imageClient = &http.Client{Timeout: 20 * time.Second}
+
+// Kind tells how an asset is stored and therefore how it is sent.
+type Kind string
+
+const (
+	KindStandard     Kind = "standard"     // SPL token with metadata
+	KindProgrammable Kind = "programmable" // Token Metadata pNFT with a token record
+	KindCompressed   Kind = "compressed"   // Bubblegum leaf
+	KindCore         Kind = "core"         // Metaplex Core asset
+)
+
+// Asset is one collectible of a wallet.
+type Asset struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Symbol string `json:"symbol,omitempty"`
+	Image  string `json:"image,omitempty"`
+	// Preview is a CDN-resized copy of Image when the RPC provides one.
+	Preview        string `json:"preview,omitempty"`
+	Collection     string `json:"collection,omitempty"`
+	CollectionName string `json:"collectionName,omitempty"`
+	Kind           Kind   `json:"kind"`
+	Frozen         bool   `json:"frozen,omitempty"`
+}
+
+// Sendable returns why the asset cannot be sent, or nil if it can.
+func (a Asset) Sendable() error {
+	switch {
+	case a.Frozen && a.Kind != KindProgrammable:
+		return fmt.Errorf("%s is frozen", a.Name)
+	case a.Kind == KindStandard, a.Kind == KindCompressed:
+		return nil
+	case a.Kind == KindProgrammable:
+		return errors.New("programmable NFTs need a Token Metadata transfer, which is not supported yet")
+	default:
+		return fmt.Errorf("%s assets cannot be sent yet", a.Kind)
+	}
+}
+
+// Collection is a group of assets sharing a verified collection. Assets
+// without one are grouped under an empty Address.
+type Collection struct {
+	Address string
+	Name    string
+	Assets  []Asset
+}
+
+// Group sorts assets into collections, ordered by name, with the assets
+// outside any collection last.
+func Group(assets []Asset) []Collection {
+	index := make(map[string]int)
+	var collections []Collection
+	for _, asset := range assets {
+		i, ok := index[asset.Collection]
+		if !ok {
+			name := asset.CollectionName
+			switch {
+			case asset.Collection == "":
+				name = "Other"
+			case name == "":
+				name = display.Address(asset.Collection)
+			}
+			i = len(collections)
+			index[asset.Collection] = i
+			collections = append(collections, Collection{Address: asset.Collection, Name: name})
+		}
+		collections[i].Assets = append(collections[i].Assets, asset)
+	}
+
+	sort.SliceStable(collections, func(i, j int) bool {
+		a, b := collections[i], collections[j]
+		if (a.Address == "") != (b.Address == "") {
+			return b.Address == ""
+		}
+		return strings.ToLower(a.Name) < strings.ToLower(b.Name)
+	})
+	for _, collection := range collections {
+		sort.SliceStable(collection.Assets, func(i, j int) bool {
+			return collection.Assets[i].Name < collection.Assets[j].Name
+		})
+	}
+	return collections
+}
+
+// Client calls the DAS methods of an RPC endpoint, such as Helius.
+type Client struct {
+	URL string
+	// HTTP sends the requests. Defaults to http.DefaultClient.
+	HTTP *http.Client
+}
+
+type dasError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// dasAsset is the part of a DAS asset the wallet uses.
+type dasAsset struct {
+	Interface string `json:"interface"`
+	ID        string `json:"id"`
+	Burnt     bool   `json:"burnt"`
+	Content   struct {
+		Metadata struct {
+			Name   string `json:"name"`
+			Symbol string `json:"symbol"`
+		} `json:"metadata"`
+		Links struct {
+			Image string `json:"image"`
+		} `json:"links"`
+		Files []struct {
+			URI    string `json:"uri"`
+			CDNURI string `json:"cdn_uri"`
+		} `json:"files"`
+	} `json:"content"`
+	Grouping []struct {
+		GroupKey           string `json:"group_key"`
+		GroupValue         string `json:"group_value"`
+		CollectionMetadata struct {
+			Name string `json:"name"`
+		} `json:"collection_metadata"`
+	} `json:"grouping"`
+	Compression struct {
+		Compressed  bool   `json:"compressed"`
+		DataHash    string `json:"data_hash"`
+		CreatorHash string `json:"creator_hash"`
+		LeafID      uint64 `json:"leaf_id"`
+		Tree        string `json:"tree"`
+	} `json:"compression"`
+	Ownership struct {
+		Frozen   bool   `json:"frozen"`
+		Owner    string `json:"owner"`
+		Delegate string `json:"delegate"`
+	} `json:"ownership"`
+}
+
+// Assets lists the NFTs and compressed NFTs owned by owner. Fungible tokens
+// are left out.
+func (c *Client) Assets(ctx context.Context, owner string) ([]Asset, error) {
+	var assets []Asset
+	for page := 1; page <= maxPages; page++ {
+		var result struct {
+			Items []dasAsset `json:"items"`
+		}
+		err := c.call(ctx, "getAssetsByOwner", map[string]interface{}{
+			"ownerAddress": owner,
+			"page":         page,
+			"limit":        pageLimit,
+			"displayOptions": map[string]bool{
+				"showFungible":           false,
+				"showCollectionMetadata": true,
+			},
+		}, &result)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range result.Items {
+			if asset, ok := item.asset(); ok {
+				assets = append(assets, asset)
+			}
+		}
+		if len(result.Items) < pageLimit {
+			break
+		}
+	}
+	return assets, nil
+}
+
+func (d dasAsset) asset() (Asset, bool) {
+	if d.Burnt {
+		return Asset{}, false
+	}
+	asset := Asset{
+		ID:     d.ID,
+		Name:   strings.TrimSpace(d.Content.Metadata.Name),
+		Symbol: strings.TrimSpace(d.Content.Metadata.Symbol),
+		Image:  d.Content.Links.Image,
+		Frozen: d.Ownership.Frozen,
+	}
+	switch {
+	case d.Compression.Compressed:
+		asset.Kind = KindCompressed
+	case d.Interface == "ProgrammableNFT":
+		asset.Kind = KindProgrammable
+	case d.Interface == "MplCoreAsset":
+		asset.Kind = KindCore
+	case d.Interface == "V1_NFT", d.Interface == "V2_NFT", d.Interface == "V1_PRINT", d.Interface == "LEGACY_NFT":
+		asset.Kind = KindStandard
+	default:
+		return Asset{}, false
+	}
+	if asset.Name == "" {
+		asset.Name = display.Address(d.ID)
+	}
+	for _, file := range d.Content.Files {
+		if file.CDNURI != "" && (file.URI == asset.Image || asset.Image == "") {
+			asset.Preview = file.CDNURI
+			break
+		}
+	}
+	if asset.Preview == "" {
+		asset.Preview = asset.Image
+	}
+	for _, group := range d.Grouping {
+		if group.GroupKey == "collection" {
+			asset.Collection = group.GroupValue
+			asset.CollectionName = strings.TrimSpace(group.CollectionMetadata.Name)
+			break
+		}
+	}
+	return asset, true
+}
+
+func (c *Client) call(ctx context.Context, method string, params interface{}, result interface{}) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      "unruggable",
+		"method":  method,
+		"params":  params,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %v", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := c.HTTP
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s failed: %v", method, err)
+	}
+	defer resp.Body.Close()
+
+	var response struct {
+		Result json.RawMessage `json:"result"`
+		Error  *dasError       `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return fmt.Errorf("failed to decode %s response: %v", method, err)
+	}
+	if response.Error != nil {
+		if response.Error.Code == -32601 {
+			return fmt.Errorf("the RPC endpoint does not support the DAS API (%s)", method)
+		}
+		return fmt.Errorf("%s: RPC error: %s", method, response.Error.Message)
+	}
+	if err := json.Unmarshal(response.Result, result); err != nil {
+		return fmt.Errorf("unexpected %s result: %v", method, err)
+	}
+	return nil
+}
+
+// ImageCache keeps downloaded previews in Dir, named by the hash of their
+// URL. An empty Dir downloads every time.
+type ImageCache struct {
+	Dir string
+}
+
+// Load returns the image at url, from the cache if possible.
+func (c ImageCache) Load(ctx context.Context, url string) ([]byte, error) {
+	url = gatewayURL(url)
+	if !strings.HasPrefix(url, "https://") && !strings.HasPrefix(url, "http://") {
+		return nil, fmt.Errorf("unsupported image URL %q", url)
+	}
+	var path string
+	if c.Dir != "" {
+		sum := sha256.Sum256([]byte(url))
+		path = filepath.Join(c.Dir, hex.EncodeToString(sum[:]))
+		if data, err := os.ReadFile(path); err == nil {
+			return data, nil
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := imageClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("bad status code: %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxImageSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxImageSize {
+		return nil, fmt.Errorf("image is larger than %d bytes", maxImageSize)
+	}
+
+	if path != "" {
+		if err := os.MkdirAll(c.Dir, 0700); err == nil {
+			os.WriteFile(path, data, 0600)
+		}
+	}
+	return data, nil
+}
+
+// gatewayURL rewrites ipfs:// and ar:// links to public HTTP gateways.
+func gatewayURL(url string) string {
+	switch {
+	case strings.HasPrefix(url, "ipfs://"):
+		return "https://ipfs.io/ipfs/" + strings.TrimPrefix(strings.TrimPrefix(url, "ipfs://"), "ipfs/")
+	case strings.HasPrefix(url, "ar://"):
+		return "https://arweave.net/" + strings.TrimPrefix(url, "ar://")
+	}
+	return url
+}
@@ -0,0 +1,159 @@
+package nft
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"math/bits"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+
+	"unruggable-go/internal/transfer"
+)
+
+// Programs involved in compressed NFT transfers.
+var (
+	BubblegumProgramID   = solana.MustPublicKeyFromBase58("BGUMAp9Gq7iTEuizy4pqaxsTyUCBK68MDfK752saRPUY")
+	noopProgramID        = solana.MustPublicKeyFromBase58("noopb9bkMVfRPU8AsbpTUg8AQkHtKwMYZiFUjNRtMmV")
+	compressionProgramID = solana.MustPublicKeyFromBase58("cmtDvXumGCrqC1Age74AVPhSRVXJMd8PJS91L8KbNCK")
+)
+
+// bubblegumTransfer is the Anchor discriminator of Bubblegum's transfer.
+var bubblegumTransfer = func() []byte {
+	sum := sha256.Sum256([]byte("global:transfer"))
+	return sum[:8]
+}()
+
+// treeHeaderSize is the account type and V1 header of a concurrent Merkle
+// tree account.
+const treeHeaderSize = 2 + 54
+
+// TransferInstructions returns the instructions sending asset from one owner
+// to another. Standard NFTs are sent like any token, creating the recipient's
+// token account if needed; compressed NFTs are moved to the new owner's leaf
+// with a proof from the DAS API.
+func TransferInstructions(ctx context.Context, client *rpc.Client, das *Client, asset Asset, from, to solana.PublicKey) ([]solana.Instruction, error) {
+	if err := asset.Sendable(); err != nil {
+		return nil, err
+	}
+	if asset.Kind == KindStandard {
+		mint, err := solana.PublicKeyFromBase58(asset.ID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid mint %s: %v", asset.ID, err)
+		}
+		return transfer.Instructions(ctx, client, transfer.Params{From: from, To: to, Amount: 1, Mint: mint})
+	}
+
+	instruction, err := compressedTransfer(ctx, client, das, asset.ID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	return []solana.Instruction{instruction}, nil
+}
+
+// compressedTransfer builds the Bubblegum transfer of the leaf of id.
+func compressedTransfer(ctx context.Context, client *rpc.Client, das *Client, id string, from, to solana.PublicKey) (solana.Instruction, error) {
+	var asset dasAsset
+	if err := das.call(ctx, "getAsset", map[string]string{"id": id}, &asset); err != nil {
+		return nil, err
+	}
+	if !asset.Compression.Compressed {
+		return nil, fmt.Errorf("asset %s is not compressed", id)
+	}
+	if asset.Ownership.Owner != from.String() {
+		return nil, fmt.Errorf("asset %s is owned by %s", id, asset.Ownership.Owner)
+	}
+
+	var proof struct {
+		Root   string   `json:"root"`
+		Proof  []string `json:"proof"`
+		TreeID string   `json:"tree_id"`
+	}
+	if err := das.call(ctx, "getAssetProof", map[string]string{"id": id}, &proof); err != nil {
+		return nil, err
+	}
+
+	tree, err := solana.PublicKeyFromBase58(proof.TreeID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid tree %q: %v", proof.TreeID, err)
+	}
+	canopy, err := canopyDepth(ctx, client, tree)
+	if err != nil {
+		return nil, err
+	}
+	if canopy > len(proof.Proof) {
+		canopy = len(proof.Proof)
+	}
+	// Nodes stored in the tree's canopy are not passed.
+	nodes := proof.Proof[:len(proof.Proof)-canopy]
+
+	delegate := from
+	if asset.Ownership.Delegate != "" {
+		if delegate, err = solana.PublicKeyFromBase58(asset.Ownership.Delegate); err != nil {
+			return nil, fmt.Errorf("invalid delegate: %v", err)
+		}
+	}
+	treeAuthority, _, err := solana.FindProgramAddress([][]byte{tree[:]}, BubblegumProgramID)
+	if err != nil {
+		return nil, err
+	}
+
+	accounts := solana.AccountMetaSlice{
+		solana.Meta(treeAuthority),
+		solana.Meta(from).SIGNER(),
+		solana.Meta(delegate),
+		solana.Meta(to),
+		solana.Meta(tree).WRITE(),
+		solana.Meta(noopProgramID),
+		solana.Meta(compressionProgramID),
+		solana.Meta(solana.SystemProgramID),
+	}
+	for _, node := range nodes {
+		key, err := solana.PublicKeyFromBase58(node)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proof node %q: %v", node, err)
+		}
+		accounts = append(accounts, solana.Meta(key))
+	}
+
+	data := append([]byte(nil), bubblegumTransfer...)
+	for _, hash := range []string{proof.Root, asset.Compression.DataHash, asset.Compression.CreatorHash} {
+		key, err := solana.PublicKeyFromBase58(hash)
+		if err != nil {
+			return nil, fmt.Errorf("invalid hash %q: %v", hash, err)
+		}
+		data = append(data, key[:]...)
+	}
+	data = binary.LittleEndian.AppendUint64(data, asset.Compression.LeafID) // nonce
+	data = binary.LittleEndian.AppendUint32(data, uint32(asset.Compression.LeafID))
+
+	return solana.NewInstruction(BubblegumProgramID, accounts, data), nil
+}
+
+// canopyDepth reads how many levels of tree are cached on chain. The account
+// is the header, the tree itself and then the canopy.
+func canopyDepth(ctx context.Context, client *rpc.Client, tree solana.PublicKey) (int, error) {
+	info, err := client.GetAccountInfoWithOpts(ctx, tree, &rpc.GetAccountInfoOpts{Commitment: rpc.CommitmentConfirmed})
+	if err != nil {
+		return 0, fmt.Errorf("error reading tree %s: %v", tree, err)
+	}
+	data := info.Value.Data.GetBinary()
+	if len(data) < treeHeaderSize {
+		return 0, fmt.Errorf("tree %s is too short", tree)
+	}
+	maxBufferSize := int(binary.LittleEndian.Uint32(data[2:]))
+	maxDepth := int(binary.LittleEndian.Uint32(data[6:]))
+
+	// sequence number, active index and buffer size, then the change logs
+	// (root, path, index and padding) and the rightmost proof
+	changeLog := 32 + 32*maxDepth + 8
+	treeSize := 24 + maxBufferSize*changeLog + (32*maxDepth + 40)
+	canopyBytes := len(data) - treeHeaderSize - treeSize
+	if canopyBytes <= 0 {
+		return 0, nil
+	}
+	// The canopy stores 2^(depth+1) - 2 nodes.
+	return bits.Len(uint(canopyBytes/32+2)) - 2, nil
+}
@@ -0,0 +1,245 @@
+package nft
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+// treeAccount fabricates a concurrent Merkle tree account of the given depth,
+// buffer size and canopy depth. Only the sizes matter to canopyDepth.
+func treeAccount(maxDepth, maxBufferSize, canopy int) []byte {
+	changeLog := 32 + 32*maxDepth + 8
+	treeSize := 24 + maxBufferSize*changeLog + (32*maxDepth + 40)
+	canopyNodes := (1 << (canopy + 1)) - 2
+	data := make([]byte, treeHeaderSize+treeSize+32*canopyNodes)
+	data[0] = 1 // ConcurrentMerkleTree account type
+	data[1] = 0 // header V1
+	binary.LittleEndian.PutUint32(data[2:], uint32(maxBufferSize))
+	binary.LittleEndian.PutUint32(data[6:], uint32(maxDepth))
+	return data
+}
+
+// fakeNode answers the DAS and RPC calls of a compressed transfer.
+type fakeNode struct {
+	asset map[string]interface{}
+	proof map[string]interface{}
+	tree  []byte
+}
+
+func (n *fakeNode) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		ID     json.RawMessage `json:"id"`
+		Method string          `json:"method"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var result interface{}
+	switch req.Method {
+	case "getAsset":
+		result = n.asset
+	case "getAssetProof":
+		result = n.proof
+	case "getAccountInfo":
+		result = map[string]interface{}{
+			"context": map[string]interface{}{"slot": 1},
+			"value": map[string]interface{}{
+				"data":       []string{base64.StdEncoding.EncodeToString(n.tree), "base64"},
+				"executable": false,
+				"lamports":   1,
+				"owner":      compressionProgramID.String(),
+				"rentEpoch":  0,
+			},
+		}
+	default:
+		http.Error(w, "unexpected method "+req.Method, http.StatusBadRequest)
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": result})
+}
+
+func TestCanopyDepth(t *testing.T) {
+	tests := []struct {
+		maxDepth, maxBufferSize, canopy int
+	}{
+		{3, 8, 0},
+		{3, 8, 1},
+		{14, 64, 0},
+		{14, 64, 10},
+		{20, 256, 14},
+		{30, 2048, 17},
+	}
+	for _, tt := range tests {
+		node := &fakeNode{tree: treeAccount(tt.maxDepth, tt.maxBufferSize, tt.canopy)}
+		server := httptest.NewServer(node)
+		got, err := canopyDepth(context.Background(), rpc.New(server.URL), solana.NewWallet().PublicKey())
+		server.Close()
+		if err != nil {
+			t.Errorf("depth %d, buffer %d: %v", tt.maxDepth, tt.maxBufferSize, err)
+			continue
+		}
+		if got != tt.canopy {
+			t.Errorf("depth %d, buffer %d: canopyDepth = %d, want %d", tt.maxDepth, tt.maxBufferSize, got, tt.canopy)
+		}
+	}
+
+	node := &fakeNode{tree: make([]byte, treeHeaderSize-1)}
+	server := httptest.NewServer(node)
+	defer server.Close()
+	if _, err := canopyDepth(context.Background(), rpc.New(server.URL), solana.NewWallet().PublicKey()); err == nil {
+		t.Error("canopyDepth accepted an account shorter than the header")
+	}
+}
+
+// account is an expected account of an instruction.
+type account struct {
+	key              solana.PublicKey
+	signer, writable bool
+}
+
+func TestCompressedTransfer(t *testing.T) {
+	from := solana.NewWallet().PublicKey()
+	to := solana.NewWallet().PublicKey()
+	delegate := solana.NewWallet().PublicKey()
+	tree := solana.NewWallet().PublicKey()
+	root := solana.NewWallet().PublicKey()
+	dataHash := solana.NewWallet().PublicKey()
+	creatorHash := solana.NewWallet().PublicKey()
+	proof := make([]solana.PublicKey, 5)
+	proofStrings := make([]string, len(proof))
+	for i := range proof {
+		proof[i] = solana.NewWallet().PublicKey()
+		proofStrings[i] = proof[i].String()
+	}
+	treeAuthority, _, err := solana.FindProgramAddress([][]byte{tree[:]}, BubblegumProgramID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name         string
+		delegate     string
+		leafID       uint64
+		canopy       int
+		wantDelegate solana.PublicKey
+		wantNodes    int
+	}{
+		{"no canopy", "", 7, 0, from, 5},
+		{"canopy", "", 7, 2, from, 3},
+		{"canopy deeper than proof", "", 7, 8, from, 0},
+		{"delegate", delegate.String(), 70000, 1, delegate, 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node := &fakeNode{
+				asset: map[string]interface{}{
+					"id": "asset",
+					"compression": map[string]interface{}{
+						"compressed":   true,
+						"data_hash":    dataHash.String(),
+						"creator_hash": creatorHash.String(),
+						"leaf_id":      tt.leafID,
+						"tree":         tree.String(),
+					},
+					"ownership": map[string]interface{}{"owner": from.String(), "delegate": tt.delegate},
+				},
+				proof: map[string]interface{}{"root": root.String(), "proof": proofStrings, "tree_id": tree.String()},
+				tree:  treeAccount(14, 64, tt.canopy),
+			}
+			server := httptest.NewServer(node)
+			defer server.Close()
+
+			instruction, err := compressedTransfer(context.Background(), rpc.New(server.URL), &Client{URL: server.URL}, "asset", from, to)
+			if err != nil {
+				t.Fatalf("compressedTransfer: %v", err)
+			}
+			if !instruction.ProgramID().Equals(BubblegumProgramID) {
+				t.Errorf("program = %s, want Bubblegum", instruction.ProgramID())
+			}
+
+			want := []account{
+				{treeAuthority, false, false},
+				{from, true, false},
+				{tt.wantDelegate, false, false},
+				{to, false, false},
+				{tree, false, true},
+				{noopProgramID, false, false},
+				{compressionProgramID, false, false},
+				{solana.SystemProgramID, false, false},
+			}
+			for _, node := range proof[:tt.wantNodes] {
+				want = append(want, account{node, false, false})
+			}
+			accounts := instruction.Accounts()
+			if len(accounts) != len(want) {
+				t.Fatalf("%d accounts, want %d", len(accounts), len(want))
+			}
+			for i, w := range want {
+				a := accounts[i]
+				if !a.PublicKey.Equals(w.key) || a.IsSigner != w.signer || a.IsWritable != w.writable {
+					t.Errorf("account %d = %s signer=%v writable=%v, want %s signer=%v writable=%v",
+						i, a.PublicKey, a.IsSigner, a.IsWritable, w.key, w.signer, w.writable)
+				}
+			}
+
+			data, err := instruction.Data()
+			if err != nil {
+				t.Fatal(err)
+			}
+			wantData := append([]byte(nil), bubblegumTransfer...)
+			wantData = append(wantData, root[:]...)
+			wantData = append(wantData, dataHash[:]...)
+			wantData = append(wantData, creatorHash[:]...)
+			wantData = binary.LittleEndian.AppendUint64(wantData, tt.leafID)
+			wantData = binary.LittleEndian.AppendUint32(wantData, uint32(tt.leafID))
+			if !bytes.Equal(data, wantData) {
+				t.Errorf("data = %x, want %x", data, wantData)
+			}
+			if len(data) != 8+3*32+8+4 {
+				t.Errorf("data is %d bytes, want %d", len(data), 8+3*32+8+4)
+			}
+		})
+	}
+}
+
+func TestBubblegumTransferDiscriminator(t *testing.T) {
+	// sha256("global:transfer")[:8], as generated by Anchor.
+	want := []byte{163, 52, 200, 231, 140, 3, 69, 186}
+	if !bytes.Equal(bubblegumTransfer, want) {
+		t.Errorf("discriminator = %v, want %v", bubblegumTransfer, want)
+	}
+}
+
+func TestCompressedTransferRejects(t *testing.T) {
+	from := solana.NewWallet().PublicKey()
+	tests := map[string]map[string]interface{}{
+		"not compressed": {
+			"compression": map[string]interface{}{"compressed": false},
+			"ownership":   map[string]interface{}{"owner": from.String()},
+		},
+		"other owner": {
+			"compression": map[string]interface{}{"compressed": true},
+			"ownership":   map[string]interface{}{"owner": solana.NewWallet().PublicKey().String()},
+		},
+	}
+	for name, asset := range tests {
+		t.Run(name, func(t *testing.T) {
+			server := httptest.NewServer(&fakeNode{asset: asset})
+			defer server.Close()
+			_, err := compressedTransfer(context.Background(), rpc.New(server.URL), &Client{URL: server.URL}, "asset", from, solana.NewWallet().PublicKey())
+			if err == nil {
+				t.Error("compressedTransfer succeeded")
+			}
+		})
+	}
+}
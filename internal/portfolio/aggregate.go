@@ -0,0 +1,121 @@
+package portfolio
+
+import (
+	"sort"
+	"sync"
+)
+
+// DefaultWorkers is how many wallets FetchAll fetches at once by default.
+const DefaultWorkers = 4
+
+// WalletBalances is the outcome of fetching one wallet.
+type WalletBalances struct {
+	Address  string    `json:"address"`
+	Balances *Balances `json:"balances,omitempty"`
+	Err      error     `json:"-"`
+}
+
+// Value returns the USD value of the wallet, zero if it failed.
+func (w WalletBalances) Value() float64 {
+	if w.Balances == nil {
+		return 0
+	}
+	return w.Balances.Value()
+}
+
+// Value returns the USD value of the SOL balance and every holding.
+func (b *Balances) Value() float64 {
+	total := b.SolBalanceUSD
+	for _, h := range b.Assets {
+		total += h.USDBalance
+	}
+	return total
+}
+
+// FetchAll fetches the balances of wallets with at most workers requests in
+// flight (DefaultWorkers if zero). Results are in the order of wallets; a
+// wallet that failed carries its error.
+func (f Fetcher) FetchAll(wallets []string, workers int) []WalletBalances {
+	if workers <= 0 {
+		workers = DefaultWorkers
+	}
+	results := make([]WalletBalances, len(wallets))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers && w < len(wallets); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				balances, err := f.Balances(wallets[i])
+				results[i] = WalletBalances{Address: wallets[i], Balances: balances, Err: err}
+			}
+		}()
+	}
+	for i := range wallets {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	return results
+}
+
+// TokenTotal is one token summed over several wallets.
+type TokenTotal struct {
+	Mint       string  `json:"mint"`
+	Symbol     string  `json:"symbol"`
+	Balance    float64 `json:"balance"`
+	USDPrice   float64 `json:"usdPrice"`
+	USDBalance float64 `json:"usdBalance"`
+	Verified   bool    `json:"verified"`
+	// Wallets holds the balance of each wallet holding the token.
+	Wallets map[string]float64 `json:"wallets"`
+}
+
+// Combine sums the holdings of wallets by mint, SOL included under SOLMint,
+// ordered by value. Failed wallets are skipped.
+func Combine(wallets []WalletBalances) []TokenTotal {
+	index := make(map[string]int)
+	var totals []TokenTotal
+	add := func(address string, h Holding) {
+		i, ok := index[h.Address]
+		if !ok {
+			i = len(totals)
+			index[h.Address] = i
+			totals = append(totals, TokenTotal{
+				Mint:     h.Address,
+				Symbol:   h.Symbol,
+				USDPrice: h.USDPrice,
+				Verified: h.Verified,
+				Wallets:  make(map[string]float64),
+			})
+		}
+		total := &totals[i]
+		total.Balance += h.Balance
+		total.USDBalance += h.USDBalance
+		total.Wallets[address] += h.Balance
+		if total.USDPrice == 0 {
+			total.USDPrice = h.USDPrice
+		}
+	}
+
+	for _, w := range wallets {
+		if w.Balances == nil {
+			continue
+		}
+		b := w.Balances
+		solPrice := 0.0
+		if b.SolBalance > 0 {
+			solPrice = b.SolBalanceUSD / b.SolBalance
+		}
+		add(w.Address, Holding{Symbol: "SOL", Address: SOLMint, Balance: b.SolBalance, USDPrice: solPrice, USDBalance: b.SolBalanceUSD, Verified: true})
+		for _, h := range b.Assets {
+			add(w.Address, h)
+		}
+	}
+
+	sort.SliceStable(totals, func(i, j int) bool {
+		return totals[i].USDBalance > totals[j].USDBalance
+	})
+	return totals
+}
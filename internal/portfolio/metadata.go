@@ -0,0 +1,349 @@
+package portfolio
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+
+	"unruggable-go/internal/network"
+)
+
+// MetadataProgramID is the Metaplex Token Metadata program.
+const MetadataProgramID = "metaqbxxUerdq28cj1RbAWkYQm3ybzjb6a8bt518x1s"
+
+// metadataRetry is how long a mint without metadata is remembered before it
+// is looked up again.
+const metadataRetry = 24 * time.Hour
+
+// maxAccountsPerRequest is the getMultipleAccounts limit.
+const maxAccountsPerRequest = 100
+
+// offChainClient fetches the JSON document a metadata URI points to.
+var offChainClient = &http.Client{Timeout: 10 * time.Second}
+
+// TokenMetadata is the on-chain name and symbol of a mint that is not on the
+// token list. The off-chain document at URI, chosen by whoever created the
+// mint, is never fetched while loading balances; MetadataCache.Image reads
+// the image from it when the token is shown.
+type TokenMetadata struct {
+	Name   string `json:"name,omitempty"`
+	Symbol string `json:"symbol,omitempty"`
+	URI    string `json:"uri,omitempty"`
+	// Found is false if the mint has no metadata at all.
+	Found   bool      `json:"found"`
+	Fetched time.Time `json:"fetched"`
+	// Image is the image of the off-chain document, read at ImageFetched.
+	Image        string    `json:"image,omitempty"`
+	ImageFetched time.Time `json:"imageFetched"`
+}
+
+// MetadataCache keeps resolved metadata in a JSON file so unknown mints are
+// only read from the chain once.
+type MetadataCache struct {
+	path    string
+	mu      sync.Mutex
+	entries map[string]TokenMetadata
+}
+
+// MetadataCacheFile is the name of the cache file of a network. Mint
+// addresses can exist on several clusters, so each network has its own.
+func MetadataCacheFile(networkName string) string {
+	return "token-metadata-" + network.FileName(networkName) + ".json"
+}
+
+// OpenMetadataCache loads the cache stored at path. An empty path or an
+// unreadable file gives an empty cache; with an empty path nothing is saved.
+func OpenMetadataCache(path string) *MetadataCache {
+	c := &MetadataCache{path: path, entries: make(map[string]TokenMetadata)}
+	if path == "" {
+		return c
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return c
+	}
+	if err := json.Unmarshal(data, &c.entries); err != nil {
+		log.Printf("Ignoring token metadata cache: %v", err)
+		c.entries = make(map[string]TokenMetadata)
+	}
+	return c
+}
+
+func (c *MetadataCache) get(mint string) (TokenMetadata, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	meta, ok := c.entries[mint]
+	if ok && !meta.Found && time.Since(meta.Fetched) > metadataRetry {
+		return meta, false
+	}
+	return meta, ok
+}
+
+func (c *MetadataCache) put(resolved map[string]TokenMetadata) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for mint, meta := range resolved {
+		c.entries[mint] = meta
+	}
+	if c.path == "" {
+		return
+	}
+	data, err := json.Marshal(c.entries)
+	if err != nil {
+		log.Printf("Failed to encode token metadata cache: %v", err)
+		return
+	}
+	if err := os.WriteFile(c.path, data, 0600); err != nil {
+		log.Printf("Failed to save token metadata cache: %v", err)
+	}
+}
+
+// Image returns the image URL of mint's off-chain metadata document. The
+// document is only fetched the first time; a mint without an image is looked
+// up again after a day. Mints the cache does not know have no image.
+func (c *MetadataCache) Image(ctx context.Context, mint string) (string, error) {
+	c.mu.Lock()
+	meta, ok := c.entries[mint]
+	c.mu.Unlock()
+	if !ok || meta.URI == "" {
+		return "", nil
+	}
+	if !meta.ImageFetched.IsZero() && (meta.Image != "" || time.Since(meta.ImageFetched) < metadataRetry) {
+		return meta.Image, nil
+	}
+
+	image, err := offChainImage(ctx, meta.URI)
+	meta.Image, meta.ImageFetched = image, time.Now()
+	c.put(map[string]TokenMetadata{mint: meta})
+	return image, err
+}
+
+// offChainImage returns the image of the JSON document at uri.
+func offChainImage(ctx context.Context, uri string) (string, error) {
+	if !strings.HasPrefix(uri, "https://") && !strings.HasPrefix(uri, "http://") {
+		return "", fmt.Errorf("unsupported metadata URI %q", uri)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, uri, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := offChainClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("bad status code: %d", resp.StatusCode)
+	}
+	var doc struct {
+		Image string `json:"image"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&doc); err != nil {
+		return "", fmt.Errorf("invalid metadata document: %v", err)
+	}
+	return doc.Image, nil
+}
+
+// multipleAccountsResponse is getMultipleAccounts with base64 or jsonParsed
+// encoding; Data is decoded by the caller.
+type multipleAccountsResponse struct {
+	Result struct {
+		Value []*struct {
+			Owner string          `json:"owner"`
+			Data  json.RawMessage `json:"data"`
+		} `json:"value"`
+	} `json:"result"`
+	Error *rpcError `json:"error"`
+}
+
+// TokenMetadata resolves metadata for mints, which maps each mint to its
+// token program. Metaplex metadata is preferred; Token-2022 mints without it
+// are checked for the token metadata extension. Lookups that fail are logged
+// and left out.
+func (f Fetcher) TokenMetadata(mints map[string]string) map[string]TokenMetadata {
+	result := make(map[string]TokenMetadata, len(mints))
+	var missing []string
+	for mint := range mints {
+		if f.Metadata != nil {
+			if meta, ok := f.Metadata.get(mint); ok {
+				result[mint] = meta
+				continue
+			}
+		}
+		missing = append(missing, mint)
+	}
+	if len(missing) == 0 {
+		return result
+	}
+
+	resolved := make(map[string]TokenMetadata, len(missing))
+	metaplex, err := f.metaplexMetadata(missing)
+	if err != nil {
+		log.Printf("Warning: Failed to read token metadata: %v", err)
+		return result
+	}
+	var token2022 []string
+	for _, mint := range missing {
+		if meta, ok := metaplex[mint]; ok {
+			resolved[mint] = meta
+		} else if mints[mint] == Token2022ProgramID {
+			token2022 = append(token2022, mint)
+		}
+	}
+	if len(token2022) > 0 {
+		extension, err := f.metadataExtension(token2022)
+		if err != nil {
+			log.Printf("Warning: Failed to read token metadata extensions: %v", err)
+			return result
+		}
+		for mint, meta := range extension {
+			resolved[mint] = meta
+		}
+	}
+
+	now := time.Now()
+	for _, mint := range missing {
+		meta, ok := resolved[mint]
+		if ok {
+			meta.Found = true
+		}
+		meta.Fetched = now
+		resolved[mint] = meta
+		result[mint] = meta
+	}
+	if f.Metadata != nil {
+		f.Metadata.put(resolved)
+	}
+	return result
+}
+
+// metaplexMetadata reads the Metaplex metadata accounts of mints.
+func (f Fetcher) metaplexMetadata(mints []string) (map[string]TokenMetadata, error) {
+	program := solana.MustPublicKeyFromBase58(MetadataProgramID)
+	addresses := make([]string, 0, len(mints))
+	index := make(map[string]string, len(mints)) // metadata account -> mint
+	for _, mint := range mints {
+		key, err := solana.PublicKeyFromBase58(mint)
+		if err != nil {
+			continue
+		}
+		pda, _, err := solana.FindProgramAddress([][]byte{[]byte("metadata"), program[:], key[:]}, program)
+		if err != nil {
+			continue
+		}
+		addresses = append(addresses, pda.String())
+		index[pda.String()] = mint
+	}
+
+	result := make(map[string]TokenMetadata)
+	err := f.multipleAccounts(addresses, "base64", func(address, owner string, data json.RawMessage) {
+		if owner != MetadataProgramID {
+			return
+		}
+		var encoded []string
+		if err := json.Unmarshal(data, &encoded); err != nil || len(encoded) == 0 {
+			return
+		}
+		raw, err := base64.StdEncoding.DecodeString(encoded[0])
+		if err != nil {
+			return
+		}
+		if meta, ok := parseMetaplexMetadata(raw); ok {
+			result[index[address]] = meta
+		}
+	})
+	return result, err
+}
+
+// parseMetaplexMetadata decodes the name, symbol and URI of a Metadata
+// account: key (1), update authority (32) and mint (32), followed by three
+// length-prefixed, zero-padded strings.
+func parseMetaplexMetadata(data []byte) (TokenMetadata, bool) {
+	offset := 1 + 32 + 32
+	readString := func() (string, bool) {
+		if len(data) < offset+4 {
+			return "", false
+		}
+		n := int(binary.LittleEndian.Uint32(data[offset:]))
+		offset += 4
+		if n < 0 || len(data) < offset+n {
+			return "", false
+		}
+		s := strings.TrimSpace(strings.TrimRight(string(data[offset:offset+n]), "\x00"))
+		offset += n
+		return s, true
+	}
+	name, ok1 := readString()
+	symbol, ok2 := readString()
+	uri, ok3 := readString()
+	if !ok1 || !ok2 || !ok3 || (name == "" && symbol == "") {
+		return TokenMetadata{}, false
+	}
+	return TokenMetadata{Name: name, Symbol: symbol, URI: uri}, true
+}
+
+// metadataExtension reads the token metadata extension of Token-2022 mints.
+func (f Fetcher) metadataExtension(mints []string) (map[string]TokenMetadata, error) {
+	result := make(map[string]TokenMetadata)
+	err := f.multipleAccounts(mints, "jsonParsed", func(address, owner string, data json.RawMessage) {
+		var parsed struct {
+			Parsed struct {
+				Info struct {
+					Extensions []struct {
+						Extension string `json:"extension"`
+						State     struct {
+							Name   string `json:"name"`
+							Symbol string `json:"symbol"`
+							URI    string `json:"uri"`
+						} `json:"state"`
+					} `json:"extensions"`
+				} `json:"info"`
+			} `json:"parsed"`
+		}
+		if err := json.Unmarshal(data, &parsed); err != nil {
+			return
+		}
+		for _, ext := range parsed.Parsed.Info.Extensions {
+			if ext.Extension == "tokenMetadata" && (ext.State.Name != "" || ext.State.Symbol != "") {
+				result[address] = TokenMetadata{Name: ext.State.Name, Symbol: ext.State.Symbol, URI: ext.State.URI}
+			}
+		}
+	})
+	return result, err
+}
+
+// multipleAccounts calls fn for every existing account of addresses, in
+// batches of maxAccountsPerRequest.
+func (f Fetcher) multipleAccounts(addresses []string, encoding string, fn func(address, owner string, data json.RawMessage)) error {
+	for start := 0; start < len(addresses); start += maxAccountsPerRequest {
+		batch := addresses[start:min(start+maxAccountsPerRequest, len(addresses))]
+		var resp multipleAccountsResponse
+		err := f.call("getMultipleAccounts", []interface{}{
+			batch,
+			map[string]string{"encoding": encoding},
+		}, &resp)
+		if err != nil {
+			return err
+		}
+		if resp.Error != nil {
+			return fmt.Errorf("RPC error: %s", resp.Error.Message)
+		}
+		for i, account := range resp.Result.Value {
+			if account != nil && i < len(batch) {
+				fn(batch[i], account.Owner, account.Data)
+			}
+		}
+	}
+	return nil
+}
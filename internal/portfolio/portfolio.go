@@ -0,0 +1,343 @@
+// Package portfolio fetches wallet balances from the RPC endpoint of a
+// network profile and prices them through the price package.
+package portfolio
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/gagliardetto/solana-go/rpc/jsonrpc"
+
+	"unruggable-go/internal/display"
+	"unruggable-go/internal/network"
+	"unruggable-go/internal/price"
+)
+
+// SOLMint is the wrapped SOL mint Jupiter quotes SOL prices under.
+const SOLMint = "So11111111111111111111111111111111111111112"
+
+// Token programs whose accounts are listed as holdings.
+const (
+	TokenProgramID     = "TokenkegQfeZyiNwAJbNbGKPFXCWuBvf9Ss623VQ5DA"
+	Token2022ProgramID = "TokenzQdBNbLqP5VEhdkAS6EPFLC1PHnBqCXEpPxuEb"
+)
+
+// TokenPrograms are queried for token accounts in this order.
+var TokenPrograms = []string{TokenProgramID, Token2022ProgramID}
+
+const tokenListCacheDuration = 1 * time.Hour
+
+// Token represents a token from the Jupiter token list
+type Token struct {
+	Address string `json:"address"`
+	Symbol  string `json:"symbol"`
+	Name    string `json:"name"`
+	LogoURI string `json:"logoURI"`
+}
+
+// Holding represents a wallet holding
+type Holding struct {
+	Symbol     string  `json:"symbol"`
+	Name       string  `json:"name,omitempty"`
+	Address    string  `json:"address"`
+	Image      string  `json:"image,omitempty"`
+	Balance    float64 `json:"balance"`
+	USDPrice   float64 `json:"usdPrice"`
+	USDBalance float64 `json:"usdBalance"`
+	Decimals   int     `json:"decimals"` // Added for SPL token transfers
+	// Verified is false for tokens missing from the token list. Their name
+	// and symbol come from on-chain metadata and can imitate other tokens.
+	Verified bool `json:"verified"`
+}
+
+// Balances is the SOL balance and priced token holdings of a wallet.
+type Balances struct {
+	SolBalance    float64   `json:"solBalance"`
+	SolBalanceUSD float64   `json:"solBalanceUSD"`
+	Assets        []Holding `json:"assets"`
+}
+
+// Token list cache, keyed by list URL
+var (
+	tokenListMu    sync.Mutex
+	tokenListCache = make(map[string]cachedTokenList)
+)
+
+type cachedTokenList struct {
+	tokens  []Token
+	fetched time.Time
+}
+
+// rpcRequest defines the structure for Solana RPC requests
+type rpcRequest struct {
+	JSONRPC string        `json:"jsonrpc"`
+	ID      string        `json:"id"`
+	Method  string        `json:"method"`
+	Params  []interface{} `json:"params"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// getBalanceResponse defines the response for getBalance
+type getBalanceResponse struct {
+	Result struct {
+		Value uint64 `json:"value"` // Lamports
+	} `json:"result"`
+	Error *rpcError `json:"error"`
+}
+
+// tokenAccount represents an SPL token account from getTokenAccountsByOwner
+type tokenAccount struct {
+	Pubkey  string `json:"pubkey"`
+	Account struct {
+		Owner string `json:"owner"` // token program
+		Data  struct {
+			Parsed struct {
+				Info struct {
+					Mint        string `json:"mint"`
+					TokenAmount struct {
+						Amount   string `json:"amount"`
+						Decimals int    `json:"decimals"`
+					} `json:"tokenAmount"`
+				} `json:"info"`
+			} `json:"parsed"`
+		} `json:"data"`
+	} `json:"account"`
+}
+
+// getTokenAccountsResponse defines the response for getTokenAccountsByOwner
+type getTokenAccountsResponse struct {
+	Result struct {
+		Value []tokenAccount `json:"value"`
+	} `json:"result"`
+	Error *rpcError `json:"error"`
+}
+
+// Fetcher reads balances and prices for one network.
+type Fetcher struct {
+	Network network.Profile
+	// RPC sends the JSON-RPC requests, e.g. through an rpcpool. Defaults to
+	// http.DefaultClient.
+	RPC *http.Client
+	// Metadata caches the metadata of tokens missing from the token list.
+	// If nil, it is read from the chain on every call.
+	Metadata *MetadataCache
+	// Prices selects the price sources holdings are valued with.
+	Prices price.Config
+}
+
+func (f Fetcher) rpcClient() *http.Client {
+	if f.RPC != nil {
+		return f.RPC
+	}
+	return http.DefaultClient
+}
+
+// prices quotes mints through the configured sources. Failures are logged and
+// leave the tokens unpriced.
+func (f Fetcher) prices(mints []string) map[string]price.Quote {
+	client := rpc.NewWithCustomRPCClient(jsonrpc.NewClientWithOpts(f.Network.RPCURL, &jsonrpc.RPCClientOpts{
+		HTTPClient: f.rpcClient(),
+	}))
+	source, err := f.Prices.Build(f.Network, client)
+	if err != nil {
+		log.Printf("Warning: No price sources: %v", err)
+		return nil
+	}
+	quotes, err := source.Prices(context.Background(), mints)
+	if err != nil {
+		log.Printf("Warning: Failed to fetch prices: %v", err)
+		return nil
+	}
+	return quotes
+}
+
+// TokenList fetches or returns the cached Jupiter token list
+func (f Fetcher) TokenList() ([]Token, error) {
+	tokenListURL := f.Network.JupiterTokensURL
+	if tokenListURL == "" {
+		return nil, fmt.Errorf("no token list endpoint configured for network %s", f.Network.Name)
+	}
+
+	tokenListMu.Lock()
+	cached, ok := tokenListCache[tokenListURL]
+	tokenListMu.Unlock()
+	if ok && time.Since(cached.fetched) < tokenListCacheDuration {
+		return cached.tokens, nil
+	}
+
+	resp, err := http.Get(tokenListURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch token list: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read token list response: %v", err)
+	}
+
+	if len(body) == 0 {
+		return nil, fmt.Errorf("token list response is empty")
+	}
+
+	var tokens []Token
+	if err := json.Unmarshal(body, &tokens); err != nil {
+		return nil, fmt.Errorf("failed to decode token list: %v", err)
+	}
+
+	log.Printf("Fetched token list: %d tokens", len(tokens))
+	tokenListMu.Lock()
+	tokenListCache[tokenListURL] = cachedTokenList{tokens: tokens, fetched: time.Now()}
+	tokenListMu.Unlock()
+
+	return tokens, nil
+}
+
+// Balances fetches the SOL balance and token holdings of owner. Tokens that
+// are not on the Jupiter token list are included unverified, named from
+// their on-chain metadata.
+func (f Fetcher) Balances(owner string) (*Balances, error) {
+	tokenList, err := f.TokenList()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get token list: %v", err)
+	}
+
+	var solResp getBalanceResponse
+	if err := f.call("getBalance", []interface{}{owner}, &solResp); err != nil {
+		return nil, fmt.Errorf("failed to fetch SOL balance: %v", err)
+	}
+	if solResp.Error != nil {
+		return nil, fmt.Errorf("RPC error fetching SOL balance: %s", solResp.Error.Message)
+	}
+
+	solBalance := float64(solResp.Result.Value) / 1e9 // Convert lamports to SOL
+
+	accounts, err := f.tokenAccounts(owner)
+	if err != nil {
+		return nil, err
+	}
+
+	// Collect token mints for price fetching
+	tokenMints := []string{SOLMint}
+	for _, account := range accounts {
+		tokenMints = append(tokenMints, account.Account.Data.Parsed.Info.Mint)
+	}
+
+	quotes := f.prices(tokenMints)
+	solPrice := quotes[SOLMint].Price
+
+	listed := make(map[string]Token, len(tokenList))
+	for _, token := range tokenList {
+		listed[token.Address] = token
+	}
+	unlisted := make(map[string]string) // mint -> token program
+	for _, account := range accounts {
+		if mint := account.Account.Data.Parsed.Info.Mint; listed[mint].Address == "" {
+			unlisted[mint] = account.Account.Owner
+		}
+	}
+	metadata := f.TokenMetadata(unlisted)
+
+	var holdings []Holding
+	for _, account := range accounts {
+		info := account.Account.Data.Parsed.Info
+		balance, err := strconv.ParseFloat(info.TokenAmount.Amount, 64)
+		if err != nil {
+			log.Printf("Failed to parse token balance for mint %s: %v", info.Mint, err)
+			continue
+		}
+		balance /= math.Pow10(info.TokenAmount.Decimals)
+
+		// Skip tiny balances
+		if balance < 0.000001 {
+			continue
+		}
+
+		holding := Holding{Address: info.Mint, Decimals: info.TokenAmount.Decimals}
+		if token, ok := listed[info.Mint]; ok {
+			holding.Symbol, holding.Name, holding.Image = token.Symbol, token.Name, token.LogoURI
+			holding.Verified = true
+		} else {
+			meta := metadata[info.Mint]
+			holding.Symbol, holding.Name = meta.Symbol, meta.Name
+			if holding.Symbol == "" {
+				holding.Symbol = display.Address(info.Mint)
+			}
+		}
+
+		holding.Balance = balance
+		holding.USDPrice = quotes[info.Mint].Price
+		holding.USDBalance = balance * holding.USDPrice
+		holdings = append(holdings, holding)
+	}
+
+	// Sort holdings by USD value (descending)
+	sort.Slice(holdings, func(i, j int) bool {
+		return holdings[i].USDBalance > holdings[j].USDBalance
+	})
+
+	return &Balances{
+		SolBalance:    solBalance,
+		SolBalanceUSD: solBalance * solPrice,
+		Assets:        holdings,
+	}, nil
+}
+
+// tokenAccounts lists the token accounts of owner under every token program.
+func (f Fetcher) tokenAccounts(owner string) ([]tokenAccount, error) {
+	var accounts []tokenAccount
+	for _, program := range TokenPrograms {
+		var tokenResp getTokenAccountsResponse
+		err := f.call("getTokenAccountsByOwner", []interface{}{
+			owner,
+			map[string]string{"programId": program},
+			map[string]string{"encoding": "jsonParsed"},
+		}, &tokenResp)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch token accounts: %v", err)
+		}
+		if tokenResp.Error != nil {
+			return nil, fmt.Errorf("RPC error fetching token accounts: %s", tokenResp.Error.Message)
+		}
+		accounts = append(accounts, tokenResp.Result.Value...)
+	}
+	return accounts, nil
+}
+
+// call posts one JSON-RPC request to the network's RPC URL and decodes the
+// response into out.
+func (f Fetcher) call(method string, params []interface{}, out interface{}) error {
+	body, err := json.Marshal(rpcRequest{JSONRPC: "2.0", ID: method, Method: method, Params: params})
+	if err != nil {
+		return err
+	}
+	resp, err := f.rpcClient().Post(f.Network.RPCURL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("invalid response: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,163 @@
+package price
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Composite queries several sources and returns the median of the quotes
+// that pass its checks. Quotes that are stale or too uncertain are dropped,
+// and a mint whose sources disagree by more than MaxDeviation gets no price.
+type Composite struct {
+	Sources       []Source
+	MaxAge        time.Duration // DefaultMaxAge if zero
+	MaxConfidence float64       // DefaultMaxConfidence if zero
+	MaxDeviation  float64       // DefaultMaxDeviation if zero
+	MinSources    int           // 1 if zero
+	// Log receives the reasons quotes were rejected. Defaults to log.Printf.
+	Log func(string)
+}
+
+func (c *Composite) Name() string {
+	names := make([]string, len(c.Sources))
+	for i, source := range c.Sources {
+		names[i] = source.Name()
+	}
+	return "median(" + strings.Join(names, ",") + ")"
+}
+
+// Prices queries every source at once. It fails only if no source could be
+// queried at all.
+func (c *Composite) Prices(ctx context.Context, mints []string) (map[string]Quote, error) {
+	results := make([]map[string]Quote, len(c.Sources))
+	errs := make([]error, len(c.Sources))
+	var wg sync.WaitGroup
+	for i, source := range c.Sources {
+		wg.Add(1)
+		go func(i int, source Source) {
+			defer wg.Done()
+			results[i], errs[i] = source.Prices(ctx, mints)
+		}(i, source)
+	}
+	wg.Wait()
+
+	failed := 0
+	for i, err := range errs {
+		if err != nil {
+			c.log(fmt.Sprintf("Price source %s failed: %v", c.Sources[i].Name(), err))
+			failed++
+		}
+	}
+	if failed == len(c.Sources) {
+		return nil, fmt.Errorf("all price sources failed: %v", errors.Join(errs...))
+	}
+
+	now := time.Now()
+	prices := make(map[string]Quote, len(mints))
+	for _, mint := range mints {
+		var quotes []Quote
+		for _, result := range results {
+			quote, ok := result[mint]
+			if !ok {
+				continue
+			}
+			if reason := c.check(quote, now); reason != "" {
+				c.log(fmt.Sprintf("Rejected %s price of %s: %s", quote.Source, mint, reason))
+				continue
+			}
+			quotes = append(quotes, quote)
+		}
+		if len(quotes) == 0 {
+			continue
+		}
+		if len(quotes) < c.minSources() {
+			c.log(fmt.Sprintf("No price for %s: %d of %d required sources", mint, len(quotes), c.minSources()))
+			continue
+		}
+		quote, err := c.combine(quotes)
+		if err != nil {
+			c.log(fmt.Sprintf("No price for %s: %v", mint, err))
+			continue
+		}
+		prices[mint] = quote
+	}
+	return prices, nil
+}
+
+// check returns why quote is rejected, or "" if it is usable.
+func (c *Composite) check(quote Quote, now time.Time) string {
+	if quote.Price <= 0 || math.IsNaN(quote.Price) || math.IsInf(quote.Price, 0) {
+		return fmt.Sprintf("invalid price %v", quote.Price)
+	}
+	maxAge := c.MaxAge
+	if maxAge <= 0 {
+		maxAge = DefaultMaxAge
+	}
+	if age := now.Sub(quote.PublishTime); age > maxAge {
+		return fmt.Sprintf("published %s ago", age.Round(time.Second))
+	}
+	maxConfidence := c.MaxConfidence
+	if maxConfidence <= 0 {
+		maxConfidence = DefaultMaxConfidence
+	}
+	if ratio := quote.Confidence / quote.Price; ratio > maxConfidence {
+		return fmt.Sprintf("confidence ±%.2f%% is wider than %.2f%%", ratio*100, maxConfidence*100)
+	}
+	return ""
+}
+
+// combine returns the median of quotes, which must be within MaxDeviation of
+// each other. The result carries the oldest publish time and the widest
+// confidence of its inputs.
+func (c *Composite) combine(quotes []Quote) (Quote, error) {
+	sort.Slice(quotes, func(i, j int) bool { return quotes[i].Price < quotes[j].Price })
+
+	median := quotes[len(quotes)/2].Price
+	if len(quotes)%2 == 0 {
+		median = (quotes[len(quotes)/2-1].Price + median) / 2
+	}
+
+	maxDeviation := c.MaxDeviation
+	if maxDeviation <= 0 {
+		maxDeviation = DefaultMaxDeviation
+	}
+	low, high := quotes[0], quotes[len(quotes)-1]
+	if spread := (high.Price - low.Price) / median; spread > maxDeviation {
+		return Quote{}, fmt.Errorf("sources disagree by %.2f%% (%s $%g, %s $%g)",
+			spread*100, low.Source, low.Price, high.Source, high.Price)
+	}
+
+	result := Quote{Price: median, PublishTime: quotes[0].PublishTime}
+	var sources []string
+	for _, quote := range quotes {
+		result.Confidence = math.Max(result.Confidence, quote.Confidence)
+		if quote.PublishTime.Before(result.PublishTime) {
+			result.PublishTime = quote.PublishTime
+		}
+		sources = append(sources, quote.Source)
+	}
+	result.Source = strings.Join(sources, "+")
+	return result, nil
+}
+
+func (c *Composite) minSources() int {
+	if c.MinSources <= 0 {
+		return 1
+	}
+	return c.MinSources
+}
+
+func (c *Composite) log(message string) {
+	if c.Log != nil {
+		c.Log(message)
+		return
+	}
+	log.Print(message)
+}
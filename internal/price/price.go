@@ -0,0 +1,167 @@
+// Package price quotes USD token prices from Jupiter, Pyth Hermes and the
+// Pyth price accounts on chain, and combines them into one checked price.
+package price
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gagliardetto/solana-go/rpc"
+
+	"unruggable-go/internal/network"
+)
+
+// Source names accepted in Config.Sources.
+const (
+	SourceJupiter     = "jupiter"
+	SourcePyth        = "pyth"         // Pyth Hermes
+	SourcePythOnChain = "pyth-onchain" // Pyth price feed accounts read over RPC
+)
+
+// SourceNames lists every source, in the order they are queried.
+var SourceNames = []string{SourceJupiter, SourcePyth, SourcePythOnChain}
+
+// Defaults of the checks in Config.
+const (
+	DefaultMaxAge        = 2 * time.Minute
+	DefaultMaxConfidence = 0.02 // confidence interval as a fraction of the price
+	DefaultMaxDeviation  = 0.03 // spread between sources as a fraction of the median
+)
+
+// httpClient is used for the Jupiter and Hermes APIs.
+var httpClient = &http.Client{Timeout: 15 * time.Second}
+
+// Quote is the USD price of a token from one source.
+type Quote struct {
+	Price float64
+	// Confidence is the uncertainty of Price in USD, zero if the source does
+	// not report one.
+	Confidence float64
+	// PublishTime is when the source last updated the price.
+	PublishTime time.Time
+	Source      string
+}
+
+// Source quotes USD prices of token mints.
+type Source interface {
+	Name() string
+	// Prices returns quotes for the mints the source knows. Mints without a
+	// price are left out; an error means the source could not be queried.
+	Prices(ctx context.Context, mints []string) (map[string]Quote, error)
+}
+
+// Config selects the sources a consumer trusts and how strictly quotes are
+// checked. The zero value uses every source available on the network with
+// the default checks.
+type Config struct {
+	Sources []string `json:"sources,omitempty"`
+	// MaxAge is how old a quote may be, in seconds.
+	MaxAge int `json:"maxAge,omitempty"`
+	// MaxConfidence is the widest accepted confidence interval, as a
+	// fraction of the price.
+	MaxConfidence float64 `json:"maxConfidence,omitempty"`
+	// MaxDeviation is the largest accepted spread between sources, as a
+	// fraction of the median.
+	MaxDeviation float64 `json:"maxDeviation,omitempty"`
+	// MinSources is how many sources must agree on a price. Defaults to 1.
+	MinSources int `json:"minSources,omitempty"`
+}
+
+// Validate checks the source names and limits.
+func (c Config) Validate() error {
+	for _, name := range c.Sources {
+		if !knownSource(name) {
+			return fmt.Errorf("unknown price source %q (known: %s)", name, strings.Join(SourceNames, ", "))
+		}
+	}
+	if c.MaxAge < 0 || c.MaxConfidence < 0 || c.MaxDeviation < 0 || c.MinSources < 0 {
+		return fmt.Errorf("price limits cannot be negative")
+	}
+	if c.MinSources > len(c.sources()) {
+		return fmt.Errorf("%d price sources required but only %d trusted", c.MinSources, len(c.sources()))
+	}
+	return nil
+}
+
+// Trusts reports whether the config uses the named source.
+func (c Config) Trusts(name string) bool {
+	for _, source := range c.sources() {
+		if source == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (c Config) sources() []string {
+	if len(c.Sources) == 0 {
+		return SourceNames
+	}
+	return c.Sources
+}
+
+// Build returns the composite of the trusted sources that profile has
+// endpoints for. The on-chain source reads through client and is skipped if
+// client is nil.
+func (c Config) Build(profile network.Profile, client *rpc.Client) (*Composite, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	composite := &Composite{
+		MaxAge:        time.Duration(c.MaxAge) * time.Second,
+		MaxConfidence: c.MaxConfidence,
+		MaxDeviation:  c.MaxDeviation,
+		MinSources:    c.MinSources,
+	}
+	for _, name := range c.sources() {
+		switch name {
+		case SourceJupiter:
+			if profile.JupiterPriceURL != "" {
+				composite.Sources = append(composite.Sources, &Jupiter{URL: profile.JupiterPriceURL})
+			}
+		case SourcePyth:
+			if profile.PythURL != "" {
+				composite.Sources = append(composite.Sources, &Hermes{URL: profile.PythURL})
+			}
+		case SourcePythOnChain:
+			if client != nil {
+				composite.Sources = append(composite.Sources, &PythOnChain{Client: client})
+			}
+		}
+	}
+	if len(composite.Sources) == 0 {
+		return nil, fmt.Errorf("none of the price sources %s is available on network %s",
+			strings.Join(c.sources(), ", "), profile.Name)
+	}
+	return composite, nil
+}
+
+func knownSource(name string) bool {
+	for _, known := range SourceNames {
+		if name == known {
+			return true
+		}
+	}
+	return false
+}
+
+// Feed is the Pyth price feed of a token.
+type Feed struct {
+	Symbol string
+	ID     string // hex, without 0x
+}
+
+// Feeds are the Pyth USD feeds by token mint. Pyth sources only quote these.
+var Feeds = map[string]Feed{
+	"So11111111111111111111111111111111111111112":  {"SOL", "ef0d8b6fda2ceba41da15d4095d1da392a0d2f8ed0c6c7bc0f4cfac8c280b56d"},
+	"EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v": {"USDC", "eaa020c61cc479712813461ce153894a96a6c00b21ed0cfc2798d1f9a9e9c94a"},
+	"Es9vMFrzaCERmJfrF4H2FYD4KCoNkY11McCe8BenwNYB": {"USDT", "2b89b9dc8fdf9f34709a5b106b472f0f39bb6ca9ce04b0fd7f2e971688e2e53b"},
+	"JUPyiwrYJFskUPiHa7hkeR8VUtAeFoSYbKedZNsDvCN":  {"JUP", "0a0408d619e9380abad35060f9192039ed5042fa6f82301d0e48bb52be830996"},
+	"jtojtomepa8beP8AuQc6eXt5FriJwfFMwQx2v2f9mCL":  {"JTO", "b43660a5f790c69354b0729a5ef9d50d68f1df92107540210b9cccba1f947cc2"},
+	"27G8MtK7VtTcCHkpASjSDdkWWYfoqT6ggEuKidVJidD4": {"JLP", "c811abc82b4bad1f9bd711a2773ccaa935b03ecef974236942cec5e0eb845a3a"},
+	"DezXAZ8z7PnrnRJjz3wXBoRgixCa6xjnB7YaB1pPB263": {"BONK", "72b021217ca3fe68922a19aaf990109cb9d84e9ad004b4d2025ad6f529314419"},
+	"EKpQGSJtjMFqKZ9KQanSqYXRcF8fBopzLHYxdM65zcjm": {"WIF", "4ca4beeca86f0d164160323817a4e42b10010a724c2217c6ee41b54cd4cc61fc"},
+}
@@ -0,0 +1,250 @@
+package price
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+// Jupiter quotes prices from the Jupiter price API. Its prices are derived
+// from live swap quotes, so they are stamped with the time of the request.
+type Jupiter struct {
+	URL string
+}
+
+func (j *Jupiter) Name() string { return SourceJupiter }
+
+func (j *Jupiter) Prices(ctx context.Context, mints []string) (map[string]Quote, error) {
+	endpoint, err := url.Parse(j.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Jupiter price URL: %v", err)
+	}
+	query := url.Values{}
+	query.Set("ids", strings.Join(mints, ","))
+	endpoint.RawQuery = query.Encode()
+
+	var resp struct {
+		Data map[string]*struct {
+			Price string `json:"price"`
+		} `json:"data"`
+	}
+	if err := getJSON(ctx, endpoint.String(), &resp); err != nil {
+		return nil, fmt.Errorf("failed to fetch Jupiter prices: %v", err)
+	}
+
+	now := time.Now()
+	quotes := make(map[string]Quote, len(resp.Data))
+	for mint, data := range resp.Data {
+		if data == nil {
+			continue
+		}
+		price, err := strconv.ParseFloat(data.Price, 64)
+		if err != nil || price <= 0 {
+			continue
+		}
+		quotes[mint] = Quote{Price: price, PublishTime: now, Source: SourceJupiter}
+	}
+	return quotes, nil
+}
+
+// Hermes quotes the Pyth feeds in Feeds from a Pyth Hermes endpoint.
+type Hermes struct {
+	URL string
+}
+
+func (h *Hermes) Name() string { return SourcePyth }
+
+func (h *Hermes) Prices(ctx context.Context, mints []string) (map[string]Quote, error) {
+	byID := make(map[string]string) // feed ID -> mint
+	query := url.Values{}
+	for _, mint := range mints {
+		if feed, ok := Feeds[mint]; ok {
+			byID[feed.ID] = mint
+			query.Add("ids[]", feed.ID)
+		}
+	}
+	if len(byID) == 0 {
+		return nil, nil
+	}
+	query.Set("parsed", "true")
+	endpoint, err := url.Parse(h.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Pyth URL: %v", err)
+	}
+	endpoint.RawQuery = query.Encode()
+
+	var resp struct {
+		Parsed []struct {
+			ID    string `json:"id"`
+			Price struct {
+				Price       string `json:"price"`
+				Conf        string `json:"conf"`
+				Expo        int    `json:"expo"`
+				PublishTime int64  `json:"publish_time"`
+			} `json:"price"`
+		} `json:"parsed"`
+	}
+	if err := getJSON(ctx, endpoint.String(), &resp); err != nil {
+		return nil, fmt.Errorf("failed to fetch Pyth prices: %v", err)
+	}
+
+	quotes := make(map[string]Quote, len(resp.Parsed))
+	for _, item := range resp.Parsed {
+		mint, ok := byID[strings.TrimPrefix(item.ID, "0x")]
+		if !ok {
+			continue
+		}
+		price, err := strconv.ParseInt(item.Price.Price, 10, 64)
+		if err != nil {
+			continue
+		}
+		conf, err := strconv.ParseUint(item.Price.Conf, 10, 64)
+		if err != nil {
+			continue
+		}
+		scale := math.Pow10(item.Price.Expo)
+		quotes[mint] = Quote{
+			Price:       float64(price) * scale,
+			Confidence:  float64(conf) * scale,
+			PublishTime: time.Unix(item.Price.PublishTime, 0),
+			Source:      SourcePyth,
+		}
+	}
+	return quotes, nil
+}
+
+// Pyth programs on Solana. Price feed accounts are derived from the push
+// oracle program and owned by the receiver program.
+var (
+	pythPushOracleProgramID = solana.MustPublicKeyFromBase58("pythWSnswVUd12oZpeFP8e9CVaEqJg25g1Vtc2biRsT")
+	pythReceiverProgramID   = solana.MustPublicKeyFromBase58("rec5EKMGg6MxZYaMdyBfgwp4d5rB9T1VQH5pJv5LtFJ")
+)
+
+// priceUpdateDiscriminator starts every PriceUpdateV2 account.
+var priceUpdateDiscriminator = func() []byte {
+	sum := sha256.Sum256([]byte("account:PriceUpdateV2"))
+	return sum[:8]
+}()
+
+// PythOnChain reads the Pyth price feed accounts of Feeds over RPC, which
+// does not depend on any off-chain API.
+type PythOnChain struct {
+	Client *rpc.Client
+}
+
+func (p *PythOnChain) Name() string { return SourcePythOnChain }
+
+func (p *PythOnChain) Prices(ctx context.Context, mints []string) (map[string]Quote, error) {
+	var accounts []solana.PublicKey
+	var accountMints []string
+	for _, mint := range mints {
+		feed, ok := Feeds[mint]
+		if !ok {
+			continue
+		}
+		account, err := feedAccount(feed.ID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid Pyth feed of %s: %v", feed.Symbol, err)
+		}
+		accounts = append(accounts, account)
+		accountMints = append(accountMints, mint)
+	}
+	if len(accounts) == 0 {
+		return nil, nil
+	}
+
+	resp, err := p.Client.GetMultipleAccountsWithOpts(ctx, accounts, &rpc.GetMultipleAccountsOpts{
+		Encoding:   solana.EncodingBase64,
+		Commitment: rpc.CommitmentConfirmed,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Pyth price accounts: %v", err)
+	}
+
+	quotes := make(map[string]Quote, len(accounts))
+	for i, account := range resp.Value {
+		if account == nil || i >= len(accountMints) || !account.Owner.Equals(pythReceiverProgramID) {
+			continue
+		}
+		if quote, ok := parsePriceUpdate(account.Data.GetBinary(), Feeds[accountMints[i]].ID); ok {
+			quotes[accountMints[i]] = quote
+		}
+	}
+	return quotes, nil
+}
+
+// feedAccount derives the price feed account of a feed on shard 0, where the
+// sponsored feeds are kept up to date.
+func feedAccount(feedID string) (solana.PublicKey, error) {
+	id, err := hex.DecodeString(feedID)
+	if err != nil || len(id) != 32 {
+		return solana.PublicKey{}, fmt.Errorf("feed ID %q is not 32 bytes of hex", feedID)
+	}
+	shard := []byte{0, 0}
+	account, _, err := solana.FindProgramAddress([][]byte{shard, id}, pythPushOracleProgramID)
+	return account, err
+}
+
+// parsePriceUpdate decodes a PriceUpdateV2 account: discriminator (8), write
+// authority (32), verification level (1, or 2 if partial) and the price feed
+// message. Only fully verified updates of feedID are accepted.
+func parsePriceUpdate(data []byte, feedID string) (Quote, bool) {
+	if len(data) < 8+32+1 || !bytes.Equal(data[:8], priceUpdateDiscriminator) {
+		return Quote{}, false
+	}
+	offset := 8 + 32
+	if data[offset] != 1 { // 0 is Partial{num_signatures}, 1 is Full
+		return Quote{}, false
+	}
+	offset++
+
+	// feed_id (32), price i64, conf u64, exponent i32, publish_time i64
+	if len(data) < offset+32+8+8+4+8 {
+		return Quote{}, false
+	}
+	if hex.EncodeToString(data[offset:offset+32]) != feedID {
+		return Quote{}, false
+	}
+	offset += 32
+	price := int64(binary.LittleEndian.Uint64(data[offset:]))
+	conf := binary.LittleEndian.Uint64(data[offset+8:])
+	expo := int32(binary.LittleEndian.Uint32(data[offset+16:]))
+	publishTime := int64(binary.LittleEndian.Uint64(data[offset+20:]))
+
+	scale := math.Pow10(int(expo))
+	return Quote{
+		Price:       float64(price) * scale,
+		Confidence:  float64(conf) * scale,
+		PublishTime: time.Unix(publishTime, 0),
+		Source:      SourcePythOnChain,
+	}, true
+}
+
+func getJSON(ctx context.Context, endpoint string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("bad status code: %d", resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
@@ -0,0 +1,134 @@
+package rpcpool
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// CheckHealth asks every endpoint for its slot and marks endpoints unhealthy
+// when they fail or lag more than MaxSlotLag behind the best one.
+func (p *Pool) CheckHealth(ctx context.Context) {
+	p.mu.Lock()
+	endpoints := append([]*endpoint(nil), p.endpoints...)
+	p.mu.Unlock()
+
+	type probe struct {
+		slot    uint64
+		latency time.Duration
+		err     error
+	}
+	probes := make([]probe, len(endpoints))
+
+	var wg sync.WaitGroup
+	for i, e := range endpoints {
+		wg.Add(1)
+		go func(i int, e *endpoint) {
+			defer wg.Done()
+			start := time.Now()
+			slot, err := p.getSlot(ctx, e)
+			probes[i] = probe{slot: slot, latency: time.Since(start), err: err}
+		}(i, e)
+	}
+	wg.Wait()
+
+	var best uint64
+	for _, pr := range probes {
+		if pr.err == nil && pr.slot > best {
+			best = pr.slot
+		}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := time.Now()
+	for i, e := range endpoints {
+		pr := probes[i]
+		e.metrics.LastChecked = now
+		if pr.err != nil {
+			e.metrics.Healthy = false
+			e.metrics.LastError = pr.err.Error()
+			continue
+		}
+		e.metrics.Slot = pr.slot
+		e.metrics.SlotLag = best - pr.slot
+		e.metrics.Healthy = e.metrics.SlotLag <= p.cfg.MaxSlotLag
+		if !e.hasSample {
+			e.metrics.Latency = pr.latency
+			e.hasSample = true
+		} else {
+			e.metrics.Latency = (e.metrics.Latency*4 + pr.latency) / 5
+		}
+	}
+}
+
+// StartHealthChecks runs CheckHealth now and then every interval until Close.
+func (p *Pool) StartHealthChecks(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			ctx, cancel := context.WithTimeout(context.Background(), p.cfg.Timeout)
+			p.CheckHealth(ctx)
+			cancel()
+
+			select {
+			case <-p.stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Close stops background health checks.
+func (p *Pool) Close() {
+	p.stopOnce.Do(func() { close(p.stop) })
+}
+
+// getSlot queries e directly, bypassing endpoint selection.
+func (p *Pool) getSlot(ctx context.Context, e *endpoint) (uint64, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.cfg.Timeout)
+	defer cancel()
+
+	body := []byte(`{"jsonrpc":"2.0","id":1,"method":"getSlot","params":[{"commitment":"confirmed"}]}`)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.url.String(), bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.cfg.Transport.RoundTrip(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("%s: HTTP %d", redact(e.url), resp.StatusCode)
+	}
+
+	var result struct {
+		Result uint64 `json:"result"`
+		Error  *struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return 0, fmt.Errorf("%s: invalid getSlot response: %v", redact(e.url), err)
+	}
+	if result.Error != nil {
+		return 0, fmt.Errorf("%s: %s", redact(e.url), result.Error.Message)
+	}
+	return result.Result, nil
+}
@@ -0,0 +1,449 @@
+// Package rpcpool spreads JSON-RPC traffic over several Solana endpoints,
+// backing off from rate-limited ones and failing over to healthy ones.
+//
+// A Pool is an http.RoundTripper: requests are sent to whichever endpoint the
+// pool picks, regardless of the URL they were built with. That lets both the
+// solana-go client and hand-written http.Post calls share the same endpoints.
+// Two kinds of request are pinned instead: those built for a URL outside the
+// pool, and Digital Asset Standard (DAS) calls, which only some providers
+// serve. Both are sent once, as built.
+package rpcpool
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// dasMethods are the Digital Asset Standard methods. Ordinary Solana nodes
+// reject them, so they must reach the endpoint they were built for.
+var dasMethods = map[string]bool{
+	"getAsset":              true,
+	"getAssetBatch":         true,
+	"getAssetProof":         true,
+	"getAssetProofBatch":    true,
+	"getAssetsByOwner":      true,
+	"getAssetsByGroup":      true,
+	"getAssetsByCreator":    true,
+	"getAssetsByAuthority":  true,
+	"searchAssets":          true,
+	"getSignaturesForAsset": true,
+	"getTokenAccounts":      true,
+	"getNftEditions":        true,
+}
+
+// RateLimitErrorCode is the JSON-RPC error code some providers return instead
+// of an HTTP 429.
+const RateLimitErrorCode = -32429
+
+// Defaults used when a Config field is zero.
+const (
+	DefaultMaxRetries     = 5
+	DefaultInitialBackoff = 1 * time.Second
+	DefaultMaxBackoff     = 30 * time.Second
+	DefaultMaxSlotLag     = 50
+	DefaultTimeout        = 30 * time.Second
+)
+
+// ErrNoEndpoints is returned by a pool created without any endpoint.
+var ErrNoEndpoints = errors.New("no RPC endpoints configured")
+
+// Config tunes retries, backoff and health checks.
+type Config struct {
+	// MaxRetries is how many endpoints are tried for one request.
+	MaxRetries int
+	// InitialBackoff is how long a rate-limited endpoint is skipped the first
+	// time. It doubles for every consecutive rate limit, up to MaxBackoff.
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	// MaxSlotLag is how far behind the best endpoint one may be and still be
+	// considered healthy.
+	MaxSlotLag uint64
+	// Timeout bounds a single attempt.
+	Timeout time.Duration
+	// Transport performs the HTTP requests. Defaults to http.DefaultTransport.
+	Transport http.RoundTripper
+}
+
+func (c Config) withDefaults() Config {
+	if c.MaxRetries <= 0 {
+		c.MaxRetries = DefaultMaxRetries
+	}
+	if c.InitialBackoff <= 0 {
+		c.InitialBackoff = DefaultInitialBackoff
+	}
+	if c.MaxBackoff <= 0 {
+		c.MaxBackoff = DefaultMaxBackoff
+	}
+	if c.MaxSlotLag == 0 {
+		c.MaxSlotLag = DefaultMaxSlotLag
+	}
+	if c.Timeout <= 0 {
+		c.Timeout = DefaultTimeout
+	}
+	if c.Transport == nil {
+		c.Transport = http.DefaultTransport
+	}
+	return c
+}
+
+// Metrics is a snapshot of one endpoint's counters and health.
+type Metrics struct {
+	URL          string
+	Healthy      bool
+	Requests     uint64
+	Failures     uint64
+	RateLimited  uint64
+	Latency      time.Duration // moving average of successful requests
+	Slot         uint64
+	SlotLag      uint64
+	BackoffUntil time.Time
+	LastError    string
+	LastChecked  time.Time
+}
+
+type endpoint struct {
+	url       *url.URL
+	metrics   Metrics
+	rateHits  int // consecutive rate limits, drives the backoff
+	hasSample bool
+}
+
+// Pool distributes requests over a fixed set of endpoints.
+type Pool struct {
+	cfg       Config
+	mu        sync.Mutex
+	endpoints []*endpoint
+	next      int
+	stop      chan struct{}
+	stopOnce  sync.Once
+}
+
+// New creates a pool over urls. Invalid URLs are rejected.
+func New(urls []string, cfg Config) (*Pool, error) {
+	p := &Pool{cfg: cfg.withDefaults(), stop: make(chan struct{})}
+	for _, raw := range urls {
+		u, err := url.Parse(raw)
+		if err != nil || u.Host == "" {
+			return nil, fmt.Errorf("invalid RPC URL %q", raw)
+		}
+		p.endpoints = append(p.endpoints, &endpoint{
+			url:     u,
+			metrics: Metrics{URL: redact(u), Healthy: true},
+		})
+	}
+	if len(p.endpoints) == 0 {
+		return nil, ErrNoEndpoints
+	}
+	return p, nil
+}
+
+// HTTPClient returns a client whose requests go through the pool. The URL of
+// each request is replaced with the endpoint the pool picks.
+func (p *Pool) HTTPClient() *http.Client {
+	return &http.Client{Transport: p}
+}
+
+// Metrics returns a snapshot of every endpoint in configuration order.
+func (p *Pool) Metrics() []Metrics {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	out := make([]Metrics, len(p.endpoints))
+	for i, e := range p.endpoints {
+		out[i] = e.metrics
+	}
+	return out
+}
+
+// RoundTrip sends req to the pool's endpoints until one answers without a
+// rate limit or transport error. Pinned requests go to req.URL only.
+func (p *Pool) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if !p.member(req.URL) || isDASRequest(body) {
+		return p.direct(req, body)
+	}
+
+	var lastErr error
+	for attempt := 0; attempt < p.cfg.MaxRetries; attempt++ {
+		e, wait := p.pick()
+		for e == nil {
+			// Every endpoint is backing off; wait for the first to recover
+			if err := sleep(req.Context(), wait); err != nil {
+				return nil, err
+			}
+			e, wait = p.pick()
+		}
+
+		resp, err := p.try(req, e, body)
+		if err == nil {
+			return resp, nil
+		}
+		lastErr = err
+		if req.Context().Err() != nil {
+			return nil, req.Context().Err()
+		}
+	}
+	return nil, fmt.Errorf("all RPC endpoints failed: %w", lastErr)
+}
+
+// try sends one attempt to e and updates its metrics.
+func (p *Pool) try(req *http.Request, e *endpoint, body []byte) (*http.Response, error) {
+	ctx, cancel := context.WithTimeout(req.Context(), p.cfg.Timeout)
+	out := req.Clone(ctx)
+	out.URL = e.url
+	out.Host = ""
+	out.Body = io.NopCloser(bytes.NewReader(body))
+	out.ContentLength = int64(len(body))
+	out.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+
+	start := time.Now()
+	resp, err := p.cfg.Transport.RoundTrip(out)
+	if err != nil {
+		cancel()
+		p.recordFailure(e, err)
+		return nil, err
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	cancel()
+	if err != nil {
+		p.recordFailure(e, err)
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusTooManyRequests || isRateLimited(data) {
+		err := fmt.Errorf("%s: rate limited", redact(e.url))
+		p.recordRateLimit(e, retryAfter(resp), err)
+		return nil, err
+	}
+	if resp.StatusCode >= 500 {
+		err := fmt.Errorf("%s: HTTP %d", redact(e.url), resp.StatusCode)
+		p.recordFailure(e, err)
+		return nil, err
+	}
+
+	p.recordSuccess(e, time.Since(start))
+	resp.Body = io.NopCloser(bytes.NewReader(data))
+	resp.ContentLength = int64(len(data))
+	return resp, nil
+}
+
+// direct sends req once to its own URL, bounded by the attempt timeout.
+func (p *Pool) direct(req *http.Request, body []byte) (*http.Response, error) {
+	ctx, cancel := context.WithTimeout(req.Context(), p.cfg.Timeout)
+	defer cancel()
+	out := req.Clone(ctx)
+	out.Body = io.NopCloser(bytes.NewReader(body))
+	out.ContentLength = int64(len(body))
+
+	resp, err := p.cfg.Transport.RoundTrip(out)
+	if err != nil {
+		return nil, err
+	}
+	data, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(data))
+	resp.ContentLength = int64(len(data))
+	return resp, nil
+}
+
+// pick returns the next usable endpoint, preferring healthy ones in
+// round-robin order. When all are backing off it returns nil and how long
+// until the first one is usable again.
+func (p *Pool) pick() (*endpoint, time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	var fallback *endpoint
+	soonest := time.Duration(-1)
+	for i := range p.endpoints {
+		e := p.endpoints[(p.next+i)%len(p.endpoints)]
+		if wait := e.metrics.BackoffUntil.Sub(now); wait > 0 {
+			if soonest < 0 || wait < soonest {
+				soonest = wait
+			}
+			continue
+		}
+		if e.metrics.Healthy {
+			p.next = (p.next + i + 1) % len(p.endpoints)
+			return e, 0
+		}
+		if fallback == nil {
+			fallback = e
+		}
+	}
+	if fallback != nil {
+		// Unhealthy endpoints are still better than none
+		return fallback, 0
+	}
+	return nil, soonest
+}
+
+func (p *Pool) recordSuccess(e *endpoint, latency time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	e.metrics.Requests++
+	e.rateHits = 0
+	e.metrics.LastError = ""
+	e.metrics.Healthy = e.metrics.SlotLag <= p.cfg.MaxSlotLag
+	if !e.hasSample {
+		e.metrics.Latency = latency
+		e.hasSample = true
+	} else {
+		e.metrics.Latency = (e.metrics.Latency*4 + latency) / 5
+	}
+}
+
+func (p *Pool) recordFailure(e *endpoint, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	e.metrics.Requests++
+	e.metrics.Failures++
+	e.metrics.LastError = err.Error()
+	e.metrics.Healthy = false
+}
+
+func (p *Pool) recordRateLimit(e *endpoint, hint time.Duration, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	e.metrics.Requests++
+	e.metrics.RateLimited++
+	e.metrics.LastError = err.Error()
+
+	backoff := p.cfg.InitialBackoff << e.rateHits
+	if backoff <= 0 || backoff > p.cfg.MaxBackoff {
+		backoff = p.cfg.MaxBackoff
+	}
+	if hint > backoff {
+		backoff = hint
+	}
+	e.rateHits++
+	e.metrics.BackoffUntil = time.Now().Add(backoff)
+}
+
+// member reports whether u is one of the pool's endpoints.
+func (p *Pool) member(u *url.URL) bool {
+	for _, e := range p.endpoints {
+		if e.url.Scheme == u.Scheme && e.url.Host == u.Host &&
+			strings.TrimSuffix(e.url.Path, "/") == strings.TrimSuffix(u.Path, "/") &&
+			e.url.RawQuery == u.RawQuery {
+			return true
+		}
+	}
+	return false
+}
+
+// isDASRequest reports whether a JSON-RPC request (single or batch) calls a
+// DAS method.
+func isDASRequest(data []byte) bool {
+	type rpcRequest struct {
+		Method string `json:"method"`
+	}
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return false
+	}
+	var requests []rpcRequest
+	if data[0] == '[' {
+		if json.Unmarshal(data, &requests) != nil {
+			return false
+		}
+	} else {
+		var single rpcRequest
+		if json.Unmarshal(data, &single) != nil {
+			return false
+		}
+		requests = append(requests, single)
+	}
+	for _, r := range requests {
+		if dasMethods[r.Method] {
+			return true
+		}
+	}
+	return false
+}
+
+// isRateLimited reports whether a JSON-RPC response (single or batch) carries
+// the rate-limit error code.
+func isRateLimited(data []byte) bool {
+	type rpcError struct {
+		Error *struct {
+			Code int `json:"code"`
+		} `json:"error"`
+	}
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return false
+	}
+	var responses []rpcError
+	if data[0] == '[' {
+		if json.Unmarshal(data, &responses) != nil {
+			return false
+		}
+	} else {
+		var single rpcError
+		if json.Unmarshal(data, &single) != nil {
+			return false
+		}
+		responses = append(responses, single)
+	}
+	for _, r := range responses {
+		if r.Error != nil && r.Error.Code == RateLimitErrorCode {
+			return true
+		}
+	}
+	return false
+}
+
+func retryAfter(resp *http.Response) time.Duration {
+	seconds, err := strconv.Atoi(resp.Header.Get("Retry-After"))
+	if err != nil || seconds <= 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// redact hides the path and query, which commonly carry API keys.
+func redact(u *url.URL) string {
+	host := u.Scheme + "://" + u.Host
+	if (u.Path != "" && u.Path != "/") || u.RawQuery != "" {
+		host += "/…"
+	}
+	return host
+}
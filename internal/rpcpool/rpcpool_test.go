@@ -0,0 +1,70 @@
+package rpcpool
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// countingServer answers every request and counts them.
+func countingServer(t *testing.T) (*httptest.Server, *int32) {
+	t.Helper()
+	var hits int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		io.WriteString(w, `{"jsonrpc":"2.0","id":1,"result":0}`)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &hits
+}
+
+func post(t *testing.T, client *http.Client, url, method string) {
+	t.Helper()
+	resp, err := client.Post(url, "application/json", strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"`+method+`"}`))
+	if err != nil {
+		t.Fatalf("%s: %v", method, err)
+	}
+	resp.Body.Close()
+}
+
+func TestPoolRotatesStandardMethods(t *testing.T) {
+	a, aHits := countingServer(t)
+	b, bHits := countingServer(t)
+	pool, err := New([]string{a.URL, b.URL}, Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := pool.HTTPClient()
+	for i := 0; i < 4; i++ {
+		post(t, client, a.URL, "getBalance")
+	}
+	if *aHits != 2 || *bHits != 2 {
+		t.Errorf("requests split %d/%d, want 2/2", *aHits, *bHits)
+	}
+}
+
+func TestPoolPinsDASAndForeignRequests(t *testing.T) {
+	a, aHits := countingServer(t)
+	b, bHits := countingServer(t)
+	das, dasHits := countingServer(t)
+	pool, err := New([]string{a.URL, b.URL}, Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := pool.HTTPClient()
+	for i := 0; i < 3; i++ {
+		post(t, client, a.URL, "getAssetsByOwner")
+	}
+	if *aHits != 3 || *bHits != 0 {
+		t.Errorf("DAS requests to a pool endpoint split %d/%d, want 3/0", *aHits, *bHits)
+	}
+	for i := 0; i < 3; i++ {
+		post(t, client, das.URL, "getAssetsByOwner")
+	}
+	if *dasHits != 3 || *aHits != 3 || *bHits != 0 {
+		t.Errorf("requests to a foreign URL reached the pool: das %d, a %d, b %d", *dasHits, *aHits, *bHits)
+	}
+}
@@ -0,0 +1,198 @@
+// Package session keeps one unlocked wallet key in memory for a limited time
+// and hands out signers instead of the raw key.
+package session
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+// DefaultTimeout is how long an unused session stays unlocked.
+const DefaultTimeout = 5 * time.Minute
+
+// ErrLocked is returned when signing with a wallet that is no longer unlocked.
+var ErrLocked = errors.New("wallet is locked")
+
+// Session holds the key of a single unlocked wallet. The key is zeroed when
+// the session locks, either explicitly or after the idle timeout.
+type Session struct {
+	mu        sync.Mutex
+	walletID  string
+	key       solana.PrivateKey
+	timeout   time.Duration
+	timer     *time.Timer
+	listeners []func(walletID string)
+}
+
+// New creates a locked session. A timeout of zero disables auto-lock.
+func New(timeout time.Duration) *Session {
+	return &Session{timeout: timeout}
+}
+
+// SetTimeout changes the idle timeout and restarts the timer if unlocked.
+func (s *Session) SetTimeout(timeout time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.timeout = timeout
+	s.resetTimer()
+}
+
+// Timeout returns the idle timeout.
+func (s *Session) Timeout() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.timeout
+}
+
+// OnLock registers fn to be called after the session locks.
+func (s *Session) OnLock(fn func(walletID string)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.listeners = append(s.listeners, fn)
+}
+
+// Unlock stores key for walletID, replacing any previously unlocked wallet.
+// The session takes its own copy and zeroes key.
+func (s *Session) Unlock(walletID string, key solana.PrivateKey) *Signer {
+	s.Lock()
+
+	s.mu.Lock()
+	s.walletID = walletID
+	s.key = append(solana.PrivateKey(nil), key...)
+	zero(key)
+	s.resetTimer()
+	pubKey := s.key.PublicKey()
+	s.mu.Unlock()
+
+	return &Signer{session: s, walletID: walletID, pubKey: pubKey}
+}
+
+// Lock zeroes the key and notifies listeners. It is a no-op when already locked.
+func (s *Session) Lock() {
+	s.mu.Lock()
+	if s.key == nil {
+		s.mu.Unlock()
+		return
+	}
+	walletID := s.walletID
+	zero(s.key)
+	s.key = nil
+	s.walletID = ""
+	if s.timer != nil {
+		s.timer.Stop()
+		s.timer = nil
+	}
+	listeners := append([]func(string){}, s.listeners...)
+	s.mu.Unlock()
+
+	for _, fn := range listeners {
+		fn(walletID)
+	}
+}
+
+// WalletID returns the unlocked wallet, or "" when locked.
+func (s *Session) WalletID() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.walletID
+}
+
+// Signer returns a signer for walletID if it is the unlocked wallet.
+func (s *Session) Signer(walletID string) (*Signer, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.key == nil || s.walletID != walletID {
+		return nil, false
+	}
+	s.resetTimer()
+	return &Signer{session: s, walletID: walletID, pubKey: s.key.PublicKey()}, true
+}
+
+// withKey runs fn with a copy of the key for walletID and zeroes the copy afterwards.
+func (s *Session) withKey(walletID string, fn func(solana.PrivateKey) error) error {
+	s.mu.Lock()
+	if s.key == nil || s.walletID != walletID {
+		s.mu.Unlock()
+		return ErrLocked
+	}
+	key := append(solana.PrivateKey(nil), s.key...)
+	s.resetTimer()
+	s.mu.Unlock()
+
+	defer zero(key)
+	return fn(key)
+}
+
+// resetTimer restarts the idle timer. s.mu must be held.
+func (s *Session) resetTimer() {
+	if s.timer != nil {
+		s.timer.Stop()
+		s.timer = nil
+	}
+	if s.key == nil || s.timeout <= 0 {
+		return
+	}
+	s.timer = time.AfterFunc(s.timeout, s.Lock)
+}
+
+func zero(key solana.PrivateKey) {
+	for i := range key {
+		key[i] = 0
+	}
+}
+
+// Signer signs on behalf of an unlocked wallet. It stops working once the
+// session locks or switches to another wallet.
+type Signer struct {
+	session  *Session
+	walletID string
+	pubKey   solana.PublicKey
+}
+
+// WalletID returns the wallet the signer belongs to.
+func (s *Signer) WalletID() string {
+	return s.walletID
+}
+
+// PublicKey returns the wallet address.
+func (s *Signer) PublicKey() solana.PublicKey {
+	return s.pubKey
+}
+
+// Unlocked reports whether the signer can still sign.
+func (s *Signer) Unlocked() bool {
+	return s.session.WalletID() == s.walletID
+}
+
+// SignTransaction adds the wallet's signature to tx.
+func (s *Signer) SignTransaction(tx *solana.Transaction) error {
+	return s.session.withKey(s.walletID, func(key solana.PrivateKey) error {
+		_, err := tx.Sign(func(pubKey solana.PublicKey) *solana.PrivateKey {
+			if pubKey.Equals(s.pubKey) {
+				return &key
+			}
+			return nil
+		})
+		return err
+	})
+}
+
+// SignMessage signs an arbitrary message with the wallet key.
+func (s *Signer) SignMessage(message []byte) (solana.Signature, error) {
+	var sig solana.Signature
+	err := s.session.withKey(s.walletID, func(key solana.PrivateKey) error {
+		var err error
+		sig, err = key.Sign(message)
+		return err
+	})
+	return sig, err
+}
+
+// WithPrivateKey passes a temporary copy of the key to fn for libraries that
+// need the key itself. fn must not retain it; it is zeroed when fn returns.
+func (s *Signer) WithPrivateKey(fn func(solana.PrivateKey) error) error {
+	return s.session.withKey(s.walletID, fn)
+}
@@ -0,0 +1,308 @@
+// Package simulate runs a transaction against the current chain state before
+// it is signed and reports what it would change for the wallet: SOL and token
+// balances of the accounts the wallet owns, fees, compute used and program
+// logs.
+package simulate
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+
+	"unruggable-go/internal/display"
+	"unruggable-go/internal/fee"
+	"unruggable-go/internal/stake"
+)
+
+// lamportsPerSignature is the base fee charged per signature.
+const lamportsPerSignature = 5000
+
+// Layout of SPL token accounts and mints, shared by Token-2022.
+const (
+	tokenAccountSize = 165
+	mintDecimals     = 44
+)
+
+// SOLChange is the change in lamports of an account the wallet owns: the
+// wallet itself or a stake account it has authority over.
+type SOLChange struct {
+	Account solana.PublicKey
+	Stake   bool // a stake account rather than the wallet
+	Before  uint64
+	After   uint64
+}
+
+// Delta returns the change in lamports.
+func (c SOLChange) Delta() int64 {
+	return int64(c.After) - int64(c.Before)
+}
+
+// TokenChange is the change in balance of a token account the wallet owns.
+// Before is zero for accounts the transaction creates and After for accounts
+// it closes.
+type TokenChange struct {
+	Account  solana.PublicKey
+	Mint     solana.PublicKey
+	Decimals uint8
+	Before   uint64 // base units
+	After    uint64
+}
+
+// Delta returns the change in whole tokens.
+func (c TokenChange) Delta() float64 {
+	return (float64(c.After) - float64(c.Before)) / pow10(c.Decimals)
+}
+
+// Amount returns After in whole tokens.
+func (c TokenChange) Amount() float64 {
+	return float64(c.After) / pow10(c.Decimals)
+}
+
+// Preview is the outcome of a simulation.
+type Preview struct {
+	// Err describes why the transaction would fail, or is empty.
+	Err           string
+	Logs          []string
+	UnitsConsumed uint64
+	Budget        fee.Budget
+	BaseFee       uint64 // lamports
+	// SOL lists the wallet first, then changed stake accounts. Tokens lists
+	// changed token accounts.
+	SOL    []SOLChange
+	Tokens []TokenChange
+}
+
+// Failed reports whether the transaction would fail.
+func (p *Preview) Failed() bool {
+	return p.Err != ""
+}
+
+// Fee returns the lamports the transaction costs in fees.
+func (p *Preview) Fee() uint64 {
+	return p.BaseFee + p.Budget.PriorityFee()
+}
+
+// String summarises the preview on one line for logs.
+func (p *Preview) String() string {
+	var parts []string
+	if p.Failed() {
+		parts = append(parts, "failed: "+p.Err)
+	} else {
+		parts = append(parts, "ok")
+	}
+	parts = append(parts,
+		fmt.Sprintf("%d CU", p.UnitsConsumed),
+		fmt.Sprintf("fee %s SOL", display.Lamports(int64(p.Fee()))))
+	for _, change := range p.SOL {
+		if change.Delta() == 0 {
+			continue
+		}
+		label := "SOL"
+		if change.Stake {
+			label = "SOL in stake " + display.Address(change.Account.String())
+		}
+		parts = append(parts, fmt.Sprintf("%s %s", label, display.Signed(display.Lamports(change.Delta()))))
+	}
+	for _, change := range p.Tokens {
+		parts = append(parts, fmt.Sprintf("%s %s", display.Address(change.Mint.String()), display.Signed(strconv.FormatFloat(change.Delta(), 'f', -1, 64))))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Unsigned returns a transaction of instructions paid by payer, ready to be
+// simulated: the node replaces its blockhash and does not check signatures,
+// but their slots must be present.
+func Unsigned(payer solana.PublicKey, instructions []solana.Instruction) (*solana.Transaction, error) {
+	tx, err := solana.NewTransaction(instructions, solana.Hash{}, solana.TransactionPayer(payer))
+	if err != nil {
+		return nil, fmt.Errorf("error creating simulation transaction: %v", err)
+	}
+	tx.Signatures = make([]solana.Signature, tx.Message.Header.NumRequiredSignatures)
+	return tx, nil
+}
+
+// Run simulates tx, signed or not, and returns the changes it would make to
+// the accounts owner owns. A transaction that would fail is not an error;
+// the reason is in Preview.Err.
+func Run(ctx context.Context, client *rpc.Client, tx *solana.Transaction, owner solana.PublicKey) (*Preview, error) {
+	accounts, err := tx.Message.Writable()
+	if err != nil {
+		return nil, fmt.Errorf("error reading transaction accounts: %v", err)
+	}
+
+	before, err := client.GetMultipleAccountsWithOpts(ctx, accounts, &rpc.GetMultipleAccountsOpts{
+		Commitment: rpc.CommitmentProcessed,
+		Encoding:   solana.EncodingBase64,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error getting accounts: %v", err)
+	}
+	result, err := client.SimulateTransactionWithOpts(ctx, tx, &rpc.SimulateTransactionOpts{
+		Commitment:             rpc.CommitmentProcessed,
+		ReplaceRecentBlockhash: true,
+		Accounts: &rpc.SimulateTransactionAccountsOpts{
+			Encoding:  solana.EncodingBase64,
+			Addresses: accounts,
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error simulating transaction: %v", err)
+	}
+
+	preview := &Preview{
+		Logs:    result.Value.Logs,
+		Budget:  fee.FromTransaction(tx),
+		BaseFee: uint64(tx.Message.Header.NumRequiredSignatures) * lamportsPerSignature,
+	}
+	if result.Value.UnitsConsumed != nil {
+		preview.UnitsConsumed = *result.Value.UnitsConsumed
+	}
+	if result.Value.Err != nil {
+		preview.Err = describeError(result.Value.Err)
+		// A failed transaction changes nothing but the fee payer.
+		return preview, nil
+	}
+	if len(before.Value) != len(accounts) || len(result.Value.Accounts) != len(accounts) {
+		return nil, fmt.Errorf("simulation returned %d of %d accounts", len(result.Value.Accounts), len(accounts))
+	}
+
+	for i, address := range accounts {
+		pre, post := before.Value[i], result.Value.Accounts[i]
+		if address == owner {
+			preview.SOL = append([]SOLChange{{Account: address, Before: lamports(pre), After: lamports(post)}}, preview.SOL...)
+			continue
+		}
+		if ownedStake(pre, owner) || ownedStake(post, owner) {
+			if change := (SOLChange{Account: address, Stake: true, Before: lamports(pre), After: lamports(post)}); change.Delta() != 0 {
+				preview.SOL = append(preview.SOL, change)
+			}
+			continue
+		}
+		preMint, preAmount, preOwned := tokenBalance(pre, owner)
+		postMint, postAmount, postOwned := tokenBalance(post, owner)
+		if !preOwned && !postOwned {
+			continue
+		}
+		change := TokenChange{Account: address, Mint: preMint}
+		if preOwned {
+			change.Before = preAmount
+		}
+		if postOwned {
+			change.Mint, change.After = postMint, postAmount
+		}
+		if change.Before != change.After {
+			preview.Tokens = append(preview.Tokens, change)
+		}
+	}
+	if len(preview.SOL) == 0 || preview.SOL[0].Account != owner {
+		// The wallet is not written to; show its balance as unchanged.
+		balance, err := client.GetBalance(ctx, owner, rpc.CommitmentProcessed)
+		if err == nil {
+			preview.SOL = append([]SOLChange{{Account: owner, Before: balance.Value, After: balance.Value}}, preview.SOL...)
+		}
+	}
+
+	if err := loadDecimals(ctx, client, preview.Tokens); err != nil {
+		return nil, err
+	}
+	sort.Slice(preview.Tokens, func(i, j int) bool {
+		return preview.Tokens[i].Mint.String() < preview.Tokens[j].Mint.String()
+	})
+	return preview, nil
+}
+
+// loadDecimals fills in the decimals of the changed tokens' mints.
+func loadDecimals(ctx context.Context, client *rpc.Client, changes []TokenChange) error {
+	if len(changes) == 0 {
+		return nil
+	}
+	var mints []solana.PublicKey
+	seen := make(map[solana.PublicKey]bool)
+	for _, change := range changes {
+		if !seen[change.Mint] {
+			seen[change.Mint] = true
+			mints = append(mints, change.Mint)
+		}
+	}
+	result, err := client.GetMultipleAccountsWithOpts(ctx, mints, &rpc.GetMultipleAccountsOpts{
+		Commitment: rpc.CommitmentProcessed,
+		Encoding:   solana.EncodingBase64,
+	})
+	if err != nil {
+		return fmt.Errorf("error getting mints: %v", err)
+	}
+	decimals := make(map[solana.PublicKey]uint8, len(mints))
+	for i, account := range result.Value {
+		if account == nil || account.Data == nil || i >= len(mints) {
+			continue
+		}
+		if data := account.Data.GetBinary(); len(data) > mintDecimals {
+			decimals[mints[i]] = data[mintDecimals]
+		}
+	}
+	for i := range changes {
+		changes[i].Decimals = decimals[changes[i].Mint]
+	}
+	return nil
+}
+
+// tokenBalance returns the mint and amount of account if it is a token
+// account owned by owner.
+func tokenBalance(account *rpc.Account, owner solana.PublicKey) (solana.PublicKey, uint64, bool) {
+	if account == nil || account.Data == nil {
+		return solana.PublicKey{}, 0, false
+	}
+	if !account.Owner.Equals(solana.TokenProgramID) && !account.Owner.Equals(solana.Token2022ProgramID) {
+		return solana.PublicKey{}, 0, false
+	}
+	data := account.Data.GetBinary()
+	if len(data) < tokenAccountSize || solana.PublicKeyFromBytes(data[32:64]) != owner {
+		return solana.PublicKey{}, 0, false
+	}
+	return solana.PublicKeyFromBytes(data[0:32]), binary.LittleEndian.Uint64(data[64:72]), true
+}
+
+// ownedStake reports whether account is a stake account owner is staker or
+// withdrawer of.
+func ownedStake(account *rpc.Account, owner solana.PublicKey) bool {
+	if account == nil || account.Data == nil || !account.Owner.Equals(solana.StakeProgramID) {
+		return false
+	}
+	parsed, err := stake.Parse(solana.PublicKey{}, account.Lamports, account.Data.GetBinary())
+	return err == nil && (parsed.Staker == owner || parsed.Withdrawer == owner)
+}
+
+func lamports(account *rpc.Account) uint64 {
+	if account == nil {
+		return 0
+	}
+	return account.Lamports
+}
+
+// describeError renders a simulation error such as
+// {"InstructionError":[2,{"Custom":1}]} compactly.
+func describeError(err interface{}) string {
+	if s, ok := err.(string); ok {
+		return s
+	}
+	data, jsonErr := json.Marshal(err)
+	if jsonErr != nil {
+		return fmt.Sprint(err)
+	}
+	return string(data)
+}
+
+func pow10(decimals uint8) float64 {
+	result := 1.0
+	for i := uint8(0); i < decimals; i++ {
+		result *= 10
+	}
+	return result
+}
@@ -0,0 +1,92 @@
+// Package squads builds the creation of a Squads v4 multisig as a plain
+// instruction. squads-go only offers a helper that builds, signs and sends
+// the transaction itself, which leaves no room for a compute budget or a
+// simulation before signing.
+package squads
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/hogyzen12/squads-go/generated/squads_multisig_program"
+	"github.com/hogyzen12/squads-go/pkg/multisig"
+)
+
+// ProgramID is the Squads v4 program on every cluster.
+var ProgramID = solana.MustPublicKeyFromBase58("SQDS4ep65T869zMMBKyuUq6aD6EgTu8psMjkvj52pCf")
+
+// Create is a multisig creation waiting to be signed.
+type Create struct {
+	// Multisig is the address of the new multisig.
+	Multisig solana.PublicKey
+	// CreateKey is the one-off key the address is derived from. It signs
+	// the transaction next to the payer.
+	CreateKey   solana.PrivateKey
+	Instruction solana.Instruction
+}
+
+// NewCreate builds the instruction creating a multisig of members, of whom
+// threshold voters must approve a transaction, paid for by payer.
+func NewCreate(client *rpc.Client, payer solana.PublicKey, members []multisig.Member, threshold uint16) (*Create, error) {
+	if len(members) == 0 {
+		return nil, errors.New("a multisig needs at least one member")
+	}
+	voters := 0
+	converted := make([]squads_multisig_program.Member, len(members))
+	for i, m := range members {
+		if m.Permissions > multisig.PermissionFull {
+			return nil, fmt.Errorf("invalid permissions %d for %s: must be 0-7", m.Permissions, m.Key)
+		}
+		if m.Permissions&multisig.PermissionVote != 0 {
+			voters++
+		}
+		converted[i] = squads_multisig_program.Member{
+			Key:         m.Key,
+			Permissions: squads_multisig_program.Permissions{Mask: m.Permissions},
+		}
+	}
+	if threshold == 0 || int(threshold) > voters {
+		return nil, fmt.Errorf("threshold must be between 1 and the %d voting members", voters)
+	}
+
+	configPDA, _ := multisig.GetProgramConfigPDA(ProgramID)
+	config, err := multisig.FetchProgramConfig(client, configPDA)
+	if err != nil {
+		return nil, fmt.Errorf("error reading Squads program config: %v", err)
+	}
+
+	createKey := solana.NewWallet().PrivateKey
+	address, _ := multisig.GetMultisigPDA(createKey.PublicKey(), ProgramID)
+	instruction := squads_multisig_program.NewMultisigCreateV2Instruction(
+		squads_multisig_program.MultisigCreateArgsV2{
+			Threshold: threshold,
+			Members:   converted,
+		},
+		configPDA,
+		config.Treasury,
+		address,
+		createKey.PublicKey(),
+		payer,
+		solana.SystemProgramID,
+	).Build()
+	return &Create{Multisig: address, CreateKey: createKey, Instruction: instruction}, nil
+}
+
+// Sign adds the signatures of the create key and of payer to tx.
+func (c *Create) Sign(tx *solana.Transaction, payer solana.PrivateKey) error {
+	_, err := tx.Sign(func(key solana.PublicKey) *solana.PrivateKey {
+		switch {
+		case key.Equals(payer.PublicKey()):
+			return &payer
+		case key.Equals(c.CreateKey.PublicKey()):
+			return &c.CreateKey
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("error signing transaction: %v", err)
+	}
+	return nil
+}
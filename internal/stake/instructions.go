@@ -0,0 +1,169 @@
+package stake
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/programs/system"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+// ConfigID is the stake config account DelegateStake still expects.
+var ConfigID = solana.MustPublicKeyFromBase58("StakeConfig11111111111111111111111111111111")
+
+// Stake program instruction indices.
+const (
+	instructionInitialize = 0
+	instructionDelegate   = 2
+	instructionSplit      = 3
+	instructionWithdraw   = 4
+	instructionDeactivate = 5
+	instructionMerge      = 7
+)
+
+// NewSeed returns a fresh seed for an account derived from the wallet.
+func NewSeed() string {
+	return fmt.Sprintf("stake:%d", time.Now().UnixNano())
+}
+
+// Address returns the stake account derived from base and seed.
+func Address(base solana.PublicKey, seed string) (solana.PublicKey, error) {
+	return solana.CreateWithSeed(base, seed, solana.StakeProgramID)
+}
+
+// RentExemptReserve returns the lamports a stake account keeps for rent.
+func RentExemptReserve(ctx context.Context, client *rpc.Client) (uint64, error) {
+	rent, err := client.GetMinimumBalanceForRentExemption(ctx, AccountSize, rpc.CommitmentConfirmed)
+	if err != nil {
+		return 0, fmt.Errorf("error getting rent exemption: %v", err)
+	}
+	return rent, nil
+}
+
+// CreateAndDelegate returns the instructions funding a stake account with
+// lamports at the address derived from owner and seed, making owner its
+// staker and withdrawer, and delegating it to vote. lamports must include
+// the rent-exempt reserve.
+func CreateAndDelegate(owner solana.PublicKey, seed string, lamports uint64, vote solana.PublicKey) ([]solana.Instruction, solana.PublicKey, error) {
+	account, err := Address(owner, seed)
+	if err != nil {
+		return nil, solana.PublicKey{}, err
+	}
+	create := system.NewCreateAccountWithSeedInstruction(owner, seed, lamports, AccountSize, solana.StakeProgramID, owner, account, owner).Build()
+
+	data := instructionData(instructionInitialize)
+	data = append(data, owner[:]...) // staker
+	data = append(data, owner[:]...) // withdrawer
+	data = append(data, make([]byte, 8+8+32)...)
+	initialize := solana.NewInstruction(solana.StakeProgramID, solana.AccountMetaSlice{
+		solana.Meta(account).WRITE(),
+		solana.Meta(solana.SysVarRentPubkey),
+	}, data)
+
+	return []solana.Instruction{create, initialize, Delegate(account, vote, owner)}, account, nil
+}
+
+// Delegate delegates account to vote, or redelegates an inactive account.
+func Delegate(account, vote, staker solana.PublicKey) solana.Instruction {
+	return solana.NewInstruction(solana.StakeProgramID, solana.AccountMetaSlice{
+		solana.Meta(account).WRITE(),
+		solana.Meta(vote),
+		solana.Meta(solana.SysVarClockPubkey),
+		solana.Meta(solana.SysVarStakeHistoryPubkey),
+		solana.Meta(ConfigID),
+		solana.Meta(staker).SIGNER(),
+	}, instructionData(instructionDelegate))
+}
+
+// Deactivate starts the cooldown of account's stake.
+func Deactivate(account, staker solana.PublicKey) solana.Instruction {
+	return solana.NewInstruction(solana.StakeProgramID, solana.AccountMetaSlice{
+		solana.Meta(account).WRITE(),
+		solana.Meta(solana.SysVarClockPubkey),
+		solana.Meta(staker).SIGNER(),
+	}, instructionData(instructionDeactivate))
+}
+
+// Withdraw moves lamports from account to recipient.
+func Withdraw(account, recipient, withdrawer solana.PublicKey, lamports uint64) solana.Instruction {
+	data := binary.LittleEndian.AppendUint64(instructionData(instructionWithdraw), lamports)
+	return solana.NewInstruction(solana.StakeProgramID, solana.AccountMetaSlice{
+		solana.Meta(account).WRITE(),
+		solana.Meta(recipient).WRITE(),
+		solana.Meta(solana.SysVarClockPubkey),
+		solana.Meta(solana.SysVarStakeHistoryPubkey),
+		solana.Meta(withdrawer).SIGNER(),
+	}, data)
+}
+
+// Split moves lamports of account's stake to a new account derived from
+// owner and seed, which owner funds with rent for its reserve. The new
+// account keeps the authorities and delegation of account.
+func Split(account, owner solana.PublicKey, seed string, lamports, rent uint64) ([]solana.Instruction, solana.PublicKey, error) {
+	split, err := Address(owner, seed)
+	if err != nil {
+		return nil, solana.PublicKey{}, err
+	}
+	create := system.NewCreateAccountWithSeedInstruction(owner, seed, rent, AccountSize, solana.StakeProgramID, owner, split, owner).Build()
+	data := binary.LittleEndian.AppendUint64(instructionData(instructionSplit), lamports)
+	instruction := solana.NewInstruction(solana.StakeProgramID, solana.AccountMetaSlice{
+		solana.Meta(account).WRITE(),
+		solana.Meta(split).WRITE(),
+		solana.Meta(owner).SIGNER(),
+	}, data)
+	return []solana.Instruction{create, instruction}, split, nil
+}
+
+// Merge moves all of source into destination and closes source. Both need
+// the same authorities and lockup, and compatible delegations.
+func Merge(destination, source, staker solana.PublicKey) solana.Instruction {
+	return solana.NewInstruction(solana.StakeProgramID, solana.AccountMetaSlice{
+		solana.Meta(destination).WRITE(),
+		solana.Meta(source).WRITE(),
+		solana.Meta(solana.SysVarClockPubkey),
+		solana.Meta(solana.SysVarStakeHistoryPubkey),
+		solana.Meta(staker).SIGNER(),
+	}, instructionData(instructionMerge))
+}
+
+// CanMerge returns why source cannot be merged into destination in epoch, or
+// nil. The stake program has the final say.
+func CanMerge(destination, source Account, epoch uint64) error {
+	if destination.Address == source.Address {
+		return fmt.Errorf("cannot merge an account into itself")
+	}
+	if destination.Staker != source.Staker || destination.Withdrawer != source.Withdrawer {
+		return fmt.Errorf("accounts have different authorities")
+	}
+	if destination.LockupTimestamp != source.LockupTimestamp || destination.LockupEpoch != source.LockupEpoch || destination.Custodian != source.Custodian {
+		return fmt.Errorf("accounts have different lockups")
+	}
+	// The program treats accounts that were never delegated as inactive.
+	a, b := destination.State(epoch), source.State(epoch)
+	if a == StateInitialized {
+		a = StateInactive
+	}
+	if b == StateInitialized {
+		b = StateInactive
+	}
+	switch {
+	case a == StateDeactivating || b == StateDeactivating:
+		return fmt.Errorf("deactivating stake cannot be merged")
+	case a == StateInactive && (b == StateInactive || b == StateActivating),
+		a == StateActivating && b == StateInactive:
+		return nil
+	case a == b && (a == StateActivating || a == StateActive):
+		if destination.Voter != source.Voter {
+			return fmt.Errorf("%s accounts must be delegated to the same validator", a)
+		}
+		return nil
+	}
+	return fmt.Errorf("cannot merge %s stake into %s stake", b, a)
+}
+
+func instructionData(index uint32) []byte {
+	return binary.LittleEndian.AppendUint32(nil, index)
+}
@@ -0,0 +1,201 @@
+package stake
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+const testEpoch = 500
+
+// accountIn returns an account of owner in state at testEpoch, delegated to
+// voter if it is delegated at all.
+func accountIn(t *testing.T, state State, owner, voter solana.PublicKey) Account {
+	t.Helper()
+	a := Account{
+		Address:           solana.NewWallet().PublicKey(),
+		Staker:            owner,
+		Withdrawer:        owner,
+		Delegated:         state != StateInitialized,
+		Voter:             voter,
+		ActivationEpoch:   testEpoch - 10,
+		DeactivationEpoch: math.MaxUint64,
+	}
+	switch state {
+	case StateActivating:
+		a.ActivationEpoch = testEpoch
+	case StateDeactivating:
+		a.DeactivationEpoch = testEpoch
+	case StateInactive:
+		a.DeactivationEpoch = testEpoch - 1
+	}
+	if got := a.State(testEpoch); got != state {
+		t.Fatalf("built a %s account instead of %s", got, state)
+	}
+	return a
+}
+
+func TestCanMerge(t *testing.T) {
+	owner := solana.NewWallet().PublicKey()
+	voter := solana.NewWallet().PublicKey()
+	states := []State{StateInitialized, StateActivating, StateActive, StateDeactivating, StateInactive}
+
+	// Mergeable pairs with both accounts delegated to the same validator, as
+	// destination -> source. The stake program treats initialized accounts
+	// as inactive; any other pair is a mismatch.
+	mergeable := map[[2]State]bool{
+		{StateInitialized, StateInitialized}: true,
+		{StateInitialized, StateInactive}:    true,
+		{StateInitialized, StateActivating}:  true,
+		{StateInactive, StateInitialized}:    true,
+		{StateInactive, StateInactive}:       true,
+		{StateInactive, StateActivating}:     true,
+		{StateActivating, StateInitialized}:  true,
+		{StateActivating, StateInactive}:     true,
+		{StateActivating, StateActivating}:   true,
+		{StateActive, StateActive}:           true,
+	}
+	for _, dst := range states {
+		for _, src := range states {
+			err := CanMerge(accountIn(t, dst, owner, voter), accountIn(t, src, owner, voter), testEpoch)
+			if want := mergeable[[2]State{dst, src}]; (err == nil) != want {
+				t.Errorf("CanMerge(%s <- %s) = %v, want mergeable %v", dst, src, err, want)
+			}
+		}
+	}
+}
+
+func TestCanMergeRequirements(t *testing.T) {
+	owner := solana.NewWallet().PublicKey()
+	voter := solana.NewWallet().PublicKey()
+	other := solana.NewWallet().PublicKey()
+
+	same := accountIn(t, StateActive, owner, voter)
+	tests := []struct {
+		name        string
+		destination Account
+		source      Account
+		wantErr     bool
+	}{
+		{"same validator", accountIn(t, StateActive, owner, voter), accountIn(t, StateActive, owner, voter), false},
+		{"itself", same, same, true},
+		{"active, other validator", accountIn(t, StateActive, owner, voter), accountIn(t, StateActive, owner, other), true},
+		{"activating, other validator", accountIn(t, StateActivating, owner, voter), accountIn(t, StateActivating, owner, other), true},
+		{"inactive, other validator", accountIn(t, StateInactive, owner, voter), accountIn(t, StateInactive, owner, other), false},
+		{"other staker", accountIn(t, StateActive, owner, voter), func() Account {
+			a := accountIn(t, StateActive, owner, voter)
+			a.Staker = other
+			return a
+		}(), true},
+		{"other withdrawer", accountIn(t, StateInactive, owner, voter), func() Account {
+			a := accountIn(t, StateInactive, owner, voter)
+			a.Withdrawer = other
+			return a
+		}(), true},
+		{"other lockup", accountIn(t, StateInactive, owner, voter), func() Account {
+			a := accountIn(t, StateInactive, owner, voter)
+			a.LockupEpoch = testEpoch + 1
+			return a
+		}(), true},
+		{"other custodian", accountIn(t, StateInactive, owner, voter), func() Account {
+			a := accountIn(t, StateInactive, owner, voter)
+			a.Custodian = other
+			return a
+		}(), true},
+	}
+	for _, tt := range tests {
+		err := CanMerge(tt.destination, tt.source, testEpoch)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: CanMerge = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestCreateAndDelegate(t *testing.T) {
+	owner := solana.NewWallet().PublicKey()
+	vote := solana.NewWallet().PublicKey()
+	instructions, account, err := CreateAndDelegate(owner, "stake:1", 5_000_000_000, vote)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := Address(owner, "stake:1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !account.Equals(want) {
+		t.Errorf("account = %s, want %s", account, want)
+	}
+	if len(instructions) != 3 {
+		t.Fatalf("%d instructions, want create, initialize and delegate", len(instructions))
+	}
+
+	initialize := instructions[1]
+	data, err := initialize.Data()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Initialize(Authorized{staker, withdrawer}, Lockup{unix_timestamp,
+	// epoch, custodian}) with no lockup.
+	if len(data) != 4+32+32+8+8+32 {
+		t.Fatalf("initialize data is %d bytes, want 116", len(data))
+	}
+	if index := binary.LittleEndian.Uint32(data); index != instructionInitialize {
+		t.Errorf("instruction index = %d, want %d", index, instructionInitialize)
+	}
+	if !bytes.Equal(data[4:36], owner[:]) || !bytes.Equal(data[36:68], owner[:]) {
+		t.Error("staker and withdrawer are not the owner")
+	}
+	if !bytes.Equal(data[68:], make([]byte, 48)) {
+		t.Errorf("lockup = %x, want none", data[68:])
+	}
+	accounts := initialize.Accounts()
+	if len(accounts) != 2 || !accounts[0].PublicKey.Equals(account) || !accounts[0].IsWritable ||
+		!accounts[1].PublicKey.Equals(solana.SysVarRentPubkey) {
+		t.Errorf("initialize accounts = %v", accounts)
+	}
+
+	delegate, err := instructions[2].Data()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(delegate, []byte{instructionDelegate, 0, 0, 0}) {
+		t.Errorf("delegate data = %v", delegate)
+	}
+}
+
+func TestAmountInstructions(t *testing.T) {
+	account := solana.NewWallet().PublicKey()
+	owner := solana.NewWallet().PublicKey()
+	const lamports = 1_234_567_890_123
+
+	split, _, err := Split(account, owner, "stake:2", lamports, 2_282_880)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name        string
+		instruction solana.Instruction
+		index       uint32
+	}{
+		{"split", split[1], instructionSplit},
+		{"withdraw", Withdraw(account, owner, owner, lamports), instructionWithdraw},
+	}
+	for _, tt := range tests {
+		data, err := tt.instruction.Data()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(data) != 12 {
+			t.Fatalf("%s data is %d bytes, want 12", tt.name, len(data))
+		}
+		if index := binary.LittleEndian.Uint32(data); index != tt.index {
+			t.Errorf("%s index = %d, want %d", tt.name, index, tt.index)
+		}
+		if amount := binary.LittleEndian.Uint64(data[4:]); amount != lamports {
+			t.Errorf("%s amount = %d, want %d", tt.name, amount, lamports)
+		}
+	}
+}
@@ -0,0 +1,205 @@
+// Package stake lists and manages native stake accounts: creating and
+// delegating them, deactivating, withdrawing, splitting and merging. New
+// accounts are created at addresses derived from the wallet with a seed, so
+// the wallet key is the only signer needed.
+package stake
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+// AccountSize is the size of a stake account.
+const AccountSize = 200
+
+// Offsets of the authorities in a stake account, used to find the accounts
+// of a wallet.
+const (
+	stakerOffset     = 12
+	withdrawerOffset = 44
+)
+
+// State is where an account is in the stake lifecycle.
+type State string
+
+const (
+	StateInitialized  State = "initialized" // funded but never delegated
+	StateActivating   State = "activating"
+	StateActive       State = "active"
+	StateDeactivating State = "deactivating"
+	StateInactive     State = "inactive"
+)
+
+// Account is a parsed stake account.
+type Account struct {
+	Address           solana.PublicKey
+	Lamports          uint64
+	RentExemptReserve uint64
+	Staker            solana.PublicKey
+	Withdrawer        solana.PublicKey
+	LockupTimestamp   int64
+	LockupEpoch       uint64
+	Custodian         solana.PublicKey
+
+	// Set for delegated accounts only.
+	Delegated         bool
+	Voter             solana.PublicKey
+	Stake             uint64 // delegated lamports
+	ActivationEpoch   uint64
+	DeactivationEpoch uint64
+}
+
+// State returns the lifecycle stage of the account in epoch. Stake warms up
+// and cools down over one epoch unless the whole cluster is changing stake
+// faster than the warmup rate, which this does not model.
+func (a Account) State(epoch uint64) State {
+	switch {
+	case !a.Delegated:
+		return StateInitialized
+	case a.DeactivationEpoch != math.MaxUint64 && a.DeactivationEpoch < epoch:
+		return StateInactive
+	case a.DeactivationEpoch != math.MaxUint64:
+		return StateDeactivating
+	case a.ActivationEpoch >= epoch:
+		return StateActivating
+	}
+	return StateActive
+}
+
+// Locked reports whether a lockup still prevents withdrawals.
+func (a Account) Locked(now time.Time, epoch uint64) bool {
+	return a.LockupTimestamp > now.Unix() || a.LockupEpoch > epoch
+}
+
+// Withdrawable returns the lamports that can be withdrawn in epoch: all of
+// them once the stake is inactive, otherwise only what exceeds the stake and
+// the rent-exempt reserve.
+func (a Account) Withdrawable(epoch uint64) uint64 {
+	switch a.State(epoch) {
+	case StateInitialized, StateInactive:
+		return a.Lamports
+	}
+	locked := a.Stake + a.RentExemptReserve
+	if a.Lamports <= locked {
+		return 0
+	}
+	return a.Lamports - locked
+}
+
+// Parse decodes the stake account at address.
+func Parse(address solana.PublicKey, lamports uint64, data []byte) (Account, error) {
+	if len(data) < AccountSize {
+		return Account{}, fmt.Errorf("stake account %s is %d bytes", address, len(data))
+	}
+	account := Account{Address: address, Lamports: lamports}
+	switch kind := binary.LittleEndian.Uint32(data); kind {
+	case 1, 2: // Initialized(Meta) or Stake(Meta, Stake, StakeFlags)
+		account.RentExemptReserve = binary.LittleEndian.Uint64(data[4:])
+		account.Staker = solana.PublicKeyFromBytes(data[12:44])
+		account.Withdrawer = solana.PublicKeyFromBytes(data[44:76])
+		account.LockupTimestamp = int64(binary.LittleEndian.Uint64(data[76:]))
+		account.LockupEpoch = binary.LittleEndian.Uint64(data[84:])
+		account.Custodian = solana.PublicKeyFromBytes(data[92:124])
+		if kind == 2 {
+			account.Delegated = true
+			account.Voter = solana.PublicKeyFromBytes(data[124:156])
+			account.Stake = binary.LittleEndian.Uint64(data[156:])
+			account.ActivationEpoch = binary.LittleEndian.Uint64(data[164:])
+			account.DeactivationEpoch = binary.LittleEndian.Uint64(data[172:])
+		}
+	default:
+		return Account{}, fmt.Errorf("stake account %s is not initialized", address)
+	}
+	return account, nil
+}
+
+// List returns the stake accounts whose staker or withdrawer is wallet,
+// largest stake first.
+func List(ctx context.Context, client *rpc.Client, wallet solana.PublicKey) ([]Account, error) {
+	found := make(map[solana.PublicKey]Account)
+	for _, offset := range []uint64{stakerOffset, withdrawerOffset} {
+		result, err := client.GetProgramAccountsWithOpts(ctx, solana.StakeProgramID, &rpc.GetProgramAccountsOpts{
+			Commitment: rpc.CommitmentConfirmed,
+			Encoding:   solana.EncodingBase64,
+			Filters: []rpc.RPCFilter{
+				{DataSize: AccountSize},
+				{Memcmp: &rpc.RPCFilterMemcmp{Offset: offset, Bytes: wallet.Bytes()}},
+			},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error listing stake accounts: %v", err)
+		}
+		for _, keyed := range result {
+			if keyed.Account == nil || keyed.Account.Data == nil {
+				continue
+			}
+			account, err := Parse(keyed.Pubkey, keyed.Account.Lamports, keyed.Account.Data.GetBinary())
+			if err != nil {
+				continue
+			}
+			found[keyed.Pubkey] = account
+		}
+	}
+
+	accounts := make([]Account, 0, len(found))
+	for _, account := range found {
+		accounts = append(accounts, account)
+	}
+	sort.Slice(accounts, func(i, j int) bool {
+		if accounts[i].Lamports != accounts[j].Lamports {
+			return accounts[i].Lamports > accounts[j].Lamports
+		}
+		return accounts[i].Address.String() < accounts[j].Address.String()
+	})
+	return accounts, nil
+}
+
+// Reward is the inflation reward credited to an account in one epoch.
+type Reward struct {
+	Epoch       uint64
+	Lamports    uint64
+	PostBalance uint64
+	Commission  *uint8 // of the validator, when known
+}
+
+// Rewards returns the rewards of accounts for up to epochs epochs before
+// current, newest first. Older epochs the RPC node no longer serves are left
+// out; only a failure for the latest epoch is an error.
+func Rewards(ctx context.Context, client *rpc.Client, accounts []solana.PublicKey, current uint64, epochs int) (map[solana.PublicKey][]Reward, error) {
+	rewards := make(map[solana.PublicKey][]Reward, len(accounts))
+	if len(accounts) == 0 {
+		return rewards, nil
+	}
+	for i := 1; i <= epochs && uint64(i) <= current; i++ {
+		epoch := current - uint64(i)
+		results, err := client.GetInflationReward(ctx, accounts, &rpc.GetInflationRewardOpts{
+			Commitment: rpc.CommitmentConfirmed,
+			Epoch:      &epoch,
+		})
+		if err != nil {
+			if i == 1 {
+				return nil, fmt.Errorf("error getting rewards for epoch %d: %v", epoch, err)
+			}
+			break
+		}
+		for j, result := range results {
+			if result == nil || j >= len(accounts) {
+				continue
+			}
+			rewards[accounts[j]] = append(rewards[accounts[j]], Reward{
+				Epoch:       result.Epoch,
+				Lamports:    result.Amount,
+				PostBalance: result.PostBalance,
+				Commission:  result.Commission,
+			})
+		}
+	}
+	return rewards, nil
+}
@@ -0,0 +1,64 @@
+package stake
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+// Validator is a vote account stake can be delegated to.
+type Validator struct {
+	Vote           solana.PublicKey
+	Identity       solana.PublicKey
+	Commission     uint8 // percent
+	ActivatedStake uint64
+	LastVote       uint64
+	Delinquent     bool
+	// LeaderSlots and SkipRate cover the current epoch. SkipRate is the
+	// share of leader slots without a block, or -1 without leader slots yet.
+	LeaderSlots int64
+	SkipRate    float64
+}
+
+// Validators returns the vote accounts of the cluster, most stake first.
+// Skip rates are left at -1 if block production cannot be read.
+func Validators(ctx context.Context, client *rpc.Client) ([]Validator, error) {
+	votes, err := client.GetVoteAccounts(ctx, &rpc.GetVoteAccountsOpts{Commitment: rpc.CommitmentConfirmed})
+	if err != nil {
+		return nil, fmt.Errorf("error getting vote accounts: %v", err)
+	}
+	var production rpc.IdentityToSlotsBlocks
+	if result, err := client.GetBlockProductionWithOpts(ctx, &rpc.GetBlockProductionOpts{Commitment: rpc.CommitmentConfirmed}); err == nil {
+		production = result.Value.ByIdentity
+	}
+
+	var validators []Validator
+	add := func(accounts []rpc.VoteAccountsResult, delinquent bool) {
+		for _, account := range accounts {
+			v := Validator{
+				Vote:           account.VotePubkey,
+				Identity:       account.NodePubkey,
+				Commission:     account.Commission,
+				ActivatedStake: account.ActivatedStake,
+				LastVote:       account.LastVote,
+				Delinquent:     delinquent,
+				SkipRate:       -1,
+			}
+			if slots, ok := production[account.NodePubkey]; ok && slots[0] > 0 {
+				v.LeaderSlots = slots[0]
+				v.SkipRate = 1 - float64(slots[1])/float64(slots[0])
+			}
+			validators = append(validators, v)
+		}
+	}
+	add(votes.Current, false)
+	add(votes.Delinquent, true)
+
+	sort.SliceStable(validators, func(i, j int) bool {
+		return validators[i].ActivatedStake > validators[j].ActivatedStake
+	})
+	return validators, nil
+}
@@ -0,0 +1,27 @@
+package storage
+
+import "time"
+
+// WalletOrigin records how a wallet came to exist.
+type WalletOrigin string
+
+const (
+	OriginGenerated WalletOrigin = "generated"
+	OriginImported  WalletOrigin = "imported"
+	OriginDerived   WalletOrigin = "derived"
+	OriginMPC       WalletOrigin = "mpc"
+	OriginWatch     WalletOrigin = "watch"
+)
+
+// WalletMetadata holds the user-facing, non-secret attributes of a wallet.
+type WalletMetadata struct {
+	Label     string       `json:"label,omitempty"`
+	Color     string       `json:"color,omitempty"` // "#rrggbb"
+	CreatedAt time.Time    `json:"createdAt"`
+	Origin    WalletOrigin `json:"origin,omitempty"`
+	Hidden    bool         `json:"hidden,omitempty"`
+
+	// SeedID and DerivationPath link a derived wallet to its encrypted seed.
+	SeedID         string `json:"seedId,omitempty"`
+	DerivationPath string `json:"derivationPath,omitempty"`
+}
@@ -0,0 +1,210 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"unruggable-go/internal/keystore"
+)
+
+// shareSecretField is the key share JSON field holding the keystore envelope.
+const shareSecretField = "secretShare"
+
+// ChangeWalletPassword verifies oldPassword against the stored wallet and
+// replaces it with a copy sealed under newPassword. A derived wallet shares
+// its password with its seed and every other wallet derived from that seed,
+// so all of them are re-sealed together; nothing is written unless each one
+// opens with oldPassword. It returns the wallets whose password changed.
+func ChangeWalletPassword(s WalletStorage, pubKey, oldPassword, newPassword string) ([]string, error) {
+	if newPassword == "" {
+		return nil, fmt.Errorf("password cannot be empty")
+	}
+
+	wallets, err := s.LoadWallets()
+	if err != nil {
+		return nil, err
+	}
+	stored, ok := wallets[pubKey]
+	if !ok {
+		return nil, fmt.Errorf("wallet %s not found", pubKey)
+	}
+	if stored.WatchOnly() {
+		return nil, fmt.Errorf("wallet %s is watch-only and has no password", pubKey)
+	}
+	metadata, err := s.LoadMetadata()
+	if err != nil {
+		return nil, err
+	}
+
+	group := []string{pubKey}
+	seedID := metadata[pubKey].SeedID
+	if seedID != "" {
+		for other, meta := range metadata {
+			if other != pubKey && meta.SeedID == seedID {
+				if w, ok := wallets[other]; ok && !w.WatchOnly() {
+					group = append(group, other)
+				}
+			}
+		}
+	}
+
+	sealedWallets := make(map[string]string, len(group))
+	for _, key := range group {
+		sealed, err := keystore.Reseal(wallets[key].EncryptedKey, oldPassword, newPassword)
+		if err != nil {
+			if key == pubKey {
+				return nil, err
+			}
+			return nil, fmt.Errorf("wallet %s, derived from the same seed, is encrypted with a different password; change it with the master password instead", key)
+		}
+		sealedWallets[key] = sealed
+	}
+
+	var sealedSeed string
+	if seedID != "" {
+		seeds, err := s.LoadSeeds()
+		if err != nil {
+			return nil, err
+		}
+		if data, ok := seeds[seedID]; ok {
+			if sealedSeed, err = keystore.Reseal(data, oldPassword, newPassword); err != nil {
+				return nil, fmt.Errorf("the seed of wallet %s is encrypted with a different password; change it with the master password instead", pubKey)
+			}
+		}
+	}
+
+	var changed []string
+	for _, key := range group {
+		if err := s.SaveWallet(key, sealedWallets[key]); err != nil {
+			return changed, fmt.Errorf("failed to save wallet %s: %v", key, err)
+		}
+		changed = append(changed, key)
+	}
+	if sealedSeed != "" {
+		if err := s.SaveSeed(seedID, sealedSeed); err != nil {
+			return changed, fmt.Errorf("failed to save seed %s: %v", seedID, err)
+		}
+	}
+	return changed, nil
+}
+
+// ReencryptReport lists what ReencryptAll re-sealed and what it left alone.
+type ReencryptReport struct {
+	Wallets []string
+	Seeds   []string
+	Shares  []string
+	// Skipped maps "wallet:", "seed:" or "share:" prefixed IDs to the reason
+	// they were not re-encrypted, usually a different password.
+	Skipped map[string]error
+}
+
+// ReencryptAll re-seals every wallet, seed and key share that opens with
+// oldPassword under newPassword. Everything is decrypted and re-sealed before
+// the first write, so a wrong password changes nothing.
+func ReencryptAll(s WalletStorage, oldPassword, newPassword string) (*ReencryptReport, error) {
+	if newPassword == "" {
+		return nil, fmt.Errorf("password cannot be empty")
+	}
+
+	report := &ReencryptReport{Skipped: make(map[string]error)}
+
+	wallets, err := s.LoadWallets()
+	if err != nil {
+		return nil, err
+	}
+	sealedWallets := make(map[string]string)
+	for pubKey, stored := range wallets {
+		if stored.WatchOnly() {
+			continue
+		}
+		sealed, err := keystore.Reseal(stored.EncryptedKey, oldPassword, newPassword)
+		if err != nil {
+			report.Skipped["wallet:"+pubKey] = err
+			continue
+		}
+		sealedWallets[pubKey] = sealed
+	}
+
+	seeds, err := s.LoadSeeds()
+	if err != nil {
+		return nil, err
+	}
+	sealedSeeds := make(map[string]string)
+	for seedID, data := range seeds {
+		sealed, err := keystore.Reseal(data, oldPassword, newPassword)
+		if err != nil {
+			report.Skipped["seed:"+seedID] = err
+			continue
+		}
+		sealedSeeds[seedID] = sealed
+	}
+
+	shares, err := s.LoadShares()
+	if err != nil {
+		return nil, err
+	}
+	sealedShares := make(map[string]string)
+	for name, data := range shares {
+		sealed, err := reencryptShare(data, oldPassword, newPassword)
+		if err != nil {
+			report.Skipped["share:"+name] = err
+			continue
+		}
+		sealedShares[name] = sealed
+	}
+
+	if len(sealedWallets)+len(sealedSeeds)+len(sealedShares) == 0 && len(report.Skipped) > 0 {
+		return report, keystore.ErrInvalidPassword
+	}
+
+	for pubKey, sealed := range sealedWallets {
+		if err := s.SaveWallet(pubKey, sealed); err != nil {
+			return report, fmt.Errorf("failed to save wallet %s: %v", pubKey, err)
+		}
+		report.Wallets = append(report.Wallets, pubKey)
+	}
+	for seedID, sealed := range sealedSeeds {
+		if err := s.SaveSeed(seedID, sealed); err != nil {
+			return report, fmt.Errorf("failed to save seed %s: %v", seedID, err)
+		}
+		report.Seeds = append(report.Seeds, seedID)
+	}
+	for name, data := range sealedShares {
+		if err := s.SaveShare(name, data); err != nil {
+			return report, fmt.Errorf("failed to save share %s: %v", name, err)
+		}
+		report.Shares = append(report.Shares, name)
+	}
+	return report, nil
+}
+
+// reencryptShare re-seals the secret share inside a key share JSON file and
+// leaves the other fields untouched.
+func reencryptShare(data, oldPassword, newPassword string) (string, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(data), &fields); err != nil {
+		return "", fmt.Errorf("invalid share file: %v", err)
+	}
+
+	var secret string
+	if err := json.Unmarshal(fields[shareSecretField], &secret); err != nil {
+		return "", fmt.Errorf("invalid share file: %v", err)
+	}
+
+	sealed, err := keystore.Reseal(secret, oldPassword, newPassword)
+	if err != nil {
+		return "", err
+	}
+
+	encoded, err := json.Marshal(sealed)
+	if err != nil {
+		return "", err
+	}
+	fields[shareSecretField] = encoded
+
+	out, err := json.Marshal(fields)
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
@@ -0,0 +1,90 @@
+//go:build !js
+
+package storage
+
+import (
+	"errors"
+	"testing"
+
+	"unruggable-go/internal/keystore"
+)
+
+// saveDerived stores a wallet derived from seedID, sealed under password.
+func saveDerived(t *testing.T, s WalletStorage, pubKey, seedID, password string) {
+	t.Helper()
+	sealed, err := keystore.Seal([]byte("key of "+pubKey), password)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SaveWallet(pubKey, sealed); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SaveMetadata(pubKey, WalletMetadata{Origin: OriginDerived, SeedID: seedID}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func opens(t *testing.T, s WalletStorage, pubKey, password string) bool {
+	t.Helper()
+	wallets, err := s.LoadWallets()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _, err = keystore.Open(wallets[pubKey].EncryptedKey, password)
+	return err == nil
+}
+
+func TestChangeWalletPasswordResealsSeedSiblings(t *testing.T) {
+	s := NewFileWalletStorage(t.TempDir())
+	seed, err := keystore.Seal([]byte("seed"), "old")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SaveSeed("seed1", seed); err != nil {
+		t.Fatal(err)
+	}
+	saveDerived(t, s, "walletA", "seed1", "old")
+	saveDerived(t, s, "walletB", "seed1", "old")
+	saveDerived(t, s, "other", "", "old")
+
+	changed, err := ChangeWalletPassword(s, "walletA", "old", "new")
+	if err != nil {
+		t.Fatalf("ChangeWalletPassword: %v", err)
+	}
+	if len(changed) != 2 {
+		t.Errorf("changed = %v, want walletA and walletB", changed)
+	}
+	for _, pubKey := range []string{"walletA", "walletB"} {
+		if !opens(t, s, pubKey, "new") {
+			t.Errorf("%s does not open with the new password", pubKey)
+		}
+	}
+	seeds, err := s.LoadSeeds()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := keystore.Open(seeds["seed1"], "new"); err != nil {
+		t.Errorf("seed does not open with the new password: %v", err)
+	}
+	if !opens(t, s, "other", "old") {
+		t.Error("an unrelated wallet was re-sealed")
+	}
+}
+
+func TestChangeWalletPasswordRefusesMixedSeedGroup(t *testing.T) {
+	s := NewFileWalletStorage(t.TempDir())
+	saveDerived(t, s, "walletA", "seed1", "old")
+	saveDerived(t, s, "walletB", "seed1", "different")
+
+	if _, err := ChangeWalletPassword(s, "walletA", "old", "new"); err == nil {
+		t.Fatal("changed the password of a seed group with mixed passwords")
+	}
+	if !opens(t, s, "walletA", "old") {
+		t.Error("walletA was written although the change failed")
+	}
+
+	_, err := ChangeWalletPassword(s, "walletA", "wrong", "new")
+	if !errors.Is(err, keystore.ErrInvalidPassword) {
+		t.Errorf("wrong password = %v, want ErrInvalidPassword", err)
+	}
+}
@@ -0,0 +1,25 @@
+package storage
+
+// AppID is the Fyne application ID. It also names the directory the desktop
+// builds keep their data in.
+const AppID = "com.unruggable.app"
+
+// WalletKind distinguishes wallets we hold a key for from watch-only addresses.
+type WalletKind string
+
+const (
+	KindKeypair   WalletKind = "keypair"
+	KindWatchOnly WalletKind = "watch"
+)
+
+// StoredWallet is a wallet entry as returned by LoadWallets.
+type StoredWallet struct {
+	Kind WalletKind
+	// EncryptedKey is the keystore envelope. It is empty for watch-only wallets.
+	EncryptedKey string
+}
+
+// WatchOnly reports whether the wallet has no key and cannot sign.
+func (w StoredWallet) WatchOnly() bool {
+	return w.Kind == KindWatchOnly
+}
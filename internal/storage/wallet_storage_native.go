@@ -4,36 +4,69 @@
 package storage
 
 import (
+	"encoding/json"
 	"io/ioutil"
 	"os"
 	"path/filepath"
 
 	"fyne.io/fyne/v2"
+
+	"unruggable-go/internal/keystore"
 )
 
 // WalletStorage is the interface that abstracts wallet persistence.
 type WalletStorage interface {
 	SaveWallet(pubKey, encryptedKey string) error
-	LoadWallets() (map[string]string, error)
+	SaveWatchOnly(pubKey string) error
+	LoadWallets() (map[string]StoredWallet, error)
+	DeleteWallet(pubKey string) error
+	SaveMetadata(pubKey string, meta WalletMetadata) error
+	LoadMetadata() (map[string]WalletMetadata, error)
+	SaveSeed(seedID, encryptedSeed string) error
+	LoadSeeds() (map[string]string, error)
+	DeleteSeed(seedID string) error
+	SaveShare(name, data string) error
+	LoadShares() (map[string]string, error)
+	SaveConfig(name, data string) error
+	LoadConfigs() (map[string]string, error)
 }
 
 // FileWalletStorage implements WalletStorage for native builds.
 type FileWalletStorage struct {
-	app fyne.App
+	root string
 }
 
 func NewWalletStorage(app fyne.App) WalletStorage {
-	return &FileWalletStorage{app: app}
+	return NewFileWalletStorage(app.Storage().RootURI().Path())
+}
+
+// NewFileWalletStorage stores wallets below root without needing a running
+// app. Use DefaultRoot to share the GUI's wallets.
+func NewFileWalletStorage(root string) WalletStorage {
+	return &FileWalletStorage{root: root}
+}
+
+// DefaultRoot returns the storage root Fyne uses for AppID on desktop
+// platforms.
+func DefaultRoot() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "fyne", AppID), nil
 }
 
 // walletsDir returns the wallets directory in the app’s storage root.
 func (fs *FileWalletStorage) walletsDir() string {
-	rootURI := fs.app.Storage().RootURI()
-	userDir := rootURI.Path()
-	return filepath.Join(userDir, "wallets")
+	return filepath.Join(fs.root, "wallets")
 }
 
+// SaveWallet writes the keystore envelope for pubKey. Anything that is not a
+// valid envelope is rejected so plaintext or legacy blobs are never written.
 func (fs *FileWalletStorage) SaveWallet(pubKey, encryptedKey string) error {
+	if _, err := keystore.Parse(encryptedKey); err != nil {
+		return err
+	}
 	walletsDir := fs.walletsDir()
 	if _, err := os.Stat(walletsDir); os.IsNotExist(err) {
 		if err := os.MkdirAll(walletsDir, 0700); err != nil {
@@ -41,11 +74,28 @@ func (fs *FileWalletStorage) SaveWallet(pubKey, encryptedKey string) error {
 		}
 	}
 	filename := filepath.Join(walletsDir, pubKey+".wallet")
-	return ioutil.WriteFile(filename, []byte(encryptedKey), 0600)
+	if err := writeFileAtomic(filename, []byte(encryptedKey)); err != nil {
+		return err
+	}
+	// Importing the key upgrades a watch-only wallet.
+	if err := os.Remove(filepath.Join(walletsDir, pubKey+".watch")); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// SaveWatchOnly records a watch-only wallet. It is stored as an empty .watch file.
+func (fs *FileWalletStorage) SaveWatchOnly(pubKey string) error {
+	walletsDir := fs.walletsDir()
+	if err := os.MkdirAll(walletsDir, 0700); err != nil {
+		return err
+	}
+	return writeFileAtomic(filepath.Join(walletsDir, pubKey+".watch"), nil)
 }
 
-func (fs *FileWalletStorage) LoadWallets() (map[string]string, error) {
-	wallets := make(map[string]string)
+// LoadWallets returns every keypair and watch-only wallet keyed by pubKey.
+func (fs *FileWalletStorage) LoadWallets() (map[string]StoredWallet, error) {
+	wallets := make(map[string]StoredWallet)
 	walletsDir := fs.walletsDir()
 	files, err := ioutil.ReadDir(walletsDir)
 	if err != nil {
@@ -60,14 +110,233 @@ func (fs *FileWalletStorage) LoadWallets() (map[string]string, error) {
 	}
 
 	for _, file := range files {
-		if filepath.Ext(file.Name()) == ".wallet" {
+		switch filepath.Ext(file.Name()) {
+		case ".wallet":
 			content, err := ioutil.ReadFile(filepath.Join(walletsDir, file.Name()))
 			if err != nil {
 				continue // Optionally log error
 			}
 			pubKey := file.Name()[:len(file.Name())-len(".wallet")]
-			wallets[pubKey] = string(content)
+			wallets[pubKey] = StoredWallet{Kind: KindKeypair, EncryptedKey: string(content)}
+		case ".watch":
+			pubKey := file.Name()[:len(file.Name())-len(".watch")]
+			if _, exists := wallets[pubKey]; !exists {
+				wallets[pubKey] = StoredWallet{Kind: KindWatchOnly}
+			}
 		}
 	}
 	return wallets, nil
 }
+
+// DeleteWallet removes the wallet or watch-only file and its metadata.
+func (fs *FileWalletStorage) DeleteWallet(pubKey string) error {
+	walletsDir := fs.walletsDir()
+	for _, ext := range []string{".wallet", ".watch", ".meta"} {
+		if err := os.Remove(filepath.Join(walletsDir, pubKey+ext)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// SaveMetadata writes the metadata sidecar file for pubKey.
+func (fs *FileWalletStorage) SaveMetadata(pubKey string, meta WalletMetadata) error {
+	walletsDir := fs.walletsDir()
+	if err := os.MkdirAll(walletsDir, 0700); err != nil {
+		return err
+	}
+	data, err := json.Marshal(meta)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(filepath.Join(walletsDir, pubKey+".meta"), data)
+}
+
+// LoadMetadata reads every metadata sidecar file, keyed by pubKey.
+func (fs *FileWalletStorage) LoadMetadata() (map[string]WalletMetadata, error) {
+	metadata := make(map[string]WalletMetadata)
+	walletsDir := fs.walletsDir()
+	files, err := ioutil.ReadDir(walletsDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return metadata, nil
+		}
+		return nil, err
+	}
+
+	for _, file := range files {
+		if filepath.Ext(file.Name()) != ".meta" {
+			continue
+		}
+		content, err := ioutil.ReadFile(filepath.Join(walletsDir, file.Name()))
+		if err != nil {
+			continue
+		}
+		var meta WalletMetadata
+		if err := json.Unmarshal(content, &meta); err != nil {
+			continue
+		}
+		pubKey := file.Name()[:len(file.Name())-len(".meta")]
+		metadata[pubKey] = meta
+	}
+	return metadata, nil
+}
+
+// seedsDir returns the directory holding encrypted mnemonic seeds.
+func (fs *FileWalletStorage) seedsDir() string {
+	return filepath.Join(fs.root, "seeds")
+}
+
+// SaveSeed writes the keystore envelope of a mnemonic seed.
+func (fs *FileWalletStorage) SaveSeed(seedID, encryptedSeed string) error {
+	if _, err := keystore.Parse(encryptedSeed); err != nil {
+		return err
+	}
+	seedsDir := fs.seedsDir()
+	if err := os.MkdirAll(seedsDir, 0700); err != nil {
+		return err
+	}
+	return writeFileAtomic(filepath.Join(seedsDir, seedID+".seed"), []byte(encryptedSeed))
+}
+
+// LoadSeeds returns every stored seed envelope keyed by seed ID.
+func (fs *FileWalletStorage) LoadSeeds() (map[string]string, error) {
+	seeds := make(map[string]string)
+	seedsDir := fs.seedsDir()
+	files, err := ioutil.ReadDir(seedsDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return seeds, nil
+		}
+		return nil, err
+	}
+
+	for _, file := range files {
+		if filepath.Ext(file.Name()) != ".seed" {
+			continue
+		}
+		content, err := ioutil.ReadFile(filepath.Join(seedsDir, file.Name()))
+		if err != nil {
+			continue
+		}
+		seedID := file.Name()[:len(file.Name())-len(".seed")]
+		seeds[seedID] = string(content)
+	}
+	return seeds, nil
+}
+
+// DeleteSeed removes a stored seed.
+func (fs *FileWalletStorage) DeleteSeed(seedID string) error {
+	err := os.Remove(filepath.Join(fs.seedsDir(), seedID+".seed"))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// sharesDir returns the directory holding MPC key share files.
+func (fs *FileWalletStorage) sharesDir() string {
+	return filepath.Join(fs.root, "shares")
+}
+
+// SaveShare replaces the share file name (without extension) with data.
+func (fs *FileWalletStorage) SaveShare(name, data string) error {
+	sharesDir := fs.sharesDir()
+	if err := os.MkdirAll(sharesDir, 0700); err != nil {
+		return err
+	}
+	return writeFileAtomic(filepath.Join(sharesDir, name+".share"), []byte(data))
+}
+
+// LoadShares returns the raw JSON of every share file keyed by file name.
+func (fs *FileWalletStorage) LoadShares() (map[string]string, error) {
+	shares := make(map[string]string)
+	sharesDir := fs.sharesDir()
+	files, err := ioutil.ReadDir(sharesDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return shares, nil
+		}
+		return nil, err
+	}
+
+	for _, file := range files {
+		if filepath.Ext(file.Name()) != ".share" {
+			continue
+		}
+		content, err := ioutil.ReadFile(filepath.Join(sharesDir, file.Name()))
+		if err != nil {
+			continue
+		}
+		name := file.Name()[:len(file.Name())-len(".share")]
+		shares[name] = string(content)
+	}
+	return shares, nil
+}
+
+// configsDir returns the directory holding bot and screen configuration files.
+func (fs *FileWalletStorage) configsDir() string {
+	return filepath.Join(fs.root, "configs")
+}
+
+// SaveConfig replaces the JSON configuration stored under name.
+func (fs *FileWalletStorage) SaveConfig(name, data string) error {
+	configsDir := fs.configsDir()
+	if err := os.MkdirAll(configsDir, 0700); err != nil {
+		return err
+	}
+	return writeFileAtomic(filepath.Join(configsDir, name+".json"), []byte(data))
+}
+
+// LoadConfigs returns every stored configuration keyed by name.
+func (fs *FileWalletStorage) LoadConfigs() (map[string]string, error) {
+	configs := make(map[string]string)
+	configsDir := fs.configsDir()
+	files, err := ioutil.ReadDir(configsDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return configs, nil
+		}
+		return nil, err
+	}
+
+	for _, file := range files {
+		if filepath.Ext(file.Name()) != ".json" {
+			continue
+		}
+		content, err := ioutil.ReadFile(filepath.Join(configsDir, file.Name()))
+		if err != nil {
+			continue
+		}
+		name := file.Name()[:len(file.Name())-len(".json")]
+		configs[name] = string(content)
+	}
+	return configs, nil
+}
+
+// writeFileAtomic writes data to a temporary file in the same directory and
+// renames it over filename, so a crash never leaves a truncated file behind.
+func writeFileAtomic(filename string, data []byte) error {
+	tmp, err := ioutil.TempFile(filepath.Dir(filename), "."+filepath.Base(filename)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(0600); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), filename)
+}
@@ -5,14 +5,28 @@ package storage
 
 import (
 	"encoding/json"
+	"errors"
 
 	"fyne.io/fyne/v2"
+
+	"unruggable-go/internal/keystore"
 )
 
 // WalletStorage is the interface that abstracts wallet persistence.
 type WalletStorage interface {
 	SaveWallet(pubKey, encryptedKey string) error
-	LoadWallets() (map[string]string, error)
+	SaveWatchOnly(pubKey string) error
+	LoadWallets() (map[string]StoredWallet, error)
+	DeleteWallet(pubKey string) error
+	SaveMetadata(pubKey string, meta WalletMetadata) error
+	LoadMetadata() (map[string]WalletMetadata, error)
+	SaveSeed(seedID, encryptedSeed string) error
+	LoadSeeds() (map[string]string, error)
+	DeleteSeed(seedID string) error
+	SaveShare(name, data string) error
+	LoadShares() (map[string]string, error)
+	SaveConfig(name, data string) error
+	LoadConfigs() (map[string]string, error)
 }
 
 // PrefWalletStorage implements WalletStorage for WASM using Preferences.
@@ -24,10 +38,19 @@ func NewWalletStorage(app fyne.App) WalletStorage {
 	return &PrefWalletStorage{app: app}
 }
 
-const walletMapKey = "walletMap"
+const (
+	walletMapKey  = "walletMap"
+	walletMetaKey = "walletMetadata"
+	seedMapKey    = "seedMap"
+	watchListKey  = "watchWallets"
+	configMapKey  = "configMap"
+)
 
-// SaveWallet saves the wallet (pubKey -> encryptedKey) to Preferences.
+// SaveWallet saves the wallet (pubKey -> keystore envelope) to Preferences.
 func (ps *PrefWalletStorage) SaveWallet(pubKey, encryptedKey string) error {
+	if _, err := keystore.Parse(encryptedKey); err != nil {
+		return err
+	}
 	prefs := ps.app.Preferences()
 	// Get the current wallet map from preferences.
 	wallets := make(map[string]string)
@@ -44,11 +67,51 @@ func (ps *PrefWalletStorage) SaveWallet(pubKey, encryptedKey string) error {
 		return err
 	}
 	prefs.SetString(walletMapKey, string(data))
+
+	// Importing the key upgrades a watch-only wallet.
+	watched, err := ps.loadWatchList()
+	if err != nil {
+		return err
+	}
+	if watched[pubKey] {
+		delete(watched, pubKey)
+		return ps.storeWatchList(watched)
+	}
 	return nil
 }
 
-// LoadWallets retrieves the wallet map from Preferences.
-func (ps *PrefWalletStorage) LoadWallets() (map[string]string, error) {
+// SaveWatchOnly adds pubKey to the watch-only list in Preferences.
+func (ps *PrefWalletStorage) SaveWatchOnly(pubKey string) error {
+	watched, err := ps.loadWatchList()
+	if err != nil {
+		return err
+	}
+	watched[pubKey] = true
+	return ps.storeWatchList(watched)
+}
+
+// LoadWallets retrieves the keypair and watch-only wallets from Preferences.
+func (ps *PrefWalletStorage) LoadWallets() (map[string]StoredWallet, error) {
+	keys, err := ps.loadKeyMap()
+	if err != nil {
+		return nil, err
+	}
+	watched, err := ps.loadWatchList()
+	if err != nil {
+		return nil, err
+	}
+
+	wallets := make(map[string]StoredWallet, len(keys)+len(watched))
+	for pubKey := range watched {
+		wallets[pubKey] = StoredWallet{Kind: KindWatchOnly}
+	}
+	for pubKey, encryptedKey := range keys {
+		wallets[pubKey] = StoredWallet{Kind: KindKeypair, EncryptedKey: encryptedKey}
+	}
+	return wallets, nil
+}
+
+func (ps *PrefWalletStorage) loadKeyMap() (map[string]string, error) {
 	wallets := make(map[string]string)
 	stored := ps.app.Preferences().String(walletMapKey)
 	if stored != "" {
@@ -58,3 +121,173 @@ func (ps *PrefWalletStorage) LoadWallets() (map[string]string, error) {
 	}
 	return wallets, nil
 }
+
+func (ps *PrefWalletStorage) loadWatchList() (map[string]bool, error) {
+	watched := make(map[string]bool)
+	stored := ps.app.Preferences().String(watchListKey)
+	if stored != "" {
+		var list []string
+		if err := json.Unmarshal([]byte(stored), &list); err != nil {
+			return nil, err
+		}
+		for _, pubKey := range list {
+			watched[pubKey] = true
+		}
+	}
+	return watched, nil
+}
+
+func (ps *PrefWalletStorage) storeWatchList(watched map[string]bool) error {
+	list := make([]string, 0, len(watched))
+	for pubKey := range watched {
+		list = append(list, pubKey)
+	}
+	data, err := json.Marshal(list)
+	if err != nil {
+		return err
+	}
+	ps.app.Preferences().SetString(watchListKey, string(data))
+	return nil
+}
+
+// DeleteWallet removes the wallet and its metadata from Preferences.
+func (ps *PrefWalletStorage) DeleteWallet(pubKey string) error {
+	wallets, err := ps.loadKeyMap()
+	if err != nil {
+		return err
+	}
+	delete(wallets, pubKey)
+	data, err := json.Marshal(wallets)
+	if err != nil {
+		return err
+	}
+	ps.app.Preferences().SetString(walletMapKey, string(data))
+
+	watched, err := ps.loadWatchList()
+	if err != nil {
+		return err
+	}
+	delete(watched, pubKey)
+	if err := ps.storeWatchList(watched); err != nil {
+		return err
+	}
+
+	metadata, err := ps.LoadMetadata()
+	if err != nil {
+		return err
+	}
+	delete(metadata, pubKey)
+	return ps.storeMetadata(metadata)
+}
+
+// SaveMetadata stores the metadata for pubKey in Preferences.
+func (ps *PrefWalletStorage) SaveMetadata(pubKey string, meta WalletMetadata) error {
+	metadata, err := ps.LoadMetadata()
+	if err != nil {
+		return err
+	}
+	metadata[pubKey] = meta
+	return ps.storeMetadata(metadata)
+}
+
+// LoadMetadata retrieves the metadata map from Preferences.
+func (ps *PrefWalletStorage) LoadMetadata() (map[string]WalletMetadata, error) {
+	metadata := make(map[string]WalletMetadata)
+	stored := ps.app.Preferences().String(walletMetaKey)
+	if stored != "" {
+		if err := json.Unmarshal([]byte(stored), &metadata); err != nil {
+			return nil, err
+		}
+	}
+	return metadata, nil
+}
+
+func (ps *PrefWalletStorage) storeMetadata(metadata map[string]WalletMetadata) error {
+	data, err := json.Marshal(metadata)
+	if err != nil {
+		return err
+	}
+	ps.app.Preferences().SetString(walletMetaKey, string(data))
+	return nil
+}
+
+// SaveSeed stores the keystore envelope of a mnemonic seed in Preferences.
+func (ps *PrefWalletStorage) SaveSeed(seedID, encryptedSeed string) error {
+	if _, err := keystore.Parse(encryptedSeed); err != nil {
+		return err
+	}
+	seeds, err := ps.LoadSeeds()
+	if err != nil {
+		return err
+	}
+	seeds[seedID] = encryptedSeed
+	return ps.storeSeeds(seeds)
+}
+
+// LoadSeeds retrieves the seed map from Preferences.
+func (ps *PrefWalletStorage) LoadSeeds() (map[string]string, error) {
+	seeds := make(map[string]string)
+	stored := ps.app.Preferences().String(seedMapKey)
+	if stored != "" {
+		if err := json.Unmarshal([]byte(stored), &seeds); err != nil {
+			return nil, err
+		}
+	}
+	return seeds, nil
+}
+
+// DeleteSeed removes a seed from Preferences.
+func (ps *PrefWalletStorage) DeleteSeed(seedID string) error {
+	seeds, err := ps.LoadSeeds()
+	if err != nil {
+		return err
+	}
+	delete(seeds, seedID)
+	return ps.storeSeeds(seeds)
+}
+
+func (ps *PrefWalletStorage) storeSeeds(seeds map[string]string) error {
+	data, err := json.Marshal(seeds)
+	if err != nil {
+		return err
+	}
+	ps.app.Preferences().SetString(seedMapKey, string(data))
+	return nil
+}
+
+// SaveShare is not supported in the browser; MPC shares live on native builds only.
+func (ps *PrefWalletStorage) SaveShare(name, data string) error {
+	return errors.New("key shares are not supported in the browser")
+}
+
+// LoadShares returns no shares in the browser.
+func (ps *PrefWalletStorage) LoadShares() (map[string]string, error) {
+	return make(map[string]string), nil
+}
+
+// SaveConfig stores the JSON configuration for name in Preferences.
+func (ps *PrefWalletStorage) SaveConfig(name, data string) error {
+	configs, err := ps.LoadConfigs()
+	if err != nil {
+		return err
+	}
+	configs[name] = data
+	encoded, err := json.Marshal(configs)
+	if err != nil {
+		return err
+	}
+	ps.app.Preferences().SetString(configMapKey, string(encoded))
+	return nil
+}
+
+// LoadConfigs retrieves the configuration map from Preferences.
+func (ps *PrefWalletStorage) LoadConfigs() (map[string]string, error) {
+	configs := make(map[string]string)
+	stored := ps.app.Preferences().String(configMapKey)
+	if stored != "" {
+		if err := json.Unmarshal([]byte(stored), &configs); err != nil {
+			return nil, err
+		}
+	}
+	return configs, nil
+}
@@ -0,0 +1,213 @@
+package transfer
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+// defaultMemo is attached to transfers into accounts that require a memo when
+// the caller did not give one.
+const defaultMemo = "Transfer"
+
+// transferCheckedInstruction is the TransferChecked instruction index, the
+// same in SPL Token and Token-2022.
+const transferCheckedInstruction = 12
+
+// Mint describes an SPL token mint and the Token-2022 extensions that change
+// how it is transferred.
+type Mint struct {
+	Address  solana.PublicKey
+	Program  solana.PublicKey // solana.TokenProgramID or solana.Token2022ProgramID
+	Decimals int
+	// TransferFee is the fee of the current epoch, nil if the mint charges none.
+	TransferFee     *TransferFee
+	NonTransferable bool
+}
+
+// TransferFee is a Token-2022 transfer fee. It is withheld from the amount
+// the recipient receives.
+type TransferFee struct {
+	BasisPoints uint16
+	Maximum     uint64 // in base units
+}
+
+// Fee returns the fee withheld when transferring amount base units.
+func (f *TransferFee) Fee(amount uint64) uint64 {
+	if f == nil || f.BasisPoints == 0 || amount == 0 {
+		return 0
+	}
+	// ceil(amount * bps / 10000), computed without overflow
+	fee := new(big.Int).SetUint64(amount)
+	fee.Mul(fee, big.NewInt(int64(f.BasisPoints)))
+	fee.Add(fee, big.NewInt(9_999))
+	fee.Div(fee, big.NewInt(10_000))
+	if !fee.IsUint64() || fee.Uint64() > f.Maximum {
+		return f.Maximum
+	}
+	return fee.Uint64()
+}
+
+// BaseUnits converts an amount in whole tokens to base units.
+func (m *Mint) BaseUnits(amount float64) uint64 {
+	return uint64(amount * math.Pow(10, float64(m.Decimals)))
+}
+
+// Tokens converts base units to whole tokens.
+func (m *Mint) Tokens(units uint64) float64 {
+	return float64(units) / math.Pow(10, float64(m.Decimals))
+}
+
+// parsedTokenAccount is the jsonParsed encoding of a mint or token account.
+type parsedTokenAccount struct {
+	Parsed struct {
+		Type string `json:"type"` // "mint" or "account"
+		Info struct {
+			Decimals   int `json:"decimals"`
+			Extensions []struct {
+				Extension string          `json:"extension"`
+				State     json.RawMessage `json:"state"`
+			} `json:"extensions"`
+		} `json:"info"`
+	} `json:"parsed"`
+}
+
+type transferFeeState struct {
+	Epoch                  uint64 `json:"epoch"`
+	MaximumFee             uint64 `json:"maximumFee"`
+	TransferFeeBasisPoints uint16 `json:"transferFeeBasisPoints"`
+}
+
+// LoadMint reads a mint of either token program with its extensions.
+func LoadMint(ctx context.Context, client *rpc.Client, address solana.PublicKey) (*Mint, error) {
+	owner, parsed, err := getParsedTokenAccount(ctx, client, address)
+	if err != nil {
+		return nil, fmt.Errorf("error reading mint %s: %v", address, err)
+	}
+	if parsed.Parsed.Type != "mint" {
+		return nil, fmt.Errorf("%s is not a token mint", address)
+	}
+
+	mint := &Mint{Address: address, Program: owner, Decimals: parsed.Parsed.Info.Decimals}
+	for _, ext := range parsed.Parsed.Info.Extensions {
+		switch ext.Extension {
+		case "nonTransferable":
+			mint.NonTransferable = true
+		case "transferFeeConfig":
+			var config struct {
+				Older transferFeeState `json:"olderTransferFee"`
+				Newer transferFeeState `json:"newerTransferFee"`
+			}
+			if err := json.Unmarshal(ext.State, &config); err != nil {
+				return nil, fmt.Errorf("invalid transfer fee of mint %s: %v", address, err)
+			}
+			epoch, err := client.GetEpochInfo(ctx, rpc.CommitmentConfirmed)
+			if err != nil {
+				return nil, fmt.Errorf("error getting epoch: %v", err)
+			}
+			fee := config.Older
+			if epoch.Epoch >= config.Newer.Epoch {
+				fee = config.Newer
+			}
+			if fee.TransferFeeBasisPoints > 0 {
+				mint.TransferFee = &TransferFee{BasisPoints: fee.TransferFeeBasisPoints, Maximum: fee.MaximumFee}
+			}
+		}
+	}
+	return mint, nil
+}
+
+// requiresMemo reports whether the token account exists and whether it only
+// accepts transfers preceded by a memo.
+func requiresMemo(ctx context.Context, client *rpc.Client, account solana.PublicKey) (exists, required bool, err error) {
+	_, parsed, err := getParsedTokenAccount(ctx, client, account)
+	if errors.Is(err, rpc.ErrNotFound) {
+		return false, false, nil
+	}
+	if err != nil {
+		return false, false, fmt.Errorf("error reading token account %s: %v", account, err)
+	}
+	for _, ext := range parsed.Parsed.Info.Extensions {
+		if ext.Extension != "memoTransfer" {
+			continue
+		}
+		var state struct {
+			RequireIncomingTransferMemos bool `json:"requireIncomingTransferMemos"`
+		}
+		if err := json.Unmarshal(ext.State, &state); err == nil && state.RequireIncomingTransferMemos {
+			return true, true, nil
+		}
+	}
+	return true, false, nil
+}
+
+func getParsedTokenAccount(ctx context.Context, client *rpc.Client, address solana.PublicKey) (solana.PublicKey, *parsedTokenAccount, error) {
+	info, err := client.GetAccountInfoWithOpts(ctx, address, &rpc.GetAccountInfoOpts{
+		Encoding:   solana.EncodingJSONParsed,
+		Commitment: rpc.CommitmentConfirmed,
+	})
+	if err != nil {
+		return solana.PublicKey{}, nil, err
+	}
+	owner := info.Value.Owner
+	if !owner.Equals(solana.TokenProgramID) && !owner.Equals(solana.Token2022ProgramID) {
+		return solana.PublicKey{}, nil, fmt.Errorf("account is owned by %s, not a token program", owner)
+	}
+	var parsed parsedTokenAccount
+	if err := json.Unmarshal(info.Value.Data.GetRawJSON(), &parsed); err != nil {
+		return solana.PublicKey{}, nil, fmt.Errorf("unexpected account data: %v", err)
+	}
+	return owner, &parsed, nil
+}
+
+// associatedTokenAddress derives the associated token account of owner for a
+// mint of the given token program.
+func associatedTokenAddress(owner, mint, program solana.PublicKey) (solana.PublicKey, error) {
+	address, _, err := solana.FindProgramAddress(
+		[][]byte{owner[:], program[:], mint[:]},
+		solana.SPLAssociatedTokenAccountProgramID,
+	)
+	return address, err
+}
+
+// createAssociatedTokenAccount creates the associated token account of owner,
+// paid by payer.
+func createAssociatedTokenAccount(payer, owner, account solana.PublicKey, mint *Mint) solana.Instruction {
+	return solana.NewInstruction(solana.SPLAssociatedTokenAccountProgramID, solana.AccountMetaSlice{
+		solana.Meta(payer).WRITE().SIGNER(),
+		solana.Meta(account).WRITE(),
+		solana.Meta(owner),
+		solana.Meta(mint.Address),
+		solana.Meta(solana.SystemProgramID),
+		solana.Meta(mint.Program),
+	}, []byte{0})
+}
+
+// transferChecked moves amount base units between token accounts, checking
+// the mint and its decimals.
+func transferChecked(amount uint64, source, destination, owner solana.PublicKey, mint *Mint) solana.Instruction {
+	data := make([]byte, 10)
+	data[0] = transferCheckedInstruction
+	binary.LittleEndian.PutUint64(data[1:9], amount)
+	data[9] = byte(mint.Decimals)
+	return solana.NewInstruction(mint.Program, solana.AccountMetaSlice{
+		solana.Meta(source).WRITE(),
+		solana.Meta(mint.Address),
+		solana.Meta(destination).WRITE(),
+		solana.Meta(owner).SIGNER(),
+	}, data)
+}
+
+// memo attaches text to the transaction, signed by signer.
+func memo(text string, signer solana.PublicKey) solana.Instruction {
+	return solana.NewInstruction(solana.MemoProgramID, solana.AccountMetaSlice{
+		solana.Meta(signer).SIGNER(),
+	}, []byte(text))
+}
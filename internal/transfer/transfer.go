@@ -0,0 +1,297 @@
+// Package transfer builds, submits and confirms SOL and SPL token transfers.
+// Transfers are sent as a Jito bundle with a tip when the network has a Jito
+// endpoint, and through plain RPC otherwise or when the bundle is rejected.
+package transfer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/programs/system"
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/mr-tron/base58"
+
+	"unruggable-go/internal/fee"
+)
+
+// TipLamports is paid to each Jito tip account when a transfer is bundled.
+const TipLamports = 100_000 // 0.0001 SOL
+
+// tipRecipients receive the bundle tip.
+var tipRecipients = []solana.PublicKey{
+	solana.MustPublicKeyFromBase58("juLesoSmdTcRtzjCzYzRoHrnF8GhVu6KCV7uxq7nJGp"),
+	solana.MustPublicKeyFromBase58("DttWaMuVvTiduZRnguLF7jNxTgiMBZ1hyAumKUiL2KRL"),
+}
+
+// Signer signs transactions for one wallet. session.Signer implements it.
+type Signer interface {
+	PublicKey() solana.PublicKey
+	SignTransaction(tx *solana.Transaction) error
+}
+
+// Params describe one transfer. A zero Mint transfers SOL.
+type Params struct {
+	From   solana.PublicKey
+	To     solana.PublicKey
+	Amount float64 // in whole tokens, e.g. 1.5 SOL
+	Mint   solana.PublicKey
+	// Memo is attached to token transfers into accounts that require one.
+	// Defaults to "Transfer".
+	Memo string
+}
+
+// Build creates the unsigned transfer transaction, with a compute budget for
+// strategy.
+func Build(ctx context.Context, client *rpc.Client, p Params, strategy fee.Strategy) (*solana.Transaction, fee.Budget, error) {
+	instructions, err := Instructions(ctx, client, p)
+	if err != nil {
+		return nil, fee.Budget{}, err
+	}
+	instructions, budget, err := fee.Prepare(ctx, client, strategy, p.From, instructions)
+	if err != nil {
+		return nil, fee.Budget{}, err
+	}
+	tx, err := NewTransaction(ctx, client, p.From, instructions)
+	if err != nil {
+		return nil, fee.Budget{}, err
+	}
+	return tx, budget, nil
+}
+
+// Instructions returns the instructions of the transfer. Tokens of both the
+// SPL Token and the Token-2022 program are sent with TransferChecked, and the
+// recipient's associated token account is created if it does not exist yet.
+// Non-transferable Token-2022 mints are refused.
+func Instructions(ctx context.Context, client *rpc.Client, p Params) ([]solana.Instruction, error) {
+	if p.Mint.IsZero() {
+		amountLamports := uint64(p.Amount * float64(solana.LAMPORTS_PER_SOL))
+		return []solana.Instruction{
+			system.NewTransferInstruction(amountLamports, p.From, p.To).Build(),
+		}, nil
+	}
+
+	mint, err := LoadMint(ctx, client, p.Mint)
+	if err != nil {
+		return nil, err
+	}
+	if mint.NonTransferable {
+		return nil, fmt.Errorf("token %s is non-transferable", p.Mint)
+	}
+
+	senderATA, err := associatedTokenAddress(p.From, mint.Address, mint.Program)
+	if err != nil {
+		return nil, fmt.Errorf("error finding sender ATA: %v", err)
+	}
+	recipientATA, err := associatedTokenAddress(p.To, mint.Address, mint.Program)
+	if err != nil {
+		return nil, fmt.Errorf("error finding recipient ATA: %v", err)
+	}
+
+	exists, memoRequired, err := requiresMemo(ctx, client, recipientATA)
+	if err != nil {
+		return nil, err
+	}
+
+	var instructions []solana.Instruction
+	if !exists {
+		instructions = append(instructions, createAssociatedTokenAccount(p.From, p.To, recipientATA, mint))
+	}
+	if memoRequired {
+		text := p.Memo
+		if text == "" {
+			text = defaultMemo
+		}
+		instructions = append(instructions, memo(text, p.From))
+	}
+	instructions = append(instructions,
+		transferChecked(mint.BaseUnits(p.Amount), senderATA, recipientATA, p.From, mint),
+	)
+	return instructions, nil
+}
+
+// NewTransaction wraps instructions in a transaction paid by payer, with a
+// fresh blockhash.
+func NewTransaction(ctx context.Context, client *rpc.Client, payer solana.PublicKey, instructions []solana.Instruction) (*solana.Transaction, error) {
+	recent, err := client.GetLatestBlockhash(ctx, rpc.CommitmentFinalized)
+	if err != nil {
+		return nil, fmt.Errorf("error getting recent blockhash: %v", err)
+	}
+	tx, err := solana.NewTransaction(instructions, recent.Value.Blockhash, solana.TransactionPayer(payer))
+	if err != nil {
+		return nil, fmt.Errorf("error creating transaction: %v", err)
+	}
+	return tx, nil
+}
+
+// BuildTip creates and signs the transaction paying the bundle tip.
+func BuildTip(ctx context.Context, client *rpc.Client, signer Signer) (*solana.Transaction, error) {
+	recent, err := client.GetLatestBlockhash(ctx, rpc.CommitmentFinalized)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get recent blockhash: %v", err)
+	}
+
+	builder := solana.NewTransactionBuilder()
+	builder.SetFeePayer(signer.PublicKey())
+	builder.SetRecentBlockHash(recent.Value.Blockhash)
+	for _, recipient := range tipRecipients {
+		builder.AddInstruction(system.NewTransferInstruction(TipLamports, signer.PublicKey(), recipient).Build())
+	}
+
+	tx, err := builder.Build()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build tip transaction: %v", err)
+	}
+	if err := signer.SignTransaction(tx); err != nil {
+		return nil, fmt.Errorf("failed to sign tip transaction: %v", err)
+	}
+	return tx, nil
+}
+
+// SendBundle submits signed transactions to the Jito block engine at jitoURL
+// and returns the bundle ID.
+func SendBundle(ctx context.Context, jitoURL string, transactions []*solana.Transaction) (string, error) {
+	if len(transactions) == 0 {
+		return "", fmt.Errorf("no transactions to send")
+	}
+
+	encoded := make([]string, len(transactions))
+	for i, tx := range transactions {
+		if len(tx.Signatures) == 0 {
+			return "", fmt.Errorf("transaction %d is not signed", i)
+		}
+		data, err := tx.MarshalBinary()
+		if err != nil {
+			return "", fmt.Errorf("failed to encode transaction %d: %v", i, err)
+		}
+		encoded[i] = base58.Encode(data)
+	}
+
+	// Jito expects params to be an array containing one array of transactions
+	bundleJSON, err := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"method":  "sendBundle",
+		"params":  []interface{}{encoded},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal bundle data: %v", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, jitoURL, bytes.NewReader(bundleJSON))
+	if err != nil {
+		return "", fmt.Errorf("failed to create HTTP request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to send bundle: %v", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read response body: %v", err)
+	}
+
+	var result struct {
+		Result interface{}     `json:"result"`
+		Error  json.RawMessage `json:"error"`
+	}
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return "", fmt.Errorf("failed to decode bundle response: %v", err)
+	}
+	if len(result.Error) > 0 && string(result.Error) != "null" {
+		var detail struct {
+			Message string `json:"message"`
+		}
+		var text string
+		switch {
+		case json.Unmarshal(result.Error, &detail) == nil && detail.Message != "":
+			text = detail.Message
+		case json.Unmarshal(result.Error, &text) == nil:
+		default:
+			text = "unknown error"
+		}
+		return "", fmt.Errorf("bundle error: %s", text)
+	}
+	if result.Result == nil {
+		return "", fmt.Errorf("no result in response")
+	}
+
+	bundleID, ok := result.Result.(string)
+	if !ok {
+		bundleID = fmt.Sprintf("%v", result.Result)
+	}
+	if bundleID == "" {
+		return "", fmt.Errorf("empty bundle ID returned")
+	}
+	return bundleID, nil
+}
+
+// Submit sends tx, which must already be signed by signer. With a jitoURL it
+// is bundled with a tip first; if that fails it is sent through the RPC.
+func Submit(ctx context.Context, client *rpc.Client, jitoURL string, tx *solana.Transaction, signer Signer) (solana.Signature, error) {
+	if len(tx.Signatures) == 0 {
+		return solana.Signature{}, errors.New("transaction is not signed")
+	}
+
+	if jitoURL != "" {
+		tipTx, err := BuildTip(ctx, client, signer)
+		if err == nil {
+			if _, err = SendBundle(ctx, jitoURL, []*solana.Transaction{tx, tipTx}); err == nil {
+				return tx.Signatures[0], nil
+			}
+		}
+		log.Printf("Bundle failed: %v. Falling back to standard transaction.", err)
+	}
+
+	sig, err := client.SendTransaction(ctx, tx)
+	if err != nil {
+		return solana.Signature{}, fmt.Errorf("error sending transaction: %v", err)
+	}
+	return sig, nil
+}
+
+// WaitConfirmed polls every second until sig is finalized, fails on chain or
+// ctx ends. It returns the slot the transaction landed in.
+func WaitConfirmed(ctx context.Context, client *rpc.Client, sig solana.Signature) (uint64, error) {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	var lastErr error
+	for {
+		select {
+		case <-ctx.Done():
+			if lastErr != nil {
+				return 0, fmt.Errorf("transaction timed out: %v", lastErr)
+			}
+			return 0, fmt.Errorf("transaction timed out")
+		case <-ticker.C:
+		}
+
+		response, err := client.GetTransaction(ctx, sig, &rpc.GetTransactionOpts{
+			Commitment: rpc.CommitmentFinalized,
+		})
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if response == nil {
+			continue
+		}
+		if response.Meta != nil && response.Meta.Err != nil {
+			return 0, fmt.Errorf("transaction failed: %v", response.Meta.Err)
+		}
+		return response.Slot, nil
+	}
+}
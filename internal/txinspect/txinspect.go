@@ -0,0 +1,163 @@
+// Package txinspect decodes Solana transactions from their wire encodings or
+// fetches them by signature, and summarises them for display.
+package txinspect
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	bin "github.com/gagliardetto/binary"
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/mr-tron/base58"
+)
+
+// Summary is a JSON-friendly view of a transaction.
+type Summary struct {
+	Signatures      []string      `json:"signatures"`
+	FeePayer        string        `json:"feePayer"`
+	RecentBlockhash string        `json:"recentBlockhash"`
+	Accounts        []Account     `json:"accounts"`
+	Instructions    []Instruction `json:"instructions"`
+}
+
+// Account is an account referenced by a transaction.
+type Account struct {
+	Address  string `json:"address"`
+	Signer   bool   `json:"signer"`
+	Writable bool   `json:"writable"`
+}
+
+// Instruction is one instruction with its accounts resolved.
+type Instruction struct {
+	Program  string   `json:"program"`
+	Accounts []string `json:"accounts"`
+	Data     string   `json:"data"` // hex
+}
+
+// Decode parses an encoded transaction, trying base58 before base64.
+func Decode(encoded string) (*solana.Transaction, error) {
+	encoded = strings.TrimSpace(encoded)
+	if tx, err := DecodeBase58(encoded); err == nil {
+		return tx, nil
+	}
+	if tx, err := DecodeBase64(encoded); err == nil {
+		return tx, nil
+	}
+	return nil, fmt.Errorf("invalid transaction format or data")
+}
+
+// DecodeBase58 decodes a base58 encoded transaction
+func DecodeBase58(encoded string) (*solana.Transaction, error) {
+	data, err := base58.Decode(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode base58: %v", err)
+	}
+	return DecodeBinary(data)
+}
+
+// DecodeBase64 decodes a standard or URL-safe base64 encoded transaction
+func DecodeBase64(encoded string) (*solana.Transaction, error) {
+	encoded = strings.TrimSpace(encoded)
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		data, err = base64.URLEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode base64: %v", err)
+		}
+	}
+	return DecodeBinary(data)
+}
+
+// DecodeBinary decodes a transaction in wire format
+func DecodeBinary(data []byte) (*solana.Transaction, error) {
+	tx, err := solana.TransactionFromDecoder(bin.NewBinDecoder(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode transaction: %v", err)
+	}
+	return tx, nil
+}
+
+// LooksLikeSignature reports whether input could be a transaction signature
+// rather than an encoded transaction.
+func LooksLikeSignature(input string) bool {
+	_, err := solana.SignatureFromBase58(strings.TrimSpace(input))
+	return err == nil
+}
+
+// Fetch loads a confirmed transaction by its signature.
+func Fetch(ctx context.Context, client *rpc.Client, signature string) (*solana.Transaction, error) {
+	sig, err := solana.SignatureFromBase58(strings.TrimSpace(signature))
+	if err != nil {
+		return nil, fmt.Errorf("invalid signature format: %v", err)
+	}
+
+	out, err := client.GetTransaction(ctx, sig, &rpc.GetTransactionOpts{
+		Encoding: solana.EncodingBase64,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error fetching transaction: %v", err)
+	}
+	if out == nil || out.Transaction == nil {
+		return nil, fmt.Errorf("transaction not found or returned empty")
+	}
+
+	data := out.Transaction.GetBinary()
+	if len(data) == 0 {
+		return nil, fmt.Errorf("transaction binary data not available")
+	}
+	tx, err := DecodeBinary(data)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding transaction: %v", err)
+	}
+	return tx, nil
+}
+
+// Summarize resolves the accounts and instructions of tx.
+func Summarize(tx *solana.Transaction) Summary {
+	msg := tx.Message
+	summary := Summary{
+		RecentBlockhash: msg.RecentBlockhash.String(),
+		Signatures:      make([]string, len(tx.Signatures)),
+		Accounts:        make([]Account, len(msg.AccountKeys)),
+		Instructions:    make([]Instruction, len(msg.Instructions)),
+	}
+	for i, sig := range tx.Signatures {
+		summary.Signatures[i] = sig.String()
+	}
+	if len(msg.AccountKeys) > 0 {
+		summary.FeePayer = msg.AccountKeys[0].String()
+	}
+	for i, key := range msg.AccountKeys {
+		writable, _ := msg.IsWritable(key)
+		summary.Accounts[i] = Account{
+			Address:  key.String(),
+			Signer:   msg.IsSigner(key),
+			Writable: writable,
+		}
+	}
+	for i, inst := range msg.Instructions {
+		accounts := make([]string, len(inst.Accounts))
+		for j, idx := range inst.Accounts {
+			accounts[j] = accountAt(msg, idx)
+		}
+		summary.Instructions[i] = Instruction{
+			Program:  accountAt(msg, inst.ProgramIDIndex),
+			Accounts: accounts,
+			Data:     hex.EncodeToString(inst.Data),
+		}
+	}
+	return summary
+}
+
+// accountAt returns the account key at idx, or a placeholder for indexes
+// into address lookup tables that are not part of the static keys.
+func accountAt(msg solana.Message, idx uint16) string {
+	if int(idx) < len(msg.AccountKeys) {
+		return msg.AccountKeys[idx].String()
+	}
+	return fmt.Sprintf("<lookup table account %d>", idx)
+}
@@ -0,0 +1,308 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"unruggable-go/internal/backup"
+)
+
+// settingKind is how a backed-up preference is stored.
+type settingKind int
+
+const (
+	settingInt settingKind = iota
+	settingBool
+	settingString
+)
+
+// backupSettings are the preferences included in backups. Window layout,
+// the last view and the local API settings and token stay with the device.
+var backupSettings = map[string]settingKind{
+	sessionTimeoutKey:    settingInt,
+	networkProfilesKey:   settingString,
+	activeNetworkKey:     settingString,
+	priorityFeeKey:       settingString,
+	homePricesKey:        settingString,
+	conditionalPricesKey: settingString,
+	walletGroupsKey:      settingString,
+	showUnverifiedKey:    settingBool,
+}
+
+// prefSettings exposes the backed-up preferences to backup.Apply.
+type prefSettings struct {
+	app fyne.App
+}
+
+// Markers for a preference that has never been written.
+const (
+	missingSetting       = math.MinInt32
+	missingStringSetting = "\x00missing"
+)
+
+func (p prefSettings) collect() map[string]string {
+	settings := make(map[string]string)
+	for key := range backupSettings {
+		if value, ok := p.Setting(key); ok {
+			settings[key] = value
+		}
+	}
+	return settings
+}
+
+func (p prefSettings) Setting(key string) (string, bool) {
+	prefs := p.app.Preferences()
+	switch backupSettings[key] {
+	case settingBool:
+		// An unset bool returns whichever fallback it is given.
+		if prefs.BoolWithFallback(key, true) != prefs.BoolWithFallback(key, false) {
+			return "", false
+		}
+		return strconv.FormatBool(prefs.Bool(key)), true
+	case settingString:
+		value := prefs.StringWithFallback(key, missingStringSetting)
+		if value == missingStringSetting {
+			return "", false
+		}
+		return value, true
+	}
+	value := prefs.IntWithFallback(key, missingSetting)
+	if value == missingSetting {
+		return "", false
+	}
+	return strconv.Itoa(value), true
+}
+
+func (p prefSettings) SetSetting(key, value string) error {
+	kind, ok := backupSettings[key]
+	if !ok {
+		return fmt.Errorf("unknown setting")
+	}
+	prefs := p.app.Preferences()
+	switch kind {
+	case settingBool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid value %q", value)
+		}
+		prefs.SetBool(key, b)
+	case settingString:
+		prefs.SetString(key, value)
+	default:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("invalid value %q", value)
+		}
+		prefs.SetInt(key, n)
+	}
+
+	switch key {
+	case sessionTimeoutKey:
+		n, _ := strconv.Atoi(value)
+		GetSession().SetTimeout(time.Duration(n) * time.Second)
+	case networkProfilesKey, activeNetworkKey:
+		InitNetwork(p.app)
+	}
+	return nil
+}
+
+// exportBackup writes every wallet, seed, key share, configuration and
+// setting into one archive encrypted with a backup password.
+func (m *WalletManager) exportBackup() {
+	passwordEntry := widget.NewPasswordEntry()
+	passwordEntry.SetPlaceHolder("Backup password")
+	confirmEntry := widget.NewPasswordEntry()
+	confirmEntry.SetPlaceHolder("Repeat backup password")
+
+	info := widget.NewLabel("Wallet keys stay encrypted with their own passwords inside the backup.\n" +
+		"The backup password protects the archive as a whole.")
+	info.Wrapping = fyne.TextWrapWord
+
+	content := container.NewVBox(info, widget.NewForm(
+		widget.NewFormItem("Password", passwordEntry),
+		widget.NewFormItem("Confirm", confirmEntry),
+	))
+
+	dialog.ShowCustomConfirm("Export Backup", "Export", "Cancel", content, func(ok bool) {
+		if !ok {
+			return
+		}
+		if passwordEntry.Text == "" {
+			dialog.ShowError(fmt.Errorf("password cannot be empty"), m.window)
+			return
+		}
+		if passwordEntry.Text != confirmEntry.Text {
+			dialog.ShowError(fmt.Errorf("passwords do not match"), m.window)
+			return
+		}
+
+		archive, err := backup.Collect(m.storage, prefSettings{m.app}.collect())
+		if err != nil {
+			dialog.ShowError(err, m.window)
+			return
+		}
+		data, err := backup.Seal(archive, passwordEntry.Text)
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("failed to encrypt backup: %v", err), m.window)
+			return
+		}
+
+		saveDialog := dialog.NewFileSave(func(writer fyne.URIWriteCloser, err error) {
+			if err != nil {
+				dialog.ShowError(err, m.window)
+				return
+			}
+			if writer == nil {
+				return
+			}
+			defer writer.Close()
+
+			if _, err := writer.Write(data); err != nil {
+				dialog.ShowError(fmt.Errorf("failed to write backup: %v", err), m.window)
+				return
+			}
+			dialog.ShowInformation("Backup Exported",
+				fmt.Sprintf("Saved %d wallet(s), %d seed(s), %d key share(s) and %d configuration(s) to %s",
+					len(archive.Wallets), len(archive.Seeds), len(archive.Shares), len(archive.Configs),
+					writer.URI().Name()), m.window)
+		}, m.window)
+		saveDialog.SetFileName("unruggable-" + time.Now().Format("20060102") + backup.FileExtension)
+		saveDialog.Show()
+	}, m.window)
+}
+
+// restoreBackup opens an archive and shows what restoring it would change.
+func (m *WalletManager) restoreBackup() {
+	fileDialog := dialog.NewFileOpen(func(reader fyne.URIReadCloser, err error) {
+		if err != nil {
+			dialog.ShowError(err, m.window)
+			return
+		}
+		if reader == nil {
+			return
+		}
+		defer reader.Close()
+
+		data, err := io.ReadAll(reader)
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("failed to read backup: %v", err), m.window)
+			return
+		}
+
+		passwordEntry := widget.NewPasswordEntry()
+		passwordEntry.SetPlaceHolder("Backup password")
+
+		dialog.ShowCustomConfirm("Open Backup", "Open", "Cancel", passwordEntry, func(ok bool) {
+			if !ok {
+				return
+			}
+
+			archive, err := backup.Open(data, passwordEntry.Text)
+			if err != nil {
+				dialog.ShowError(fmt.Errorf("failed to open backup: %v", err), m.window)
+				return
+			}
+
+			settings := prefSettings{m.app}
+			plan, err := backup.Plan(archive, m.storage, settings.collect())
+			if err != nil {
+				dialog.ShowError(err, m.window)
+				return
+			}
+			m.showRestorePlan(archive, plan)
+		}, m.window)
+	}, m.window)
+	fileDialog.Show()
+}
+
+// showRestorePlan lists every archive item with its status and lets the user
+// pick how each new or conflicting item is restored.
+func (m *WalletManager) showRestorePlan(archive *backup.Archive, plan []backup.Item) {
+	rows := container.NewVBox()
+	changes := 0
+
+	for i := range plan {
+		item := &plan[i]
+
+		name := item.ID
+		if item.Kind == backup.KindWallet {
+			name = walletDisplayName(item.ID, m.metadata[item.ID])
+			if meta := archive.Wallets[item.ID].Metadata; meta != nil && meta.Label != "" {
+				name = meta.Label
+			}
+		}
+		label := widget.NewLabel(fmt.Sprintf("%s: %s (%s)", item.Kind, name, item.Status))
+
+		var control fyne.CanvasObject
+		switch item.Status {
+		case backup.StatusNew:
+			changes++
+			check := widget.NewCheck("Add", func(add bool) {
+				if add {
+					item.Resolution = backup.ResolveOverwrite
+				} else {
+					item.Resolution = backup.ResolveSkip
+				}
+			})
+			check.SetChecked(true)
+			control = check
+		case backup.StatusConflict:
+			changes++
+			options := []string{string(backup.ResolveSkip), string(backup.ResolveMerge), string(backup.ResolveOverwrite)}
+			if item.Kind == backup.KindSeed || item.Kind == backup.KindShare {
+				// Encrypted blobs cannot be merged field by field.
+				options = []string{string(backup.ResolveSkip), string(backup.ResolveOverwrite)}
+			}
+			choice := widget.NewSelect(options, func(value string) {
+				item.Resolution = backup.Resolution(value)
+			})
+			choice.SetSelected(string(item.Resolution))
+			control = choice
+		default:
+			control = widget.NewLabel("unchanged")
+		}
+
+		rows.Add(container.NewBorder(nil, nil, nil, control, label))
+	}
+
+	summary := widget.NewLabel(fmt.Sprintf("Backup from %s. %d of %d item(s) differ from this device.",
+		archive.CreatedAt.Local().Format("Jan 2 2006 15:04"), changes, len(plan)))
+	summary.Wrapping = fyne.TextWrapWord
+
+	scroll := container.NewVScroll(rows)
+	scroll.SetMinSize(fyne.NewSize(520, 320))
+
+	content := container.NewBorder(summary, nil, nil, nil, scroll)
+
+	dialog.ShowCustomConfirm("Restore Backup", "Restore", "Cancel", content, func(ok bool) {
+		if !ok {
+			return
+		}
+
+		applied, failures, err := backup.Apply(archive, plan, m.storage, prefSettings{m.app})
+		m.loadSavedWallets()
+		if err != nil {
+			dialog.ShowError(err, m.window)
+			return
+		}
+		if len(failures) > 0 {
+			lines := []string{fmt.Sprintf("Restored %d item(s). %d could not be restored:", len(applied), len(failures))}
+			for _, failure := range failures {
+				lines = append(lines, fmt.Sprintf("%s %s: %v", failure.Item.Kind, failure.Item.ID, failure.Err))
+			}
+			dialog.ShowError(errors.New(strings.Join(lines, "\n")), m.window)
+			return
+		}
+		dialog.ShowInformation("Backup Restored", fmt.Sprintf("Restored %d item(s).", len(applied)), m.window)
+	}, m.window)
+}
@@ -0,0 +1,238 @@
+package ui
+
+import (
+	"image/color"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// chartPalette colours the series of a chart.
+var chartPalette = []color.Color{
+	color.NRGBA{R: 0x4e, G: 0x79, B: 0xa7, A: 0xff},
+	color.NRGBA{R: 0xf2, G: 0x8e, B: 0x2b, A: 0xff},
+	color.NRGBA{R: 0x59, G: 0xa1, B: 0x4f, A: 0xff},
+	color.NRGBA{R: 0xe1, G: 0x57, B: 0x59, A: 0xff},
+	color.NRGBA{R: 0x76, G: 0xb7, B: 0xb2, A: 0xff},
+	color.NRGBA{R: 0xed, G: 0xc9, B: 0x48, A: 0xff},
+	color.NRGBA{R: 0xb0, G: 0x7a, B: 0xa1, A: 0xff},
+}
+
+// chartOtherColor colours the series that lumps the smallest ones together.
+var chartOtherColor color.Color = color.NRGBA{R: 0x9c, G: 0x9c, B: 0x9c, A: 0xff}
+
+var chartMinSize = fyne.NewSize(400, 180)
+
+// lineChart plots values evenly spaced from left to right, with the range of
+// the values on the left and labels under both ends.
+type lineChart struct {
+	widget.BaseWidget
+	values               []float64
+	startLabel, endLabel string
+	format               func(float64) string
+}
+
+func newLineChart(format func(float64) string) *lineChart {
+	c := &lineChart{format: format}
+	c.ExtendBaseWidget(c)
+	return c
+}
+
+// SetData replaces the plotted values and the labels under the ends.
+func (c *lineChart) SetData(values []float64, startLabel, endLabel string) {
+	c.values, c.startLabel, c.endLabel = values, startLabel, endLabel
+	c.Refresh()
+}
+
+func (c *lineChart) CreateRenderer() fyne.WidgetRenderer {
+	r := &lineChartRenderer{
+		chart: c,
+		axis:  canvas.NewLine(theme.DisabledColor()),
+		max:   canvas.NewText("", theme.ForegroundColor()),
+		min:   canvas.NewText("", theme.ForegroundColor()),
+		start: canvas.NewText("", theme.ForegroundColor()),
+		end:   canvas.NewText("", theme.ForegroundColor()),
+	}
+	r.end.Alignment = fyne.TextAlignTrailing
+	r.Refresh()
+	return r
+}
+
+type lineChartRenderer struct {
+	chart                *lineChart
+	axis                 *canvas.Line
+	max, min, start, end *canvas.Text
+	lines                []*canvas.Line
+	low, high            float64
+}
+
+func (r *lineChartRenderer) Layout(size fyne.Size) {
+	labelHeight := r.start.MinSize().Height
+	left := fyne.Max(r.max.MinSize().Width, r.min.MinSize().Width) + theme.Padding()
+	plot := fyne.NewSize(size.Width-left, size.Height-labelHeight-theme.Padding())
+
+	r.max.Move(fyne.NewPos(0, 0))
+	r.min.Move(fyne.NewPos(0, plot.Height-labelHeight))
+	r.start.Move(fyne.NewPos(left, plot.Height+theme.Padding()))
+	r.end.Move(fyne.NewPos(size.Width-r.end.MinSize().Width, plot.Height+theme.Padding()))
+	r.axis.Position1 = fyne.NewPos(left, plot.Height)
+	r.axis.Position2 = fyne.NewPos(size.Width, plot.Height)
+
+	values := r.chart.values
+	point := func(i int) fyne.Position {
+		x := left + plot.Width*float32(i)/float32(len(values)-1)
+		y := plot.Height / 2
+		if r.high > r.low {
+			y = plot.Height * float32(1-(values[i]-r.low)/(r.high-r.low))
+		}
+		return fyne.NewPos(x, y)
+	}
+	for i, line := range r.lines {
+		line.Position1, line.Position2 = point(i), point(i+1)
+	}
+}
+
+func (r *lineChartRenderer) MinSize() fyne.Size {
+	return chartMinSize
+}
+
+func (r *lineChartRenderer) Refresh() {
+	values := r.chart.values
+	r.low, r.high = 0, 0
+	for i, v := range values {
+		if i == 0 || v < r.low {
+			r.low = v
+		}
+		if i == 0 || v > r.high {
+			r.high = v
+		}
+	}
+
+	segments := len(values) - 1
+	if segments < 0 {
+		segments = 0
+	}
+	for len(r.lines) < segments {
+		line := canvas.NewLine(theme.PrimaryColor())
+		line.StrokeWidth = 2
+		r.lines = append(r.lines, line)
+	}
+	r.lines = r.lines[:segments]
+
+	r.max.Text, r.min.Text = "", ""
+	if len(values) > 0 {
+		r.max.Text, r.min.Text = r.chart.format(r.high), r.chart.format(r.low)
+	}
+	r.start.Text, r.end.Text = r.chart.startLabel, r.chart.endLabel
+	for _, text := range []*canvas.Text{r.max, r.min, r.start, r.end} {
+		text.Color = theme.ForegroundColor()
+	}
+	for _, line := range r.lines {
+		line.StrokeColor = theme.PrimaryColor()
+	}
+	r.axis.StrokeColor = theme.DisabledColor()
+
+	r.Layout(r.chart.Size())
+	canvas.Refresh(r.chart)
+}
+
+func (r *lineChartRenderer) Objects() []fyne.CanvasObject {
+	objects := []fyne.CanvasObject{r.axis, r.max, r.min, r.start, r.end}
+	for _, line := range r.lines {
+		objects = append(objects, line)
+	}
+	return objects
+}
+
+func (r *lineChartRenderer) Destroy() {}
+
+// stackedChart draws one bar per sample, split into the shares of its series.
+// Each sample's shares should add up to at most 1.
+type stackedChart struct {
+	widget.BaseWidget
+	samples [][]float64
+	colors  []color.Color
+}
+
+func newStackedChart() *stackedChart {
+	c := &stackedChart{}
+	c.ExtendBaseWidget(c)
+	return c
+}
+
+// SetSamples replaces the bars. Series i is drawn in colors[i].
+func (c *stackedChart) SetSamples(samples [][]float64, colors []color.Color) {
+	c.samples, c.colors = samples, colors
+	c.Refresh()
+}
+
+func (c *stackedChart) CreateRenderer() fyne.WidgetRenderer {
+	r := &stackedChartRenderer{chart: c}
+	r.Refresh()
+	return r
+}
+
+type stackedChartRenderer struct {
+	chart *stackedChart
+	bars  [][]*canvas.Rectangle
+}
+
+func (r *stackedChartRenderer) Layout(size fyne.Size) {
+	if len(r.bars) == 0 {
+		return
+	}
+	slot := size.Width / float32(len(r.bars))
+	gap := fyne.Min(slot/4, theme.Padding())
+	for i, bar := range r.bars {
+		bottom := size.Height
+		for j, rect := range bar {
+			height := size.Height * float32(r.chart.samples[i][j])
+			rect.Resize(fyne.NewSize(slot-gap, height))
+			rect.Move(fyne.NewPos(float32(i)*slot, bottom-height))
+			bottom -= height
+		}
+	}
+}
+
+func (r *stackedChartRenderer) MinSize() fyne.Size {
+	return chartMinSize
+}
+
+func (r *stackedChartRenderer) Refresh() {
+	r.bars = r.bars[:0]
+	for _, sample := range r.chart.samples {
+		bar := make([]*canvas.Rectangle, len(sample))
+		for j := range sample {
+			bar[j] = canvas.NewRectangle(r.chart.colors[j])
+		}
+		r.bars = append(r.bars, bar)
+	}
+	r.Layout(r.chart.Size())
+	canvas.Refresh(r.chart)
+}
+
+func (r *stackedChartRenderer) Objects() []fyne.CanvasObject {
+	var objects []fyne.CanvasObject
+	for _, bar := range r.bars {
+		for _, rect := range bar {
+			objects = append(objects, rect)
+		}
+	}
+	return objects
+}
+
+func (r *stackedChartRenderer) Destroy() {}
+
+// chartLegend names the series of a chart next to their colours.
+func chartLegend(names []string, colors []color.Color) fyne.CanvasObject {
+	items := make([]fyne.CanvasObject, 0, len(names))
+	for i, name := range names {
+		swatch := canvas.NewRectangle(colors[i])
+		swatch.SetMinSize(fyne.NewSize(12, 12))
+		items = append(items, container.NewHBox(container.NewCenter(swatch), widget.NewLabel(name)))
+	}
+	return container.NewGridWrap(fyne.NewSize(120, 36), items...)
+}
@@ -0,0 +1,317 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/gagliardetto/solana-go"
+
+	"unruggable-go/internal/fee"
+	"unruggable-go/internal/nft"
+	"unruggable-go/internal/session"
+	"unruggable-go/internal/transfer"
+)
+
+// nftImageDir holds cached NFT previews in the storage root.
+const nftImageDir = "nft-images"
+
+// imageLoads bounds the previews downloaded at the same time.
+const imageLoads = 4
+
+var (
+	previewSize = fyne.NewSize(140, 140)
+	tileSize    = fyne.NewSize(160, 240)
+)
+
+// CollectiblesScreen shows the NFTs and compressed NFTs of the selected
+// wallet, grouped by collection, and sends them.
+type CollectiblesScreen struct {
+	window      fyne.Window
+	app         fyne.App
+	walletLabel *widget.Label
+	status      *widget.Label
+	gallery     *fyne.Container
+	images      nft.ImageCache
+	loads       chan struct{}
+
+	mu    sync.Mutex
+	cache map[string][]nft.Asset // last listing per wallet
+}
+
+// NewCollectiblesScreen creates the collectibles gallery. Listings come from
+// the DAS API of the active RPC endpoint.
+func NewCollectiblesScreen(window fyne.Window, app fyne.App) fyne.CanvasObject {
+	s := &CollectiblesScreen{
+		window:      window,
+		app:         app,
+		walletLabel: widget.NewLabel("No wallet selected"),
+		status:      widget.NewLabel(""),
+		gallery:     container.NewVBox(),
+		images:      nft.ImageCache{Dir: filepath.Join(app.Storage().RootURI().Path(), nftImageDir)},
+		loads:       make(chan struct{}, imageLoads),
+		cache:       make(map[string][]nft.Asset),
+	}
+
+	refreshButton := widget.NewButton("Refresh", func() {
+		s.load(GetGlobalState().GetSelectedWallet())
+	})
+	refreshButton.Importance = widget.HighImportance
+
+	bus := GetGlobalState().Events
+	bus.WalletSelected.Subscribe(func(e WalletSelectedEvent) {
+		s.showWallet(e.WalletID)
+	})
+	bus.NetworkChanged.Subscribe(func(NetworkChangedEvent) {
+		// Listings belong to the previous cluster
+		s.mu.Lock()
+		s.cache = make(map[string][]nft.Asset)
+		s.mu.Unlock()
+		s.showWallet(GetGlobalState().GetSelectedWallet())
+	})
+	s.showWallet(GetGlobalState().GetSelectedWallet())
+
+	top := container.NewVBox(
+		widget.NewLabelWithStyle("Collectibles", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		s.walletLabel,
+		s.status,
+	)
+	return container.NewBorder(top, refreshButton, nil, nil, container.NewVScroll(s.gallery))
+}
+
+func (s *CollectiblesScreen) das() (*nft.Client, error) {
+	url, err := activeNetwork().DASEndpoint()
+	if err != nil {
+		return nil, err
+	}
+	return &nft.Client{URL: url, HTTP: rpcHTTPClient()}, nil
+}
+
+// showWallet renders walletID from the cache and fetches a fresh listing.
+func (s *CollectiblesScreen) showWallet(walletID string) {
+	if walletID == "" {
+		s.walletLabel.SetText("No wallet selected")
+		s.status.SetText("Please select a wallet to view its collectibles.")
+		s.render(nil)
+		return
+	}
+	s.walletLabel.SetText(fmt.Sprintf("Wallet: %s", walletID))
+
+	s.mu.Lock()
+	cached, ok := s.cache[walletID]
+	s.mu.Unlock()
+	if ok {
+		s.render(cached)
+	} else {
+		s.render(nil)
+	}
+	s.load(walletID)
+}
+
+// load fetches the assets of walletID and shows them if it is still selected.
+func (s *CollectiblesScreen) load(walletID string) {
+	if walletID == "" {
+		return
+	}
+	das, err := s.das()
+	if err != nil {
+		s.status.SetText(fmt.Sprintf("Collectibles are unavailable: %v", err))
+		return
+	}
+	s.status.SetText("Loading collectibles...")
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+		assets, err := das.Assets(ctx, walletID)
+		if walletID != GetGlobalState().GetSelectedWallet() {
+			return
+		}
+		if err != nil {
+			s.status.SetText(fmt.Sprintf("Error loading collectibles: %v", err))
+			return
+		}
+		s.mu.Lock()
+		s.cache[walletID] = assets
+		s.mu.Unlock()
+		s.render(assets)
+	}()
+}
+
+func (s *CollectiblesScreen) render(assets []nft.Asset) {
+	if len(assets) == 0 {
+		s.gallery.Objects = nil
+		s.gallery.Refresh()
+		if GetGlobalState().GetSelectedWallet() != "" {
+			s.status.SetText("No collectibles found.")
+		}
+		return
+	}
+
+	collections := nft.Group(assets)
+	accordion := widget.NewAccordion()
+	for _, collection := range collections {
+		grid := container.NewGridWrap(tileSize)
+		for _, asset := range collection.Assets {
+			grid.Add(s.tile(asset))
+		}
+		accordion.Append(widget.NewAccordionItem(fmt.Sprintf("%s (%d)", collection.Name, len(collection.Assets)), grid))
+	}
+	if len(collections) == 1 {
+		accordion.Open(0)
+	}
+	s.status.SetText(fmt.Sprintf("%d collectibles in %d collections", len(assets), len(collections)))
+	s.gallery.Objects = []fyne.CanvasObject{accordion}
+	s.gallery.Refresh()
+}
+
+// tile shows one asset with its preview, which is loaded in the background.
+func (s *CollectiblesScreen) tile(asset nft.Asset) fyne.CanvasObject {
+	image := canvas.NewImageFromResource(nil)
+	image.FillMode = canvas.ImageFillContain
+	image.SetMinSize(previewSize)
+	if asset.Preview != "" {
+		go s.loadPreview(asset, image)
+	}
+
+	name := widget.NewLabel(asset.Name)
+	name.Truncation = fyne.TextTruncateEllipsis
+	kind := widget.NewLabelWithStyle(string(asset.Kind), fyne.TextAlignLeading, fyne.TextStyle{Italic: true})
+
+	send := widget.NewButton("Send", func() { s.sendAsset(asset) })
+	if asset.Sendable() != nil {
+		send.Disable()
+	}
+	return container.NewVBox(image, name, kind, send)
+}
+
+func (s *CollectiblesScreen) loadPreview(asset nft.Asset, image *canvas.Image) {
+	s.loads <- struct{}{}
+	defer func() { <-s.loads }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	data, err := s.images.Load(ctx, asset.Preview)
+	if err != nil {
+		return
+	}
+	image.Resource = fyne.NewStaticResource(asset.ID, data)
+	image.Refresh()
+}
+
+// sendAsset asks for a recipient and sends asset from the selected wallet.
+func (s *CollectiblesScreen) sendAsset(asset nft.Asset) {
+	if err := asset.Sendable(); err != nil {
+		dialog.ShowError(err, s.window)
+		return
+	}
+	walletID := GetGlobalState().GetSelectedWallet()
+	recipient := widget.NewEntry()
+	recipient.SetPlaceHolder("Recipient address")
+
+	dialog.ShowForm(fmt.Sprintf("Send %s", asset.Name), "Send", "Cancel",
+		[]*widget.FormItem{widget.NewFormItem("Recipient", recipient)},
+		func(ok bool) {
+			if !ok {
+				return
+			}
+			to, err := solana.PublicKeyFromBase58(strings.TrimSpace(recipient.Text))
+			if err != nil {
+				dialog.ShowError(fmt.Errorf("invalid recipient: %v", err), s.window)
+				return
+			}
+			go s.confirmSend(walletID, asset, to)
+		}, s.window)
+}
+
+// confirmSend prepares and simulates the transfer of asset, so its priority
+// fee and effects can be shown, and sends it once confirmed.
+func (s *CollectiblesScreen) confirmSend(walletID string, asset nft.Asset, to solana.PublicKey) {
+	ctx := context.Background()
+	client := newRPCClient()
+	from, err := solana.PublicKeyFromBase58(walletID)
+	if err != nil {
+		dialog.ShowError(fmt.Errorf("invalid wallet: %v", err), s.window)
+		return
+	}
+
+	das, err := s.das()
+	if err != nil {
+		dialog.ShowError(err, s.window)
+		return
+	}
+
+	s.status.SetText(fmt.Sprintf("Preparing transfer of %s...", asset.Name))
+	instructions, err := nft.TransferInstructions(ctx, client, das, asset, from, to)
+	if err != nil {
+		s.status.SetText("")
+		dialog.ShowError(fmt.Errorf("failed to create transfer transaction: %v", err), s.window)
+		return
+	}
+	instructions, budget, err := fee.Prepare(ctx, client, priorityFeeStrategy(), from, instructions)
+	s.status.SetText("")
+	if err != nil {
+		dialog.ShowError(err, s.window)
+		return
+	}
+
+	text := fmt.Sprintf("Send %s (%s NFT %s) to %s?\n\n%s", asset.Name, asset.Kind, shortenAddress(asset.ID), shortenAddress(to.String()), budget)
+	s.status.SetText(fmt.Sprintf("Simulating transfer of %s...", asset.Name))
+	confirmWithPreview(ctx, client, s.window, "Confirm Transfer", text, from, instructions, func() {
+		requestSigner(s.app, s.window, walletID, func(signer *session.Signer) {
+			go s.executeSend(walletID, asset, signer, instructions)
+		}, nil)
+	})
+	s.status.SetText("")
+}
+
+func (s *CollectiblesScreen) executeSend(walletID string, asset nft.Asset, signer *session.Signer, instructions []solana.Instruction) {
+	const confirmTimeout = time.Minute
+	ctx := context.Background()
+	client := newRPCClient()
+
+	fail := func(err error) {
+		s.status.SetText(fmt.Sprintf("Failed to send %s", asset.Name))
+		dialog.ShowError(err, s.window)
+	}
+
+	s.status.SetText(fmt.Sprintf("Sending %s...", asset.Name))
+	tx, err := transfer.NewTransaction(ctx, client, signer.PublicKey(), instructions)
+	if err != nil {
+		fail(fmt.Errorf("failed to create transfer transaction: %v", err))
+		return
+	}
+	if err := signer.SignTransaction(tx); err != nil {
+		fail(fmt.Errorf("error signing transfer transaction: %v", err))
+		return
+	}
+	sig, err := transfer.Submit(ctx, client, activeNetwork().JitoURL, tx, signer)
+	if err != nil {
+		fail(err)
+		return
+	}
+	publishTxStatus("Collectibles", walletID, sig.String(), nil)
+	s.status.SetText(fmt.Sprintf("Transaction sent with ID: %s", shortenAddress(sig.String())))
+
+	confirmCtx, cancel := context.WithTimeout(ctx, confirmTimeout)
+	defer cancel()
+	if _, err := transfer.WaitConfirmed(confirmCtx, client, sig); err != nil {
+		publishTxStatus("Collectibles", walletID, sig.String(), err)
+		fail(err)
+		return
+	}
+	GetGlobalState().Events.TxStatus.Publish(TxStatusEvent{
+		Source:   "Collectibles",
+		WalletID: walletID,
+		ID:       sig.String(),
+		Status:   TxConfirmed,
+	})
+	s.load(walletID)
+}
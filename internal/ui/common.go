@@ -2,22 +2,18 @@ package ui
 
 import (
 	"bytes"
-	"crypto/aes"
-	"crypto/cipher"
-	"crypto/sha256"
-	"encoding/base64"
-	"encoding/hex"
 	"encoding/json"
-	"fmt"
+
+	"unruggable-go/internal/display"
+	"unruggable-go/internal/portfolio"
 )
 
-// Token represents a token from the Jupiter token list
-type Token struct {
-	Address string `json:"address"`
-	Symbol  string `json:"symbol"`
-	Name    string `json:"name"`
-	LogoURI string `json:"logoURI"`
-}
+// Balance types are defined in the portfolio package so the CLI shares them.
+type (
+	Token          = portfolio.Token
+	Holding        = portfolio.Holding
+	WalletResponse = portfolio.Balances
+)
 
 // TokenInfo represents token details from the RPC response
 type TokenInfo struct {
@@ -55,60 +51,6 @@ type CalypsoAssetsResponse struct {
 	} `json:"result"`
 }
 
-// Holding represents a wallet holding
-type Holding struct {
-	Symbol     string  `json:"symbol"`
-	Address    string  `json:"address"`
-	Balance    float64 `json:"balance"`
-	USDPrice   float64 `json:"usdPrice"`
-	USDBalance float64 `json:"usdBalance"`
-	Decimals   int     `json:"decimals"` // Added for SPL token transfers
-}
-
-// WalletResponse represents the wallet balance response
-type WalletResponse struct {
-	SolBalance    float64   `json:"solBalance"`
-	SolBalanceUSD float64   `json:"solBalanceUSD"`
-	Assets        []Holding `json:"assets"`
-}
-
-// decryptShare decrypts an encrypted share using a password
-func decryptShare(encryptedData string, nonceStr string, password []byte) ([]byte, error) {
-	key := sha256.Sum256(password)
-	block, err := aes.NewCipher(key[:])
-	if err != nil {
-		return nil, err
-	}
-
-	aesgcm, err := cipher.NewGCM(block)
-	if err != nil {
-		return nil, err
-	}
-
-	ciphertext, err := base64.StdEncoding.DecodeString(encryptedData)
-	if err != nil {
-		return nil, err
-	}
-
-	nonce, err := base64.StdEncoding.DecodeString(nonceStr)
-	if err != nil {
-		return nil, err
-	}
-
-	plaintext, err := aesgcm.Open(nil, nonce, ciphertext, nil)
-	if err != nil {
-		return nil, err
-	}
-
-	return plaintext, nil
-}
-
-// hashPassword generates a SHA-256 hash of a password
-func hashPassword(password []byte) string {
-	hash := sha256.Sum256(password)
-	return hex.EncodeToString(hash[:])
-}
-
 // jsonEncode encodes a value to JSON and returns it as a buffer
 func jsonEncode(v interface{}) *bytes.Buffer {
 	buf := new(bytes.Buffer)
@@ -116,17 +58,6 @@ func jsonEncode(v interface{}) *bytes.Buffer {
 	return buf
 }
 
-// padKey pads a key to 32 bytes
-func padKey(key string) string {
-	for len(key) < 32 {
-		key += key
-	}
-	return key[:32]
-}
-
 func shortenAddress(address string) string {
-	if len(address) <= 8 {
-		return address
-	}
-	return fmt.Sprintf("%s...%s", address[:4], address[len(address)-4:])
+	return display.Address(address)
 }
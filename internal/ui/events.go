@@ -0,0 +1,63 @@
+package ui
+
+import (
+	"unruggable-go/internal/events"
+	"unruggable-go/internal/network"
+)
+
+// EventBus carries the application-wide events screens subscribe to instead
+// of being rebuilt. Handlers run on the publisher's goroutine.
+type EventBus struct {
+	WalletSelected  events.Topic[WalletSelectedEvent]
+	BalancesUpdated events.Topic[BalancesUpdatedEvent]
+	NetworkChanged  events.Topic[NetworkChangedEvent]
+	TxStatus        events.Topic[TxStatusEvent]
+}
+
+// WalletSelectedEvent is published when the selected wallet changes.
+type WalletSelectedEvent struct {
+	WalletID string
+	Previous string
+}
+
+// BalancesUpdatedEvent is published after balances were fetched for a wallet.
+type BalancesUpdatedEvent struct {
+	WalletID string
+	Balances *WalletResponse
+}
+
+// NetworkChangedEvent is published when another network profile is activated
+// or the active one is edited.
+type NetworkChangedEvent struct {
+	Network  network.Profile
+	Previous network.Profile
+}
+
+// TxStatus is the lifecycle stage of a submitted transaction or bundle.
+type TxStatus string
+
+const (
+	TxSubmitted TxStatus = "submitted"
+	TxConfirmed TxStatus = "confirmed"
+	TxFailed    TxStatus = "failed"
+)
+
+// TxStatusEvent reports progress of a transaction sent by a screen or bot.
+type TxStatusEvent struct {
+	Source   string // screen or bot that sent it, e.g. "Send" or "Calypso"
+	WalletID string
+	ID       string // transaction signature or Jito bundle ID
+	Status   TxStatus
+	Err      error
+}
+
+// publishTxStatus reports the outcome of sending id from walletID: failed if
+// err is set, submitted otherwise.
+func publishTxStatus(source, walletID, id string, err error) {
+	event := TxStatusEvent{Source: source, WalletID: walletID, ID: id, Status: TxSubmitted}
+	if err != nil {
+		event.Status = TxFailed
+		event.Err = err
+	}
+	GetGlobalState().Events.TxStatus.Publish(event)
+}
@@ -21,13 +21,15 @@ import (
 	confirm "github.com/gagliardetto/solana-go/rpc/sendAndConfirmTransaction"
 	"github.com/gagliardetto/solana-go/rpc/ws"
 	"github.com/tarm/serial"
+
+	"unruggable-go/internal/fee"
+	"unruggable-go/internal/transfer"
 )
 
 const (
 	RECIPIENT_PUBLIC_KEY = "6tBou5MHL5aWpDy6cgf3wiwGGK2mR8qs68ujtpaoWrf2"
 	LAMPORTS_TO_SEND     = 1000000
 	SERIAL_PORT          = "/dev/tty.usbserial-0001"
-	RPC_URL              = "https://special-blue-fog.solana-mainnet.quiknode.pro/d009d548b4b9dd9f062a8124a868fb915937976c/"
 )
 
 // getESP32PublicKey writes "GET_PUBKEY\n" to the serial port and reads the newline‑terminated public key.
@@ -55,18 +57,13 @@ func getESP32PublicKey(port *serial.Port) (solana.PublicKey, error) {
 }
 
 // createUnsignedTransaction builds a transaction transferring lamports from the ESP32 wallet (as fee payer)
-// to the RECIPIENT_PUBLIC_KEY.
-func createUnsignedTransaction(client *rpc.Client, esp32Pubkey solana.PublicKey) (*solana.Transaction, error) {
+// to the RECIPIENT_PUBLIC_KEY, with a compute budget for the chosen priority fee.
+func createUnsignedTransaction(client *rpc.Client, esp32Pubkey solana.PublicKey) (*solana.Transaction, fee.Budget, error) {
 	recipient, err := solana.PublicKeyFromBase58(RECIPIENT_PUBLIC_KEY)
 	if err != nil {
-		return nil, err
+		return nil, fee.Budget{}, err
 	}
 	ctx := context.Background()
-	resp, err := client.GetLatestBlockhash(ctx, rpc.CommitmentFinalized)
-	if err != nil {
-		return nil, err
-	}
-	recentBlockhash := resp.Value.Blockhash
 
 	instr := system.NewTransferInstruction(
 		LAMPORTS_TO_SEND,
@@ -74,15 +71,15 @@ func createUnsignedTransaction(client *rpc.Client, esp32Pubkey solana.PublicKey)
 		recipient,
 	).Build()
 
-	tx, err := solana.NewTransaction(
-		[]solana.Instruction{instr},
-		recentBlockhash,
-		solana.TransactionPayer(esp32Pubkey),
-	)
+	instructions, budget, err := fee.Prepare(ctx, client, priorityFeeStrategy(), esp32Pubkey, []solana.Instruction{instr})
+	if err != nil {
+		return nil, fee.Budget{}, err
+	}
+	tx, err := transfer.NewTransaction(ctx, client, esp32Pubkey, instructions)
 	if err != nil {
-		return nil, err
+		return nil, fee.Budget{}, err
 	}
-	return tx, nil
+	return tx, budget, nil
 }
 
 // sendToESP32AndGetSignature sends the base64-encoded transaction message over the serial port,
@@ -131,8 +128,9 @@ func signTransaction(output *widget.Entry) {
 	}
 	defer port.Close()
 
-	updateOutput("Creating RPC client...")
-	client := rpc.New(RPC_URL)
+	profile := activeNetwork()
+	updateOutput(fmt.Sprintf("Creating RPC client for %s...", profile.Name))
+	client := newRPCClient()
 
 	updateOutput("Requesting public key from ESP32...")
 	esp32Pubkey, err := getESP32PublicKey(port)
@@ -143,11 +141,12 @@ func signTransaction(output *widget.Entry) {
 	updateOutput("Received ESP32 public key: " + esp32Pubkey.String())
 
 	updateOutput("Creating unsigned transaction...")
-	tx, err := createUnsignedTransaction(client, esp32Pubkey)
+	tx, budget, err := createUnsignedTransaction(client, esp32Pubkey)
 	if err != nil {
 		updateOutput(fmt.Sprintf("Error creating transaction: %v", err))
 		return
 	}
+	updateOutput(budget.String())
 
 	msgBytes, err := tx.Message.MarshalBinary()
 	if err != nil {
@@ -175,7 +174,7 @@ func signTransaction(output *widget.Entry) {
 	tx.Signatures = []solana.Signature{signature}
 
 	updateOutput("Connecting to WS for transaction confirmation...")
-	wsClient, err := ws.Connect(context.Background(), "wss://api.mainnet-beta.solana.com")
+	wsClient, err := ws.Connect(context.Background(), profile.WebSocketURL())
 	if err != nil {
 		updateOutput(fmt.Sprintf("Error connecting to WS: %v", err))
 		return
@@ -0,0 +1,392 @@
+package ui
+
+import (
+	"fmt"
+	"image/color"
+	"log"
+	"math"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"unruggable-go/internal/history"
+	"unruggable-go/internal/storage"
+)
+
+// historyRefresh is how often every wallet is snapshotted while the app runs.
+const historyRefresh = time.Hour
+
+// maxAllocationBars bounds the bars of the allocation chart.
+const maxAllocationBars = 40
+
+var (
+	historyOnce  sync.Once
+	historyStore *history.Store
+)
+
+// portfolioHistory returns the snapshot store in the storage root.
+func portfolioHistory() *history.Store {
+	historyOnce.Do(func() {
+		dir := history.Dir
+		if app := fyne.CurrentApp(); app != nil {
+			dir = filepath.Join(app.Storage().RootURI().Path(), history.Dir)
+		}
+		historyStore = history.Open(dir)
+	})
+	return historyStore
+}
+
+// InitHistory records portfolio snapshots whenever balances are fetched and
+// refreshes every wallet that is not hidden once per historyRefresh.
+// Snapshots of a wallet are kept at least history.Interval apart.
+func InitHistory(app fyne.App) {
+	GetGlobalState().Events.BalancesUpdated.Subscribe(func(e BalancesUpdatedEvent) {
+		recordSnapshot(e.WalletID, e.Balances)
+	})
+	go func() {
+		ticker := time.NewTicker(historyRefresh)
+		defer ticker.Stop()
+		for range ticker.C {
+			snapshotWallets(app)
+		}
+	}()
+}
+
+func recordSnapshot(walletID string, balances *WalletResponse) {
+	snapshot := history.FromBalances(activeNetwork().Name, walletID, "app", balances)
+	if _, err := portfolioHistory().Record(snapshot); err != nil {
+		log.Printf("Failed to record portfolio history: %v", err)
+	}
+}
+
+// snapshotWallets fetches the balances of every visible wallet. The selected
+// wallet goes through the event bus so open screens update as well.
+func snapshotWallets(app fyne.App) {
+	walletStorage := storage.NewWalletStorage(app)
+	wallets, err := walletStorage.LoadWallets()
+	if err != nil {
+		log.Printf("Failed to load wallets: %v", err)
+		return
+	}
+	metadata, err := walletStorage.LoadMetadata()
+	if err != nil {
+		log.Printf("Failed to load wallet metadata: %v", err)
+	}
+	selected := GetGlobalState().GetSelectedWallet()
+	for walletID := range wallets {
+		if metadata[walletID].Hidden {
+			continue
+		}
+		if walletID == selected {
+			if _, err := getWalletBalances(walletID); err != nil {
+				log.Printf("Failed to fetch balances of %s: %v", walletID, err)
+			}
+			continue
+		}
+		balances, err := balanceFetcher().Balances(walletID)
+		if err != nil {
+			log.Printf("Failed to fetch balances of %s: %v", walletID, err)
+			continue
+		}
+		recordSnapshot(walletID, balances)
+	}
+}
+
+// HistoryScreen charts the recorded value and allocation of the selected
+// wallet and works out its profit and loss over a range.
+type HistoryScreen struct {
+	window      fyne.Window
+	walletLabel *widget.Label
+	rangeSelect *widget.Select
+	summary     *widget.Label
+	valueChart  *lineChart
+	allocation  *stackedChart
+	legend      *fyne.Container
+	pnlSummary  *widget.Label
+	pnlTable    *fyne.Container
+
+	mu        sync.Mutex
+	snapshots []history.Snapshot // shown now, for export
+}
+
+// NewHistoryScreen creates the portfolio history screen.
+func NewHistoryScreen(window fyne.Window) fyne.CanvasObject {
+	s := &HistoryScreen{
+		window:      window,
+		walletLabel: widget.NewLabel("No wallet selected"),
+		summary:     widget.NewLabel(""),
+		valueChart:  newLineChart(func(v float64) string { return fmt.Sprintf("$%.2f", v) }),
+		allocation:  newStackedChart(),
+		legend:      container.NewStack(),
+		pnlSummary:  widget.NewLabel(""),
+		pnlTable:    container.NewGridWithColumns(6),
+	}
+	s.summary.Wrapping = fyne.TextWrapWord
+
+	s.rangeSelect = widget.NewSelect(history.Ranges, func(string) { s.load() })
+	s.rangeSelect.SetSelected("30d")
+
+	recordButton := widget.NewButton("Record Now", s.recordNow)
+	exportButton := widget.NewButton("Export CSV", s.exportCSV)
+	exportButton.Importance = widget.HighImportance
+
+	bus := GetGlobalState().Events
+	bus.WalletSelected.Subscribe(func(WalletSelectedEvent) { s.load() })
+	bus.NetworkChanged.Subscribe(func(NetworkChangedEvent) { s.load() })
+	bus.BalancesUpdated.Subscribe(func(e BalancesUpdatedEvent) {
+		if e.WalletID == GetGlobalState().GetSelectedWallet() {
+			s.load()
+		}
+	})
+
+	note := widget.NewLabel("Profit and loss is measured from the start of the range at average cost. " +
+		"Deposits and withdrawals count as buys and sales at the price of the snapshot that saw them.")
+	note.Wrapping = fyne.TextWrapWord
+
+	top := container.NewVBox(
+		widget.NewLabelWithStyle("Portfolio History", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		s.walletLabel,
+		container.NewHBox(widget.NewLabel("Range:"), s.rangeSelect, recordButton, exportButton),
+	)
+	content := container.NewVBox(
+		s.summary,
+		widget.NewLabelWithStyle("Value", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		s.valueChart,
+		widget.NewLabelWithStyle("Allocation", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		s.allocation,
+		s.legend,
+		widget.NewLabelWithStyle("Profit and Loss", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		s.pnlSummary,
+		s.pnlTable,
+		note,
+	)
+	return container.NewBorder(top, nil, nil, nil, container.NewVScroll(content))
+}
+
+// load reads the snapshots of the selected wallet in the chosen range.
+func (s *HistoryScreen) load() {
+	walletID := GetGlobalState().GetSelectedWallet()
+	if walletID == "" {
+		s.walletLabel.SetText("No wallet selected")
+		s.render(nil, "Please select a wallet to view its history.")
+		return
+	}
+	s.walletLabel.SetText(fmt.Sprintf("Wallet: %s", walletID))
+
+	since, err := history.RangeStart(s.rangeSelect.Selected, time.Now())
+	if err != nil {
+		s.render(nil, err.Error())
+		return
+	}
+	snapshots, err := portfolioHistory().Load(activeNetwork().Name, walletID, since)
+	if err != nil {
+		s.render(nil, fmt.Sprintf("Error loading history: %v", err))
+		return
+	}
+	if len(snapshots) == 0 {
+		s.render(nil, "No snapshots in this range yet. They are taken when balances are updated and every hour while the app runs.")
+		return
+	}
+	s.render(snapshots, "")
+}
+
+func (s *HistoryScreen) render(snapshots []history.Snapshot, message string) {
+	s.mu.Lock()
+	s.snapshots = snapshots
+	s.mu.Unlock()
+
+	if len(snapshots) == 0 {
+		s.summary.SetText(message)
+		s.valueChart.SetData(nil, "", "")
+		s.allocation.SetSamples(nil, nil)
+		s.legend.Objects = nil
+		s.legend.Refresh()
+		s.pnlSummary.SetText("")
+		s.pnlTable.Objects = nil
+		s.pnlTable.Refresh()
+		return
+	}
+
+	first, last := snapshots[0], snapshots[len(snapshots)-1]
+	values := make([]float64, len(snapshots))
+	for i, snapshot := range snapshots {
+		values[i] = snapshot.Value
+	}
+	s.valueChart.SetData(values, formatSnapshotTime(first.Time), formatSnapshotTime(last.Time))
+
+	change := last.Value - first.Value
+	percent := ""
+	if first.Value > 0 {
+		percent = fmt.Sprintf(", %+.2f%%", change/first.Value*100)
+	}
+	s.summary.SetText(fmt.Sprintf("Value: $%.2f → $%.2f (%s%s) over %d snapshots",
+		first.Value, last.Value, formatSignedUSD(change), percent, len(snapshots)))
+
+	s.renderAllocation(snapshots)
+	s.renderPnL(history.ComputePnL(snapshots))
+}
+
+// renderAllocation shows each token's share of the value over time. The
+// tokens worth most over the range are named, the rest shown as Other.
+func (s *HistoryScreen) renderAllocation(snapshots []history.Snapshot) {
+	if len(snapshots) > maxAllocationBars {
+		sampled := make([]history.Snapshot, maxAllocationBars)
+		for i := range sampled {
+			sampled[i] = snapshots[i*(len(snapshots)-1)/(maxAllocationBars-1)]
+		}
+		snapshots = sampled
+	}
+
+	totals := make(map[string]float64)
+	symbols := make(map[string]string)
+	for _, snapshot := range snapshots {
+		for _, h := range snapshot.Holdings {
+			totals[h.Mint] += h.Value
+			symbols[h.Mint] = h.Symbol
+		}
+	}
+	var mints []string
+	for mint, total := range totals {
+		if total > 0 {
+			mints = append(mints, mint)
+		}
+	}
+	sort.Slice(mints, func(i, j int) bool { return totals[mints[i]] > totals[mints[j]] })
+
+	named := mints
+	other := false
+	if len(mints) > len(chartPalette) {
+		named, other = mints[:len(chartPalette)-1], true
+	}
+	series := make(map[string]int, len(named))
+	var names []string
+	var colors []color.Color
+	for i, mint := range named {
+		series[mint] = i
+		names = append(names, symbols[mint])
+		colors = append(colors, chartPalette[i])
+	}
+	if other {
+		names = append(names, "Other")
+		colors = append(colors, chartOtherColor)
+	}
+
+	samples := make([][]float64, len(snapshots))
+	for i, snapshot := range snapshots {
+		shares := make([]float64, len(names))
+		if snapshot.Value > 0 {
+			for _, h := range snapshot.Holdings {
+				j, ok := series[h.Mint]
+				if !ok {
+					if !other {
+						continue
+					}
+					j = len(names) - 1
+				}
+				shares[j] += h.Value / snapshot.Value
+			}
+		}
+		samples[i] = shares
+	}
+	s.allocation.SetSamples(samples, colors)
+	s.legend.Objects = []fyne.CanvasObject{chartLegend(names, colors)}
+	s.legend.Refresh()
+}
+
+func (s *HistoryScreen) renderPnL(pnl history.PnL) {
+	s.pnlSummary.SetText(fmt.Sprintf("Realized: %s    Unrealized: %s    Total: %s",
+		formatSignedUSD(pnl.Realized), formatSignedUSD(pnl.Unrealized), formatSignedUSD(pnl.Realized+pnl.Unrealized)))
+
+	bold := fyne.TextStyle{Bold: true}
+	objects := []fyne.CanvasObject{
+		widget.NewLabelWithStyle("Token", fyne.TextAlignLeading, bold),
+		widget.NewLabelWithStyle("Balance", fyne.TextAlignTrailing, bold),
+		widget.NewLabelWithStyle("Value", fyne.TextAlignTrailing, bold),
+		widget.NewLabelWithStyle("Cost Basis", fyne.TextAlignTrailing, bold),
+		widget.NewLabelWithStyle("Realized", fyne.TextAlignTrailing, bold),
+		widget.NewLabelWithStyle("Unrealized", fyne.TextAlignTrailing, bold),
+	}
+	for _, token := range pnl.Tokens {
+		objects = append(objects,
+			widget.NewLabel(token.Symbol),
+			widget.NewLabelWithStyle(fmt.Sprintf("%.6f", token.Balance), fyne.TextAlignTrailing, fyne.TextStyle{}),
+			widget.NewLabelWithStyle(fmt.Sprintf("$%.2f", token.Value), fyne.TextAlignTrailing, fyne.TextStyle{}),
+			widget.NewLabelWithStyle(fmt.Sprintf("$%.2f", token.CostBasis), fyne.TextAlignTrailing, fyne.TextStyle{}),
+			widget.NewLabelWithStyle(formatSignedUSD(token.Realized), fyne.TextAlignTrailing, fyne.TextStyle{}),
+			widget.NewLabelWithStyle(formatSignedUSD(token.Unrealized), fyne.TextAlignTrailing, fyne.TextStyle{}),
+		)
+	}
+	s.pnlTable.Objects = objects
+	s.pnlTable.Refresh()
+}
+
+// recordNow snapshots the selected wallet even if one was taken recently.
+func (s *HistoryScreen) recordNow() {
+	walletID := GetGlobalState().GetSelectedWallet()
+	if walletID == "" {
+		dialog.ShowInformation("No Wallet", "Please select a wallet first.", s.window)
+		return
+	}
+	go func() {
+		fetcher := balanceFetcher()
+		balances, err := fetcher.Balances(walletID)
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("error fetching balances: %v", err), s.window)
+			return
+		}
+		if err := portfolioHistory().Append(history.FromBalances(fetcher.Network.Name, walletID, "app", balances)); err != nil {
+			dialog.ShowError(err, s.window)
+			return
+		}
+		// Reloads this screen and updates the others
+		GetGlobalState().UpdateWalletBalances(walletID, fetcher.Network.Name, balances)
+	}()
+}
+
+// exportCSV saves the snapshots shown now as CSV.
+func (s *HistoryScreen) exportCSV() {
+	s.mu.Lock()
+	snapshots := s.snapshots
+	s.mu.Unlock()
+	if len(snapshots) == 0 {
+		dialog.ShowInformation("Nothing to Export", "There are no snapshots in this range.", s.window)
+		return
+	}
+
+	save := dialog.NewFileSave(func(writer fyne.URIWriteCloser, err error) {
+		if err != nil {
+			dialog.ShowError(err, s.window)
+			return
+		}
+		if writer == nil {
+			return
+		}
+		defer writer.Close()
+		if err := history.WriteCSV(writer, snapshots); err != nil {
+			dialog.ShowError(fmt.Errorf("error writing CSV: %v", err), s.window)
+			return
+		}
+		dialog.ShowInformation("Exported", fmt.Sprintf("Saved %d snapshots to %s", len(snapshots), writer.URI().Name()), s.window)
+	}, s.window)
+	save.SetFileName(fmt.Sprintf("portfolio-%s-%s.csv", snapshots[0].Wallet, s.rangeSelect.Selected))
+	save.Show()
+}
+
+func formatSnapshotTime(t time.Time) string {
+	return t.Local().Format("Jan 2 15:04")
+}
+
+// formatSignedUSD formats v as dollars with an explicit sign.
+func formatSignedUSD(v float64) string {
+	sign := "+"
+	if v < 0 {
+		sign = "-"
+	}
+	return fmt.Sprintf("%s$%.2f", sign, math.Abs(v))
+}
@@ -0,0 +1,336 @@
+//go:build !js && !wasm
+// +build !js,!wasm
+
+package ui
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/gagliardetto/solana-go"
+
+	"unruggable-go/internal/events"
+	"unruggable-go/internal/fee"
+	"unruggable-go/internal/localapi"
+	"unruggable-go/internal/portfolio"
+	"unruggable-go/internal/session"
+	"unruggable-go/internal/simulate"
+)
+
+// Preference keys for the local API. It is off until the user enables it.
+const (
+	apiEnabledKey = "apiEnabled"
+	apiPortKey    = "apiPort"
+	apiTokenKey   = "apiToken"
+)
+
+// apiLogFile is the call log in the storage root.
+const apiLogFile = "api.log"
+
+// apiRecentCalls is how many calls the Local API screen lists.
+const apiRecentCalls = 50
+
+var (
+	apiMu     sync.Mutex
+	apiServer *localapi.Server
+	apiCalls  []localapi.Entry // newest last
+	apiCalled events.Topic[localapi.Entry]
+)
+
+// InitLocalAPI starts the local API if the user enabled it in a previous run.
+// Signature requests are shown on window.
+func InitLocalAPI(app fyne.App, window fyne.Window) {
+	if !app.Preferences().Bool(apiEnabledKey) {
+		return
+	}
+	if err := startLocalAPI(app, window); err != nil {
+		log.Printf("Failed to start local API: %v", err)
+	}
+}
+
+// apiToken returns the saved bearer token, creating one on first use.
+func apiToken(app fyne.App) (string, error) {
+	if token := app.Preferences().String(apiTokenKey); token != "" {
+		return token, nil
+	}
+	return regenerateAPIToken(app)
+}
+
+// regenerateAPIToken replaces the bearer token. A running server keeps the
+// old one until it is restarted.
+func regenerateAPIToken(app fyne.App) (string, error) {
+	token, err := localapi.NewToken()
+	if err != nil {
+		return "", err
+	}
+	app.Preferences().SetString(apiTokenKey, token)
+	return token, nil
+}
+
+func apiPort(app fyne.App) int {
+	return app.Preferences().IntWithFallback(apiPortKey, localapi.DefaultPort)
+}
+
+func startLocalAPI(app fyne.App, window fyne.Window) error {
+	token, err := apiToken(app)
+	if err != nil {
+		return err
+	}
+	logPath := filepath.Join(app.Storage().RootURI().Path(), apiLogFile)
+
+	server := &localapi.Server{
+		Token:   token,
+		Wallet:  GetGlobalState().GetSelectedWallet,
+		Network: activeNetwork,
+		Client:  newRPCClient,
+		Fee:     priorityFeeStrategy,
+		Balances: func(ctx context.Context, address string) (*portfolio.Balances, error) {
+			return getWalletBalances(address)
+		},
+		WatchOnly: func(wallet string) bool {
+			return isWatchOnlyWallet(app, wallet)
+		},
+		Approve: func(ctx context.Context, req localapi.SignRequest) (*session.Signer, error) {
+			return approveSignRequest(ctx, app, window, req)
+		},
+		Log: func(entry localapi.Entry) {
+			if err := localapi.AppendLog(logPath, entry); err != nil {
+				log.Printf("Failed to write API log: %v", err)
+			}
+			apiMu.Lock()
+			apiCalls = append(apiCalls, entry)
+			if len(apiCalls) > apiRecentCalls {
+				apiCalls = apiCalls[len(apiCalls)-apiRecentCalls:]
+			}
+			apiMu.Unlock()
+			apiCalled.Publish(entry)
+		},
+	}
+	if err := server.Start(apiPort(app)); err != nil {
+		return err
+	}
+
+	apiMu.Lock()
+	previous := apiServer
+	apiServer = server
+	apiMu.Unlock()
+	if previous != nil {
+		previous.Close()
+	}
+	return nil
+}
+
+func stopLocalAPI() {
+	apiMu.Lock()
+	server := apiServer
+	apiServer = nil
+	apiMu.Unlock()
+	if server != nil {
+		server.Close()
+	}
+}
+
+func localAPIRunning() bool {
+	apiMu.Lock()
+	defer apiMu.Unlock()
+	return apiServer != nil
+}
+
+// approveSignRequest simulates req, shows it to the user with the outcome
+// and, once they approve, asks for the wallet password if the session is
+// locked. A transaction that would fail needs an explicit override.
+// Cancelling the prompt or a wrong password rejects the request.
+func approveSignRequest(ctx context.Context, app fyne.App, window fyne.Window, req localapi.SignRequest) (*session.Signer, error) {
+	type approval struct {
+		signer *session.Signer
+		err    error
+	}
+	// Hiding the dialog on timeout reports a rejection too, hence room for two.
+	result := make(chan approval, 2)
+
+	owner, err := solana.PublicKeyFromBase58(req.Wallet)
+	if err != nil {
+		return nil, fmt.Errorf("invalid wallet address: %v", err)
+	}
+	preview, simErr := simulate.Run(ctx, newRPCClient(), req.Transaction, owner)
+
+	confirm := showPreview(window, "Approve API Signature", formatSignRequest(req), preview, simErr, "Sign", "Reject", func(ok bool) {
+		if !ok {
+			result <- approval{err: localapi.ErrRejected}
+			return
+		}
+		requestSigner(app, window, req.Wallet, func(signer *session.Signer) {
+			result <- approval{signer: signer}
+		}, func(err error) {
+			result <- approval{err: fmt.Errorf("%w: %v", localapi.ErrRejected, err)}
+		})
+	})
+
+	select {
+	case a := <-result:
+		return a.signer, a.err
+	case <-ctx.Done():
+		confirm.Hide()
+		return nil, fmt.Errorf("no approval before the request ended: %v", ctx.Err())
+	}
+}
+
+func formatSignRequest(req localapi.SignRequest) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "A local application asks to sign a transaction with %s.\n\n", req.Wallet)
+	fmt.Fprintf(&b, "Fee payer: %s\n", req.Summary.FeePayer)
+	fmt.Fprintf(&b, "Network: %s\n", activeNetwork().Name)
+	fmt.Fprintf(&b, "%s\n\n", fee.FromTransaction(req.Transaction))
+
+	fmt.Fprintf(&b, "Instructions (%d):\n", len(req.Summary.Instructions))
+	for i, inst := range req.Summary.Instructions {
+		fmt.Fprintf(&b, "%d. Program %s, %d accounts\n", i+1, inst.Program, len(inst.Accounts))
+	}
+
+	b.WriteString("\nWritable accounts:\n")
+	for _, acc := range req.Summary.Accounts {
+		if acc.Writable {
+			fmt.Fprintf(&b, "  %s\n", acc.Address)
+		}
+	}
+	return b.String()
+}
+
+// NewLocalAPIScreen lets the user turn the local API on and off, shows the
+// bearer token scripts need and lists the most recent calls.
+func NewLocalAPIScreen(window fyne.Window, app fyne.App) fyne.CanvasObject {
+	statusLabel := widget.NewLabel("")
+	portEntry := widget.NewEntry()
+	portEntry.SetText(strconv.Itoa(apiPort(app)))
+
+	tokenEntry := widget.NewEntry()
+	token, err := apiToken(app)
+	if err != nil {
+		statusLabel.SetText(err.Error())
+	}
+	tokenEntry.SetText(token)
+	tokenEntry.Disable()
+
+	updateStatus := func() {
+		if localAPIRunning() {
+			statusLabel.SetText(fmt.Sprintf("Listening on http://127.0.0.1:%d/", apiPort(app)))
+		} else {
+			statusLabel.SetText("Stopped")
+		}
+	}
+
+	enableCheck := widget.NewCheck("Enable local API", nil)
+	enableCheck.SetChecked(localAPIRunning())
+	enableCheck.OnChanged = func(enabled bool) {
+		app.Preferences().SetBool(apiEnabledKey, enabled)
+		if !enabled {
+			stopLocalAPI()
+			updateStatus()
+			return
+		}
+		if err := startLocalAPI(app, window); err != nil {
+			dialog.ShowError(err, window)
+		}
+		updateStatus()
+	}
+
+	savePortButton := widget.NewButton("Save Port", func() {
+		port, err := strconv.Atoi(strings.TrimSpace(portEntry.Text))
+		if err != nil || port <= 0 || port > 65535 {
+			dialog.ShowError(fmt.Errorf("invalid port %q", portEntry.Text), window)
+			return
+		}
+		app.Preferences().SetInt(apiPortKey, port)
+		if localAPIRunning() {
+			stopLocalAPI()
+			if err := startLocalAPI(app, window); err != nil {
+				dialog.ShowError(err, window)
+			}
+		}
+		updateStatus()
+	})
+
+	copyButton := widget.NewButton("Copy", func() {
+		window.Clipboard().SetContent(tokenEntry.Text)
+	})
+	regenerateButton := widget.NewButton("Regenerate", func() {
+		dialog.ShowConfirm("Regenerate Token", "Scripts using the current token will be rejected. Continue?", func(ok bool) {
+			if !ok {
+				return
+			}
+			token, err := regenerateAPIToken(app)
+			if err != nil {
+				dialog.ShowError(err, window)
+				return
+			}
+			tokenEntry.SetText(token)
+			if localAPIRunning() {
+				stopLocalAPI()
+				if err := startLocalAPI(app, window); err != nil {
+					dialog.ShowError(err, window)
+				}
+			}
+			updateStatus()
+		}, window)
+	})
+
+	callsList := widget.NewList(
+		func() int {
+			apiMu.Lock()
+			defer apiMu.Unlock()
+			return len(apiCalls)
+		},
+		func() fyne.CanvasObject {
+			return widget.NewLabel("")
+		},
+		func(id widget.ListItemID, item fyne.CanvasObject) {
+			apiMu.Lock()
+			if id >= len(apiCalls) {
+				apiMu.Unlock()
+				return
+			}
+			entry := apiCalls[len(apiCalls)-1-id] // newest first
+			apiMu.Unlock()
+
+			text := fmt.Sprintf("%s  %s  %s", entry.Time.Format("15:04:05"), entry.Method, entry.Status)
+			if entry.Error != "" {
+				text += ": " + entry.Error
+			}
+			item.(*widget.Label).SetText(text)
+		},
+	)
+	apiCalled.Subscribe(func(localapi.Entry) {
+		callsList.Refresh()
+	})
+
+	updateStatus()
+
+	form := widget.NewForm(
+		widget.NewFormItem("Port", container.NewBorder(nil, nil, nil, savePortButton, portEntry)),
+		widget.NewFormItem("Token", container.NewBorder(nil, nil, nil, container.NewHBox(copyButton, regenerateButton), tokenEntry)),
+	)
+
+	help := widget.NewLabel("Scripts send JSON-RPC 2.0 requests with the header \"Authorization: Bearer <token>\". " +
+		"Methods: getWallet, getWalletBalances, createTransferTransaction, decodeTransaction, signTransaction. " +
+		"Every signature must be approved here. Calls are logged to " + filepath.Join(app.Storage().RootURI().Path(), apiLogFile) + ".")
+	help.Wrapping = fyne.TextWrapWord
+
+	top := container.NewVBox(
+		widget.NewLabelWithStyle("Local API", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		enableCheck,
+		statusLabel,
+		form,
+		help,
+		widget.NewLabelWithStyle("Recent calls", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+	)
+	return container.NewBorder(top, nil, nil, nil, callsList)
+}
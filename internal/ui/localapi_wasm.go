@@ -0,0 +1,17 @@
+//go:build js || wasm
+// +build js wasm
+
+package ui
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/widget"
+)
+
+// InitLocalAPI does nothing in the Web build.
+func InitLocalAPI(app fyne.App, window fyne.Window) {}
+
+// NewLocalAPIScreen is not available in the Web build.
+func NewLocalAPIScreen(window fyne.Window, app fyne.App) fyne.CanvasObject {
+	return widget.NewLabel("The local API is not supported in the web version.")
+}
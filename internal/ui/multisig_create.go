@@ -3,12 +3,13 @@ package ui
 import (
 	"context"
 	"errors"
+	"fmt"
 	"strconv"
 	"strings"
-
-	"unruggable-go/internal/storage"
+	"time"
 
 	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
 	"github.com/hogyzen12/squads-go/pkg/multisig"
 
 	"fyne.io/fyne/v2"
@@ -16,6 +17,11 @@ import (
 	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
+
+	"unruggable-go/internal/fee"
+	"unruggable-go/internal/session"
+	"unruggable-go/internal/squads"
+	"unruggable-go/internal/transfer"
 )
 
 type memberRow struct {
@@ -23,6 +29,12 @@ type memberRow struct {
 	permSelect *widget.Select
 }
 
+// savedMember is a member row as persisted between runs.
+type savedMember struct {
+	Keys       string `json:"keys"`
+	Permission string `json:"permission"`
+}
+
 const (
 	permFull    = "Full (7)"
 	permVote    = "Vote (2)"
@@ -46,12 +58,53 @@ func permMask(label string) uint8 {
 	}
 }
 
+// multisigCreateTimeout bounds sending and finalizing a multisig creation.
+const multisigCreateTimeout = 90 * time.Second
+
+// prepareMultisig builds the creation of a multisig paid by payer, with the
+// compute budget of the chosen priority fee strategy.
+func prepareMultisig(ctx context.Context, client *rpc.Client, payer solana.PublicKey, members []multisig.Member, threshold uint16) (*squads.Create, []solana.Instruction, fee.Budget, error) {
+	create, err := squads.NewCreate(client, payer, members, threshold)
+	if err != nil {
+		return nil, nil, fee.Budget{}, err
+	}
+	instructions, budget, err := fee.Prepare(ctx, client, priorityFeeStrategy(), payer, []solana.Instruction{create.Instruction})
+	if err != nil {
+		return nil, nil, fee.Budget{}, err
+	}
+	return create, instructions, budget, nil
+}
+
+// sendMultisig signs instructions creating create with signer, sends them
+// and waits until the transaction is finalized.
+func sendMultisig(ctx context.Context, client *rpc.Client, signer *session.Signer, create *squads.Create, instructions []solana.Instruction) (solana.Signature, error) {
+	tx, err := transfer.NewTransaction(ctx, client, signer.PublicKey(), instructions)
+	if err != nil {
+		return solana.Signature{}, err
+	}
+	err = signer.WithPrivateKey(func(key solana.PrivateKey) error {
+		return create.Sign(tx, key)
+	})
+	if err != nil {
+		return solana.Signature{}, err
+	}
+	sig, err := transfer.Submit(ctx, client, "", tx, signer)
+	if err != nil {
+		return solana.Signature{}, err
+	}
+	if _, err := transfer.WaitConfirmed(ctx, client, sig); err != nil {
+		return sig, err
+	}
+	return sig, nil
+}
+
 func NewMultisigCreateScreen(win fyne.Window) fyne.CanvasObject {
 	//----------------------------------------------------------------
 	// RPC + admin
 	//----------------------------------------------------------------
 	rpcEntry := widget.NewEntry()
-	rpcEntry.SetText(GetGlobalState().RPCURL)
+	rpcEntry.SetText(GetGlobalState().GetRPCURL())
+	followNetwork(rpcEntry)
 
 	adminEntry := widget.NewEntry() // auto-filled after wallet unlock
 	adminEntry.Disable()
@@ -64,19 +117,38 @@ func NewMultisigCreateScreen(win fyne.Window) fyne.CanvasObject {
 	rowsBox := container.NewVBox()
 	var rows []*memberRow
 
-	addRow := func() {
+	// members entered so far are kept across restarts
+	prefs := fyne.CurrentApp().Preferences()
+	saveMembers := func() {
+		saved := make([]savedMember, len(rows))
+		for i, r := range rows {
+			saved[i] = savedMember{Keys: r.keyEntry.Text, Permission: r.permSelect.Selected}
+		}
+		saveJSONPref(prefs, multisigMembersKey, saved)
+	}
+
+	addRow := func(keys, perm string) {
 		e := widget.NewEntry()
+		e.SetText(keys)
+		e.OnChanged = func(string) { saveMembers() }
 		s := widget.NewSelect(
 			[]string{permFull, permVote, permPropose, permExecute, permCustom},
 			nil,
 		)
-		s.SetSelected(permFull)
+		s.SetSelected(perm)
 		row := &memberRow{keyEntry: e, permSelect: s}
 		rows = append(rows, row)
 		rowsBox.Add(container.NewGridWithColumns(2, e, s))
 	}
 
-	addRow() // initial row
+	var saved []savedMember
+	loadJSONPref(prefs, multisigMembersKey, &saved)
+	for _, m := range saved {
+		addRow(m.Keys, m.Permission)
+	}
+	if len(rows) == 0 {
+		addRow("", permFull) // initial row
+	}
 
 	//----------------------------------------------------------------
 	// threshold slider
@@ -113,9 +185,9 @@ func NewMultisigCreateScreen(win fyne.Window) fyne.CanvasObject {
 	createBtn.Disable() // enabled after wallet unlock
 
 	//----------------------------------------------------------------
-	// keep admin private key around after unlock
+	// keep the admin signer around after unlock
 	//----------------------------------------------------------------
-	var adminPriv solana.PrivateKey
+	var adminSigner *session.Signer
 
 	unlockBtn := widget.NewButtonWithIcon("Use selected wallet", theme.LoginIcon(), func() {
 		wid := GetGlobalState().GetSelectedWallet()
@@ -124,32 +196,12 @@ func NewMultisigCreateScreen(win fyne.Window) fyne.CanvasObject {
 			return
 		}
 
-		pass := widget.NewPasswordEntry()
-		dialog.ShowCustomConfirm("Unlock wallet", "Unlock", "Cancel", pass, func(ok bool) {
-			if !ok {
-				return
-			}
-			store := storage.NewWalletStorage(fyne.CurrentApp())
-			wmap, err := store.LoadWallets()
-			if err != nil {
-				dialog.ShowError(err, win)
-				return
-			}
-			enc, ok := wmap[wid]
-			if !ok {
-				dialog.ShowError(errors.New("wallet not found"), win)
-				return
-			}
-			dec, err := decrypt(enc, pass.Text)
-			if err != nil {
-				dialog.ShowError(err, win)
-				return
-			}
-			adminPriv = solana.MustPrivateKeyFromBase58(string(dec))
-			adminEntry.SetText(adminPriv.PublicKey().String())
+		requestSigner(fyne.CurrentApp(), win, wid, func(signer *session.Signer) {
+			adminSigner = signer
+			adminEntry.SetText(adminSigner.PublicKey().String())
 			adminEntry.Refresh()
 			createBtn.Enable()
-		}, win)
+		}, nil)
 	})
 
 	//----------------------------------------------------------------
@@ -157,21 +209,23 @@ func NewMultisigCreateScreen(win fyne.Window) fyne.CanvasObject {
 	//----------------------------------------------------------------
 	for _, r := range rows {
 		r := r
-		r.permSelect.OnChanged = func(string) { updateSlider() }
+		r.permSelect.OnChanged = func(string) { updateSlider(); saveMembers() }
 	}
+	updateSlider()
 
 	addMemberBtn := widget.NewButtonWithIcon("", theme.ContentAddIcon(), func() {
-		addRow()
+		addRow("", permFull)
 		// hook new row’s select
-		rows[len(rows)-1].permSelect.OnChanged = func(string) { updateSlider() }
+		rows[len(rows)-1].permSelect.OnChanged = func(string) { updateSlider(); saveMembers() }
 		updateSlider()
+		saveMembers()
 	})
 
 	//----------------------------------------------------------------
 	// create multisig handler
 	//----------------------------------------------------------------
 	createBtn.OnTapped = func() {
-		if adminPriv == nil {
+		if adminSigner == nil || !adminSigner.Unlocked() {
 			dialog.ShowError(errors.New("wallet not unlocked"), win)
 			return
 		}
@@ -201,28 +255,44 @@ func NewMultisigCreateScreen(win fyne.Window) fyne.CanvasObject {
 		}
 
 		createBtn.Disable()
-		status.SetText("Submitting…")
-
-		go func() {
-			sig, addr, _, err := multisig.CreateMultisigWithParams(context.Background(),
-				multisig.CreateParams{
-					RPCURL:    rpcEntry.Text,
-					WSURL:     strings.Replace(rpcEntry.Text, "https://", "wss://", 1),
-					Payer:     adminPriv,
-					Members:   members,
-					Threshold: uint16(thresholdSlider.Value),
-				})
+		status.SetText("Simulating…")
+		client := rpc.New(strings.TrimSpace(rpcEntry.Text))
+		threshold := uint16(thresholdSlider.Value)
 
+		send := func(create *squads.Create, instructions []solana.Instruction) {
+			createBtn.Disable()
+			status.SetText("Submitting…")
+			ctx, cancel := context.WithTimeout(context.Background(), multisigCreateTimeout)
+			defer cancel()
+			sig, err := sendMultisig(ctx, client, adminSigner, create, instructions)
 			if err != nil {
 				dialog.ShowError(err, win)
 				status.SetText("Error: " + err.Error())
 			} else {
 				dialog.ShowInformation("Multisig created",
-					"PDA:\n"+addr.String()+"\n\nTx:\n"+sig.String(), win)
-				status.SetText("Multisig: " + addr.String())
+					"PDA:\n"+create.Multisig.String()+"\n\nTx:\n"+sig.String(), win)
+				status.SetText("Multisig: " + create.Multisig.String())
 			}
 			createBtn.Enable()
 			win.Canvas().Refresh(status)
+		}
+
+		go func() {
+			defer createBtn.Enable()
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+			create, instructions, budget, err := prepareMultisig(ctx, client, adminSigner.PublicKey(), members, threshold)
+			if err != nil {
+				dialog.ShowError(err, win)
+				status.SetText("Error: " + err.Error())
+				return
+			}
+			text := fmt.Sprintf("Create multisig %s with %d members and a threshold of %d?\n\n%s",
+				create.Multisig, len(members), threshold, budget)
+			confirmWithPreview(ctx, client, win, "Confirm Multisig", text, adminSigner.PublicKey(), instructions, func() {
+				go send(create, instructions)
+			})
+			status.SetText("")
 		}()
 	}
 
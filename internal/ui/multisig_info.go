@@ -20,10 +20,15 @@ func NewMultisigInfoScreen(win fyne.Window) fyne.CanvasObject {
 	// inputs
 	// ----------------------------------------------------------------
 	rpcEntry := widget.NewEntry()
-	rpcEntry.SetText(GetGlobalState().RPCURL)
+	rpcEntry.SetText(GetGlobalState().GetRPCURL())
 
 	addrEntry := widget.NewEntry()
 	addrEntry.SetPlaceHolder("Multisig address (Base58)")
+	prefs := fyne.CurrentApp().Preferences()
+	addrEntry.SetText(prefs.String(multisigAddressKey))
+	addrEntry.OnChanged = func(text string) {
+		prefs.SetString(multisigAddressKey, strings.TrimSpace(text))
+	}
 
 	// ----------------------------------------------------------------
 	// output + action
@@ -84,5 +89,7 @@ func NewMultisigInfoScreen(win fyne.Window) fyne.CanvasObject {
 		widget.NewFormItem("Multisig address", addrEntry),
 	)
 
+	followNetwork(rpcEntry)
+
 	return container.NewVBox(form, fetchBtn, output)
 }
@@ -0,0 +1,443 @@
+package ui
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"unruggable-go/internal/fee"
+	"unruggable-go/internal/network"
+)
+
+// Preferences keys for network profiles and the priority fee strategy.
+const (
+	networkProfilesKey = network.ProfilesPreferenceKey
+	activeNetworkKey   = network.ActivePreferenceKey
+	priorityFeeKey     = fee.PreferenceKey
+)
+
+// loadNetworkProfiles returns the built-in profiles followed by the user's own.
+func loadNetworkProfiles(app fyne.App) []network.Profile {
+	profiles := network.Builtin()
+
+	stored := app.Preferences().String(networkProfilesKey)
+	if stored == "" {
+		return profiles
+	}
+	var custom []network.Profile
+	if err := json.Unmarshal([]byte(stored), &custom); err != nil {
+		log.Printf("Failed to load network profiles: %v", err)
+		return profiles
+	}
+	return append(profiles, custom...)
+}
+
+// saveCustomProfiles stores the user-defined profiles in Preferences.
+func saveCustomProfiles(app fyne.App, profiles []network.Profile) error {
+	var custom []network.Profile
+	for _, p := range profiles {
+		if !p.Builtin {
+			custom = append(custom, p)
+		}
+	}
+	data, err := json.Marshal(custom)
+	if err != nil {
+		return err
+	}
+	app.Preferences().SetString(networkProfilesKey, string(data))
+	return nil
+}
+
+func findNetworkProfile(profiles []network.Profile, name string) (network.Profile, bool) {
+	for _, p := range profiles {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return network.Profile{}, false
+}
+
+// InitNetwork activates the profile saved in Preferences.
+func InitNetwork(app fyne.App) {
+	name := app.Preferences().StringWithFallback(activeNetworkKey, network.Mainnet)
+	profile, ok := findNetworkProfile(loadNetworkProfiles(app), name)
+	if !ok {
+		profile = network.Default()
+	}
+	GetGlobalState().SetNetwork(profile)
+}
+
+// setActiveNetwork switches every screen opened from now on to profile.
+func setActiveNetwork(app fyne.App, profile network.Profile) {
+	app.Preferences().SetString(activeNetworkKey, profile.Name)
+	GetGlobalState().SetNetwork(profile)
+}
+
+// activeNetwork returns the profile currently in use.
+func activeNetwork() network.Profile {
+	return GetGlobalState().GetNetwork()
+}
+
+// priorityFeeStrategy returns the priority fee strategy chosen on the
+// network screen.
+func priorityFeeStrategy() fee.Strategy {
+	app := fyne.CurrentApp()
+	if app == nil {
+		return fee.Default
+	}
+	strategy, err := fee.ParseStrategy(app.Preferences().String(priorityFeeKey))
+	if err != nil {
+		return fee.Default
+	}
+	return strategy
+}
+
+// priorityFeeCard lets the user choose how transactions are prioritized.
+func priorityFeeCard(app fyne.App) fyne.CanvasObject {
+	levels := make([]string, len(fee.Levels))
+	for i, level := range fee.Levels {
+		levels[i] = string(level)
+	}
+	current := priorityFeeStrategy()
+	status := widget.NewLabel("")
+
+	percentileEntry := widget.NewEntry()
+	percentileEntry.SetPlaceHolder("Percentile, 0-100")
+	if current.Level == fee.Custom {
+		percentileEntry.SetText(strconv.Itoa(current.Percentile))
+	}
+	levelSelect := widget.NewSelect(levels, nil)
+
+	save := func() {
+		text := levelSelect.Selected
+		if fee.Level(text) == fee.Custom {
+			text = percentileEntry.Text
+		}
+		strategy, err := fee.ParseStrategy(text)
+		if err != nil {
+			status.SetText(err.Error())
+			return
+		}
+		app.Preferences().SetString(priorityFeeKey, strategy.String())
+		status.SetText(fmt.Sprintf("Transactions pay the %s price of recent fees.", strategy))
+	}
+	levelSelect.OnChanged = func(level string) {
+		if fee.Level(level) == fee.Custom {
+			percentileEntry.Show()
+		} else {
+			percentileEntry.Hide()
+		}
+		save()
+	}
+	percentileEntry.OnChanged = func(string) { save() }
+	levelSelect.SetSelected(string(current.Level))
+
+	note := widget.NewLabel(fmt.Sprintf("Compute units are sized by simulating each transaction. Their price is a percentile of the fees recently paid for the accounts the transaction writes to: low is the 25th, medium the 50th and high the 75th, capped at %d µlamports per unit.", fee.MaxMicroLamports))
+	note.Wrapping = fyne.TextWrapWord
+	return widget.NewCard("Priority Fee", "Applies to every network", container.NewVBox(
+		note,
+		container.NewGridWithColumns(2, levelSelect, percentileEntry),
+		status,
+	))
+}
+
+// followNetwork keeps entry on the active RPC URL across network switches,
+// unless the user typed another endpoint into it.
+func followNetwork(entry *widget.Entry) {
+	GetGlobalState().Events.NetworkChanged.Subscribe(func(e NetworkChangedEvent) {
+		if entry.Text == e.Previous.RPCURL {
+			entry.SetText(e.Network.RPCURL)
+		}
+	})
+}
+
+// rpcDiagnosticsRows renders one row of metrics per endpoint of the active pool.
+func rpcDiagnosticsRows() []fyne.CanvasObject {
+	pool := activeRPCPool()
+	if pool == nil {
+		return []fyne.CanvasObject{widget.NewLabel("No RPC endpoints available")}
+	}
+
+	grid := container.NewGridWithColumns(6,
+		widget.NewLabelWithStyle("Endpoint", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		widget.NewLabelWithStyle("Status", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		widget.NewLabelWithStyle("Latency", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		widget.NewLabelWithStyle("Slot lag", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		widget.NewLabelWithStyle("Requests", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		widget.NewLabelWithStyle("Failed / 429", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+	)
+	rows := []fyne.CanvasObject{grid}
+	for _, m := range pool.Metrics() {
+		status := "Healthy"
+		switch {
+		case time.Now().Before(m.BackoffUntil):
+			status = fmt.Sprintf("Backing off %s", time.Until(m.BackoffUntil).Round(time.Second))
+		case !m.Healthy:
+			status = "Unhealthy"
+		}
+		grid.Add(widget.NewLabel(m.URL))
+		grid.Add(widget.NewLabel(status))
+		grid.Add(widget.NewLabel(m.Latency.Round(time.Millisecond).String()))
+		grid.Add(widget.NewLabel(fmt.Sprintf("%d", m.SlotLag)))
+		grid.Add(widget.NewLabel(fmt.Sprintf("%d", m.Requests)))
+		grid.Add(widget.NewLabel(fmt.Sprintf("%d / %d", m.Failures, m.RateLimited)))
+		if m.LastError != "" {
+			errLabel := widget.NewLabel(fmt.Sprintf("%s: %s", m.URL, m.LastError))
+			errLabel.Wrapping = fyne.TextWrapWord
+			rows = append(rows, errLabel)
+		}
+	}
+	return rows
+}
+
+// splitLines returns the non-empty trimmed lines of text.
+func splitLines(text string) []string {
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// NewNetworkScreen lets the user pick, add, edit and delete network profiles.
+func NewNetworkScreen(window fyne.Window, app fyne.App) fyne.CanvasObject {
+	profiles := loadNetworkProfiles(app)
+
+	nameEntry := widget.NewEntry()
+	clusterSelect := widget.NewSelect([]string{"mainnet-beta", "devnet", "testnet", "custom"}, nil)
+	rpcEntry := widget.NewEntry()
+	extraRPCEntry := widget.NewMultiLineEntry()
+	extraRPCEntry.SetPlaceHolder("Optional failover endpoints, one per line")
+	extraRPCEntry.SetMinRowsVisible(2)
+	wsEntry := widget.NewEntry()
+	wsEntry.SetPlaceHolder("Derived from the RPC URL when empty")
+	jitoEntry := widget.NewEntry()
+	jitoEntry.SetPlaceHolder("Leave empty to send without Jito bundles")
+	quoteEntry := widget.NewEntry()
+	swapEntry := widget.NewEntry()
+	priceEntry := widget.NewEntry()
+	tokensEntry := widget.NewEntry()
+	pythEntry := widget.NewEntry()
+	dasEntry := widget.NewEntry()
+	dasEntry.SetPlaceHolder("Asset (DAS) API for collectibles; the RPC URL is used when empty")
+
+	fields := []*widget.Entry{nameEntry, rpcEntry, extraRPCEntry, wsEntry, jitoEntry, quoteEntry, swapEntry, priceEntry, tokensEntry, pythEntry, dasEntry}
+
+	form := widget.NewForm(
+		widget.NewFormItem("Name", nameEntry),
+		widget.NewFormItem("Cluster", clusterSelect),
+		widget.NewFormItem("RPC URL", rpcEntry),
+		widget.NewFormItem("Extra RPC URLs", extraRPCEntry),
+		widget.NewFormItem("WebSocket URL", wsEntry),
+		widget.NewFormItem("Jito bundles", jitoEntry),
+		widget.NewFormItem("Jupiter quote", quoteEntry),
+		widget.NewFormItem("Jupiter swap", swapEntry),
+		widget.NewFormItem("Jupiter price", priceEntry),
+		widget.NewFormItem("Jupiter tokens", tokensEntry),
+		widget.NewFormItem("Pyth", pythEntry),
+		widget.NewFormItem("DAS", dasEntry),
+	)
+
+	status := widget.NewLabel("")
+	status.Wrapping = fyne.TextWrapWord
+
+	diagnostics := container.NewVBox()
+	refreshDiagnostics := func() {
+		diagnostics.Objects = rpcDiagnosticsRows()
+		diagnostics.Refresh()
+	}
+	var checkButton *widget.Button
+	checkButton = widget.NewButton("Check Now", func() {
+		pool := activeRPCPool()
+		if pool == nil {
+			return
+		}
+		checkButton.Disable()
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+			pool.CheckHealth(ctx)
+			refreshDiagnostics()
+			checkButton.Enable()
+		}()
+	})
+	refreshDiagnostics()
+
+	var saveButton, deleteButton, useButton *widget.Button
+	var profileSelect *widget.Select
+
+	showProfile := func(p network.Profile) {
+		nameEntry.SetText(p.Name)
+		clusterSelect.SetSelected(p.Cluster)
+		rpcEntry.SetText(p.RPCURL)
+		extraRPCEntry.SetText(strings.Join(p.ExtraRPCURLs, "\n"))
+		wsEntry.SetText(p.WSURL)
+		jitoEntry.SetText(p.JitoURL)
+		quoteEntry.SetText(p.JupiterQuoteURL)
+		swapEntry.SetText(p.JupiterSwapURL)
+		priceEntry.SetText(p.JupiterPriceURL)
+		tokensEntry.SetText(p.JupiterTokensURL)
+		pythEntry.SetText(p.PythURL)
+		dasEntry.SetText(p.DASURL)
+
+		for _, entry := range fields {
+			if p.Builtin {
+				entry.Disable()
+			} else {
+				entry.Enable()
+			}
+		}
+		if p.Builtin {
+			clusterSelect.Disable()
+			saveButton.Disable()
+			deleteButton.Disable()
+		} else {
+			clusterSelect.Enable()
+			saveButton.Enable()
+			deleteButton.Enable()
+		}
+	}
+
+	profileNames := func() []string {
+		names := make([]string, len(profiles))
+		for i, p := range profiles {
+			names[i] = p.Name
+		}
+		return names
+	}
+
+	profileSelect = widget.NewSelect(profileNames(), func(name string) {
+		if p, ok := findNetworkProfile(profiles, name); ok {
+			showProfile(p)
+		}
+	})
+
+	useButton = widget.NewButton("Use This Network", func() {
+		p, ok := findNetworkProfile(profiles, profileSelect.Selected)
+		if !ok {
+			return
+		}
+		setActiveNetwork(app, p)
+	})
+
+	newButton := widget.NewButton("New Profile", func() {
+		p := activeNetwork()
+		p.Name = ""
+		p.Cluster = "custom"
+		p.Builtin = false
+		profileSelect.ClearSelected()
+		showProfile(p)
+	})
+
+	saveButton = widget.NewButton("Save Profile", func() {
+		p := network.Profile{
+			Name:             strings.TrimSpace(nameEntry.Text),
+			Cluster:          clusterSelect.Selected,
+			RPCURL:           strings.TrimSpace(rpcEntry.Text),
+			ExtraRPCURLs:     splitLines(extraRPCEntry.Text),
+			WSURL:            strings.TrimSpace(wsEntry.Text),
+			JitoURL:          strings.TrimSpace(jitoEntry.Text),
+			JupiterQuoteURL:  strings.TrimSpace(quoteEntry.Text),
+			JupiterSwapURL:   strings.TrimSpace(swapEntry.Text),
+			JupiterPriceURL:  strings.TrimSpace(priceEntry.Text),
+			JupiterTokensURL: strings.TrimSpace(tokensEntry.Text),
+			PythURL:          strings.TrimSpace(pythEntry.Text),
+			DASURL:           strings.TrimSpace(dasEntry.Text),
+		}
+		if err := p.Validate(); err != nil {
+			dialog.ShowError(err, window)
+			return
+		}
+
+		replaced := false
+		for i, existing := range profiles {
+			if existing.Name != p.Name {
+				continue
+			}
+			if existing.Builtin {
+				dialog.ShowError(fmt.Errorf("%q is a built-in profile; choose another name", p.Name), window)
+				return
+			}
+			profiles[i] = p
+			replaced = true
+		}
+		if !replaced {
+			profiles = append(profiles, p)
+		}
+
+		if err := saveCustomProfiles(app, profiles); err != nil {
+			dialog.ShowError(fmt.Errorf("failed to save profile: %v", err), window)
+			return
+		}
+		if activeNetwork().Name == p.Name {
+			setActiveNetwork(app, p)
+		}
+
+		profileSelect.Options = profileNames()
+		profileSelect.SetSelected(p.Name)
+		status.SetText(fmt.Sprintf("Saved profile %s", p.Name))
+	})
+
+	deleteButton = widget.NewButton("Delete Profile", func() {
+		name := profileSelect.Selected
+		p, ok := findNetworkProfile(profiles, name)
+		if !ok || p.Builtin {
+			return
+		}
+
+		dialog.ShowConfirm("Delete Profile", fmt.Sprintf("Delete network profile %s?", name), func(confirmed bool) {
+			if !confirmed {
+				return
+			}
+			var kept []network.Profile
+			for _, existing := range profiles {
+				if existing.Name != name {
+					kept = append(kept, existing)
+				}
+			}
+			profiles = kept
+			if err := saveCustomProfiles(app, profiles); err != nil {
+				dialog.ShowError(fmt.Errorf("failed to delete profile: %v", err), window)
+				return
+			}
+			if activeNetwork().Name == name {
+				setActiveNetwork(app, network.Default())
+			}
+
+			profileSelect.Options = profileNames()
+			profileSelect.SetSelected(activeNetwork().Name)
+			status.SetText(fmt.Sprintf("Deleted profile %s", name))
+		}, window)
+	})
+
+	profileSelect.SetSelected(activeNetwork().Name)
+	status.SetText(fmt.Sprintf("Active network: %s (%s)", activeNetwork().Name, activeNetwork().RPCURL))
+	GetGlobalState().Events.NetworkChanged.Subscribe(func(e NetworkChangedEvent) {
+		status.SetText(fmt.Sprintf("Active network: %s (%s)", e.Network.Name, e.Network.RPCURL))
+		refreshDiagnostics()
+	})
+
+	return container.NewVScroll(container.NewVBox(
+		widget.NewLabel("Network Profiles"),
+		container.NewBorder(nil, nil, nil, useButton, profileSelect),
+		status,
+		widget.NewCard("Endpoints", "", form),
+		container.NewGridWithColumns(3, newButton, saveButton, deleteButton),
+		priorityFeeCard(app),
+		widget.NewCard("RPC Diagnostics", "Health of the active network's endpoints", container.NewVBox(
+			diagnostics,
+			checkButton,
+		)),
+	))
+}
@@ -0,0 +1,555 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"unruggable-go/internal/portfolio"
+	"unruggable-go/internal/storage"
+	"unruggable-go/internal/wallet"
+)
+
+// allWalletsGroup is the group select entry for every visible wallet.
+const allWalletsGroup = "All wallets"
+
+// portfolioCacheTTL is how long fetched balances are reused before the
+// portfolio screen fetches them again.
+const portfolioCacheTTL = 2 * time.Minute
+
+// walletGroup is a named set of wallets whose balances are summed together.
+type walletGroup struct {
+	Name    string   `json:"name"`
+	Wallets []string `json:"wallets"`
+}
+
+// loadWalletGroups returns the saved wallet groups, sorted by name.
+func loadWalletGroups(prefs fyne.Preferences) []walletGroup {
+	var groups []walletGroup
+	loadJSONPref(prefs, walletGroupsKey, &groups)
+	sort.Slice(groups, func(i, j int) bool {
+		return strings.ToLower(groups[i].Name) < strings.ToLower(groups[j].Name)
+	})
+	return groups
+}
+
+type cachedBalances struct {
+	balances *WalletResponse
+	fetched  time.Time
+}
+
+// PortfolioScreen adds up the balances of all wallets, or of a wallet group,
+// and breaks them down by token and by wallet.
+type PortfolioScreen struct {
+	window      fyne.Window
+	app         fyne.App
+	groupSelect *widget.Select
+	status      *widget.Label
+	groups      *fyne.Container
+	tokens      *fyne.Container
+	wallets     *fyne.Container
+
+	mu      sync.Mutex
+	cache   map[string]cachedBalances
+	errors  map[string]error // last fetch failure per wallet
+	loading bool
+	// generation counts network changes. A fetch started before the latest
+	// one belongs to the previous cluster and its results are dropped.
+	generation int
+}
+
+// NewPortfolioScreen creates the aggregate portfolio view.
+func NewPortfolioScreen(window fyne.Window, app fyne.App) fyne.CanvasObject {
+	s := &PortfolioScreen{
+		window:  window,
+		app:     app,
+		status:  widget.NewLabel(""),
+		groups:  container.NewGridWithColumns(3),
+		tokens:  container.NewGridWithColumns(4),
+		wallets: container.NewVBox(),
+		cache:   make(map[string]cachedBalances),
+		errors:  make(map[string]error),
+	}
+	s.groupSelect = widget.NewSelect(nil, func(string) { s.render() })
+	s.updateGroupOptions()
+	s.groupSelect.SetSelected(allWalletsGroup)
+
+	refreshButton := widget.NewButton("Refresh All", func() { s.load(true) })
+	refreshButton.Importance = widget.HighImportance
+	groupsButton := widget.NewButton("Manage Groups", s.manageGroups)
+
+	bus := GetGlobalState().Events
+	bus.BalancesUpdated.Subscribe(func(e BalancesUpdatedEvent) {
+		s.mu.Lock()
+		s.cache[e.WalletID] = cachedBalances{balances: e.Balances, fetched: time.Now()}
+		delete(s.errors, e.WalletID)
+		s.mu.Unlock()
+		s.render()
+	})
+	bus.NetworkChanged.Subscribe(func(NetworkChangedEvent) {
+		// Cached balances belong to the previous cluster
+		s.mu.Lock()
+		s.cache = make(map[string]cachedBalances)
+		s.errors = make(map[string]error)
+		s.generation++
+		s.loading = false
+		s.mu.Unlock()
+		s.load(false)
+	})
+	s.load(false)
+
+	bold := fyne.TextStyle{Bold: true}
+	top := container.NewVBox(
+		widget.NewLabelWithStyle("Portfolio", fyne.TextAlignLeading, bold),
+		container.NewHBox(widget.NewLabel("Show:"), s.groupSelect, groupsButton, refreshButton),
+		s.status,
+	)
+	content := container.NewVBox(
+		widget.NewLabelWithStyle("Groups", fyne.TextAlignLeading, bold),
+		s.groups,
+		widget.NewSeparator(),
+		widget.NewLabelWithStyle("Combined Holdings", fyne.TextAlignLeading, bold),
+		s.tokens,
+		widget.NewSeparator(),
+		widget.NewLabelWithStyle("Wallets", fyne.TextAlignLeading, bold),
+		s.wallets,
+	)
+	return container.NewBorder(top, nil, nil, nil, container.NewVScroll(content))
+}
+
+// visibleWallets returns the stored wallets that are not hidden, oldest first.
+func (s *PortfolioScreen) visibleWallets() ([]wallet.Info, error) {
+	infos, err := wallet.List(storage.NewWalletStorage(s.app))
+	if err != nil {
+		return nil, err
+	}
+	visible := infos[:0]
+	for _, info := range infos {
+		if !info.Hidden {
+			visible = append(visible, info)
+		}
+	}
+	return visible, nil
+}
+
+// load fetches the wallets whose cached balances are missing or older than
+// portfolioCacheTTL, or all of them if force is set.
+func (s *PortfolioScreen) load(force bool) {
+	s.mu.Lock()
+	if s.loading {
+		s.mu.Unlock()
+		return
+	}
+	s.loading = true
+	generation := s.generation
+	s.mu.Unlock()
+
+	go func() {
+		defer func() {
+			s.mu.Lock()
+			if s.generation == generation {
+				s.loading = false
+			}
+			s.mu.Unlock()
+			s.render()
+		}()
+
+		infos, err := s.visibleWallets()
+		if err != nil {
+			s.status.SetText(fmt.Sprintf("Error loading wallets: %v", err))
+			return
+		}
+		var stale []string
+		s.mu.Lock()
+		for _, info := range infos {
+			cached, ok := s.cache[info.Address]
+			if force || !ok || time.Since(cached.fetched) > portfolioCacheTTL {
+				stale = append(stale, info.Address)
+			}
+		}
+		s.mu.Unlock()
+		if len(stale) == 0 {
+			return
+		}
+
+		s.status.SetText(fmt.Sprintf("Fetching %d wallets...", len(stale)))
+		results := balanceFetcher().FetchAll(stale, portfolio.DefaultWorkers)
+		now := time.Now()
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if s.generation != generation {
+			return
+		}
+		for _, result := range results {
+			if result.Err != nil {
+				s.errors[result.Address] = result.Err
+				continue
+			}
+			s.cache[result.Address] = cachedBalances{balances: result.Balances, fetched: now}
+			delete(s.errors, result.Address)
+		}
+	}()
+}
+
+// render redraws the screen from the cache.
+func (s *PortfolioScreen) render() {
+	infos, err := s.visibleWallets()
+	if err != nil {
+		s.status.SetText(fmt.Sprintf("Error loading wallets: %v", err))
+		return
+	}
+	groups := loadWalletGroups(s.app.Preferences())
+
+	s.mu.Lock()
+	results := make(map[string]portfolio.WalletBalances, len(infos))
+	for _, info := range infos {
+		result := portfolio.WalletBalances{Address: info.Address, Err: s.errors[info.Address]}
+		if cached, ok := s.cache[info.Address]; ok {
+			result.Balances = cached.balances
+		}
+		results[info.Address] = result
+	}
+	loading := s.loading
+	s.mu.Unlock()
+
+	// The selected group, or every visible wallet
+	members := make(map[string]bool)
+	selected := s.groupSelect.Selected
+	for _, group := range groups {
+		if group.Name == selected {
+			for _, address := range group.Wallets {
+				members[address] = true
+			}
+		}
+	}
+	var shown []wallet.Info
+	var fetched []portfolio.WalletBalances
+	failed, pending := 0, 0
+	for _, info := range infos {
+		if selected != allWalletsGroup && !members[info.Address] {
+			continue
+		}
+		shown = append(shown, info)
+		result := results[info.Address]
+		switch {
+		case result.Balances != nil:
+			fetched = append(fetched, result)
+		case result.Err != nil:
+			failed++
+		default:
+			pending++
+		}
+	}
+
+	total := 0.0
+	for _, result := range fetched {
+		total += result.Value()
+	}
+	status := fmt.Sprintf("Total: $%.2f across %d wallets", total, len(shown))
+	if failed > 0 {
+		status += fmt.Sprintf(", %d failed", failed)
+	}
+	if pending > 0 || loading {
+		status += " (loading...)"
+	}
+	s.status.SetText(status)
+	if pending > 0 && !loading {
+		// A wallet was added since the last fetch
+		s.load(false)
+	}
+
+	s.renderGroups(groups, infos, results)
+	s.renderTokens(portfolio.Combine(fetched), total)
+	s.renderWallets(shown, results)
+}
+
+// renderGroups sums every group separately. Wallets in several groups count
+// towards each of them.
+func (s *PortfolioScreen) renderGroups(groups []walletGroup, infos []wallet.Info, results map[string]portfolio.WalletBalances) {
+	bold := fyne.TextStyle{Bold: true}
+	objects := []fyne.CanvasObject{
+		widget.NewLabelWithStyle("Group", fyne.TextAlignLeading, bold),
+		widget.NewLabelWithStyle("Wallets", fyne.TextAlignTrailing, bold),
+		widget.NewLabelWithStyle("Value", fyne.TextAlignTrailing, bold),
+	}
+	row := func(name string, addresses []string) {
+		value := 0.0
+		count := 0
+		for _, address := range addresses {
+			if result, ok := results[address]; ok {
+				value += result.Value()
+				count++
+			}
+		}
+		objects = append(objects,
+			widget.NewLabel(name),
+			widget.NewLabelWithStyle(fmt.Sprintf("%d", count), fyne.TextAlignTrailing, fyne.TextStyle{}),
+			widget.NewLabelWithStyle(fmt.Sprintf("$%.2f", value), fyne.TextAlignTrailing, fyne.TextStyle{}),
+		)
+	}
+	all := make([]string, len(infos))
+	for i, info := range infos {
+		all[i] = info.Address
+	}
+	row(allWalletsGroup, all)
+	for _, group := range groups {
+		row(group.Name, group.Wallets)
+	}
+	s.groups.Objects = objects
+	s.groups.Refresh()
+}
+
+func (s *PortfolioScreen) renderTokens(tokens []portfolio.TokenTotal, total float64) {
+	bold := fyne.TextStyle{Bold: true}
+	objects := []fyne.CanvasObject{
+		widget.NewLabelWithStyle("Token", fyne.TextAlignLeading, bold),
+		widget.NewLabelWithStyle("Balance", fyne.TextAlignTrailing, bold),
+		widget.NewLabelWithStyle("Value", fyne.TextAlignTrailing, bold),
+		widget.NewLabelWithStyle("Share", fyne.TextAlignTrailing, bold),
+	}
+	hidden := 0
+	for _, token := range tokens {
+		if !token.Verified && !showUnverifiedTokens() {
+			hidden++
+			continue
+		}
+		name := token.Symbol
+		if !token.Verified {
+			name = fmt.Sprintf("%s (unverified %s)", token.Symbol, shortenAddress(token.Mint))
+		}
+		share := 0.0
+		if total > 0 {
+			share = token.USDBalance / total * 100
+		}
+		objects = append(objects,
+			widget.NewLabel(fmt.Sprintf("%s in %d wallets", name, len(token.Wallets))),
+			widget.NewLabelWithStyle(fmt.Sprintf("%.6f", token.Balance), fyne.TextAlignTrailing, fyne.TextStyle{}),
+			widget.NewLabelWithStyle(fmt.Sprintf("$%.2f", token.USDBalance), fyne.TextAlignTrailing, fyne.TextStyle{}),
+			widget.NewLabelWithStyle(fmt.Sprintf("%.1f%%", share), fyne.TextAlignTrailing, fyne.TextStyle{}),
+		)
+	}
+	if hidden > 0 {
+		objects = append(objects,
+			widget.NewLabel(fmt.Sprintf("%d unverified tokens hidden", hidden)),
+			widget.NewLabel(""), widget.NewLabel(""), widget.NewLabel(""))
+	}
+	s.tokens.Objects = objects
+	s.tokens.Refresh()
+}
+
+// renderWallets lists each wallet with its value, expandable to its holdings.
+func (s *PortfolioScreen) renderWallets(infos []wallet.Info, results map[string]portfolio.WalletBalances) {
+	accordion := widget.NewAccordion()
+	for _, info := range infos {
+		name := info.Label
+		if name == "" {
+			name = shortenAddress(info.Address)
+		}
+		result := results[info.Address]
+		var title string
+		var detail fyne.CanvasObject
+		switch {
+		case result.Balances != nil:
+			title = fmt.Sprintf("%s: $%.2f", name, result.Value())
+			detail = walletHoldings(info.Address, result.Balances)
+		case result.Err != nil:
+			title = fmt.Sprintf("%s: failed", name)
+			detail = widget.NewLabel(fmt.Sprintf("Error fetching balances: %v", result.Err))
+		default:
+			title = fmt.Sprintf("%s: loading...", name)
+			detail = widget.NewLabel("Loading balances...")
+		}
+		accordion.Append(widget.NewAccordionItem(title, detail))
+	}
+	s.wallets.Objects = []fyne.CanvasObject{accordion}
+	s.wallets.Refresh()
+}
+
+// walletHoldings lists the holdings of one wallet and selects it on request.
+func walletHoldings(address string, balances *WalletResponse) fyne.CanvasObject {
+	rows := container.NewVBox(
+		widget.NewLabel(address),
+		widget.NewLabel(fmt.Sprintf("SOL: %.6f ($%.2f)", balances.SolBalance, balances.SolBalanceUSD)),
+	)
+	for _, holding := range balances.Assets {
+		if !holding.Verified && !showUnverifiedTokens() {
+			continue
+		}
+		label := widget.NewLabel(fmt.Sprintf("%s: %.6f ($%.2f)", holding.Symbol, holding.Balance, holding.USDBalance))
+		if holding.Verified {
+			rows.Add(label)
+		} else {
+			rows.Add(unverifiedRow(holding, label))
+		}
+	}
+	rows.Add(widget.NewButton("Select Wallet", func() {
+		GetGlobalState().SetSelectedWallet(address)
+	}))
+	return rows
+}
+
+func (s *PortfolioScreen) updateGroupOptions() {
+	options := []string{allWalletsGroup}
+	for _, group := range loadWalletGroups(s.app.Preferences()) {
+		options = append(options, group.Name)
+	}
+	s.groupSelect.Options = options
+	s.groupSelect.Refresh()
+}
+
+func (s *PortfolioScreen) saveGroups(groups []walletGroup) {
+	saveJSONPref(s.app.Preferences(), walletGroupsKey, groups)
+	s.updateGroupOptions()
+	selected := s.groupSelect.Selected
+	for _, group := range groups {
+		if group.Name == selected {
+			s.render()
+			return
+		}
+	}
+	// The shown group was renamed or deleted
+	s.groupSelect.SetSelected(allWalletsGroup)
+}
+
+// manageGroups lists the wallet groups with buttons to edit or delete them.
+func (s *PortfolioScreen) manageGroups() {
+	list := container.NewVBox()
+	var rebuild func()
+	rebuild = func() {
+		groups := loadWalletGroups(s.app.Preferences())
+		list.Objects = nil
+		if len(groups) == 0 {
+			list.Add(widget.NewLabel("No groups yet."))
+		}
+		for i, group := range groups {
+			i, group := i, group
+			edit := widget.NewButton("Edit", func() {
+				s.editGroup(groups, i, rebuild)
+			})
+			remove := widget.NewButton("Delete", func() {
+				dialog.ShowConfirm("Delete Group", fmt.Sprintf("Delete the group %q? Its wallets are kept.", group.Name), func(ok bool) {
+					if !ok {
+						return
+					}
+					s.saveGroups(append(groups[:i:i], groups[i+1:]...))
+					rebuild()
+				}, s.window)
+			})
+			summary := widget.NewLabel(fmt.Sprintf("%s (%d wallets)", group.Name, len(group.Wallets)))
+			list.Add(container.NewBorder(nil, nil, nil, container.NewHBox(edit, remove), summary))
+		}
+		list.Refresh()
+	}
+	rebuild()
+
+	newButton := widget.NewButton("New Group", func() {
+		s.editGroup(loadWalletGroups(s.app.Preferences()), -1, rebuild)
+	})
+	content := container.NewBorder(nil, newButton, nil, nil, container.NewVScroll(list))
+	d := dialog.NewCustom("Wallet Groups", "Close", content, s.window)
+	d.Resize(fyne.NewSize(450, 400))
+	d.Show()
+}
+
+// editGroup edits groups[index], or adds a group if index is -1, and calls
+// done once it is saved.
+func (s *PortfolioScreen) editGroup(groups []walletGroup, index int, done func()) {
+	infos, err := wallet.List(storage.NewWalletStorage(s.app))
+	if err != nil {
+		dialog.ShowError(err, s.window)
+		return
+	}
+	var group walletGroup
+	if index >= 0 {
+		group = groups[index]
+	}
+
+	// Options show labels; several wallets may share one, so the address is
+	// part of each option
+	options := make([]string, len(infos))
+	addresses := make(map[string]string, len(infos))
+	inGroup := make(map[string]bool, len(group.Wallets))
+	for _, address := range group.Wallets {
+		inGroup[address] = true
+	}
+	var checked []string
+	for i, info := range infos {
+		option := shortenAddress(info.Address)
+		if info.Label != "" {
+			option = fmt.Sprintf("%s (%s)", info.Label, option)
+		}
+		options[i] = option
+		addresses[option] = info.Address
+		if inGroup[info.Address] {
+			checked = append(checked, option)
+		}
+	}
+
+	nameEntry := widget.NewEntry()
+	nameEntry.SetText(group.Name)
+	nameEntry.SetPlaceHolder("e.g. ops, cold, bots")
+	walletChecks := widget.NewCheckGroup(options, nil)
+	walletChecks.SetSelected(checked)
+	scroll := container.NewVScroll(walletChecks)
+	scroll.SetMinSize(fyne.NewSize(300, 200))
+
+	title := "New Group"
+	if index >= 0 {
+		title = "Edit Group"
+	}
+	dialog.ShowForm(title, "Save", "Cancel", []*widget.FormItem{
+		widget.NewFormItem("Name", nameEntry),
+		widget.NewFormItem("Wallets", scroll),
+	}, func(ok bool) {
+		if !ok {
+			return
+		}
+		name := strings.TrimSpace(nameEntry.Text)
+		if err := validateGroupName(name, groups, index); err != nil {
+			dialog.ShowError(err, s.window)
+			return
+		}
+		if len(walletChecks.Selected) == 0 {
+			dialog.ShowError(errors.New("select at least one wallet"), s.window)
+			return
+		}
+		edited := walletGroup{Name: name}
+		for _, option := range walletChecks.Selected {
+			edited.Wallets = append(edited.Wallets, addresses[option])
+		}
+
+		updated := append([]walletGroup(nil), groups...)
+		if index >= 0 {
+			updated[index] = edited
+		} else {
+			updated = append(updated, edited)
+		}
+		s.saveGroups(updated)
+		done()
+	}, s.window)
+}
+
+// validateGroupName checks that name is usable for groups[index], or a new
+// group if index is -1.
+func validateGroupName(name string, groups []walletGroup, index int) error {
+	if name == "" {
+		return errors.New("group name cannot be empty")
+	}
+	if strings.EqualFold(name, allWalletsGroup) {
+		return fmt.Errorf("%q is reserved", allWalletsGroup)
+	}
+	for i, group := range groups {
+		if i != index && strings.EqualFold(group.Name, name) {
+			return fmt.Errorf("a group named %q already exists", group.Name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package ui
+
+import (
+	"encoding/json"
+	"log"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+
+	"unruggable-go/internal/storage"
+)
+
+// Preference keys for state restored on the next launch. Preferences are
+// used on every platform, so this works the same in native and WASM builds.
+const (
+	selectedWalletKey     = "selectedWallet"
+	lastViewKey           = "lastView"
+	windowWidthKey        = "windowWidth"
+	windowHeightKey       = "windowHeight"
+	splitOffsetKey        = "splitOffset"
+	calypsoAllocationsKey = "calypsoAllocations"
+	multisigAddressKey    = "multisigAddress"
+	multisigMembersKey    = "multisigMembers"
+	homePricesKey         = "homePriceSources"
+	conditionalPricesKey  = "conditionalPriceSources"
+	walletGroupsKey       = "walletGroups"
+)
+
+// Layout used when nothing was saved yet.
+const (
+	defaultWindowWidth  = 1000
+	defaultWindowHeight = 700
+	defaultSplitOffset  = 0.2
+)
+
+// InitAppState restores the selected wallet and last view from a previous run
+// and keeps them saved from now on. A saved wallet that is no longer in
+// wallet storage is dropped. Call it before creating the wallet manager.
+func InitAppState(app fyne.App) {
+	prefs := app.Preferences()
+	state := GetGlobalState()
+
+	globalStateLock.Lock()
+	state.prefs = prefs
+	state.CurrentView = prefs.String(lastViewKey)
+	globalStateLock.Unlock()
+
+	if walletID := prefs.String(selectedWalletKey); walletID != "" {
+		wallets, err := storage.NewWalletStorage(app).LoadWallets()
+		if err != nil {
+			log.Printf("Failed to load wallets: %v", err)
+		}
+		if _, ok := wallets[walletID]; ok {
+			state.SetSelectedWallet(walletID)
+		} else {
+			prefs.RemoveValue(selectedWalletKey)
+		}
+	}
+}
+
+// RestoreLayout applies the saved window size and sidebar split, and saves
+// them again when the window is closed.
+func RestoreLayout(app fyne.App, window fyne.Window, split *container.Split) {
+	prefs := app.Preferences()
+	width := prefs.FloatWithFallback(windowWidthKey, defaultWindowWidth)
+	height := prefs.FloatWithFallback(windowHeightKey, defaultWindowHeight)
+	offset := prefs.FloatWithFallback(splitOffsetKey, defaultSplitOffset)
+	if width <= 0 || height <= 0 {
+		width, height = defaultWindowWidth, defaultWindowHeight
+	}
+	if offset <= 0 || offset >= 1 {
+		offset = defaultSplitOffset
+	}
+	window.Resize(fyne.NewSize(float32(width), float32(height)))
+	split.SetOffset(offset)
+
+	window.SetCloseIntercept(func() {
+		size := window.Canvas().Size()
+		prefs.SetFloat(windowWidthKey, float64(size.Width))
+		prefs.SetFloat(windowHeightKey, float64(size.Height))
+		prefs.SetFloat(splitOffsetKey, split.Offset)
+		window.Close()
+	})
+}
+
+// loadJSONPref decodes the JSON stored under key into v. It reports false if
+// nothing usable was saved.
+func loadJSONPref(prefs fyne.Preferences, key string, v interface{}) bool {
+	data := prefs.String(key)
+	if data == "" {
+		return false
+	}
+	if err := json.Unmarshal([]byte(data), v); err != nil {
+		log.Printf("Ignoring saved %s: %v", key, err)
+		return false
+	}
+	return true
+}
+
+// saveJSONPref stores v as JSON under key.
+func saveJSONPref(prefs fyne.Preferences, key string, v interface{}) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		log.Printf("Failed to save %s: %v", key, err)
+		return
+	}
+	prefs.SetString(key, string(data))
+}
@@ -0,0 +1,45 @@
+package ui
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/widget"
+
+	"unruggable-go/internal/price"
+)
+
+// loadPriceConfig returns the price settings saved under key. Nothing saved
+// trusts every source.
+func loadPriceConfig(prefs fyne.Preferences, key string) price.Config {
+	var config price.Config
+	if loadJSONPref(prefs, key, &config) && config.Validate() != nil {
+		return price.Config{}
+	}
+	return config
+}
+
+// newPriceSourcesCheck lets the user pick the trusted price sources of
+// config. At least one stays selected.
+func newPriceSourcesCheck(config price.Config, onChanged func(price.Config)) *widget.CheckGroup {
+	var selected []string
+	for _, name := range price.SourceNames {
+		if config.Trusts(name) {
+			selected = append(selected, name)
+		}
+	}
+	group := widget.NewCheckGroup(price.SourceNames, nil)
+	group.Horizontal = true
+	group.SetSelected(selected)
+	group.OnChanged = func(sources []string) {
+		if len(sources) == 0 {
+			group.SetSelected(selected)
+			return
+		}
+		selected = sources
+		config.Sources = sources
+		if config.MinSources > len(sources) {
+			config.MinSources = len(sources)
+		}
+		onChanged(config)
+	}
+	return group
+}
@@ -0,0 +1,70 @@
+package ui
+
+import (
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/gagliardetto/solana-go/rpc/jsonrpc"
+
+	"unruggable-go/internal/rpcpool"
+)
+
+// rpcHealthInterval is how often the active pool probes its endpoints.
+const rpcHealthInterval = 30 * time.Second
+
+var (
+	rpcPoolMu   sync.Mutex
+	rpcPool     *rpcpool.Pool
+	rpcPoolURLs string
+)
+
+// activeRPCPool returns the pool for the active network, rebuilding it when
+// the network's RPC endpoints change. It returns nil if no pool can be built.
+func activeRPCPool() *rpcpool.Pool {
+	urls := activeNetwork().RPCURLs()
+	key := strings.Join(urls, "\n")
+
+	rpcPoolMu.Lock()
+	defer rpcPoolMu.Unlock()
+	if rpcPool != nil && rpcPoolURLs == key {
+		return rpcPool
+	}
+
+	pool, err := rpcpool.New(urls, rpcpool.Config{})
+	if err != nil {
+		log.Printf("Failed to create RPC pool: %v", err)
+		return nil
+	}
+	if rpcPool != nil {
+		rpcPool.Close()
+	}
+	pool.StartHealthChecks(rpcHealthInterval)
+	rpcPool, rpcPoolURLs = pool, key
+	return pool
+}
+
+// rpcHTTPClient returns an HTTP client for hand-built JSON-RPC requests.
+// Requests to one of the pool's endpoints may be sent to another for
+// failover; DAS methods and URLs outside the pool go out unchanged.
+func rpcHTTPClient() *http.Client {
+	if pool := activeRPCPool(); pool != nil {
+		return pool.HTTPClient()
+	}
+	return http.DefaultClient
+}
+
+// newRPCClient returns a solana-go client whose calls go through the pool.
+func newRPCClient() *rpc.Client {
+	endpoint := activeNetwork().RPCURL
+	pool := activeRPCPool()
+	if pool == nil {
+		return rpc.New(endpoint)
+	}
+	return rpc.NewWithCustomRPCClient(jsonrpc.NewClientWithOpts(endpoint, &jsonrpc.RPCClientOpts{
+		HTTPClient: pool.HTTPClient(),
+	}))
+}
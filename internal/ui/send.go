@@ -5,13 +5,10 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"io/ioutil"
-	"math"
 	"net/http"
 	"net/url"
 	"strconv"
 	"time"
-	"unruggable-go/internal/storage"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
@@ -20,18 +17,12 @@ import (
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
 	"github.com/gagliardetto/solana-go"
-	associatedtokenaccount "github.com/gagliardetto/solana-go/programs/associated-token-account"
-	"github.com/gagliardetto/solana-go/programs/system"
-	"github.com/gagliardetto/solana-go/programs/token"
 	"github.com/gagliardetto/solana-go/rpc"
-	"github.com/mr-tron/base58"
-)
 
-// Using a public Solana RPC endpoint as fallback
-const (
-	SOLANA_RPC_ENDPOINT = "https://special-blue-fog.solana-mainnet.quiknode.pro/d009d548b4b9dd9f062a8124a868fb915937976c/"
-	CALYPSO_ENDPOINT    = "https://special-blue-fog.solana-mainnet.quiknode.pro/d009d548b4b9dd9f062a8124a868fb915937976c/"
-	JITO_URL            = "https://mainnet.block-engine.jito.wtf/api/v1/bundles"
+	"unruggable-go/internal/fee"
+	"unruggable-go/internal/network"
+	"unruggable-go/internal/session"
+	"unruggable-go/internal/transfer"
 )
 
 type SendScreen struct {
@@ -45,11 +36,21 @@ type SendScreen struct {
 	statusLabel      *widget.Label
 	window           fyne.Window
 	client           *rpc.Client
-	fromAccount      *solana.PrivateKey
+	rpcHTTP          *http.Client
+	network          network.Profile
+	signer           *session.Signer
 	app              fyne.App
 	selectedWalletID string
+	watchOnly        bool
 	isLoadingBalance bool
-	isVerboseLogging bool // Add this line
+	drafts           map[string]sendDraft
+}
+
+// sendDraft is the unsent form input of one wallet, restored when switching back.
+type sendDraft struct {
+	token     string
+	amount    string
+	recipient string
 }
 
 // Direct RPC request structure for getBalance
@@ -77,23 +78,21 @@ type RpcResponse struct {
 }
 
 func NewSendScreen(window fyne.Window, app fyne.App) fyne.CanvasObject {
+	profile := activeNetwork()
 	s := &SendScreen{
+		network:          profile,
 		window:           window,
 		app:              app,
-		client:           rpc.New(CALYPSO_ENDPOINT),
+		client:           newRPCClient(),
+		rpcHTTP:          rpcHTTPClient(),
 		recipientBalance: widget.NewLabel(""),
 		statusLabel:      widget.NewLabel(""),
 		isLoadingBalance: false,
+		drafts:           make(map[string]sendDraft),
 	}
 
 	// Get the globally selected wallet
-	selectedWallet := GetGlobalState().GetSelectedWallet()
-	if selectedWallet != "" {
-		s.selectedWalletID = selectedWallet
-		s.statusLabel.SetText(fmt.Sprintf("Using wallet: %s", shortenAddress(selectedWallet)))
-	} else {
-		s.statusLabel.SetText("No wallet selected. Please select a wallet from the Wallet tab.")
-	}
+	s.loadWallet(GetGlobalState().GetSelectedWallet())
 
 	// Token selection
 	tokenOptions := s.getTokenOptions()
@@ -121,11 +120,8 @@ func NewSendScreen(window fyne.Window, app fyne.App) fyne.CanvasObject {
 				availableBalance = balances.SolBalance - 0.01
 			}
 		} else {
-			for _, holding := range balances.Assets {
-				if holding.Symbol == s.tokenSelect.Selected {
-					availableBalance = holding.Balance
-					break
-				}
+			if holding, ok := s.selectedHolding(); ok {
+				availableBalance = holding.Balance
 			}
 		}
 
@@ -202,9 +198,77 @@ func NewSendScreen(window fyne.Window, app fyne.App) fyne.CanvasObject {
 		s.tokenSelect.SetSelected(tokenOptions[0])
 	}
 
+	bus := GetGlobalState().Events
+	bus.WalletSelected.Subscribe(func(e WalletSelectedEvent) {
+		s.saveDraft(e.Previous)
+		s.loadWallet(e.WalletID)
+		s.restoreDraft(e.WalletID)
+		s.refreshWalletBalances()
+	})
+	bus.BalancesUpdated.Subscribe(func(e BalancesUpdatedEvent) {
+		if e.WalletID != s.selectedWalletID {
+			return
+		}
+		s.tokenSelect.Options = s.getTokenOptions()
+		s.tokenSelect.Refresh()
+		s.validateForm()
+		s.updateBalanceInfo()
+	})
+	bus.NetworkChanged.Subscribe(func(e NetworkChangedEvent) {
+		s.network = e.Network
+		s.client = newRPCClient()
+		s.rpcHTTP = rpcHTTPClient()
+		s.statusLabel.SetText(fmt.Sprintf("Switched to network %s", e.Network.Name))
+		s.refreshWalletBalances()
+	})
+
 	return s.container
 }
 
+// loadWallet makes walletID the sending wallet. A signer is requested again
+// on the next send.
+func (s *SendScreen) loadWallet(walletID string) {
+	s.selectedWalletID = walletID
+	s.watchOnly = walletID != "" && isWatchOnlyWallet(s.app, walletID)
+	switch {
+	case walletID == "":
+		s.statusLabel.SetText("No wallet selected. Please select a wallet from the Wallet tab.")
+	case s.watchOnly:
+		s.statusLabel.SetText(fmt.Sprintf("Wallet %s is watch-only. Sending is disabled.", shortenAddress(walletID)))
+	default:
+		s.statusLabel.SetText(fmt.Sprintf("Using wallet: %s", shortenAddress(walletID)))
+	}
+}
+
+// saveDraft remembers the form input entered for walletID.
+func (s *SendScreen) saveDraft(walletID string) {
+	if walletID == "" {
+		return
+	}
+	s.drafts[walletID] = sendDraft{
+		token:     s.tokenSelect.Selected,
+		amount:    s.amountEntry.Text,
+		recipient: s.recipientEntry.Text,
+	}
+}
+
+// restoreDraft fills the form with walletID's saved input, or clears it.
+func (s *SendScreen) restoreDraft(walletID string) {
+	draft := s.drafts[walletID]
+	s.tokenSelect.Options = s.getTokenOptions()
+	if draft.token == "" {
+		s.tokenSelect.ClearSelected()
+	} else {
+		s.tokenSelect.SetSelected(draft.token)
+	}
+	s.amountEntry.SetText(draft.amount)
+	s.recipientEntry.SetText(draft.recipient)
+	if draft.recipient == "" {
+		s.recipientBalance.SetText("")
+	}
+	s.validateForm()
+}
+
 func (s *SendScreen) getTokenOptions() []string {
 	balances := GetGlobalState().GetWalletBalances()
 	if balances == nil {
@@ -212,13 +276,39 @@ func (s *SendScreen) getTokenOptions() []string {
 	}
 	options := []string{"SOL"}
 	for _, asset := range balances.Assets {
-		if asset.Balance > 0 { // Only show tokens with non-zero balance
-			options = append(options, asset.Symbol)
+		// Only show tokens with non-zero balance; unverified ones only if
+		// the user chose to see them
+		if asset.Balance > 0 && (asset.Verified || showUnverifiedTokens()) {
+			options = append(options, tokenOption(asset))
 		}
 	}
 	return options
 }
 
+// tokenOption is the label of a holding in the token selector. Unverified
+// tokens carry their mint, so one cannot pass for a listed token by copying
+// its symbol.
+func tokenOption(holding Holding) string {
+	if holding.Verified {
+		return holding.Symbol
+	}
+	return fmt.Sprintf("%s (unverified %s)", holding.Symbol, shortenAddress(holding.Address))
+}
+
+// selectedHolding returns the holding of the selected token option.
+func (s *SendScreen) selectedHolding() (Holding, bool) {
+	balances := GetGlobalState().GetWalletBalances()
+	if balances == nil || s.tokenSelect.Selected == "" {
+		return Holding{}, false
+	}
+	for _, holding := range balances.Assets {
+		if tokenOption(holding) == s.tokenSelect.Selected {
+			return holding, true
+		}
+	}
+	return Holding{}, false
+}
+
 func (s *SendScreen) onTokenSelected(value string) {
 	if value != "" {
 		s.validateForm()
@@ -243,12 +333,9 @@ func (s *SendScreen) updateBalanceInfo() {
 		availableBalance = balances.SolBalance
 		symbol = "SOL"
 	} else {
-		for _, holding := range balances.Assets {
-			if holding.Symbol == s.tokenSelect.Selected {
-				availableBalance = holding.Balance
-				symbol = holding.Symbol
-				break
-			}
+		if holding, ok := s.selectedHolding(); ok {
+			availableBalance = holding.Balance
+			symbol = holding.Symbol
 		}
 	}
 
@@ -269,54 +356,37 @@ func (s *SendScreen) fetchBalanceWithRPC(address string) {
 	go func() {
 		defer func() { s.isLoadingBalance = false }()
 
-		// Try multiple RPC endpoints
-		endpoints := []string{CALYPSO_ENDPOINT, SOLANA_RPC_ENDPOINT}
-
-		for _, endpoint := range endpoints {
-			// Create RPC request
-			reqBody := RpcRequest{
-				JsonRPC: "2.0",
-				ID:      1,
-				Method:  "getBalance",
-				Params:  []interface{}{address},
-			}
-
-			jsonData, err := json.Marshal(reqBody)
-			if err != nil {
-				continue // Try next endpoint
-			}
-
-			// Send request
-			resp, err := http.Post(endpoint, "application/json", bytes.NewBuffer(jsonData))
-			if err != nil {
-				continue // Try next endpoint
-			}
-			defer resp.Body.Close()
-
-			// Read and parse response
-			respData, err := ioutil.ReadAll(resp.Body)
-			if err != nil {
-				continue // Try next endpoint
-			}
+		// Create RPC request
+		reqBody := RpcRequest{
+			JsonRPC: "2.0",
+			ID:      1,
+			Method:  "getBalance",
+			Params:  []interface{}{address},
+		}
 
-			var rpcResp RpcResponse
-			if err := json.Unmarshal(respData, &rpcResp); err != nil {
-				continue // Try next endpoint
-			}
+		jsonData, err := json.Marshal(reqBody)
+		if err != nil {
+			s.recipientBalance.SetText("Could not fetch recipient balance")
+			return
+		}
 
-			// Check for errors
-			if rpcResp.Error != nil {
-				continue // Try next endpoint
-			}
+		// Send request; the pool fails over between the network's endpoints
+		resp, err := s.rpcHTTP.Post(s.network.RPCURL, "application/json", bytes.NewBuffer(jsonData))
+		if err != nil {
+			s.recipientBalance.SetText("Could not fetch recipient balance")
+			return
+		}
+		defer resp.Body.Close()
 
-			// Success - update UI and return
-			solBalance := float64(rpcResp.Result.Value) / float64(solana.LAMPORTS_PER_SOL)
-			s.recipientBalance.SetText(fmt.Sprintf("Recipient SOL: %.6f", solBalance))
+		// Read and parse response
+		var rpcResp RpcResponse
+		if err := json.NewDecoder(resp.Body).Decode(&rpcResp); err != nil || rpcResp.Error != nil {
+			s.recipientBalance.SetText("Could not fetch recipient balance")
 			return
 		}
 
-		// All endpoints failed
-		s.recipientBalance.SetText("Could not fetch recipient balance")
+		solBalance := float64(rpcResp.Result.Value) / float64(solana.LAMPORTS_PER_SOL)
+		s.recipientBalance.SetText(fmt.Sprintf("Recipient SOL: %.6f", solBalance))
 	}()
 }
 
@@ -335,17 +405,11 @@ func (s *SendScreen) validateAndFetchBalance(address string) {
 func (s *SendScreen) refreshWalletBalances() {
 	s.statusLabel.SetText("Refreshing balances...")
 
+	// The form updates from the BalancesUpdated event
 	go func() {
 		if err := RefreshWalletBalances(); err != nil {
 			s.statusLabel.SetText(fmt.Sprintf("Refresh failed: %v", err))
-			return
 		}
-
-		s.tokenSelect.Options = s.getTokenOptions()
-		s.tokenSelect.Refresh()
-		s.validateForm()
-		s.updateBalanceInfo()
-		s.window.Canvas().Refresh(s.container)
 	}()
 }
 
@@ -366,6 +430,12 @@ func (s *SendScreen) validateForm() {
 		return
 	}
 
+	// Watch-only wallets have no key to sign with
+	if s.watchOnly {
+		s.sendButton.Disable()
+		return
+	}
+
 	// If any field is empty, disable the button and return early
 	if s.amountEntry.Text == "" || s.recipientEntry.Text == "" {
 		s.sendButton.Disable()
@@ -399,11 +469,8 @@ func (s *SendScreen) validateForm() {
 	if selectedToken == "SOL" {
 		availableBalance = balances.SolBalance
 	} else {
-		for _, holding := range balances.Assets {
-			if holding.Symbol == selectedToken {
-				availableBalance = holding.Balance
-				break
-			}
+		if holding, ok := s.selectedHolding(); ok {
+			availableBalance = holding.Balance
 		}
 	}
 
@@ -424,6 +491,10 @@ func (s *SendScreen) handleSendTransaction() {
 		dialog.ShowError(fmt.Errorf("no wallet selected - please select a wallet first"), s.window)
 		return
 	}
+	if s.watchOnly {
+		dialog.ShowError(watchOnlyError(s.selectedWalletID), s.window)
+		return
+	}
 
 	amount, err := strconv.ParseFloat(s.amountEntry.Text, 64)
 	if err != nil {
@@ -431,412 +502,140 @@ func (s *SendScreen) handleSendTransaction() {
 		return
 	}
 
-	// Confirm the transaction
-	confirmText := fmt.Sprintf("Send %.6f %s to %s?",
-		amount,
-		s.tokenSelect.Selected,
-		shortenAddress(s.recipientEntry.Text))
+	token := s.tokenSelect.Selected
+	recipient := s.recipientEntry.Text
+	walletID := s.selectedWalletID
 
-	dialog.ShowConfirm("Confirm Transaction", confirmText, func(confirmed bool) {
-		if !confirmed {
+	// The transaction is prepared and simulated up front so the confirmation
+	// shows its priority fee and balance changes; it gets a fresh blockhash
+	// when sent.
+	s.statusLabel.SetText("Preparing transaction...")
+	go func() {
+		ctx := context.Background()
+		params, err := s.transferParams(walletID, recipient, amount)
+		if err != nil {
+			dialog.ShowError(err, s.window)
 			return
 		}
 
-		// Show password dialog for decryption
-		passwordEntry := widget.NewPasswordEntry()
-		passwordEntry.SetPlaceHolder("Enter wallet password")
-
-		dialog.ShowCustomConfirm("Decrypt Wallet", "Send", "Cancel", passwordEntry, func(confirm bool) {
-			if !confirm {
+		// Token-2022 mints may withhold a fee or refuse transfers altogether
+		var transferFeeText string
+		if token != "SOL" {
+			mint, err := transfer.LoadMint(ctx, s.client, params.Mint)
+			if err != nil {
+				dialog.ShowError(err, s.window)
 				return
 			}
-
-			// Decrypt wallet
-			if err := s.decryptAndPrepareWallet(passwordEntry.Text); err != nil {
-				dialog.ShowError(err, s.window)
+			s.updateBalanceInfo()
+			if mint.NonTransferable {
+				dialog.ShowError(fmt.Errorf("%s is a non-transferable token", token), s.window)
 				return
 			}
+			if mint.TransferFee != nil {
+				units := mint.BaseUnits(amount)
+				withheld := mint.TransferFee.Fee(units)
+				transferFeeText = fmt.Sprintf("\n\nTransfer fee: %s %s (%.2f%%, max %s)\nRecipient receives: %s %s",
+					strconv.FormatFloat(mint.Tokens(withheld), 'f', -1, 64), token,
+					float64(mint.TransferFee.BasisPoints)/100,
+					strconv.FormatFloat(mint.Tokens(mint.TransferFee.Maximum), 'f', -1, 64),
+					strconv.FormatFloat(mint.Tokens(units-withheld), 'f', -1, 64), token)
+			}
+		}
 
-			// Proceed with transaction
-			go s.executeTransaction(amount)
-		}, s.window)
-	}, s.window)
-}
-
-func (s *SendScreen) decryptAndPrepareWallet(password string) error {
-	// Use the storage abstraction to access wallet data
-	walletStorage := storage.NewWalletStorage(s.app)
-	walletMap, err := walletStorage.LoadWallets()
-	if err != nil {
-		return fmt.Errorf("error loading wallets: %v", err)
-	}
-
-	// Get the encrypted wallet data
-	encryptedData, ok := walletMap[s.selectedWalletID]
-	if !ok {
-		return fmt.Errorf("wallet %s not found", s.selectedWalletID)
-	}
-
-	decryptedKey, err := decrypt(encryptedData, password)
-	if err != nil {
-		return fmt.Errorf("failed to decrypt wallet: %v", err)
-	}
-
-	privateKey := solana.MustPrivateKeyFromBase58(string(decryptedKey))
-	s.fromAccount = &privateKey
-	return nil
-}
-
-func (s *SendScreen) createTransferTransaction(fromWallet, toAddress string, amount float64) (*solana.Transaction, error) {
-	fromPubkey := solana.MustPublicKeyFromBase58(fromWallet)
-	toPubkey := solana.MustPublicKeyFromBase58(toAddress)
-
-	selectedToken := s.tokenSelect.Selected
-	recent, err := s.client.GetRecentBlockhash(context.Background(), rpc.CommitmentFinalized)
-	if err != nil {
-		return nil, fmt.Errorf("error getting recent blockhash: %v", err)
-	}
-
-	if selectedToken == "SOL" {
-		// SOL transfer
-		amountLamports := uint64(amount * float64(solana.LAMPORTS_PER_SOL))
-		tx, err := solana.NewTransaction(
-			[]solana.Instruction{
-				system.NewTransferInstruction(
-					amountLamports,
-					fromPubkey,
-					toPubkey,
-				).Build(),
-			},
-			recent.Value.Blockhash,
-			solana.TransactionPayer(fromPubkey),
-		)
+		instructions, err := transfer.Instructions(ctx, s.client, params)
 		if err != nil {
-			return nil, fmt.Errorf("error creating SOL transaction: %v", err)
+			dialog.ShowError(fmt.Errorf("failed to create transfer transaction: %v", err), s.window)
+			return
 		}
-		return tx, nil
-	}
-
-	// SPL token transfer
-	balances := GetGlobalState().GetWalletBalances()
-	var mint string
-	var decimals int
-	for _, holding := range balances.Assets {
-		if holding.Symbol == selectedToken {
-			mint = holding.Address
-			decimals = holding.Decimals
-			break
+		instructions, budget, err := fee.Prepare(ctx, s.client, priorityFeeStrategy(), params.From, instructions)
+		if err != nil {
+			dialog.ShowError(err, s.window)
+			return
 		}
-	}
-	if mint == "" {
-		return nil, fmt.Errorf("token %s not found in wallet", selectedToken)
-	}
-
-	// Find sender's token account
-	senderATA, _, err := solana.FindAssociatedTokenAddress(fromPubkey, solana.MustPublicKeyFromBase58(mint))
-	if err != nil {
-		return nil, fmt.Errorf("error finding sender ATA: %v", err)
-	}
 
-	// Find or create recipient's token account
-	recipientATA, _, err := solana.FindAssociatedTokenAddress(toPubkey, solana.MustPublicKeyFromBase58(mint))
-	if err != nil {
-		return nil, fmt.Errorf("error finding recipient ATA: %v", err)
-	}
-
-	// Check if recipient ATA exists
-	_, err = s.client.GetAccountInfo(context.Background(), recipientATA)
-	createRecipientATA := err != nil
-
-	var instructions []solana.Instruction
-	if createRecipientATA {
-		instructions = append(instructions,
-			associatedtokenaccount.NewCreateInstruction(
-				fromPubkey,
-				toPubkey,
-				solana.MustPublicKeyFromBase58(mint),
-			).Build(),
-		)
-	}
-
-	// Add transfer instruction
-	amountLamports := uint64(amount * math.Pow(10, float64(decimals)))
-	instructions = append(instructions,
-		token.NewTransferInstruction(
-			amountLamports,
-			senderATA,
-			recipientATA,
-			fromPubkey,
-			[]solana.PublicKey{}, // No multisigners
-		).Build(),
-	)
-
-	tx, err := solana.NewTransaction(
-		instructions,
-		recent.Value.Blockhash,
-		solana.TransactionPayer(fromPubkey),
-	)
-	if err != nil {
-		return nil, fmt.Errorf("error creating SPL transaction: %v", err)
-	}
-
-	return tx, nil
+		confirmText := fmt.Sprintf("Send %.6f %s to %s?%s\n\n%s",
+			amount,
+			token,
+			shortenAddress(recipient),
+			transferFeeText,
+			budget)
+		s.statusLabel.SetText("Simulating transaction...")
+		confirmWithPreview(ctx, s.client, s.window, "Confirm Transaction", confirmText, params.From, instructions, func() {
+			requestSigner(s.app, s.window, walletID, func(signer *session.Signer) {
+				s.signer = signer
+				go s.executeTransaction(instructions)
+			}, nil)
+		})
+		s.statusLabel.SetText("")
+	}()
 }
 
-func (s *SendScreen) createTipTransaction() (*solana.Transaction, error) {
-	recent, err := s.client.GetRecentBlockhash(context.Background(), rpc.CommitmentFinalized)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get recent blockhash: %v", err)
-	}
-
-	builder := solana.NewTransactionBuilder()
-	builder.SetFeePayer(s.fromAccount.PublicKey())
-	builder.SetRecentBlockHash(recent.Value.Blockhash)
-
-	tipRecipients := []string{
-		"juLesoSmdTcRtzjCzYzRoHrnF8GhVu6KCV7uxq7nJGp",
-		"DttWaMuVvTiduZRnguLF7jNxTgiMBZ1hyAumKUiL2KRL",
-	}
-
-	for _, recipient := range tipRecipients {
-		tipInstruction := system.NewTransferInstruction(
-			100_000, // 0.0001 SOL
-			s.fromAccount.PublicKey(),
-			solana.MustPublicKeyFromBase58(recipient),
-		).Build()
-		builder.AddInstruction(tipInstruction)
-	}
-
-	tx, err := builder.Build()
-	if err != nil {
-		return nil, fmt.Errorf("failed to build tip transaction: %v", err)
-	}
-
-	_, err = tx.Sign(func(key solana.PublicKey) *solana.PrivateKey {
-		if key.Equals(s.fromAccount.PublicKey()) {
-			return s.fromAccount
-		}
-		return nil
-	})
-	if err != nil {
-		return nil, fmt.Errorf("failed to sign tip transaction: %v", err)
+// selectedMint returns the mint of the selected token.
+func (s *SendScreen) selectedMint() (solana.PublicKey, error) {
+	holding, ok := s.selectedHolding()
+	if !ok {
+		return solana.PublicKey{}, fmt.Errorf("token %s not found in wallet", s.tokenSelect.Selected)
 	}
-
-	return tx, nil
+	return solana.PublicKeyFromBase58(holding.Address)
 }
 
-// Fixed sendBundle function with proper error handling and request structure
-func (s *SendScreen) sendBundle(transactions []*solana.Transaction) (string, error) {
-	// Validate that we have transactions to send
-	if len(transactions) == 0 {
-		return "", fmt.Errorf("no transactions to send")
-	}
-
-	// Encode transactions
-	encodedTransactions := make([]string, len(transactions))
-	for i, tx := range transactions {
-		// Make sure transaction is signed
-		if len(tx.Signatures) == 0 {
-			return "", fmt.Errorf("transaction %d is not signed", i)
-		}
-
-		// Marshal the transaction to binary
-		encodedTx, err := tx.MarshalBinary()
-		if err != nil {
-			return "", fmt.Errorf("failed to encode transaction %d: %v", i, err)
-		}
-
-		// Encode the binary to base58
-		encodedTransactions[i] = base58.Encode(encodedTx)
-
-		// Debug log for troubleshooting
-		if s.isVerboseLogging {
-			s.logDebug(fmt.Sprintf("Encoded tx %d: %s (first 20 chars)", i, encodedTransactions[i][:20]))
-		}
-	}
-
-	// Create the bundle request with proper structure
-	// Note: Jito expects params to be an array containing an array of transactions
-	bundleData := map[string]interface{}{
-		"jsonrpc": "2.0",
-		"id":      1,
-		"method":  "sendBundle",
-		"params":  []interface{}{encodedTransactions}, // This is the key fix - params is an array containing one array
-	}
-
-	// Marshal to JSON
-	bundleJSON, err := json.Marshal(bundleData)
-	if err != nil {
-		return "", fmt.Errorf("failed to marshal bundle data: %v", err)
-	}
-
-	// Debug log
-	if s.isVerboseLogging {
-		s.logDebug(fmt.Sprintf("Sending bundle request: %s", string(bundleJSON)))
-	}
-
-	// Create HTTP request with proper headers
-	req, err := http.NewRequest("POST", JITO_URL, bytes.NewBuffer(bundleJSON))
-	if err != nil {
-		return "", fmt.Errorf("failed to create HTTP request: %v", err)
-	}
-
-	// Set content type header
-	req.Header.Set("Content-Type", "application/json")
-
-	// Send the request
-	client := &http.Client{Timeout: 30 * time.Second}
-	resp, err := client.Do(req)
+// transferParams resolves the selected token into the parameters of a
+// transfer from walletID.
+func (s *SendScreen) transferParams(walletID, toAddress string, amount float64) (transfer.Params, error) {
+	from, err := solana.PublicKeyFromBase58(walletID)
 	if err != nil {
-		return "", fmt.Errorf("failed to send bundle: %v", err)
+		return transfer.Params{}, fmt.Errorf("invalid wallet: %v", err)
 	}
-	defer resp.Body.Close()
-
-	// Read response body
-	respBody, err := ioutil.ReadAll(resp.Body)
+	to, err := solana.PublicKeyFromBase58(toAddress)
 	if err != nil {
-		return "", fmt.Errorf("failed to read response body: %v", err)
+		return transfer.Params{}, fmt.Errorf("invalid recipient: %v", err)
 	}
-
-	// Debug log
-	if s.isVerboseLogging {
-		s.logDebug(fmt.Sprintf("Bundle response: %s", string(respBody)))
-	}
-
-	// Parse response
-	var result map[string]interface{}
-	if err := json.Unmarshal(respBody, &result); err != nil {
-		return "", fmt.Errorf("failed to decode bundle response: %v", err)
-	}
-
-	// Check for error field in response
-	if errorData, ok := result["error"]; ok {
-		// Try to extract detailed error information
-		errorMsg := "unknown error"
-		if errObj, ok := errorData.(map[string]interface{}); ok {
-			if msg, ok := errObj["message"].(string); ok {
-				errorMsg = msg
-			}
-		} else if errStr, ok := errorData.(string); ok {
-			errorMsg = errStr
-		}
-		return "", fmt.Errorf("bundle error: %s", errorMsg)
+	params := transfer.Params{From: from, To: to, Amount: amount}
+	if s.tokenSelect.Selected == "SOL" {
+		return params, nil
 	}
-
-	// Get result field, which should be the bundle ID
-	resultField, ok := result["result"]
-	if !ok {
-		return "", fmt.Errorf("no result in response")
+	if params.Mint, err = s.selectedMint(); err != nil {
+		return transfer.Params{}, err
 	}
+	return params, nil
+}
 
-	// The result could be a string (bundle ID) or could have a different structure
-	bundleID, ok := resultField.(string)
-	if !ok {
-		// Try to handle case where result might be structured differently
-		bundleID = fmt.Sprintf("%v", resultField)
-	}
+// executeTransaction signs and submits the prepared transfer instructions,
+// bundled through Jito when the network supports it.
+func (s *SendScreen) executeTransaction(instructions []solana.Instruction) {
+	walletID := s.selectedWalletID
+	ctx := context.Background()
 
-	if bundleID == "" {
-		return "", fmt.Errorf("empty bundle ID returned")
+	fail := func(err error) {
+		dialog.ShowError(err, s.window)
+		s.sendButton.Enable()
+		s.statusLabel.SetText("Transaction failed")
 	}
 
-	return bundleID, nil
-}
-
-// Enhanced executeTransaction function to properly build and send the bundle
-func (s *SendScreen) executeTransaction(amount float64) {
-	// Add a flag for verbose logging for debugging
-	s.isVerboseLogging = false // Set to true when debugging is needed
-
 	s.statusLabel.SetText("Creating transaction...")
 	s.sendButton.Disable()
 
-	// 1. Create the main transfer transaction
-	transferTx, err := s.createTransferTransaction(s.fromAccount.PublicKey().String(), s.recipientEntry.Text, amount)
+	transferTx, err := transfer.NewTransaction(ctx, s.client, s.signer.PublicKey(), instructions)
 	if err != nil {
-		dialog.ShowError(fmt.Errorf("failed to create transfer transaction: %v", err), s.window)
-		s.sendButton.Enable()
-		s.statusLabel.SetText("Transaction failed")
+		fail(fmt.Errorf("failed to create transfer transaction: %v", err))
 		return
 	}
-
-	// 2. Sign the transfer transaction
-	_, err = transferTx.Sign(func(key solana.PublicKey) *solana.PrivateKey {
-		if key.Equals(s.fromAccount.PublicKey()) {
-			return s.fromAccount
-		}
-		return nil
-	})
-	if err != nil {
-		dialog.ShowError(fmt.Errorf("error signing transfer transaction: %v", err), s.window)
-		s.sendButton.Enable()
-		s.statusLabel.SetText("Transaction failed")
+	if err := s.signer.SignTransaction(transferTx); err != nil {
+		fail(fmt.Errorf("error signing transfer transaction: %v", err))
 		return
 	}
 
-	// Save the transfer transaction signature before bundling
-	transferSig := transferTx.Signatures[0].String()
-
-	// 3. Create tip transaction
-	tipTx, err := s.createTipTransaction()
+	s.statusLabel.SetText("Sending transaction...")
+	sig, err := transfer.Submit(ctx, s.client, s.network.JitoURL, transferTx, s.signer)
 	if err != nil {
-		// If tip transaction fails, we can still proceed with just the transfer
-		s.logDebug(fmt.Sprintf("Failed to create tip transaction: %v", err))
-
-		// Fall back to sending just the transfer transaction
-		encodedTx, err := transferTx.MarshalBinary()
-		if err != nil {
-			dialog.ShowError(fmt.Errorf("error encoding transaction: %v", err), s.window)
-			s.sendButton.Enable()
-			s.statusLabel.SetText("Transaction failed")
-			return
-		}
-
-		// Send single transaction using standard Solana RPC
-		s.statusLabel.SetText("Sending transaction...")
-		encodedTxBase58 := base58.Encode(encodedTx)
-		sig, err := s.client.SendEncodedTransaction(context.Background(), encodedTxBase58)
-		if err != nil {
-			dialog.ShowError(fmt.Errorf("error sending transaction: %v", err), s.window)
-			s.sendButton.Enable()
-			s.statusLabel.SetText("Transaction failed")
-			return
-		}
-
-		// Use signature from RPC response
-		transferSig = sig.String()
-	} else {
-		// 4. Bundle transactions and send
-		s.statusLabel.SetText("Sending transaction bundle...")
-		_, err = s.sendBundle([]*solana.Transaction{transferTx, tipTx})
-		if err != nil {
-			s.logDebug(fmt.Sprintf("Bundle failed: %v. Falling back to standard transaction.", err))
-
-			// Fallback to sending just the transfer if bundle fails
-			encodedTx, err := transferTx.MarshalBinary()
-			if err != nil {
-				dialog.ShowError(fmt.Errorf("error encoding transaction: %v", err), s.window)
-				s.sendButton.Enable()
-				s.statusLabel.SetText("Transaction failed")
-				return
-			}
-
-			encodedTxBase58 := base58.Encode(encodedTx)
-			sig, err := s.client.SendEncodedTransaction(context.Background(), encodedTxBase58)
-			if err != nil {
-				dialog.ShowError(fmt.Errorf("error sending transaction: %v", err), s.window)
-				s.sendButton.Enable()
-				s.statusLabel.SetText("Transaction failed")
-				return
-			}
-
-			// Use signature from RPC response
-			transferSig = sig.String()
-		}
+		fail(err)
+		return
 	}
+	transferSig := sig.String()
 
 	s.statusLabel.SetText(fmt.Sprintf("Transaction sent with ID: %s", shortenAddress(transferSig)))
-	s.monitorTransaction(transferSig)
+	publishTxStatus("Send", walletID, transferSig, nil)
+	s.monitorTransaction(walletID, transferSig)
 	s.clearForm()
 
 	// Refresh balances after a short delay
@@ -846,17 +645,8 @@ func (s *SendScreen) executeTransaction(amount float64) {
 	}()
 }
 
-// Add debugging helper method
-func (s *SendScreen) logDebug(message string) {
-	if s.isVerboseLogging {
-		fmt.Println(message)
-		// Could also update UI or log to file
-	}
-}
-
-func (s *SendScreen) monitorTransaction(signatureStr string) {
-	const maxAttempts = 30
-	attempts := 0
+func (s *SendScreen) monitorTransaction(walletID, signatureStr string) {
+	const confirmTimeout = 30 * time.Second
 
 	signature := solana.MustSignatureFromBase58(signatureStr)
 
@@ -871,55 +661,38 @@ func (s *SendScreen) monitorTransaction(signatureStr string) {
 	statusPopup.Show()
 
 	go func() {
-		ticker := time.NewTicker(time.Second)
-		defer ticker.Stop()
 		defer statusPopup.Hide()
 
-		for range ticker.C {
-			attempts++
-
-			response, err := s.client.GetTransaction(
-				context.Background(),
-				signature,
-				&rpc.GetTransactionOpts{
-					Commitment: rpc.CommitmentFinalized,
-				},
-			)
-
-			if err != nil {
-				if attempts >= maxAttempts {
-					dialog.ShowError(fmt.Errorf("Transaction timed out: %v", err), s.window)
-					return
-				}
-				continue
-			}
-
-			if response != nil {
-				if response.Meta.Err != nil {
-					dialog.ShowError(fmt.Errorf("Transaction failed: %v", response.Meta.Err), s.window)
+		ctx, cancel := context.WithTimeout(context.Background(), confirmTimeout)
+		defer cancel()
+		slot, err := transfer.WaitConfirmed(ctx, s.client, signature)
+		if err != nil {
+			publishTxStatus("Send", walletID, signatureStr, err)
+			dialog.ShowError(err, s.window)
+			return
+		}
+		GetGlobalState().Events.TxStatus.Publish(TxStatusEvent{
+			Source:   "Send",
+			WalletID: walletID,
+			ID:       signatureStr,
+			Status:   TxConfirmed,
+		})
+
+		// Show success dialog
+		successContent := container.NewVBox(
+			widget.NewIcon(theme.ConfirmIcon()),
+			widget.NewLabel("Transaction Confirmed!"),
+			widget.NewLabel(fmt.Sprintf("Block: %d", slot)),
+			widget.NewButtonWithIcon("View in Explorer", theme.ComputerIcon(), func() {
+				explorerURL, err := url.Parse(s.network.ExplorerTxURL(signatureStr))
+				if err != nil {
 					return
 				}
+				s.app.OpenURL(explorerURL)
+			}),
+		)
 
-				// Show success dialog
-				successContent := container.NewVBox(
-					widget.NewIcon(theme.ConfirmIcon()),
-					widget.NewLabel("Transaction Confirmed!"),
-					widget.NewLabel(fmt.Sprintf("Block: %d", response.Slot)),
-					widget.NewButtonWithIcon("View in Explorer", theme.ComputerIcon(), func() {
-						explorerURL := fmt.Sprintf("https://explorer.solana.com/tx/%s", signatureStr)
-						s.app.OpenURL(&url.URL{Path: explorerURL})
-					}),
-				)
-
-				dialog.ShowCustom("Success", "Close", successContent, s.window)
-				return
-			}
-
-			if attempts >= maxAttempts {
-				dialog.ShowError(fmt.Errorf("Transaction timed out"), s.window)
-				return
-			}
-		}
+		dialog.ShowCustom("Success", "Close", successContent, s.window)
 	}()
 }
 
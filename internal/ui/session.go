@@ -0,0 +1,153 @@
+package ui
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/gagliardetto/solana-go"
+
+	"unruggable-go/internal/session"
+)
+
+// sessionTimeoutKey stores the auto-lock timeout in seconds. Zero means never.
+const sessionTimeoutKey = "sessionTimeout"
+
+// sessionTimeouts are the auto-lock choices offered on the wallet screen.
+var sessionTimeouts = []struct {
+	Name    string
+	Timeout time.Duration
+}{
+	{"1 minute", time.Minute},
+	{"5 minutes", 5 * time.Minute},
+	{"15 minutes", 15 * time.Minute},
+	{"1 hour", time.Hour},
+	{"Never", 0},
+}
+
+var (
+	walletSession     *session.Session
+	walletSessionOnce sync.Once
+)
+
+// GetSession returns the shared wallet unlock session.
+func GetSession() *session.Session {
+	walletSessionOnce.Do(func() {
+		walletSession = session.New(session.DefaultTimeout)
+	})
+	return walletSession
+}
+
+// InitSession applies the saved auto-lock timeout and locks the session
+// whenever the app leaves the foreground.
+func InitSession(app fyne.App) {
+	s := GetSession()
+	seconds := app.Preferences().IntWithFallback(sessionTimeoutKey, int(session.DefaultTimeout/time.Second))
+	s.SetTimeout(time.Duration(seconds) * time.Second)
+	app.Lifecycle().SetOnExitedForeground(s.Lock)
+}
+
+// setSessionTimeout changes and persists the auto-lock timeout.
+func setSessionTimeout(app fyne.App, timeout time.Duration) {
+	GetSession().SetTimeout(timeout)
+	app.Preferences().SetInt(sessionTimeoutKey, int(timeout/time.Second))
+}
+
+func sessionTimeoutNames() []string {
+	names := make([]string, len(sessionTimeouts))
+	for i, t := range sessionTimeouts {
+		names[i] = t.Name
+	}
+	return names
+}
+
+func sessionTimeoutName(timeout time.Duration) string {
+	for _, t := range sessionTimeouts {
+		if t.Timeout == timeout {
+			return t.Name
+		}
+	}
+	return timeout.String()
+}
+
+// errUnlockCancelled is passed to onCancel when the user dismisses the
+// password prompt.
+var errUnlockCancelled = errors.New("wallet unlock cancelled")
+
+// requestSigner calls onReady with a signer for walletID, asking for the wallet
+// password only if the session does not already hold that wallet. If no
+// signer can be had (a watch-only wallet, a cancelled prompt or a wrong
+// password) the error is shown and onCancel, if set, is called with it.
+func requestSigner(app fyne.App, window fyne.Window, walletID string, onReady func(*session.Signer), onCancel func(error)) {
+	if isWatchOnlyWallet(app, walletID) {
+		err := watchOnlyError(walletID)
+		dialog.ShowError(err, window)
+		if onCancel != nil {
+			onCancel(err)
+		}
+		return
+	}
+	if signer, ok := GetSession().Signer(walletID); ok {
+		onReady(signer)
+		return
+	}
+	promptWalletKey(app, window, walletID, func(privateKey solana.PrivateKey) {
+		onReady(GetSession().Unlock(walletID, privateKey))
+	}, onCancel)
+}
+
+// requestBotSigner calls onReady with a signer for walletID backed by its own
+// session without auto-lock. A running bot keeps trading when the shared
+// session locks after idling, on a wallet switch or when the window loses
+// focus. release zeroes the bot's copy of the key.
+func requestBotSigner(app fyne.App, window fyne.Window, walletID string, onReady func(signer *session.Signer, release func())) {
+	if isWatchOnlyWallet(app, walletID) {
+		dialog.ShowError(watchOnlyError(walletID), window)
+		return
+	}
+	own := session.New(0)
+	if shared, ok := GetSession().Signer(walletID); ok {
+		var signer *session.Signer
+		err := shared.WithPrivateKey(func(key solana.PrivateKey) error {
+			signer = own.Unlock(walletID, key)
+			return nil
+		})
+		if err == nil {
+			onReady(signer, own.Lock)
+			return
+		}
+	}
+	promptWalletKey(app, window, walletID, func(privateKey solana.PrivateKey) {
+		onReady(own.Unlock(walletID, privateKey), own.Lock)
+	}, nil)
+}
+
+// promptWalletKey asks for the password of walletID and calls onKey with its
+// decrypted key, or onCancel, if set, when the prompt is dismissed or the
+// password is wrong.
+func promptWalletKey(app fyne.App, window fyne.Window, walletID string, onKey func(solana.PrivateKey), onCancel func(error)) {
+	passwordEntry := widget.NewPasswordEntry()
+	passwordEntry.SetPlaceHolder("Enter wallet password")
+
+	dialog.ShowCustomConfirm("Unlock Wallet", "Unlock", "Cancel", passwordEntry, func(unlock bool) {
+		if !unlock {
+			if onCancel != nil {
+				onCancel(errUnlockCancelled)
+			}
+			return
+		}
+
+		privateKey, err := unlockWallet(app, walletID, passwordEntry.Text)
+		if err != nil {
+			dialog.ShowError(err, window)
+			if onCancel != nil {
+				onCancel(err)
+			}
+			return
+		}
+		onKey(privateKey)
+	}, window)
+}
@@ -9,7 +9,11 @@ import (
 type Sidebar struct {
 	widget.BaseWidget
 	OnHomeClicked           func()
+	OnPortfolioClicked      func()
 	OnSendClicked           func()
+	OnCollectiblesClicked   func()
+	OnStakingClicked        func()
+	OnHistoryClicked        func()
 	OnWalletClicked         func()
 	OnAddressBookClicked    func()
 	OnTxHistoryClicked      func()
@@ -19,6 +23,8 @@ type Sidebar struct {
 	OnTxInspectorClicked    func()
 	OnMultisigCreateClicked func()
 	OnMultisigInfoClicked   func()
+	OnNetworkClicked        func()
+	OnLocalAPIClicked       func()
 }
 
 func NewSidebar() *Sidebar {
@@ -33,11 +39,31 @@ func (s *Sidebar) CreateRenderer() fyne.WidgetRenderer {
 			s.OnHomeClicked()
 		}
 	})
+	portfolioBtn := widget.NewButton("Portfolio", func() {
+		if s.OnPortfolioClicked != nil {
+			s.OnPortfolioClicked()
+		}
+	})
 	sendBtn := widget.NewButton("Send", func() {
 		if s.OnSendClicked != nil {
 			s.OnSendClicked()
 		}
 	})
+	collectiblesBtn := widget.NewButton("Collectibles", func() {
+		if s.OnCollectiblesClicked != nil {
+			s.OnCollectiblesClicked()
+		}
+	})
+	stakingBtn := widget.NewButton("Staking", func() {
+		if s.OnStakingClicked != nil {
+			s.OnStakingClicked()
+		}
+	})
+	historyBtn := widget.NewButton("History", func() {
+		if s.OnHistoryClicked != nil {
+			s.OnHistoryClicked()
+		}
+	})
 	walletBtn := widget.NewButton("Wallet", func() {
 		if s.OnWalletClicked != nil {
 			s.OnWalletClicked()
@@ -77,16 +103,34 @@ func (s *Sidebar) CreateRenderer() fyne.WidgetRenderer {
 		}
 	})
 
+	networkBtn := widget.NewButton("Network", func() {
+		if s.OnNetworkClicked != nil {
+			s.OnNetworkClicked()
+		}
+	})
+
+	localAPIBtn := widget.NewButton("Local API", func() {
+		if s.OnLocalAPIClicked != nil {
+			s.OnLocalAPIClicked()
+		}
+	})
+
 	content := container.NewVBox(
 		homeBtn,
+		portfolioBtn,
 		sendBtn,
+		collectiblesBtn,
+		stakingBtn,
+		historyBtn,
 		walletBtn,
 		calypsoBtn,
 		conditionalBotBtn,
 		hardwareSignBtn,
 		txInspectorBtn,
 		OnMultisigCreateClickedBtn,
-		infoBtn)
+		infoBtn,
+		networkBtn,
+		localAPIBtn)
 
 	return widget.NewSimpleRenderer(content)
 }
@@ -0,0 +1,148 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+
+	"unruggable-go/internal/display"
+	"unruggable-go/internal/simulate"
+)
+
+// confirmWithPreview simulates instructions paid by payer and asks to
+// confirm text next to what the transaction would do to the wallet. If the
+// simulation fails or cannot run, sending needs a second, explicit override.
+// It blocks on RPC calls, so call it from a goroutine.
+func confirmWithPreview(ctx context.Context, client *rpc.Client, window fyne.Window, title, text string, payer solana.PublicKey, instructions []solana.Instruction, onConfirm func()) {
+	var preview *simulate.Preview
+	tx, err := simulate.Unsigned(payer, instructions)
+	if err == nil {
+		preview, err = simulate.Run(ctx, client, tx, payer)
+	}
+	showPreview(window, title, text, preview, err, "Sign & Send", "Cancel", func(confirmed bool) {
+		if confirmed {
+			onConfirm()
+		}
+	})
+}
+
+// showPreview asks to confirm text next to the outcome of a simulation,
+// which failed to run if simErr is set, and passes the answer to onDecide.
+// Confirming a transaction that would fail needs a second, explicit
+// override. The dialog is returned so it can be hidden.
+func showPreview(window fyne.Window, title, text string, preview *simulate.Preview, simErr error, confirm, dismiss string, onDecide func(bool)) dialog.Dialog {
+	var problem string
+	switch {
+	case simErr != nil:
+		problem = fmt.Sprintf("Simulation unavailable: %v", simErr)
+	case preview.Failed():
+		problem = "Simulation failed: " + preview.Err
+	}
+
+	summary := widget.NewLabel(text)
+	summary.Wrapping = fyne.TextWrapWord
+	content := container.NewVBox(summary)
+	if problem != "" {
+		warning := widget.NewLabelWithStyle(problem, fyne.TextAlignLeading, fyne.TextStyle{Bold: true})
+		warning.Importance = widget.DangerImportance
+		warning.Wrapping = fyne.TextWrapWord
+		content.Add(warning)
+	}
+	if preview != nil {
+		content.Add(simulationDetails(preview))
+	}
+
+	confirmLabel := confirm
+	if problem != "" {
+		confirmLabel = "Sign Anyway"
+	}
+	d := dialog.NewCustomConfirm(title, confirmLabel, dismiss, container.NewVScroll(content), func(confirmed bool) {
+		if !confirmed || problem == "" {
+			onDecide(confirmed)
+			return
+		}
+		dialog.ShowConfirm("Sign Anyway?",
+			"The transaction could not be simulated successfully and will likely fail, costing its fee.\n\nSign it anyway?",
+			onDecide, window)
+	}, window)
+	d.Resize(fyne.NewSize(600, 520))
+	d.Show()
+	return d
+}
+
+// simulationDetails lays out the balance changes, fees, compute and logs of
+// preview.
+func simulationDetails(preview *simulate.Preview) fyne.CanvasObject {
+	details := container.NewVBox()
+
+	if !preview.Failed() {
+		changes := container.NewGridWithColumns(3,
+			widget.NewLabelWithStyle("Asset", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+			widget.NewLabelWithStyle("Change", fyne.TextAlignTrailing, fyne.TextStyle{Bold: true}),
+			widget.NewLabelWithStyle("After", fyne.TextAlignTrailing, fyne.TextStyle{Bold: true}),
+		)
+		addRow := func(asset, change, after string, delta float64) {
+			changeLabel := widget.NewLabelWithStyle(change, fyne.TextAlignTrailing, fyne.TextStyle{})
+			switch {
+			case delta > 0:
+				changeLabel.Importance = widget.SuccessImportance
+			case delta < 0:
+				changeLabel.Importance = widget.DangerImportance
+			}
+			changes.Add(widget.NewLabel(asset))
+			changes.Add(changeLabel)
+			changes.Add(widget.NewLabelWithStyle(after, fyne.TextAlignTrailing, fyne.TextStyle{}))
+		}
+		for _, change := range preview.SOL {
+			asset := "SOL"
+			if change.Stake {
+				asset = "SOL (stake " + shortenAddress(change.Account.String()) + ")"
+			}
+			addRow(asset, display.Signed(display.Lamports(change.Delta())),
+				display.Lamports(int64(change.After)), float64(change.Delta()))
+		}
+		for _, change := range preview.Tokens {
+			addRow(tokenSymbol(change.Mint),
+				display.Signed(strconv.FormatFloat(change.Delta(), 'f', -1, 64)),
+				strconv.FormatFloat(change.Amount(), 'f', -1, 64), change.Delta())
+		}
+		details.Add(widget.NewCard("Balance changes", "Accounts owned by this wallet", changes))
+	}
+
+	costs := widget.NewForm(
+		widget.NewFormItem("Network fee", widget.NewLabel(fmt.Sprintf("%s SOL (base %s + priority %s)",
+			display.Lamports(int64(preview.Fee())),
+			display.Lamports(int64(preview.BaseFee)),
+			display.Lamports(int64(preview.Budget.PriorityFee()))))),
+		widget.NewFormItem("Compute", widget.NewLabel(fmt.Sprintf("%d of %d CU", preview.UnitsConsumed, preview.Budget.Units))),
+	)
+	details.Add(widget.NewCard("Costs", "", costs))
+
+	if len(preview.Logs) > 0 {
+		logs := widget.NewLabelWithStyle(strings.Join(preview.Logs, "\n"), fyne.TextAlignLeading, fyne.TextStyle{Monospace: true})
+		logs.Wrapping = fyne.TextWrapBreak
+		details.Add(widget.NewAccordion(widget.NewAccordionItem(fmt.Sprintf("Program logs (%d)", len(preview.Logs)), logs)))
+	}
+	return details
+}
+
+// tokenSymbol returns the symbol of mint from the selected wallet's
+// holdings, or its shortened address.
+func tokenSymbol(mint solana.PublicKey) string {
+	if balances := GetGlobalState().GetWalletBalances(); balances != nil {
+		for _, holding := range balances.Assets {
+			if holding.Address == mint.String() && holding.Symbol != "" {
+				return holding.Symbol
+			}
+		}
+	}
+	return shortenAddress(mint.String())
+}
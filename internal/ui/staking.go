@@ -0,0 +1,667 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/shopspring/decimal"
+
+	"unruggable-go/internal/display"
+	"unruggable-go/internal/fee"
+	"unruggable-go/internal/session"
+	"unruggable-go/internal/stake"
+	"unruggable-go/internal/transfer"
+)
+
+// stakeRewardEpochs is how many past epochs of rewards are shown per account.
+const stakeRewardEpochs = 5
+
+// StakingScreen lists the native stake accounts of the selected wallet and
+// creates, delegates, deactivates, withdraws, splits and merges them.
+type StakingScreen struct {
+	window      fyne.Window
+	app         fyne.App
+	walletLabel *widget.Label
+	epochLabel  *widget.Label
+	status      *widget.Label
+	list        *fyne.Container
+
+	mu         sync.Mutex
+	epoch      uint64
+	accounts   []stake.Account
+	rewards    map[solana.PublicKey][]stake.Reward
+	validators map[solana.PublicKey]stake.Validator // by vote account
+	ranked     []stake.Validator                    // most stake first
+}
+
+// NewStakingScreen creates the staking screen.
+func NewStakingScreen(window fyne.Window, app fyne.App) fyne.CanvasObject {
+	s := &StakingScreen{
+		window:      window,
+		app:         app,
+		walletLabel: widget.NewLabel("No wallet selected"),
+		epochLabel:  widget.NewLabel(""),
+		status:      widget.NewLabel(""),
+		list:        container.NewVBox(),
+	}
+
+	refreshButton := widget.NewButton("Refresh", func() {
+		s.load(GetGlobalState().GetSelectedWallet())
+	})
+	stakeButton := widget.NewButton("Stake SOL", s.showStakeDialog)
+	stakeButton.Importance = widget.HighImportance
+
+	bus := GetGlobalState().Events
+	bus.WalletSelected.Subscribe(func(e WalletSelectedEvent) {
+		s.load(e.WalletID)
+	})
+	bus.NetworkChanged.Subscribe(func(NetworkChangedEvent) {
+		// Validators belong to the previous cluster
+		s.mu.Lock()
+		s.validators, s.ranked = nil, nil
+		s.mu.Unlock()
+		s.load(GetGlobalState().GetSelectedWallet())
+	})
+	s.load(GetGlobalState().GetSelectedWallet())
+
+	top := container.NewVBox(
+		widget.NewLabelWithStyle("Staking", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		s.walletLabel,
+		s.epochLabel,
+		s.status,
+	)
+	return container.NewBorder(top, container.NewGridWithColumns(2, refreshButton, stakeButton), nil, nil, container.NewVScroll(s.list))
+}
+
+// load fetches the stake accounts, rewards and validators for walletID and
+// shows them if it is still selected.
+func (s *StakingScreen) load(walletID string) {
+	if walletID == "" {
+		s.walletLabel.SetText("No wallet selected")
+		s.status.SetText("Please select a wallet to view its stake accounts.")
+		s.epochLabel.SetText("")
+		s.list.Objects = nil
+		s.list.Refresh()
+		return
+	}
+	owner, err := solana.PublicKeyFromBase58(walletID)
+	if err != nil {
+		s.status.SetText(fmt.Sprintf("Invalid wallet address: %v", err))
+		return
+	}
+	s.walletLabel.SetText(fmt.Sprintf("Wallet: %s", walletID))
+	s.status.SetText("Loading stake accounts...")
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+		client := newRPCClient()
+
+		info, err := client.GetEpochInfo(ctx, rpc.CommitmentConfirmed)
+		if err != nil {
+			s.status.SetText(fmt.Sprintf("Error getting epoch: %v", err))
+			return
+		}
+		accounts, err := stake.List(ctx, client, owner)
+		if err != nil {
+			s.status.SetText(fmt.Sprintf("Error loading stake accounts: %v", err))
+			return
+		}
+		addresses := make([]solana.PublicKey, len(accounts))
+		for i, account := range accounts {
+			addresses[i] = account.Address
+		}
+		rewards, err := stake.Rewards(ctx, client, addresses, info.Epoch, stakeRewardEpochs)
+		if err != nil {
+			log.Printf("Error loading stake rewards: %v", err)
+		}
+		if err := s.loadValidators(ctx); err != nil {
+			log.Printf("Error loading validators: %v", err)
+		}
+		if walletID != GetGlobalState().GetSelectedWallet() {
+			return
+		}
+
+		s.mu.Lock()
+		s.epoch = info.Epoch
+		s.accounts = accounts
+		s.rewards = rewards
+		s.mu.Unlock()
+
+		progress := 0.0
+		if info.SlotsInEpoch > 0 {
+			progress = float64(info.SlotIndex) / float64(info.SlotsInEpoch) * 100
+		}
+		s.epochLabel.SetText(fmt.Sprintf("Epoch %d (%.0f%% complete)", info.Epoch, progress))
+		if len(accounts) == 0 {
+			s.status.SetText("No stake accounts found for this wallet.")
+		} else {
+			s.status.SetText(fmt.Sprintf("%d stake accounts", len(accounts)))
+		}
+		s.render(owner)
+	}()
+}
+
+// loadValidators fetches the validators of the cluster once.
+func (s *StakingScreen) loadValidators(ctx context.Context) error {
+	s.mu.Lock()
+	loaded := s.validators != nil
+	s.mu.Unlock()
+	if loaded {
+		return nil
+	}
+	ranked, err := stake.Validators(ctx, newRPCClient())
+	if err != nil {
+		return err
+	}
+	validators := make(map[solana.PublicKey]stake.Validator, len(ranked))
+	for _, v := range ranked {
+		validators[v.Vote] = v
+	}
+	s.mu.Lock()
+	s.validators, s.ranked = validators, ranked
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *StakingScreen) render(owner solana.PublicKey) {
+	s.mu.Lock()
+	epoch, accounts, rewards := s.epoch, s.accounts, s.rewards
+	s.mu.Unlock()
+
+	var cards []fyne.CanvasObject
+	for _, account := range accounts {
+		cards = append(cards, s.card(owner, account, epoch, rewards[account.Address]))
+	}
+	s.list.Objects = cards
+	s.list.Refresh()
+}
+
+func (s *StakingScreen) card(owner solana.PublicKey, account stake.Account, epoch uint64, rewards []stake.Reward) fyne.CanvasObject {
+	state := account.State(epoch)
+	lines := []string{
+		fmt.Sprintf("Balance: %s SOL", display.Lamports(int64(account.Lamports))),
+	}
+	if account.Delegated {
+		lines = append(lines, fmt.Sprintf("Delegated: %s SOL to %s", display.Lamports(int64(account.Stake)), s.validatorName(account.Voter)))
+		switch state {
+		case stake.StateActivating:
+			lines = append(lines, fmt.Sprintf("Active from epoch %d", account.ActivationEpoch+1))
+		case stake.StateDeactivating:
+			lines = append(lines, fmt.Sprintf("Withdrawable from epoch %d", account.DeactivationEpoch+1))
+		}
+	}
+	if account.Locked(time.Now(), epoch) {
+		lines = append(lines, "Locked up: withdrawals need the custodian")
+	}
+	if account.Staker != owner {
+		lines = append(lines, fmt.Sprintf("Staker: %s", shortenAddress(account.Staker.String())))
+	}
+	if account.Withdrawer != owner {
+		lines = append(lines, fmt.Sprintf("Withdrawer: %s", shortenAddress(account.Withdrawer.String())))
+	}
+	if len(rewards) == 0 {
+		lines = append(lines, "No rewards in recent epochs")
+	} else {
+		var parts []string
+		for _, r := range rewards {
+			parts = append(parts, fmt.Sprintf("epoch %d +%s", r.Epoch, display.Lamports(int64(r.Lamports))))
+		}
+		lines = append(lines, "Rewards (SOL): "+strings.Join(parts, ", "))
+	}
+
+	isStaker := account.Staker == owner
+	isWithdrawer := account.Withdrawer == owner
+	var buttons []fyne.CanvasObject
+	if isStaker && (state == stake.StateInitialized || state == stake.StateInactive) {
+		buttons = append(buttons, widget.NewButton("Delegate", func() { s.showDelegateDialog(account) }))
+	}
+	if isStaker && (state == stake.StateActive || state == stake.StateActivating) {
+		buttons = append(buttons, widget.NewButton("Deactivate", func() { s.deactivate(account) }))
+	}
+	if isWithdrawer && account.Withdrawable(epoch) > 0 {
+		buttons = append(buttons, widget.NewButton("Withdraw", func() { s.showWithdrawDialog(account) }))
+	}
+	if isStaker && state != stake.StateDeactivating {
+		buttons = append(buttons, widget.NewButton("Split", func() { s.showSplitDialog(account) }))
+		buttons = append(buttons, widget.NewButton("Merge", func() { s.showMergeDialog(account) }))
+	}
+
+	title := widget.NewLabelWithStyle(fmt.Sprintf("%s — %s", shortenAddress(account.Address.String()), state), fyne.TextAlignLeading, fyne.TextStyle{Bold: true})
+	body := widget.NewLabel(strings.Join(lines, "\n"))
+	body.Wrapping = fyne.TextWrapWord
+	return widget.NewCard("", "", container.NewVBox(title, body, container.NewHBox(buttons...)))
+}
+
+// validatorName describes the vote account vote with its commission.
+func (s *StakingScreen) validatorName(vote solana.PublicKey) string {
+	s.mu.Lock()
+	v, ok := s.validators[vote]
+	s.mu.Unlock()
+	if !ok {
+		return shortenAddress(vote.String())
+	}
+	name := fmt.Sprintf("%s (%d%% commission)", shortenAddress(vote.String()), v.Commission)
+	if v.Delinquent {
+		name += " — delinquent"
+	}
+	return name
+}
+
+// validatorPicker returns a searchable validator list and a func returning
+// the picked one.
+func (s *StakingScreen) validatorPicker() (fyne.CanvasObject, func() (stake.Validator, bool)) {
+	s.mu.Lock()
+	all := s.ranked
+	s.mu.Unlock()
+
+	// Delinquent validators earn nothing, list them last
+	ranked := make([]stake.Validator, len(all))
+	copy(ranked, all)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return !ranked[i].Delinquent && ranked[j].Delinquent
+	})
+
+	shown := ranked
+	var picked *stake.Validator
+	selected := widget.NewLabel("No validator selected")
+
+	list := widget.NewList(
+		func() int { return len(shown) },
+		func() fyne.CanvasObject { return widget.NewLabel("") },
+		func(id widget.ListItemID, item fyne.CanvasObject) {
+			item.(*widget.Label).SetText(validatorSummary(shown[id]))
+		},
+	)
+	list.OnSelected = func(id widget.ListItemID) {
+		v := shown[id]
+		picked = &v
+		selected.SetText("Selected: " + validatorSummary(v))
+	}
+
+	search := widget.NewEntry()
+	search.SetPlaceHolder("Search vote or identity address")
+	search.OnChanged = func(text string) {
+		text = strings.TrimSpace(text)
+		shown = ranked
+		if text != "" {
+			shown = nil
+			for _, v := range ranked {
+				if strings.Contains(v.Vote.String(), text) || strings.Contains(v.Identity.String(), text) {
+					shown = append(shown, v)
+				}
+			}
+		}
+		list.UnselectAll()
+		list.Refresh()
+	}
+
+	note := widget.NewLabel("Validators by active stake; skip rate covers the current epoch.")
+	content := container.NewBorder(container.NewVBox(note, search), selected, nil, nil, list)
+	return content, func() (stake.Validator, bool) {
+		if picked == nil {
+			return stake.Validator{}, false
+		}
+		return *picked, true
+	}
+}
+
+func validatorSummary(v stake.Validator) string {
+	skip := "n/a"
+	if v.SkipRate >= 0 {
+		skip = fmt.Sprintf("%.1f%%", v.SkipRate*100)
+	}
+	summary := fmt.Sprintf("%s  commission %d%%  skip %s  stake %.0f SOL",
+		shortenAddress(v.Vote.String()), v.Commission, skip, float64(v.ActivatedStake)/float64(solana.LAMPORTS_PER_SOL))
+	if v.Delinquent {
+		summary += "  delinquent"
+	}
+	return summary
+}
+
+// withValidators runs fn once the validators are loaded.
+func (s *StakingScreen) withValidators(fn func()) {
+	s.mu.Lock()
+	loaded := s.validators != nil
+	s.mu.Unlock()
+	if loaded {
+		fn()
+		return
+	}
+	s.status.SetText("Loading validators...")
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+		if err := s.loadValidators(ctx); err != nil {
+			s.status.SetText("Failed to load validators")
+			dialog.ShowError(err, s.window)
+			return
+		}
+		s.status.SetText("")
+		fn()
+	}()
+}
+
+// showStakeDialog creates a stake account funded from the wallet and
+// delegates it to a picked validator.
+func (s *StakingScreen) showStakeDialog() {
+	walletID := GetGlobalState().GetSelectedWallet()
+	if walletID == "" {
+		dialog.ShowInformation("No Wallet", "Please select a wallet first.", s.window)
+		return
+	}
+	s.withValidators(func() {
+		amount := widget.NewEntry()
+		amount.SetPlaceHolder("Amount to stake (SOL)")
+		picker, picked := s.validatorPicker()
+		content := container.NewBorder(widget.NewForm(widget.NewFormItem("Amount", amount)), nil, nil, nil, picker)
+
+		d := dialog.NewCustomConfirm("Stake SOL", "Continue", "Cancel", content, func(ok bool) {
+			if !ok {
+				return
+			}
+			lamports, err := parseLamports(amount.Text)
+			if err != nil {
+				dialog.ShowError(err, s.window)
+				return
+			}
+			validator, ok := picked()
+			if !ok {
+				dialog.ShowError(fmt.Errorf("no validator selected"), s.window)
+				return
+			}
+			go s.confirmStake(walletID, lamports, validator)
+		}, s.window)
+		d.Resize(fyne.NewSize(600, 500))
+		d.Show()
+	})
+}
+
+func (s *StakingScreen) confirmStake(walletID string, lamports uint64, validator stake.Validator) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	rent, err := stake.RentExemptReserve(ctx, newRPCClient())
+	if err != nil {
+		dialog.ShowError(err, s.window)
+		return
+	}
+	seed := stake.NewSeed()
+	text := fmt.Sprintf("Stake %s SOL with %s?\n\nA new stake account also holds %s SOL for rent, returned when it is withdrawn.",
+		display.Lamports(int64(lamports)), validatorSummary(validator), display.Lamports(int64(rent)))
+	s.confirmAndSubmit("Confirm Stake", text, walletID, func(ctx context.Context, client *rpc.Client, owner solana.PublicKey) ([]solana.Instruction, error) {
+		instructions, _, err := stake.CreateAndDelegate(owner, seed, lamports+rent, validator.Vote)
+		return instructions, err
+	})
+}
+
+func (s *StakingScreen) showDelegateDialog(account stake.Account) {
+	walletID := GetGlobalState().GetSelectedWallet()
+	s.withValidators(func() {
+		picker, picked := s.validatorPicker()
+		d := dialog.NewCustomConfirm("Delegate Stake", "Continue", "Cancel", picker, func(ok bool) {
+			if !ok {
+				return
+			}
+			validator, ok := picked()
+			if !ok {
+				dialog.ShowError(fmt.Errorf("no validator selected"), s.window)
+				return
+			}
+			text := fmt.Sprintf("Delegate %s to %s?", shortenAddress(account.Address.String()), validatorSummary(validator))
+			s.confirmAndSubmit("Confirm Delegation", text, walletID, func(_ context.Context, _ *rpc.Client, owner solana.PublicKey) ([]solana.Instruction, error) {
+				return []solana.Instruction{stake.Delegate(account.Address, validator.Vote, owner)}, nil
+			})
+		}, s.window)
+		d.Resize(fyne.NewSize(600, 500))
+		d.Show()
+	})
+}
+
+func (s *StakingScreen) deactivate(account stake.Account) {
+	walletID := GetGlobalState().GetSelectedWallet()
+	text := fmt.Sprintf("Deactivate %s?\n\nThe stake stops earning rewards and can be withdrawn once the current epoch ends.",
+		shortenAddress(account.Address.String()))
+	s.confirmAndSubmit("Confirm Deactivation", text, walletID, func(_ context.Context, _ *rpc.Client, owner solana.PublicKey) ([]solana.Instruction, error) {
+		return []solana.Instruction{stake.Deactivate(account.Address, owner)}, nil
+	})
+}
+
+func (s *StakingScreen) showWithdrawDialog(account stake.Account) {
+	walletID := GetGlobalState().GetSelectedWallet()
+	s.mu.Lock()
+	epoch := s.epoch
+	s.mu.Unlock()
+	if account.Locked(time.Now(), epoch) {
+		dialog.ShowError(fmt.Errorf("stake account %s is locked up", shortenAddress(account.Address.String())), s.window)
+		return
+	}
+	available := account.Withdrawable(epoch)
+
+	amount := widget.NewEntry()
+	amount.SetText(display.Lamports(int64(available)))
+	recipient := widget.NewEntry()
+	recipient.SetText(walletID)
+
+	dialog.ShowForm("Withdraw Stake", "Continue", "Cancel", []*widget.FormItem{
+		widget.NewFormItem("Amount (SOL)", amount),
+		widget.NewFormItem("Recipient", recipient),
+	}, func(ok bool) {
+		if !ok {
+			return
+		}
+		lamports, err := parseLamports(amount.Text)
+		if err != nil {
+			dialog.ShowError(err, s.window)
+			return
+		}
+		if lamports > available {
+			dialog.ShowError(fmt.Errorf("at most %s SOL can be withdrawn", display.Lamports(int64(available))), s.window)
+			return
+		}
+		to, err := solana.PublicKeyFromBase58(strings.TrimSpace(recipient.Text))
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("invalid recipient: %v", err), s.window)
+			return
+		}
+		text := fmt.Sprintf("Withdraw %s SOL from %s to %s?", display.Lamports(int64(lamports)), shortenAddress(account.Address.String()), shortenAddress(to.String()))
+		if lamports == account.Lamports {
+			text += "\n\nThe stake account is closed."
+		}
+		s.confirmAndSubmit("Confirm Withdrawal", text, walletID, func(_ context.Context, _ *rpc.Client, owner solana.PublicKey) ([]solana.Instruction, error) {
+			return []solana.Instruction{stake.Withdraw(account.Address, to, owner, lamports)}, nil
+		})
+	}, s.window)
+}
+
+func (s *StakingScreen) showSplitDialog(account stake.Account) {
+	walletID := GetGlobalState().GetSelectedWallet()
+	amount := widget.NewEntry()
+	amount.SetPlaceHolder("Amount to move (SOL)")
+
+	dialog.ShowForm("Split Stake", "Continue", "Cancel", []*widget.FormItem{
+		widget.NewFormItem("Amount", amount),
+	}, func(ok bool) {
+		if !ok {
+			return
+		}
+		lamports, err := parseLamports(amount.Text)
+		if err != nil {
+			dialog.ShowError(err, s.window)
+			return
+		}
+		if lamports >= account.Lamports {
+			dialog.ShowError(fmt.Errorf("split amount must be less than the %s SOL balance", display.Lamports(int64(account.Lamports))), s.window)
+			return
+		}
+		seed := stake.NewSeed()
+		text := fmt.Sprintf("Move %s SOL of %s to a new stake account?\n\nThe wallet pays the rent reserve of the new account.",
+			display.Lamports(int64(lamports)), shortenAddress(account.Address.String()))
+		s.confirmAndSubmit("Confirm Split", text, walletID, func(ctx context.Context, client *rpc.Client, owner solana.PublicKey) ([]solana.Instruction, error) {
+			rent, err := stake.RentExemptReserve(ctx, client)
+			if err != nil {
+				return nil, err
+			}
+			instructions, _, err := stake.Split(account.Address, owner, seed, lamports, rent)
+			return instructions, err
+		})
+	}, s.window)
+}
+
+// showMergeDialog merges another stake account of the wallet into account.
+func (s *StakingScreen) showMergeDialog(account stake.Account) {
+	walletID := GetGlobalState().GetSelectedWallet()
+	s.mu.Lock()
+	epoch, accounts := s.epoch, s.accounts
+	s.mu.Unlock()
+
+	var names []string
+	sources := make(map[string]stake.Account)
+	for _, source := range accounts {
+		if stake.CanMerge(account, source, epoch) != nil {
+			continue
+		}
+		name := fmt.Sprintf("%s — %s SOL, %s", shortenAddress(source.Address.String()), display.Lamports(int64(source.Lamports)), source.State(epoch))
+		names = append(names, name)
+		sources[name] = source
+	}
+	if len(names) == 0 {
+		dialog.ShowInformation("Merge Stake", "No other stake account of this wallet can be merged into this one.", s.window)
+		return
+	}
+
+	sourceSelect := widget.NewSelect(names, nil)
+	dialog.ShowForm("Merge Stake", "Continue", "Cancel", []*widget.FormItem{
+		widget.NewFormItem("Merge", sourceSelect),
+		widget.NewFormItem("Into", widget.NewLabel(shortenAddress(account.Address.String()))),
+	}, func(ok bool) {
+		if !ok {
+			return
+		}
+		source, found := sources[sourceSelect.Selected]
+		if !found {
+			dialog.ShowError(fmt.Errorf("no stake account selected"), s.window)
+			return
+		}
+		text := fmt.Sprintf("Merge %s into %s?\n\n%s is closed and its balance moves to %s.",
+			shortenAddress(source.Address.String()), shortenAddress(account.Address.String()),
+			shortenAddress(source.Address.String()), shortenAddress(account.Address.String()))
+		s.confirmAndSubmit("Confirm Merge", text, walletID, func(_ context.Context, _ *rpc.Client, owner solana.PublicKey) ([]solana.Instruction, error) {
+			return []solana.Instruction{stake.Merge(account.Address, source.Address, owner)}, nil
+		})
+	}, s.window)
+}
+
+// stakeBuilder returns the instructions of a staking action by owner.
+type stakeBuilder func(ctx context.Context, client *rpc.Client, owner solana.PublicKey) ([]solana.Instruction, error)
+
+// confirmAndSubmit prepares the instructions build returns, asks to confirm
+// text with their priority fee and simulated effects, unlocks walletID and
+// sends them.
+func (s *StakingScreen) confirmAndSubmit(title, text, walletID string, build stakeBuilder) {
+	owner, err := solana.PublicKeyFromBase58(walletID)
+	if err != nil {
+		dialog.ShowError(fmt.Errorf("invalid wallet: %v", err), s.window)
+		return
+	}
+	go func() {
+		ctx := context.Background()
+		client := newRPCClient()
+
+		s.status.SetText("Preparing staking transaction...")
+		instructions, err := build(ctx, client, owner)
+		var budget fee.Budget
+		if err == nil {
+			instructions, budget, err = fee.Prepare(ctx, client, priorityFeeStrategy(), owner, instructions)
+		}
+		s.status.SetText("")
+		if err != nil {
+			dialog.ShowError(err, s.window)
+			return
+		}
+
+		s.status.SetText("Simulating staking transaction...")
+		confirmWithPreview(ctx, client, s.window, title, text+"\n\n"+budget.String(), owner, instructions, func() {
+			requestSigner(s.app, s.window, walletID, func(signer *session.Signer) {
+				go s.execute(walletID, signer, instructions)
+			}, nil)
+		})
+		s.status.SetText("")
+	}()
+}
+
+func (s *StakingScreen) execute(walletID string, signer *session.Signer, instructions []solana.Instruction) {
+	const confirmTimeout = time.Minute
+	ctx := context.Background()
+	client := newRPCClient()
+
+	fail := func(err error) {
+		s.status.SetText("Staking transaction failed")
+		dialog.ShowError(err, s.window)
+	}
+
+	s.status.SetText("Sending staking transaction...")
+	tx, err := transfer.NewTransaction(ctx, client, signer.PublicKey(), instructions)
+	if err != nil {
+		fail(err)
+		return
+	}
+	if err := signer.SignTransaction(tx); err != nil {
+		fail(fmt.Errorf("error signing staking transaction: %v", err))
+		return
+	}
+	sig, err := transfer.Submit(ctx, client, activeNetwork().JitoURL, tx, signer)
+	if err != nil {
+		fail(err)
+		return
+	}
+	publishTxStatus("Staking", walletID, sig.String(), nil)
+	s.status.SetText(fmt.Sprintf("Transaction sent with ID: %s", shortenAddress(sig.String())))
+
+	confirmCtx, cancel := context.WithTimeout(ctx, confirmTimeout)
+	defer cancel()
+	if _, err := transfer.WaitConfirmed(confirmCtx, client, sig); err != nil {
+		publishTxStatus("Staking", walletID, sig.String(), err)
+		fail(err)
+		return
+	}
+	GetGlobalState().Events.TxStatus.Publish(TxStatusEvent{
+		Source:   "Staking",
+		WalletID: walletID,
+		ID:       sig.String(),
+		Status:   TxConfirmed,
+	})
+	if walletID == GetGlobalState().GetSelectedWallet() {
+		s.load(walletID)
+	}
+}
+
+// parseLamports parses a positive SOL amount into lamports.
+func parseLamports(text string) (uint64, error) {
+	amount, err := decimal.NewFromString(strings.TrimSpace(text))
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount: %v", err)
+	}
+	if !amount.IsPositive() {
+		return 0, fmt.Errorf("amount must be positive")
+	}
+	lamports := amount.Shift(9)
+	if !lamports.IsInteger() {
+		return 0, fmt.Errorf("amount has more than 9 decimals")
+	}
+	if lamports.GreaterThan(decimal.NewFromUint64(math.MaxUint64)) {
+		return 0, fmt.Errorf("amount is too large")
+	}
+	return lamports.BigInt().Uint64(), nil
+}
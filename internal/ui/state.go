@@ -2,14 +2,21 @@ package ui
 
 import (
 	"sync"
+
+	"fyne.io/fyne/v2"
+
+	"unruggable-go/internal/network"
 )
 
 // AppState holds the global application state.
 type AppState struct {
 	SelectedWallet string
 	CurrentView    string
-	RPCURL         string
+	Network        network.Profile
 	WalletBalances *WalletResponse // Pointer to allow nil checks
+	Events         *EventBus
+
+	prefs fyne.Preferences // set by InitAppState; persists wallet and view
 }
 
 // Singleton pattern for global state with thread safety.
@@ -27,18 +34,30 @@ func GetGlobalState() *AppState {
 		globalState = &AppState{
 			SelectedWallet: "",
 			CurrentView:    "",
-			RPCURL:         "https://special-blue-fog.solana-mainnet.quiknode.pro/d009d548b4b9dd9f062a8124a868fb915937976c/",
+			Network:        network.Default(),
 			WalletBalances: nil,
+			Events:         &EventBus{},
 		}
 	}
 	return globalState
 }
 
-// UpdateWalletBalances updates the balances in the global state.
-func (s *AppState) UpdateWalletBalances(balances *WalletResponse) {
+// UpdateWalletBalances stores freshly fetched balances for walletID and
+// publishes them. Balances of a wallet that is no longer selected are
+// published but not stored. networkName is the network they were fetched
+// on; if another one was activated since, they are dropped.
+func (s *AppState) UpdateWalletBalances(walletID, networkName string, balances *WalletResponse) {
 	globalStateLock.Lock()
-	defer globalStateLock.Unlock()
-	s.WalletBalances = balances
+	if s.Network.Name != networkName {
+		globalStateLock.Unlock()
+		return
+	}
+	if s.SelectedWallet == walletID {
+		s.WalletBalances = balances
+	}
+	globalStateLock.Unlock()
+
+	s.Events.BalancesUpdated.Publish(BalancesUpdatedEvent{WalletID: walletID, Balances: balances})
 }
 
 // GetWalletBalances retrieves the current balances.
@@ -49,11 +68,26 @@ func (s *AppState) GetWalletBalances() *WalletResponse {
 }
 
 // SetSelectedWallet updates the selected wallet and resets balances.
+// Switching to another wallet locks the unlock session.
 func (s *AppState) SetSelectedWallet(wallet string) {
 	globalStateLock.Lock()
-	defer globalStateLock.Unlock()
+	previous := s.SelectedWallet
+	switched := previous != wallet
 	s.SelectedWallet = wallet
-	s.WalletBalances = nil // Reset balances until refreshed
+	if switched {
+		s.WalletBalances = nil // Reset balances until refreshed
+	}
+	prefs := s.prefs
+	globalStateLock.Unlock()
+
+	if prefs != nil {
+		prefs.SetString(selectedWalletKey, wallet)
+	}
+
+	if switched {
+		GetSession().Lock()
+		s.Events.WalletSelected.Publish(WalletSelectedEvent{WalletID: wallet, Previous: previous})
+	}
 }
 
 // GetSelectedWallet returns the currently selected wallet.
@@ -66,8 +100,13 @@ func (s *AppState) GetSelectedWallet() string {
 // SetCurrentView updates the current view.
 func (s *AppState) SetCurrentView(view string) {
 	globalStateLock.Lock()
-	defer globalStateLock.Unlock()
 	s.CurrentView = view
+	prefs := s.prefs
+	globalStateLock.Unlock()
+
+	if prefs != nil {
+		prefs.SetString(lastViewKey, view)
+	}
 }
 
 // GetCurrentView returns the current view.
@@ -76,3 +115,27 @@ func (s *AppState) GetCurrentView() string {
 	defer globalStateLock.Unlock()
 	return s.CurrentView
 }
+
+// SetNetwork switches the active network profile. Balances belong to the
+// previous cluster and are reset.
+func (s *AppState) SetNetwork(profile network.Profile) {
+	globalStateLock.Lock()
+	previous := s.Network
+	s.Network = profile
+	s.WalletBalances = nil
+	globalStateLock.Unlock()
+
+	s.Events.NetworkChanged.Publish(NetworkChangedEvent{Network: profile, Previous: previous})
+}
+
+// GetNetwork returns the active network profile.
+func (s *AppState) GetNetwork() network.Profile {
+	globalStateLock.Lock()
+	defer globalStateLock.Unlock()
+	return s.Network
+}
+
+// GetRPCURL returns the HTTP RPC endpoint of the active network.
+func (s *AppState) GetRPCURL() string {
+	return s.GetNetwork().RPCURL
+}
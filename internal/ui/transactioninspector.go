@@ -3,7 +3,6 @@ package ui
 import (
 	"bytes"
 	"context"
-	"encoding/base64"
 	"fmt"
 	"strings"
 
@@ -12,10 +11,10 @@ import (
 	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
-	bin "github.com/gagliardetto/binary"
 	"github.com/gagliardetto/solana-go"
 	"github.com/gagliardetto/solana-go/rpc"
-	"github.com/mr-tron/base58"
+
+	"unruggable-go/internal/txinspect"
 )
 
 // TransactionInspector represents the transaction inspection screen
@@ -42,7 +41,7 @@ func NewTransactionInspectorScreen(window fyne.Window, app fyne.App) fyne.Canvas
 	inspector := &TransactionInspector{
 		window:   window,
 		app:      app,
-		client:   rpc.New(CALYPSO_ENDPOINT),
+		client:   newRPCClient(),
 		viewMode: "full", // Default view mode
 	}
 
@@ -151,6 +150,10 @@ func NewTransactionInspectorScreen(window fyne.Window, app fyne.App) fyne.Canvas
 		container.NewVScroll(content),
 	)
 
+	GetGlobalState().Events.NetworkChanged.Subscribe(func(NetworkChangedEvent) {
+		inspector.client = newRPCClient()
+	})
+
 	return inspector.container
 }
 
@@ -222,39 +225,12 @@ func (t *TransactionInspector) tryDecodeAutodetect(input string) (*solana.Transa
 
 // decodeBase58 decodes a base58 encoded transaction
 func (t *TransactionInspector) decodeBase58(encoded string) (*solana.Transaction, error) {
-	data, err := base58.Decode(encoded)
-	if err != nil {
-		return nil, fmt.Errorf("failed to decode base58: %v", err)
-	}
-
-	return t.decodeBinary(data)
+	return txinspect.DecodeBase58(encoded)
 }
 
 // decodeBase64 decodes a base64 encoded transaction
 func (t *TransactionInspector) decodeBase64(encoded string) (*solana.Transaction, error) {
-	// Remove any padding that might have been added
-	encoded = strings.TrimSpace(encoded)
-
-	// Try standard base64
-	data, err := base64.StdEncoding.DecodeString(encoded)
-	if err != nil {
-		// Try URL-safe variant
-		data, err = base64.URLEncoding.DecodeString(encoded)
-		if err != nil {
-			return nil, fmt.Errorf("failed to decode base64: %v", err)
-		}
-	}
-
-	return t.decodeBinary(data)
-}
-
-// decodeBinary decodes binary transaction data
-func (t *TransactionInspector) decodeBinary(data []byte) (*solana.Transaction, error) {
-	tx, err := solana.TransactionFromDecoder(bin.NewBinDecoder(data))
-	if err != nil {
-		return nil, fmt.Errorf("failed to decode transaction: %v", err)
-	}
-	return tx, nil
+	return txinspect.DecodeBase64(encoded)
 }
 
 // fetchBySignature fetches a transaction by its signature
@@ -279,57 +255,10 @@ func (t *TransactionInspector) fetchBySignature() {
 	progress.Show()
 
 	go func() {
-		// Fetch transaction with binary encoding
-		txSig := solana.MustSignatureFromBase58(signature)
-		out, err := t.client.GetTransaction(
-			context.Background(),
-			txSig,
-			&rpc.GetTransactionOpts{
-				Encoding: solana.EncodingBase64,
-			},
-		)
-
-		// We need to update UI on the main thread
-		// This is done by using a goroutine to update UI elements
-		// Similar to how it's done in your other screens
-
-		// Using common go patterns for thread-safety
-		var tx *solana.Transaction
-		var decodeErr error
-
-		if err == nil && out != nil && out.Transaction != nil {
-			// Decode the transaction binary
-			txData := out.Transaction.GetBinary()
-			if txData != nil && len(txData) > 0 {
-				tx, decodeErr = solana.TransactionFromDecoder(bin.NewBinDecoder(txData))
-			}
-		}
-
-		// Update the UI from the main thread - we simply defer operations
-		// until we return to the main thread - just like in conditional_bot.go
-		t.window.Canvas().Refresh(t.container)
-
-		// Make sure to hide progress dialog regardless of result
+		tx, err := txinspect.Fetch(context.Background(), t.client, signature)
 		progress.Hide()
-
 		if err != nil {
-			t.statusLabel.SetText(fmt.Sprintf("Error fetching transaction: %v", err))
-			return
-		}
-
-		if out == nil || out.Transaction == nil {
-			t.statusLabel.SetText("Transaction not found or returned empty")
-			return
-		}
-
-		// Check if we had decoded the transaction
-		if decodeErr != nil {
-			t.statusLabel.SetText(fmt.Sprintf("Error decoding transaction: %v", decodeErr))
-			return
-		}
-
-		if tx == nil {
-			t.statusLabel.SetText("Transaction binary data not available")
+			t.statusLabel.SetText(fmt.Sprintf("Error: %v", err))
 			return
 		}
 
@@ -389,22 +318,12 @@ func (t *TransactionInspector) formatAccounts() string {
 
 	// Account list with metadata
 	buffer.WriteString("All Accounts:\n")
-	for i, acc := range t.currentTx.Message.AccountKeys {
-		// Check if account is a signer
-		isSigner := i < int(t.currentTx.Message.Header.NumRequiredSignatures)
-
-		// Check if account is writable
-		// For simplicity, we'll assume the first NumRequiredSignatures accounts are writable
-		// This is a simplification - in reality writable accounts are determined by
-		// NumRequiredSignatures and NumReadonlySigners
-		isWritable := i < int(t.currentTx.Message.Header.NumRequiredSignatures-
-			t.currentTx.Message.Header.NumReadonlySignedAccounts)
-
+	for i, acc := range txinspect.Summarize(t.currentTx).Accounts {
 		attributes := []string{}
-		if isSigner {
+		if acc.Signer {
 			attributes = append(attributes, "Signer")
 		}
-		if isWritable {
+		if acc.Writable {
 			attributes = append(attributes, "Writable")
 		}
 
@@ -413,7 +332,7 @@ func (t *TransactionInspector) formatAccounts() string {
 			attrStr = " (" + strings.Join(attributes, ", ") + ")"
 		}
 
-		buffer.WriteString(fmt.Sprintf("%d. %s%s\n", i+1, acc.String(), attrStr))
+		buffer.WriteString(fmt.Sprintf("%d. %s%s\n", i+1, acc.Address, attrStr))
 	}
 
 	return buffer.String()
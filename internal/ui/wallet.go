@@ -1,27 +1,49 @@
 package ui
 
 import (
-	"crypto/aes"
-	"crypto/cipher"
-	"crypto/rand"
-	"encoding/hex"
+	"errors"
 	"fmt"
+	"image/color"
 	"io"
 	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
 	"github.com/gagliardetto/solana-go"
 
+	"unruggable-go/internal/keypair"
 	"unruggable-go/internal/storage"
+	"unruggable-go/internal/wallet"
 )
 
+// walletColors are the colors offered when labelling a wallet.
+var walletColors = []struct {
+	Name string
+	Hex  string
+}{
+	{"None", ""},
+	{"Red", "#e53935"},
+	{"Orange", "#fb8c00"},
+	{"Yellow", "#fdd835"},
+	{"Green", "#43a047"},
+	{"Blue", "#1e88e5"},
+	{"Purple", "#8e24aa"},
+}
+
 type WalletManager struct {
 	window        fyne.Window
 	walletList    *container.Scroll
 	wallets       []string
+	metadata      map[string]storage.WalletMetadata
+	watchOnly     map[string]bool
+	showHidden    bool
 	currentWallet *widget.Label
 	walletTabs    *WalletTabs
 	app           fyne.App
@@ -32,6 +54,8 @@ func NewWalletManager(window fyne.Window, walletTabs *WalletTabs, app fyne.App)
 	manager := &WalletManager{
 		window:        window,
 		wallets:       []string{},
+		metadata:      make(map[string]storage.WalletMetadata),
+		watchOnly:     make(map[string]bool),
 		currentWallet: widget.NewLabel("No wallet selected"),
 		walletTabs:    walletTabs,
 		app:           app,
@@ -56,9 +80,9 @@ func NewWalletManager(window fyne.Window, walletTabs *WalletTabs, app fyne.App)
 				break
 			}
 		}
-	} else if len(manager.wallets) > 0 {
+	} else if visible := manager.visibleWallets(); len(visible) > 0 {
 		// If no wallet is selected but wallets exist, select the first one
-		manager.SetSelectedWallet(manager.wallets[0])
+		manager.SetSelectedWallet(visible[0])
 	}
 
 	return manager
@@ -71,25 +95,13 @@ func (m *WalletManager) NewWalletScreen() fyne.CanvasObject {
 		m.loadSavedWallets()
 	})
 
-	walletItems := container.NewVBox()
-
-	// Create a button for each wallet.
-	for _, wallet := range m.wallets {
-		walletButton := widget.NewButton(wallet[:8]+"...", func(wlt string) func() {
-			return func() {
-				m.SetSelectedWallet(wlt)
-			}
-		}(wallet))
-
-		// Highlight the currently selected wallet
-		if wallet == GetGlobalState().GetSelectedWallet() {
-			walletButton.Importance = widget.HighImportance
-		}
-
-		walletItems.Add(walletButton)
-	}
+	showHiddenCheck := widget.NewCheck("Show hidden wallets", func(show bool) {
+		m.showHidden = show
+		m.refreshWalletList()
+	})
+	showHiddenCheck.SetChecked(m.showHidden)
 
-	m.walletList = container.NewVScroll(walletItems)
+	m.walletList = container.NewVScroll(m.createWalletItemsList())
 	m.walletList.SetMinSize(fyne.NewSize(200, 200))
 
 	importEntry := widget.NewPasswordEntry()
@@ -100,16 +112,70 @@ func (m *WalletManager) NewWalletScreen() fyne.CanvasObject {
 		importEntry.SetText("") // Clear for security
 	})
 
+	importFileButton := widget.NewButton("Import Keypair File", func() {
+		m.importKeypairFile()
+	})
+
+	autoLockSelect := widget.NewSelect(sessionTimeoutNames(), func(name string) {
+		for _, t := range sessionTimeouts {
+			if t.Name == name {
+				setSessionTimeout(m.app, t.Timeout)
+				return
+			}
+		}
+	})
+	autoLockSelect.SetSelected(sessionTimeoutName(GetSession().Timeout()))
+
+	lockButton := widget.NewButtonWithIcon("Lock Now", theme.LogoutIcon(), func() {
+		GetSession().Lock()
+	})
+
+	reencryptButton := widget.NewButton("Change Master Password", func() {
+		m.reencryptAllWallets()
+	})
+
+	exportBackupButton := widget.NewButton("Export Backup", func() {
+		m.exportBackup()
+	})
+
+	restoreBackupButton := widget.NewButton("Restore Backup", func() {
+		m.restoreBackup()
+	})
+
+	watchEntry := widget.NewEntry()
+	watchEntry.SetPlaceHolder("Enter address to watch")
+
+	watchButton := widget.NewButton("Add Watch-Only", func() {
+		m.addWatchOnlyWallet(watchEntry.Text)
+		watchEntry.SetText("")
+	})
+
 	generateButton := widget.NewButton("Generate New Wallet", func() {
 		m.generateWallet()
 	})
 
+	vanityButton := widget.NewButton("Generate Vanity Address", func() {
+		m.generateVanityWallet()
+	})
+
+	createSeedButton := widget.NewButton("Create Seed Phrase Wallet", func() {
+		m.createSeedWallet()
+	})
+
+	importSeedButton := widget.NewButton("Import Seed Phrase", func() {
+		m.importSeedWallet()
+	})
+
 	controls := container.NewVBox(
 		widget.NewLabel("Wallet Management"),
 		m.currentWallet,
-		refreshButton,
-		container.NewHBox(importEntry, importButton),
-		generateButton,
+		container.NewHBox(refreshButton, showHiddenCheck),
+		container.NewHBox(importEntry, importButton, importFileButton),
+		container.NewHBox(watchEntry, watchButton),
+		container.NewGridWithColumns(2, generateButton, vanityButton),
+		container.NewGridWithColumns(2, createSeedButton, importSeedButton),
+		container.NewHBox(widget.NewLabel("Auto-lock after"), autoLockSelect, lockButton, reencryptButton),
+		container.NewGridWithColumns(2, exportBackupButton, restoreBackupButton),
 	)
 
 	return container.NewBorder(controls, nil, nil, nil, m.walletList)
@@ -122,27 +188,42 @@ func (m *WalletManager) loadSavedWallets() {
 		return
 	}
 
+	metadata, err := m.storage.LoadMetadata()
+	if err != nil {
+		dialog.ShowError(fmt.Errorf("failed to load wallet metadata: %v", err), m.window)
+		metadata = make(map[string]storage.WalletMetadata)
+	}
+	m.metadata = metadata
+
 	// Clear existing wallets list
 	m.wallets = []string{}
+	m.watchOnly = make(map[string]bool)
 
 	// Add wallets from the storage
-	for pubKey := range walletMap {
+	for pubKey, stored := range walletMap {
 		m.wallets = append(m.wallets, pubKey)
+		if stored.WatchOnly() {
+			m.watchOnly[pubKey] = true
+		}
 	}
 
-	// Sort wallets for consistent display
-	sort.Strings(m.wallets)
+	// Sort wallets by display name for consistent display
+	sort.Slice(m.wallets, func(i, j int) bool {
+		ni := strings.ToLower(m.walletName(m.wallets[i]))
+		nj := strings.ToLower(m.walletName(m.wallets[j]))
+		if ni != nj {
+			return ni < nj
+		}
+		return m.wallets[i] < m.wallets[j]
+	})
 
 	// Update UI
 	if m.walletTabs != nil {
-		m.walletTabs.Update(m.wallets)
+		m.walletTabs.Update(m.visibleWallets(), m.walletLabels())
 	}
 
 	// Update wallet list if it exists
-	if m.walletList != nil {
-		m.walletList.Content = m.createWalletItemsList()
-		m.walletList.Refresh()
-	}
+	m.refreshWalletList()
 
 	// Check if the currently selected wallet still exists
 	currentSelection := GetGlobalState().GetSelectedWallet()
@@ -157,8 +238,8 @@ func (m *WalletManager) loadSavedWallets() {
 
 	// If the selected wallet no longer exists, clear it or select a different one
 	if !walletExists {
-		if len(m.wallets) > 0 {
-			m.SetSelectedWallet(m.wallets[0])
+		if visible := m.visibleWallets(); len(visible) > 0 {
+			m.SetSelectedWallet(visible[0])
 		} else {
 			m.currentWallet.SetText("No wallet selected")
 			GetGlobalState().SetSelectedWallet("")
@@ -166,6 +247,41 @@ func (m *WalletManager) loadSavedWallets() {
 	}
 }
 
+// visibleWallets returns the wallets that are not marked hidden.
+func (m *WalletManager) visibleWallets() []string {
+	var visible []string
+	for _, wallet := range m.wallets {
+		if !m.metadata[wallet].Hidden {
+			visible = append(visible, wallet)
+		}
+	}
+	return visible
+}
+
+// walletLabels maps each wallet to the name shown in the UI.
+func (m *WalletManager) walletLabels() map[string]string {
+	labels := make(map[string]string, len(m.wallets))
+	for _, wallet := range m.wallets {
+		labels[wallet] = m.walletName(wallet)
+		if m.watchOnly[wallet] {
+			labels[wallet] += " (watch)"
+		}
+	}
+	return labels
+}
+
+// walletName returns the wallet's label, or its shortened address if unlabelled.
+func (m *WalletManager) walletName(walletID string) string {
+	return walletDisplayName(walletID, m.metadata[walletID])
+}
+
+func (m *WalletManager) refreshWalletList() {
+	if m.walletList != nil {
+		m.walletList.Content = m.createWalletItemsList()
+		m.walletList.Refresh()
+	}
+}
+
 // Helper method to create the wallet items list
 func (m *WalletManager) createWalletItemsList() fyne.CanvasObject {
 	walletItems := container.NewVBox()
@@ -173,7 +289,20 @@ func (m *WalletManager) createWalletItemsList() fyne.CanvasObject {
 	selectedWallet := GetGlobalState().GetSelectedWallet()
 
 	for _, wallet := range m.wallets {
-		walletButton := widget.NewButton(formatWalletName(wallet), func(wlt string) func() {
+		meta := m.metadata[wallet]
+		if meta.Hidden && !m.showHidden {
+			continue
+		}
+
+		name := m.walletName(wallet)
+		if m.watchOnly[wallet] {
+			name += " (watch-only)"
+		}
+		if meta.Hidden {
+			name += " (hidden)"
+		}
+
+		walletButton := widget.NewButton(name, func(wlt string) func() {
 			return func() {
 				m.SetSelectedWallet(wlt)
 			}
@@ -184,7 +313,26 @@ func (m *WalletManager) createWalletItemsList() fyne.CanvasObject {
 			walletButton.Importance = widget.HighImportance
 		}
 
-		walletItems.Add(walletButton)
+		swatch := canvas.NewRectangle(color.Transparent)
+		if c, ok := parseHexColor(meta.Color); ok {
+			swatch.FillColor = c
+		}
+		swatch.SetMinSize(fyne.NewSize(8, 8))
+
+		editButton := widget.NewButtonWithIcon("", theme.DocumentCreateIcon(), func(wlt string) func() {
+			return func() {
+				m.editWallet(wlt)
+			}
+		}(wallet))
+
+		deleteButton := widget.NewButtonWithIcon("", theme.DeleteIcon(), func(wlt string) func() {
+			return func() {
+				m.deleteWallet(wlt)
+			}
+		}(wallet))
+
+		walletItems.Add(container.NewBorder(nil, nil, swatch,
+			container.NewHBox(editButton, deleteButton), walletButton))
 	}
 
 	if len(m.wallets) == 0 {
@@ -194,31 +342,152 @@ func (m *WalletManager) createWalletItemsList() fyne.CanvasObject {
 	return walletItems
 }
 
-func (m *WalletManager) importWallet(privateKey string) {
-	if privateKey == "" {
+// editWallet lets the user change the label, color and hidden flag of a wallet.
+func (m *WalletManager) editWallet(walletID string) {
+	meta := m.metadata[walletID]
+
+	labelEntry := widget.NewEntry()
+	labelEntry.SetPlaceHolder(shortenAddress(walletID))
+	labelEntry.SetText(meta.Label)
+
+	colorSelect := widget.NewSelect(walletColorNames(), nil)
+	colorSelect.SetSelected(walletColorName(meta.Color))
+
+	hiddenCheck := widget.NewCheck("Hide from wallet tabs", nil)
+	hiddenCheck.SetChecked(meta.Hidden)
+
+	details := widget.NewLabel(fmt.Sprintf("Address: %s", walletID))
+	details.Wrapping = fyne.TextWrapBreak
+	if meta.Origin != "" || !meta.CreatedAt.IsZero() {
+		details.SetText(details.Text + fmt.Sprintf("\nOrigin: %s\nCreated: %s",
+			walletOriginText(meta.Origin), walletCreatedText(meta.CreatedAt)))
+	}
+
+	if meta.DerivationPath != "" {
+		details.SetText(details.Text + fmt.Sprintf("\nDerivation path: %s", meta.DerivationPath))
+	}
+
+	form := widget.NewForm(
+		widget.NewFormItem("Label", labelEntry),
+		widget.NewFormItem("Color", colorSelect),
+		widget.NewFormItem("", hiddenCheck),
+	)
+
+	content := container.NewVBox(details, form)
+	if !m.watchOnly[walletID] {
+		content.Add(widget.NewButton("Change Password", func() {
+			m.changeWalletPassword(walletID)
+		}))
+		content.Add(widget.NewButton("Export Keypair File", func() {
+			m.exportKeypairFile(walletID)
+		}))
+	}
+	if meta.SeedID != "" {
+		content.Add(widget.NewButton("Derive Next Account From Seed", func() {
+			m.deriveNextAccount(walletID)
+		}))
+	}
+
+	dialog.ShowCustomConfirm("Edit Wallet", "Save", "Cancel", content, func(save bool) {
+		if !save {
+			return
+		}
+
+		meta.Label = strings.TrimSpace(labelEntry.Text)
+		meta.Color = walletColorHex(colorSelect.Selected)
+		meta.Hidden = hiddenCheck.Checked
+
+		if err := m.storage.SaveMetadata(walletID, meta); err != nil {
+			dialog.ShowError(fmt.Errorf("failed to save wallet details: %v", err), m.window)
+			return
+		}
+
+		m.loadSavedWallets()
+		if walletID == GetGlobalState().GetSelectedWallet() {
+			m.SetSelectedWallet(walletID)
+		}
+	}, m.window)
+}
+
+// deleteWallet removes a wallet from storage after confirmation.
+func (m *WalletManager) deleteWallet(walletID string) {
+	message := fmt.Sprintf("Delete wallet %s?\n\nThe encrypted key will be removed from this device.\n"+
+		"Make sure you have a backup of the private key before continuing.", m.walletName(walletID))
+	if m.watchOnly[walletID] {
+		message = fmt.Sprintf("Stop watching %s?", m.walletName(walletID))
+	}
+
+	dialog.ShowConfirm("Delete Wallet", message, func(confirmed bool) {
+		if !confirmed {
+			return
+		}
+
+		seedID := m.metadata[walletID].SeedID
+		if err := m.storage.DeleteWallet(walletID); err != nil {
+			dialog.ShowError(fmt.Errorf("failed to delete wallet: %v", err), m.window)
+			return
+		}
+
+		// Drop the seed once no wallet derived from it remains.
+		if seedID != "" && !m.seedInUse(seedID, walletID) {
+			if err := m.storage.DeleteSeed(seedID); err != nil {
+				dialog.ShowError(fmt.Errorf("failed to delete seed: %v", err), m.window)
+			}
+		}
+
+		m.loadSavedWallets()
+	}, m.window)
+}
+
+// seedInUse reports whether any wallet other than except was derived from seedID.
+func (m *WalletManager) seedInUse(seedID, except string) bool {
+	for wallet, meta := range m.metadata {
+		if wallet != except && meta.SeedID == seedID {
+			return true
+		}
+	}
+	return false
+}
+
+// walletSaveForm builds the password and label fields shown when saving a new wallet.
+func walletSaveForm() (*widget.Form, *widget.Entry, *widget.Entry) {
+	labelEntry := widget.NewEntry()
+	labelEntry.SetPlaceHolder("Optional wallet label")
+
+	passwordEntry := widget.NewPasswordEntry()
+	passwordEntry.SetPlaceHolder("Enter password to encrypt wallet")
+
+	form := widget.NewForm(
+		widget.NewFormItem("Label", labelEntry),
+		widget.NewFormItem("Password", passwordEntry),
+	)
+	return form, labelEntry, passwordEntry
+}
+
+func (m *WalletManager) importWallet(input string) {
+	if strings.TrimSpace(input) == "" {
 		dialog.ShowError(fmt.Errorf("please enter a private key"), m.window)
 		return
 	}
 
-	wallet, err := solana.WalletFromPrivateKeyBase58(privateKey)
+	key, err := keypair.Parse(input)
 	if err != nil {
 		dialog.ShowError(fmt.Errorf("invalid private key: %v", err), m.window)
 		return
 	}
-	pubKey := wallet.PublicKey().String()
+	pubKey := key.PublicKey().String()
 
-	// Check if wallet already exists
+	// Check if wallet already exists. A watch-only entry is upgraded by the import.
 	walletMap, _ := m.storage.LoadWallets()
-	if _, exists := walletMap[pubKey]; exists {
+	if stored, exists := walletMap[pubKey]; exists && !stored.WatchOnly() {
 		dialog.ShowInformation("Wallet Exists", "This wallet is already imported. Selecting it now.", m.window)
 		m.SetSelectedWallet(pubKey)
 		return
 	}
 
-	passwordEntry := widget.NewPasswordEntry()
-	passwordEntry.SetPlaceHolder("Enter password to encrypt wallet")
+	form, labelEntry, passwordEntry := walletSaveForm()
 
-	passwordDialog := dialog.NewCustomConfirm("Encrypt Wallet", "Save", "Cancel", passwordEntry, func(encrypt bool) {
+	passwordDialog := dialog.NewCustomConfirm("Encrypt Wallet", "Save", "Cancel", form, func(encrypt bool) {
 		if !encrypt {
 			return
 		}
@@ -229,7 +498,12 @@ func (m *WalletManager) importWallet(privateKey string) {
 			return
 		}
 
-		err := m.saveEncryptedWallet(pubKey, privateKey, password)
+		meta := storage.WalletMetadata{
+			Label:     strings.TrimSpace(labelEntry.Text),
+			CreatedAt: time.Now(),
+			Origin:    storage.OriginImported,
+		}
+		err := m.saveEncryptedWallet(key, password, meta)
 		if err != nil {
 			dialog.ShowError(fmt.Errorf("failed to save wallet: %v", err), m.window)
 			return
@@ -247,15 +521,233 @@ func (m *WalletManager) importWallet(privateKey string) {
 	passwordDialog.Show()
 }
 
-func (m *WalletManager) generateWallet() {
-	wallet := solana.NewWallet()
-	pubKey := wallet.PublicKey().String()
-	privateKey := wallet.PrivateKey.String()
+// passwordChangeForm builds the current/new/confirm password fields.
+func passwordChangeForm() (*widget.Form, *widget.Entry, *widget.Entry, *widget.Entry) {
+	oldEntry := widget.NewPasswordEntry()
+	oldEntry.SetPlaceHolder("Current password")
+
+	newEntry := widget.NewPasswordEntry()
+	newEntry.SetPlaceHolder("New password")
+
+	confirmEntry := widget.NewPasswordEntry()
+	confirmEntry.SetPlaceHolder("Repeat new password")
 
+	form := widget.NewForm(
+		widget.NewFormItem("Current", oldEntry),
+		widget.NewFormItem("New", newEntry),
+		widget.NewFormItem("Confirm", confirmEntry),
+	)
+	return form, oldEntry, newEntry, confirmEntry
+}
+
+// changeWalletPassword re-encrypts one wallet under a new password, along
+// with its seed and the other wallets derived from it.
+func (m *WalletManager) changeWalletPassword(walletID string) {
+	form, oldEntry, newEntry, confirmEntry := passwordChangeForm()
+
+	dialog.ShowCustomConfirm("Change Password", "Change", "Cancel", form, func(ok bool) {
+		if !ok {
+			return
+		}
+		if newEntry.Text == "" {
+			dialog.ShowError(fmt.Errorf("password cannot be empty"), m.window)
+			return
+		}
+		if newEntry.Text != confirmEntry.Text {
+			dialog.ShowError(fmt.Errorf("new passwords do not match"), m.window)
+			return
+		}
+
+		changed, err := storage.ChangeWalletPassword(m.storage, walletID, oldEntry.Text, newEntry.Text)
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("failed to change password: %v", err), m.window)
+			return
+		}
+		message := fmt.Sprintf("Wallet %s is now encrypted with the new password.", m.walletName(walletID))
+		if len(changed) > 1 {
+			names := make([]string, 0, len(changed)-1)
+			for _, id := range changed {
+				if id != walletID {
+					names = append(names, m.walletName(id))
+				}
+			}
+			message += fmt.Sprintf("\n\nIt shares a seed phrase with %s, which now use the new password too.", strings.Join(names, ", "))
+		}
+		dialog.ShowInformation("Password Changed", message, m.window)
+	}, m.window)
+}
+
+// reencryptAllWallets re-encrypts every wallet, seed and key share that opens
+// with the current password under a new master password.
+func (m *WalletManager) reencryptAllWallets() {
+	form, oldEntry, newEntry, confirmEntry := passwordChangeForm()
+
+	dialog.ShowCustomConfirm("Change Master Password", "Re-encrypt", "Cancel", form, func(ok bool) {
+		if !ok {
+			return
+		}
+		if newEntry.Text == "" {
+			dialog.ShowError(fmt.Errorf("password cannot be empty"), m.window)
+			return
+		}
+		if newEntry.Text != confirmEntry.Text {
+			dialog.ShowError(fmt.Errorf("new passwords do not match"), m.window)
+			return
+		}
+
+		progress := dialog.NewCustomWithoutButtons("Re-encrypting", widget.NewProgressBarInfinite(), m.window)
+		progress.Show()
+
+		go func() {
+			report, err := storage.ReencryptAll(m.storage, oldEntry.Text, newEntry.Text)
+			progress.Hide()
+			if err != nil {
+				dialog.ShowError(fmt.Errorf("re-encryption failed: %v", err), m.window)
+				return
+			}
+
+			message := fmt.Sprintf("Re-encrypted %d wallet(s), %d seed(s) and %d key share(s).",
+				len(report.Wallets), len(report.Seeds), len(report.Shares))
+			if len(report.Skipped) > 0 {
+				var skipped []string
+				for id := range report.Skipped {
+					skipped = append(skipped, id)
+				}
+				sort.Strings(skipped)
+				message += fmt.Sprintf("\n\nSkipped %d item(s) that use a different password:\n%s",
+					len(skipped), strings.Join(skipped, "\n"))
+			}
+			dialog.ShowInformation("Master Password Changed", message, m.window)
+		}()
+	}, m.window)
+}
+
+// importKeypairFile imports a solana-keygen JSON keypair chosen from disk.
+func (m *WalletManager) importKeypairFile() {
+	fileDialog := dialog.NewFileOpen(func(reader fyne.URIReadCloser, err error) {
+		if err != nil {
+			dialog.ShowError(err, m.window)
+			return
+		}
+		if reader == nil {
+			return
+		}
+		defer reader.Close()
+
+		data, err := io.ReadAll(reader)
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("failed to read keypair file: %v", err), m.window)
+			return
+		}
+		if _, err := keypair.ParseJSON(data); err != nil {
+			dialog.ShowError(err, m.window)
+			return
+		}
+		m.importWallet(string(data))
+	}, m.window)
+	fileDialog.Show()
+}
+
+// exportKeypairFile writes a wallet in the solana-keygen JSON format after the
+// user re-enters the wallet password.
+func (m *WalletManager) exportKeypairFile(walletID string) {
 	passwordEntry := widget.NewPasswordEntry()
-	passwordEntry.SetPlaceHolder("Enter password to encrypt wallet")
+	passwordEntry.SetPlaceHolder("Enter wallet password")
+
+	warning := widget.NewLabel("The exported file contains the unencrypted private key.\n" +
+		"Anyone with access to it can spend from this wallet.")
+	warning.Wrapping = fyne.TextWrapWord
+
+	content := container.NewVBox(warning, passwordEntry)
+
+	dialog.ShowCustomConfirm("Export Keypair", "Export", "Cancel", content, func(confirmed bool) {
+		if !confirmed {
+			return
+		}
 
-	passwordDialog := dialog.NewCustomConfirm("Encrypt Wallet", "Save", "Cancel", passwordEntry, func(encrypt bool) {
+		key, err := unlockWallet(m.app, walletID, passwordEntry.Text)
+		if err != nil {
+			dialog.ShowError(err, m.window)
+			return
+		}
+
+		data, err := keypair.MarshalJSON(key)
+		if err != nil {
+			dialog.ShowError(err, m.window)
+			return
+		}
+
+		saveDialog := dialog.NewFileSave(func(writer fyne.URIWriteCloser, err error) {
+			if err != nil {
+				dialog.ShowError(err, m.window)
+				return
+			}
+			if writer == nil {
+				return
+			}
+			defer writer.Close()
+
+			if _, err := writer.Write(data); err != nil {
+				dialog.ShowError(fmt.Errorf("failed to write keypair file: %v", err), m.window)
+				return
+			}
+			dialog.ShowInformation("Keypair Exported", fmt.Sprintf("Saved keypair to %s", writer.URI().Name()), m.window)
+		}, m.window)
+		saveDialog.SetFileName(walletID + ".json")
+		saveDialog.Show()
+	}, m.window)
+}
+
+// addWatchOnlyWallet tracks an address without a private key. Watch-only
+// wallets show balances and history but cannot sign.
+func (m *WalletManager) addWatchOnlyWallet(address string) {
+	address = strings.TrimSpace(address)
+	pubKey, err := solana.PublicKeyFromBase58(address)
+	if err != nil {
+		dialog.ShowError(fmt.Errorf("invalid address: %v", err), m.window)
+		return
+	}
+
+	walletMap, _ := m.storage.LoadWallets()
+	if _, exists := walletMap[pubKey.String()]; exists {
+		dialog.ShowInformation("Wallet Exists", "This address is already in your wallets. Selecting it now.", m.window)
+		m.SetSelectedWallet(pubKey.String())
+		return
+	}
+
+	labelEntry := widget.NewEntry()
+	labelEntry.SetPlaceHolder("Optional wallet label")
+
+	dialog.ShowCustomConfirm("Add Watch-Only Wallet", "Add", "Cancel", labelEntry, func(add bool) {
+		if !add {
+			return
+		}
+
+		if err := m.storage.SaveWatchOnly(pubKey.String()); err != nil {
+			dialog.ShowError(fmt.Errorf("failed to save wallet: %v", err), m.window)
+			return
+		}
+		meta := storage.WalletMetadata{
+			Label:     strings.TrimSpace(labelEntry.Text),
+			CreatedAt: time.Now(),
+			Origin:    storage.OriginWatch,
+		}
+		if err := m.storage.SaveMetadata(pubKey.String(), meta); err != nil {
+			dialog.ShowError(fmt.Errorf("failed to save wallet details: %v", err), m.window)
+		}
+
+		m.loadSavedWallets()
+		m.SetSelectedWallet(pubKey.String())
+	}, m.window)
+}
+
+func (m *WalletManager) generateWallet() {
+	generated := solana.NewWallet()
+	pubKey := generated.PublicKey().String()
+
+	form, labelEntry, passwordEntry := walletSaveForm()
+
+	passwordDialog := dialog.NewCustomConfirm("Encrypt Wallet", "Save", "Cancel", form, func(encrypt bool) {
 		if !encrypt {
 			return
 		}
@@ -266,7 +758,12 @@ func (m *WalletManager) generateWallet() {
 			return
 		}
 
-		err := m.saveEncryptedWallet(pubKey, privateKey, password)
+		meta := storage.WalletMetadata{
+			Label:     strings.TrimSpace(labelEntry.Text),
+			CreatedAt: time.Now(),
+			Origin:    storage.OriginGenerated,
+		}
+		err := m.saveEncryptedWallet(generated.PrivateKey, password, meta)
 		if err != nil {
 			dialog.ShowError(fmt.Errorf("failed to save wallet: %v", err), m.window)
 			return
@@ -290,7 +787,11 @@ func (m *WalletManager) generateWallet() {
 
 func (m *WalletManager) SetSelectedWallet(walletID string) {
 	// Set the display text
-	m.currentWallet.SetText("Selected wallet: " + shortenAddress(walletID))
+	if m.watchOnly[walletID] {
+		m.currentWallet.SetText("Selected wallet: " + m.walletName(walletID) + " (watch-only)")
+	} else {
+		m.currentWallet.SetText("Selected wallet: " + m.walletName(walletID))
+	}
 
 	// Update global state
 	GetGlobalState().SetSelectedWallet(walletID)
@@ -301,52 +802,92 @@ func (m *WalletManager) SetSelectedWallet(walletID string) {
 	}
 
 	// Update the wallet list to highlight the selected wallet
-	if m.walletList != nil {
-		m.walletList.Content = m.createWalletItemsList()
-		m.walletList.Refresh()
-	}
+	m.refreshWalletList()
 }
 
 func (m *WalletManager) GetWallets() []string {
 	return m.wallets
 }
 
-func (m *WalletManager) saveEncryptedWallet(pubKey, privateKey, password string) error {
-	encryptedKey, err := encrypt([]byte(privateKey), password)
-	if err != nil {
-		return err
+func (m *WalletManager) saveEncryptedWallet(key solana.PrivateKey, password string, meta storage.WalletMetadata) error {
+	return wallet.Save(m.storage, key, password, meta)
+}
+
+// walletDisplayName returns the label of a wallet, falling back to its shortened address.
+func walletDisplayName(walletID string, meta storage.WalletMetadata) string {
+	if meta.Label != "" {
+		return meta.Label
 	}
+	return shortenAddress(walletID)
+}
 
-	// Save via our storage abstraction.
-	if err := m.storage.SaveWallet(pubKey, encryptedKey); err != nil {
-		return err
+func walletColorNames() []string {
+	names := make([]string, len(walletColors))
+	for i, c := range walletColors {
+		names[i] = c.Name
 	}
+	return names
+}
 
-	return nil
+func walletColorName(hex string) string {
+	for _, c := range walletColors {
+		if strings.EqualFold(c.Hex, hex) {
+			return c.Name
+		}
+	}
+	return walletColors[0].Name
 }
 
-// Helper function to format wallet name (shortened address)
-func formatWalletName(address string) string {
-	return shortenAddress(address)
+func walletColorHex(name string) string {
+	for _, c := range walletColors {
+		if c.Name == name {
+			return c.Hex
+		}
+	}
+	return ""
 }
 
-// encrypt encrypts data using AES-GCM.
-func encrypt(data []byte, passphrase string) (string, error) {
-	block, err := aes.NewCipher([]byte(padKey(passphrase)))
+// parseHexColor parses a "#rrggbb" string.
+func parseHexColor(hex string) (color.Color, bool) {
+	if len(hex) != 7 || hex[0] != '#' {
+		return nil, false
+	}
+	v, err := strconv.ParseUint(hex[1:], 16, 32)
 	if err != nil {
-		return "", err
+		return nil, false
+	}
+	return color.NRGBA{R: uint8(v >> 16), G: uint8(v >> 8), B: uint8(v), A: 0xff}, true
+}
+
+func walletOriginText(origin storage.WalletOrigin) string {
+	if origin == "" {
+		return "unknown"
 	}
+	return string(origin)
+}
 
-	gcm, err := cipher.NewGCM(block)
-	if err != nil {
-		return "", err
+func walletCreatedText(createdAt time.Time) string {
+	if createdAt.IsZero() {
+		return "unknown"
 	}
+	return createdAt.Format("Jan 2 2006 15:04")
+}
 
-	nonce := make([]byte, gcm.NonceSize())
-	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
-		return "", err
+// unlockWallet decrypts the stored key for walletID.
+func unlockWallet(app fyne.App, walletID, password string) (solana.PrivateKey, error) {
+	key, err := wallet.Unlock(storage.NewWalletStorage(app), walletID, password)
+	if errors.Is(err, wallet.ErrWatchOnly) {
+		return nil, watchOnlyError(walletID)
 	}
+	return key, err
+}
+
+// isWatchOnlyWallet reports whether walletID is stored without a private key.
+func isWatchOnlyWallet(app fyne.App, walletID string) bool {
+	return wallet.IsWatchOnly(storage.NewWalletStorage(app), walletID)
+}
 
-	ciphertext := gcm.Seal(nonce, nonce, data, nil)
-	return hex.EncodeToString(ciphertext), nil
+// watchOnlyError is shown when a screen is asked to sign with a watch-only wallet.
+func watchOnlyError(walletID string) error {
+	return fmt.Errorf("wallet %s is watch-only and cannot sign transactions", shortenAddress(walletID))
 }
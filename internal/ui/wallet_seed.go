@@ -0,0 +1,412 @@
+package ui
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"unruggable-go/internal/hdwallet"
+	"unruggable-go/internal/keystore"
+	"unruggable-go/internal/storage"
+)
+
+const autoDiscoverScheme = "Auto-discover (scan chain)"
+
+// createSeedWallet generates a new mnemonic, shows it for backup and saves
+// the first standard account.
+func (m *WalletManager) createSeedWallet() {
+	lengthSelect := widget.NewRadioGroup([]string{"12 words", "24 words"}, nil)
+	lengthSelect.Horizontal = true
+	lengthSelect.SetSelected("12 words")
+
+	dialog.ShowCustomConfirm("New Seed Phrase", "Generate", "Cancel", lengthSelect, func(ok bool) {
+		if !ok {
+			return
+		}
+
+		words := 12
+		if lengthSelect.Selected == "24 words" {
+			words = 24
+		}
+		mnemonic, err := hdwallet.NewMnemonic(words)
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("failed to generate seed phrase: %v", err), m.window)
+			return
+		}
+
+		m.showMnemonicBackup(mnemonic, func() {
+			secret := hdwallet.Secret{Mnemonic: mnemonic}
+			seed, err := secret.Seed()
+			if err != nil {
+				dialog.ShowError(err, m.window)
+				return
+			}
+			account, err := hdwallet.DeriveAccount(seed, hdwallet.SchemeStandard, 0)
+			if err != nil {
+				dialog.ShowError(err, m.window)
+				return
+			}
+			m.promptSaveSeedAccounts(secret, seed, []hdwallet.Account{account})
+		})
+	}, m.window)
+}
+
+// showMnemonicBackup displays the words and only continues once the user
+// confirms they have written them down.
+func (m *WalletManager) showMnemonicBackup(mnemonic string, onConfirmed func()) {
+	words := strings.Fields(mnemonic)
+	grid := container.NewGridWithColumns(3)
+	for i, word := range words {
+		grid.Add(widget.NewLabel(fmt.Sprintf("%2d. %s", i+1, word)))
+	}
+
+	warning := widget.NewLabel("Write these words down in order and keep them offline.\n" +
+		"Anyone with this phrase can spend from every account derived from it.")
+	warning.Wrapping = fyne.TextWrapWord
+
+	confirmCheck := widget.NewCheck("I have written down my seed phrase", nil)
+
+	content := container.NewVBox(warning, grid, confirmCheck)
+	dialog.ShowCustomConfirm("Back Up Seed Phrase", "Continue", "Cancel", content, func(ok bool) {
+		if !ok {
+			return
+		}
+		if !confirmCheck.Checked {
+			dialog.ShowError(fmt.Errorf("please confirm you have backed up the seed phrase"), m.window)
+			return
+		}
+		onConfirmed()
+	}, m.window)
+}
+
+// importSeedWallet imports a BIP39 mnemonic, optionally discovering used accounts on chain.
+func (m *WalletManager) importSeedWallet() {
+	phraseEntry := widget.NewMultiLineEntry()
+	phraseEntry.SetPlaceHolder("Enter 12 or 24 word seed phrase")
+	phraseEntry.Wrapping = fyne.TextWrapWord
+	phraseEntry.SetMinRowsVisible(3)
+
+	passphraseEntry := widget.NewPasswordEntry()
+	passphraseEntry.SetPlaceHolder("Optional BIP39 passphrase")
+
+	schemeOptions := []string{autoDiscoverScheme}
+	for _, scheme := range hdwallet.Schemes {
+		schemeOptions = append(schemeOptions, scheme.Name)
+	}
+	schemeSelect := widget.NewSelect(schemeOptions, nil)
+	schemeSelect.SetSelected(autoDiscoverScheme)
+
+	form := widget.NewForm(
+		widget.NewFormItem("Seed phrase", phraseEntry),
+		widget.NewFormItem("Passphrase", passphraseEntry),
+		widget.NewFormItem("Derivation", schemeSelect),
+	)
+
+	importDialog := dialog.NewCustomConfirm("Import Seed Phrase", "Next", "Cancel", form, func(ok bool) {
+		if !ok {
+			return
+		}
+
+		secret := hdwallet.Secret{
+			Mnemonic:   hdwallet.NormalizeMnemonic(phraseEntry.Text),
+			Passphrase: passphraseEntry.Text,
+		}
+		phraseEntry.SetText("") // Clear for security
+		passphraseEntry.SetText("")
+
+		seed, err := secret.Seed()
+		if err != nil {
+			dialog.ShowError(err, m.window)
+			return
+		}
+
+		if schemeSelect.Selected != autoDiscoverScheme {
+			for _, scheme := range hdwallet.Schemes {
+				if scheme.Name == schemeSelect.Selected {
+					account, err := hdwallet.DeriveAccount(seed, scheme, 0)
+					if err != nil {
+						dialog.ShowError(err, m.window)
+						return
+					}
+					m.promptSaveSeedAccounts(secret, seed, []hdwallet.Account{account})
+					return
+				}
+			}
+			return
+		}
+
+		m.discoverSeedAccounts(secret, seed)
+	}, m.window)
+	importDialog.Resize(fyne.NewSize(480, 320))
+	importDialog.Show()
+}
+
+// discoverSeedAccounts scans every derivation scheme for used accounts and lets
+// the user choose which to import.
+func (m *WalletManager) discoverSeedAccounts(secret hdwallet.Secret, seed []byte) {
+	progress := dialog.NewCustomWithoutButtons("Scanning", container.NewVBox(
+		widget.NewLabel("Scanning the chain for used accounts..."),
+		widget.NewProgressBarInfinite(),
+	), m.window)
+	progress.Show()
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+		defer cancel()
+
+		client := newRPCClient()
+		accounts, err := hdwallet.Discover(ctx, seed, hdwallet.Schemes, hdwallet.DefaultGap, hdwallet.RPCActivity(client))
+		progress.Hide()
+		if err != nil && len(accounts) == 0 {
+			dialog.ShowError(fmt.Errorf("account discovery failed: %v", err), m.window)
+			return
+		}
+
+		if len(accounts) == 0 {
+			account, err := hdwallet.DeriveAccount(seed, hdwallet.SchemeStandard, 0)
+			if err != nil {
+				dialog.ShowError(err, m.window)
+				return
+			}
+			dialog.ShowInformation("No Activity Found",
+				"No used accounts were found. The first standard account will be imported.", m.window)
+			m.promptSaveSeedAccounts(secret, seed, []hdwallet.Account{account})
+			return
+		}
+
+		m.chooseSeedAccounts(secret, seed, accounts)
+	}()
+}
+
+// chooseSeedAccounts shows the discovered accounts with a checkbox each.
+func (m *WalletManager) chooseSeedAccounts(secret hdwallet.Secret, seed []byte, accounts []hdwallet.Account) {
+	checks := make([]*widget.Check, len(accounts))
+	list := container.NewVBox()
+	for i, account := range accounts {
+		checks[i] = widget.NewCheck(fmt.Sprintf("%s  (%s)", shortenAddress(account.PublicKey().String()), account.Path), nil)
+		checks[i].SetChecked(true)
+		list.Add(checks[i])
+	}
+
+	scroll := container.NewVScroll(list)
+	scroll.SetMinSize(fyne.NewSize(400, 200))
+
+	dialog.ShowCustomConfirm(fmt.Sprintf("Found %d Account(s)", len(accounts)), "Import", "Cancel", scroll, func(ok bool) {
+		if !ok {
+			return
+		}
+
+		var selected []hdwallet.Account
+		for i, check := range checks {
+			if check.Checked {
+				selected = append(selected, accounts[i])
+			}
+		}
+		if len(selected) == 0 {
+			dialog.ShowError(fmt.Errorf("no accounts selected"), m.window)
+			return
+		}
+		m.promptSaveSeedAccounts(secret, seed, selected)
+	}, m.window)
+}
+
+// promptSaveSeedAccounts asks for a label and password, then stores the seed
+// and each account as its own wallet.
+func (m *WalletManager) promptSaveSeedAccounts(secret hdwallet.Secret, seed []byte, accounts []hdwallet.Account) {
+	form, labelEntry, passwordEntry := walletSaveForm()
+
+	dialog.ShowCustomConfirm("Encrypt Seed Wallet", "Save", "Cancel", form, func(ok bool) {
+		if !ok {
+			return
+		}
+
+		password := passwordEntry.Text
+		if password == "" {
+			dialog.ShowError(fmt.Errorf("password cannot be empty"), m.window)
+			return
+		}
+
+		saved, err := m.saveSeedAccounts(secret, seed, accounts, strings.TrimSpace(labelEntry.Text), password)
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("failed to save seed wallet: %v", err), m.window)
+			return
+		}
+
+		m.loadSavedWallets()
+		if len(saved) > 0 {
+			m.SetSelectedWallet(saved[0])
+		}
+
+		dialog.ShowInformation("Seed Wallet Saved",
+			fmt.Sprintf("%d account(s) derived and securely stored.", len(saved)), m.window)
+	}, m.window)
+}
+
+// saveSeedAccounts encrypts the seed and every account under password. A
+// seed already stored must open with password. Accounts that already exist
+// are skipped. It returns the saved public keys.
+func (m *WalletManager) saveSeedAccounts(secret hdwallet.Secret, seed []byte, accounts []hdwallet.Account, label, password string) ([]string, error) {
+	seedID := hdwallet.Fingerprint(seed)
+
+	seeds, err := m.storage.LoadSeeds()
+	if err != nil {
+		return nil, err
+	}
+	if stored, exists := seeds[seedID]; exists {
+		// A seed and its accounts share one password, so deriving more
+		// accounts later needs only that one.
+		if _, _, err := keystore.Open(stored, password); err != nil {
+			return nil, fmt.Errorf("this seed phrase is already stored under a different password: use that password to add accounts")
+		}
+	} else {
+		plaintext, err := json.Marshal(secret)
+		if err != nil {
+			return nil, err
+		}
+		sealed, err := keystore.Seal(plaintext, password)
+		if err != nil {
+			return nil, err
+		}
+		if err := m.storage.SaveSeed(seedID, sealed); err != nil {
+			return nil, err
+		}
+	}
+
+	walletMap, err := m.storage.LoadWallets()
+	if err != nil {
+		return nil, err
+	}
+
+	var saved []string
+	for _, account := range accounts {
+		pubKey := account.PublicKey().String()
+		if _, exists := walletMap[pubKey]; exists {
+			continue
+		}
+
+		accountLabel := label
+		if accountLabel != "" && len(accounts) > 1 {
+			accountLabel = fmt.Sprintf("%s #%d", label, account.Index+1)
+		}
+
+		meta := storage.WalletMetadata{
+			Label:          accountLabel,
+			CreatedAt:      time.Now(),
+			Origin:         storage.OriginDerived,
+			SeedID:         seedID,
+			DerivationPath: account.Path,
+		}
+		if err := m.saveEncryptedWallet(account.PrivateKey, password, meta); err != nil {
+			return saved, err
+		}
+		saved = append(saved, pubKey)
+	}
+	return saved, nil
+}
+
+// deriveNextAccount derives the next unused standard account from the seed
+// that walletID was derived from.
+func (m *WalletManager) deriveNextAccount(walletID string) {
+	seedID := m.metadata[walletID].SeedID
+	if seedID == "" {
+		dialog.ShowError(fmt.Errorf("wallet was not derived from a seed phrase"), m.window)
+		return
+	}
+
+	passwordEntry := widget.NewPasswordEntry()
+	passwordEntry.SetPlaceHolder("Enter seed password")
+
+	dialog.ShowCustomConfirm("Derive Next Account", "Derive", "Cancel", passwordEntry, func(ok bool) {
+		if !ok {
+			return
+		}
+
+		secret, seed, err := m.unlockSeed(seedID, passwordEntry.Text)
+		if err != nil {
+			dialog.ShowError(err, m.window)
+			return
+		}
+
+		used := make(map[string]bool)
+		for _, meta := range m.metadata {
+			if meta.SeedID == seedID {
+				used[meta.DerivationPath] = true
+			}
+		}
+
+		var next uint32
+		for used[hdwallet.SchemeStandard.Path(next)] {
+			next++
+		}
+
+		account, err := hdwallet.DeriveAccount(seed, hdwallet.SchemeStandard, next)
+		if err != nil {
+			dialog.ShowError(err, m.window)
+			return
+		}
+
+		label := ""
+		if base := m.metadata[walletID].Label; base != "" {
+			label = fmt.Sprintf("%s #%d", seedLabelBase(base), next+1)
+		}
+
+		saved, err := m.saveSeedAccounts(secret, seed, []hdwallet.Account{account}, label, passwordEntry.Text)
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("failed to save derived account: %v", err), m.window)
+			return
+		}
+
+		m.loadSavedWallets()
+		if len(saved) > 0 {
+			m.SetSelectedWallet(saved[0])
+		}
+	}, m.window)
+}
+
+// unlockSeed decrypts a stored seed.
+func (m *WalletManager) unlockSeed(seedID, password string) (hdwallet.Secret, []byte, error) {
+	var secret hdwallet.Secret
+
+	seeds, err := m.storage.LoadSeeds()
+	if err != nil {
+		return secret, nil, fmt.Errorf("error loading seeds: %v", err)
+	}
+	encrypted, ok := seeds[seedID]
+	if !ok {
+		return secret, nil, fmt.Errorf("seed %s not found", seedID)
+	}
+
+	plaintext, _, err := keystore.Open(encrypted, password)
+	if err != nil {
+		return secret, nil, fmt.Errorf("failed to decrypt seed: %v", err)
+	}
+	if err := json.Unmarshal(plaintext, &secret); err != nil {
+		return secret, nil, fmt.Errorf("invalid seed data: %v", err)
+	}
+
+	seed, err := secret.Seed()
+	if err != nil {
+		return secret, nil, err
+	}
+	return secret, seed, nil
+}
+
+// seedLabelBase strips a trailing " #n" account suffix from a label.
+func seedLabelBase(label string) string {
+	i := strings.LastIndex(label, " #")
+	if i < 0 {
+		return label
+	}
+	if _, err := strconv.Atoi(label[i+2:]); err != nil {
+		return label
+	}
+	return label[:i]
+}
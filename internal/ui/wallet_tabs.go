@@ -11,23 +11,25 @@ type WalletTabs struct {
 	tabs           map[string]*widget.Button
 	onSwitch       func(string)
 	selectedWallet string
-	mainContent    *fyne.Container
-	window         fyne.Window
-	app            fyne.App
 }
 
-// Update constructor to initialize these fields
+// NewWalletTabs creates the tab bar. Tabs follow the selected wallet wherever
+// it is changed from; onSwitch is called when the user clicks a tab.
 func NewWalletTabs(onSwitch func(string)) *WalletTabs {
 	wt := &WalletTabs{
 		container: container.NewHBox(),
 		tabs:      make(map[string]*widget.Button),
 		onSwitch:  onSwitch,
 	}
+	GetGlobalState().Events.WalletSelected.Subscribe(func(e WalletSelectedEvent) {
+		wt.highlight(e.WalletID)
+	})
 	return wt
 }
 
-// Update refreshes the wallet tabs with the current list of wallets
-func (wt *WalletTabs) Update(wallets []string) {
+// Update refreshes the wallet tabs with the current list of wallets.
+// labels maps wallets to their display names; unlabelled wallets show a shortened address.
+func (wt *WalletTabs) Update(wallets []string, labels map[string]string) {
 	if wt.container == nil {
 		wt.container = container.NewHBox()
 	}
@@ -46,19 +48,15 @@ func (wt *WalletTabs) Update(wallets []string) {
 	// Add tabs for each wallet
 	for _, wallet := range wallets {
 		wallet := wallet // Capture for closure
-		displayName := formatWalletDisplay(wallet)
+		displayName := labels[wallet]
+		if displayName == "" {
+			displayName = formatWalletDisplay(wallet)
+		}
 
 		tab := widget.NewButton(displayName, func() {
-			// Update global state immediately
-			GetGlobalState().SetSelectedWallet(wallet)
-
-			// Update visual state
+			// Open screens update themselves from the WalletSelected event
 			wt.SetSelectedWallet(wallet)
 
-			// Get current view and refresh screen
-			currentView := GetGlobalState().GetCurrentView()
-			wt.refreshCurrentScreen(currentView)
-
 			// Notify any listeners
 			if wt.onSwitch != nil {
 				wt.onSwitch(wallet)
@@ -91,70 +89,21 @@ func formatWalletDisplay(wallet string) string {
 	return wallet[:6] + "..." + wallet[len(wallet)-4:]
 }
 
-// Refresh the current screen with updated wallet information
-func (wt *WalletTabs) refreshCurrentScreen(view string) {
-	if wt.mainContent == nil {
-		return
-	}
-
-	// Save the current view to global state
-	GetGlobalState().SetCurrentView(view)
-
-	// Remove all content and rebuild
-	wt.mainContent.RemoveAll()
-
-	// Create new instance of current screen with updated wallet
-	var newContent fyne.CanvasObject
-	switch view {
-	case "home":
-		newContent = NewHomeScreen()
-	case "send":
-		newContent = NewSendScreen(wt.window, wt.app)
-	case "calypso":
-		newContent = NewCalypsoScreen(wt.window, wt.app)
-	case "conditionalbot":
-		newContent = NewConditionalBotScreen(wt.window, wt.app)
-	case "wallet":
-		// Keep the wallet screen available as a fallback option
-		return
-	default:
-		// Default to home screen if unknown view
-		newContent = NewHomeScreen()
-		GetGlobalState().SetCurrentView("home")
-	}
-
-	wt.mainContent.Add(newContent)
-	wt.mainContent.Refresh()
-}
-
-// SetMainContent connects the wallet tabs to the main content area
-func (wt *WalletTabs) SetMainContent(content *fyne.Container) {
-	wt.mainContent = content
-}
-
-// SetWindow sets the window reference
-func (wt *WalletTabs) SetWindow(window fyne.Window) {
-	wt.window = window
-}
-
-// SetApp sets the app reference
-func (wt *WalletTabs) SetApp(app fyne.App) {
-	wt.app = app
-}
-
 // SetSelectedWallet updates the selected wallet in both local and global state
 func (wt *WalletTabs) SetSelectedWallet(walletID string) {
 	if walletID == "" {
 		return
 	}
 
-	// Set the selected wallet in our local state
-	wt.selectedWallet = walletID
-
-	// Ensure global state is updated
+	// Ensure global state is updated; this publishes WalletSelected if the
+	// wallet changed
 	GetGlobalState().SetSelectedWallet(walletID)
+	wt.highlight(walletID)
+}
 
-	// Update visual state of tabs
+// highlight marks walletID's tab as the selected one.
+func (wt *WalletTabs) highlight(walletID string) {
+	wt.selectedWallet = walletID
 	for id, tab := range wt.tabs {
 		if id == walletID {
 			tab.Importance = widget.HighImportance
@@ -163,11 +112,6 @@ func (wt *WalletTabs) SetSelectedWallet(walletID string) {
 		}
 		tab.Refresh()
 	}
-
-	// Refresh current screen if it exists and we have a view set
-	if wt.mainContent != nil && GetGlobalState().GetCurrentView() != "" {
-		wt.refreshCurrentScreen(GetGlobalState().GetCurrentView())
-	}
 }
 
 // VerifyGlobalState checks if the local and global state are in sync
@@ -0,0 +1,186 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/gagliardetto/solana-go"
+
+	"unruggable-go/internal/storage"
+	"unruggable-go/internal/vanity"
+)
+
+// generateVanityWallet asks for a pattern and searches for a matching keypair.
+func (m *WalletManager) generateVanityWallet() {
+	prefixEntry := widget.NewEntry()
+	prefixEntry.SetPlaceHolder("Address starts with")
+
+	suffixEntry := widget.NewEntry()
+	suffixEntry.SetPlaceHolder("Address ends with")
+
+	ignoreCaseCheck := widget.NewCheck("Case-insensitive", nil)
+
+	estimate := widget.NewLabel("")
+	estimate.Wrapping = fyne.TextWrapWord
+	updateEstimate := func() {
+		pattern := vanity.Pattern{
+			Prefix:     strings.TrimSpace(prefixEntry.Text),
+			Suffix:     strings.TrimSpace(suffixEntry.Text),
+			IgnoreCase: ignoreCaseCheck.Checked,
+		}
+		if err := pattern.Validate(); err != nil {
+			estimate.SetText(err.Error())
+			return
+		}
+		estimate.SetText(fmt.Sprintf("About %s attempts on average.", formatAttempts(pattern.ExpectedAttempts())))
+	}
+	prefixEntry.OnChanged = func(string) { updateEstimate() }
+	suffixEntry.OnChanged = func(string) { updateEstimate() }
+	ignoreCaseCheck.OnChanged = func(bool) { updateEstimate() }
+
+	form := widget.NewForm(
+		widget.NewFormItem("Prefix", prefixEntry),
+		widget.NewFormItem("Suffix", suffixEntry),
+		widget.NewFormItem("", ignoreCaseCheck),
+	)
+
+	dialog.ShowCustomConfirm("Vanity Address", "Search", "Cancel", container.NewVBox(form, estimate), func(ok bool) {
+		if !ok {
+			return
+		}
+
+		pattern := vanity.Pattern{
+			Prefix:     strings.TrimSpace(prefixEntry.Text),
+			Suffix:     strings.TrimSpace(suffixEntry.Text),
+			IgnoreCase: ignoreCaseCheck.Checked,
+		}
+		if err := pattern.Validate(); err != nil {
+			dialog.ShowError(err, m.window)
+			return
+		}
+		m.runVanitySearch(pattern)
+	}, m.window)
+}
+
+// runVanitySearch searches on one goroutine per CPU, showing live progress
+// until a match is found or the user cancels.
+func (m *WalletManager) runVanitySearch(pattern vanity.Pattern) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var attempts atomic.Uint64
+	workers := vanity.DefaultWorkers()
+
+	progress := widget.NewLabel("Starting search...")
+	progressDialog := dialog.NewCustom("Searching for Vanity Address", "Cancel",
+		container.NewVBox(widget.NewProgressBarInfinite(), progress), m.window)
+	progressDialog.SetOnClosed(cancel)
+	progressDialog.Show()
+
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(500 * time.Millisecond)
+		defer ticker.Stop()
+		start := time.Now()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				elapsed := time.Since(start).Seconds()
+				tried := attempts.Load()
+				rate := float64(tried) / elapsed
+				progress.SetText(fmt.Sprintf("Attempts: %d on %d threads\nRate: %.0f/s\nEstimated time: %s",
+					tried, workers, rate, formatEstimate(pattern.EstimateDuration(rate))))
+			}
+		}
+	}()
+
+	go func() {
+		key, err := vanity.Search(ctx, pattern, workers, &attempts)
+		close(done)
+		if errors.Is(err, context.Canceled) {
+			return
+		}
+		progressDialog.SetOnClosed(nil)
+		progressDialog.Hide()
+		cancel()
+
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("vanity search failed: %v", err), m.window)
+			return
+		}
+		m.promptSaveVanityWallet(key, attempts.Load())
+	}()
+}
+
+// promptSaveVanityWallet encrypts and stores a found vanity keypair.
+func (m *WalletManager) promptSaveVanityWallet(key solana.PrivateKey, attempts uint64) {
+	pubKey := key.PublicKey().String()
+
+	found := widget.NewLabel(fmt.Sprintf("Found %s after %d attempts.", pubKey, attempts))
+	found.Wrapping = fyne.TextWrapBreak
+
+	form, labelEntry, passwordEntry := walletSaveForm()
+
+	dialog.ShowCustomConfirm("Save Vanity Wallet", "Save", "Discard", container.NewVBox(found, form), func(save bool) {
+		if !save {
+			return
+		}
+
+		password := passwordEntry.Text
+		if password == "" {
+			dialog.ShowError(fmt.Errorf("password cannot be empty"), m.window)
+			return
+		}
+
+		meta := storage.WalletMetadata{
+			Label:     strings.TrimSpace(labelEntry.Text),
+			CreatedAt: time.Now(),
+			Origin:    storage.OriginGenerated,
+		}
+		if err := m.saveEncryptedWallet(key, password, meta); err != nil {
+			dialog.ShowError(fmt.Errorf("failed to save wallet: %v", err), m.window)
+			return
+		}
+
+		m.loadSavedWallets()
+		m.SetSelectedWallet(pubKey)
+	}, m.window)
+}
+
+// formatAttempts renders large attempt counts compactly.
+func formatAttempts(n float64) string {
+	switch {
+	case n >= 1e12:
+		return fmt.Sprintf("%.1f trillion", n/1e12)
+	case n >= 1e9:
+		return fmt.Sprintf("%.1f billion", n/1e9)
+	case n >= 1e6:
+		return fmt.Sprintf("%.1f million", n/1e6)
+	default:
+		return fmt.Sprintf("%.0f", n)
+	}
+}
+
+// formatEstimate renders an expected search duration.
+func formatEstimate(d time.Duration) string {
+	switch {
+	case d <= 0:
+		return "calculating..."
+	case d < time.Minute:
+		return fmt.Sprintf("%.0f seconds", d.Seconds())
+	case d < time.Hour:
+		return fmt.Sprintf("%.0f minutes", d.Minutes())
+	case d < 48*time.Hour:
+		return fmt.Sprintf("%.1f hours", d.Hours())
+	default:
+		return fmt.Sprintf("%.0f days", d.Hours()/24)
+	}
+}
@@ -0,0 +1,233 @@
+// Package vanity searches for Solana keypairs whose address starts or ends
+// with a chosen pattern.
+package vanity
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"fmt"
+	"math"
+	"math/big"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unicode"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+// Alphabet is the base58 alphabet used by Solana addresses.
+const Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+// MaxAddressLength is the length of the longest base58 encoding of a 32-byte
+// public key.
+const MaxAddressLength = 44
+
+// Pattern describes the address to search for.
+type Pattern struct {
+	Prefix     string
+	Suffix     string
+	IgnoreCase bool
+}
+
+// Validate checks that the pattern is non-empty, uses only base58 characters
+// and can be matched by some address. Ignoring case, a character is valid if
+// either of its cases is, so O, I and l match o, i and L.
+func (p Pattern) Validate() error {
+	if p.Prefix == "" && p.Suffix == "" {
+		return fmt.Errorf("enter a prefix or suffix")
+	}
+	if len(p.Prefix)+len(p.Suffix) > MaxAddressLength {
+		return fmt.Errorf("prefix and suffix together cannot be longer than %d characters, the longest address", MaxAddressLength)
+	}
+	for _, part := range []string{p.Prefix, p.Suffix} {
+		for _, c := range part {
+			if strings.ContainsRune(Alphabet, c) {
+				continue
+			}
+			if !p.IgnoreCase {
+				return fmt.Errorf("%q is not a base58 character (0, O, I and l are not allowed)", c)
+			}
+			if !strings.ContainsRune(Alphabet, unicode.ToLower(c)) && !strings.ContainsRune(Alphabet, unicode.ToUpper(c)) {
+				return fmt.Errorf("%q is not a base58 character in any case (0 is not allowed)", c)
+			}
+		}
+	}
+	if !p.prefixFits() {
+		return fmt.Errorf("no address starts with %q and still has room for the suffix", p.Prefix)
+	}
+	return nil
+}
+
+// prefixFits reports whether some 32-byte key encodes to an address that
+// starts with the prefix and is long enough to also end with the suffix.
+// Each leading 1 stands for a zero byte; the rest of the prefix must be the
+// leading digits of the remaining bytes read as one number.
+func (p Pattern) prefixFits() bool {
+	rest := strings.TrimLeft(p.Prefix, "1")
+	zeros := len(p.Prefix) - len(rest)
+	if rest == "" {
+		return zeros <= 32
+	}
+	if zeros >= 32 {
+		return false
+	}
+
+	// Ignoring case, the lowest spelling of the prefix is the easiest to fit.
+	value := new(big.Int)
+	for _, c := range rest {
+		digit := strings.IndexRune(Alphabet, c)
+		if p.IgnoreCase {
+			for i, a := range Alphabet {
+				if strings.EqualFold(string(a), string(c)) {
+					digit = i
+					break
+				}
+			}
+		}
+		value.Mul(value, big.NewInt(58))
+		value.Add(value, big.NewInt(int64(digit)))
+	}
+	// The bytes after the zeros start with a non-zero byte, so they are a
+	// number in [256^(31-zeros), 256^(32-zeros)).
+	least := new(big.Int).Lsh(big.NewInt(1), uint(8*(31-zeros)))
+	limit := new(big.Int).Lsh(big.NewInt(1), uint(8*(32-zeros)))
+
+	scale := big.NewInt(1)
+	for length := len(rest); zeros+length <= MaxAddressLength; length++ {
+		lo := new(big.Int).Mul(value, scale)
+		hi := new(big.Int).Add(lo, scale)
+		if lo.Cmp(limit) >= 0 {
+			return false
+		}
+		if hi.Cmp(least) > 0 && zeros+length >= len(p.Prefix)+len(p.Suffix) {
+			return true
+		}
+		scale.Mul(scale, big.NewInt(58))
+	}
+	return false
+}
+
+// Match reports whether address satisfies the pattern.
+func (p Pattern) Match(address string) bool {
+	if p.IgnoreCase {
+		address = strings.ToLower(address)
+		return strings.HasPrefix(address, strings.ToLower(p.Prefix)) &&
+			strings.HasSuffix(address, strings.ToLower(p.Suffix))
+	}
+	return strings.HasPrefix(address, p.Prefix) && strings.HasSuffix(address, p.Suffix)
+}
+
+// ExpectedAttempts estimates how many keypairs must be tried on average to
+// find a match, treating every address character as uniformly distributed.
+func (p Pattern) ExpectedAttempts() float64 {
+	attempts := 1.0
+	for _, c := range p.Prefix + p.Suffix {
+		matches := 1
+		if p.IgnoreCase {
+			matches = 0
+			for _, a := range Alphabet {
+				if strings.EqualFold(string(a), string(c)) {
+					matches++
+				}
+			}
+		}
+		attempts *= float64(len(Alphabet)) / float64(matches)
+	}
+	return attempts
+}
+
+// EstimateDuration returns the expected time to find a match at rate
+// attempts per second.
+func (p Pattern) EstimateDuration(rate float64) time.Duration {
+	if rate <= 0 {
+		return 0
+	}
+	seconds := p.ExpectedAttempts() / rate
+	if seconds > math.MaxInt64/float64(time.Second) {
+		return time.Duration(math.MaxInt64)
+	}
+	return time.Duration(seconds * float64(time.Second))
+}
+
+// DefaultWorkers is the number of search goroutines, one per CPU.
+func DefaultWorkers() int {
+	return runtime.NumCPU()
+}
+
+// Search generates keypairs on workers goroutines until one matches the
+// pattern or ctx is cancelled. attempts, if non-nil, is incremented as keys
+// are tried so callers can report progress.
+func Search(ctx context.Context, p Pattern, workers int, attempts *atomic.Uint64) (solana.PrivateKey, error) {
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	if workers <= 0 {
+		workers = DefaultWorkers()
+	}
+	if attempts == nil {
+		attempts = new(atomic.Uint64)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	found := make(chan solana.PrivateKey, 1)
+	errs := make(chan error, workers)
+	var wg sync.WaitGroup
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ctx.Err() == nil {
+				pub, priv, err := ed25519.GenerateKey(rand.Reader)
+				if err != nil {
+					errs <- err
+					return
+				}
+				attempts.Add(1)
+
+				if p.Match(solana.PublicKeyFromBytes(pub).String()) {
+					select {
+					case found <- solana.PrivateKey(priv):
+						cancel()
+					default:
+					}
+					return
+				}
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(errs)
+	}()
+
+	select {
+	case key := <-found:
+		return key, nil
+	case err, ok := <-errs:
+		if ok {
+			return nil, err
+		}
+		// All workers stopped: either a match raced the cancellation or ctx ended.
+		select {
+		case key := <-found:
+			return key, nil
+		default:
+			return nil, ctx.Err()
+		}
+	case <-ctx.Done():
+		select {
+		case key := <-found:
+			return key, nil
+		default:
+			return nil, ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package vanity
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+// maxAddress is the encoding of the largest 32-byte key, 2^256-1.
+const maxAddress = "JEKNVnkbo3jma5nREBBJCDoXFVeKkD56V3xKrvRmWxFG"
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern Pattern
+		wantErr bool
+	}{
+		{"empty", Pattern{}, true},
+		{"prefix", Pattern{Prefix: "abc"}, false},
+		{"suffix", Pattern{Suffix: "xyz"}, false},
+		{"zero", Pattern{Prefix: "a0"}, true},
+		{"capital O", Pattern{Prefix: "O"}, true},
+		{"capital O ignoring case", Pattern{Prefix: "O", IgnoreCase: true}, false},
+		{"lowercase l ignoring case", Pattern{Suffix: "l", IgnoreCase: true}, false},
+		{"zero ignoring case", Pattern{Prefix: "0", IgnoreCase: true}, true},
+		{"any first character", Pattern{Prefix: "z"}, false},
+		{"longest address", Pattern{Prefix: maxAddress}, false},
+		{"longest address split", Pattern{Prefix: maxAddress[:20], Suffix: maxAddress[20:]}, false},
+		{"above the largest key", Pattern{Prefix: "JEKNVnkbo3jma5nREBBJCDoXFVeKkD56V3xKrvRmWxFH"}, true},
+		{"too long together", Pattern{Prefix: strings.Repeat("2", 30), Suffix: strings.Repeat("3", 15)}, true},
+		{"no room for the suffix", Pattern{Prefix: "K", Suffix: strings.Repeat("2", 43)}, true},
+		{"room for the suffix", Pattern{Prefix: "K", Suffix: strings.Repeat("2", 42)}, false},
+		{"lowest spelling ignoring case", Pattern{Prefix: "j", Suffix: strings.Repeat("2", 43), IgnoreCase: true}, false},
+		{"all-zero key", Pattern{Prefix: strings.Repeat("1", 32)}, false},
+		{"more zeros than bytes", Pattern{Prefix: strings.Repeat("1", 33)}, true},
+		{"digits after 32 zeros", Pattern{Prefix: strings.Repeat("1", 32) + "2"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.pattern.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate(%+v) = %v, wantErr %v", tt.pattern, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateAcceptsRealAddresses(t *testing.T) {
+	for i := 0; i < 200; i++ {
+		pub, _, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		address := solana.PublicKeyFromBytes(pub).String()
+		split := i % len(address)
+		for _, p := range []Pattern{
+			{Prefix: address},
+			{Prefix: address[:split], Suffix: address[split:]},
+			{Prefix: strings.ToLower(address[:split]), Suffix: address[split:], IgnoreCase: true},
+		} {
+			if p.Prefix == "" && p.Suffix == "" {
+				continue
+			}
+			if err := p.Validate(); err != nil {
+				t.Fatalf("Validate(%+v) = %v for address %s", p, err, address)
+			}
+		}
+	}
+}
+
+func TestMatch(t *testing.T) {
+	const address = "AbcDEFghijkmnopqrstuvwxyz123456789ABCDEFGH"
+	tests := []struct {
+		pattern Pattern
+		want    bool
+	}{
+		{Pattern{Prefix: "Abc"}, true},
+		{Pattern{Prefix: "abc"}, false},
+		{Pattern{Prefix: "abc", IgnoreCase: true}, true},
+		{Pattern{Suffix: "FGH"}, true},
+		{Pattern{Suffix: "fgh"}, false},
+		{Pattern{Suffix: "fgh", IgnoreCase: true}, true},
+		{Pattern{Prefix: "Abc", Suffix: "FGH"}, true},
+		{Pattern{Prefix: "Abc", Suffix: "XYZ"}, false},
+		{Pattern{Prefix: "ABCDEF", Suffix: "fgh", IgnoreCase: true}, true},
+	}
+	for _, tt := range tests {
+		if got := tt.pattern.Match(address); got != tt.want {
+			t.Errorf("%+v.Match(%s) = %v, want %v", tt.pattern, address, got, tt.want)
+		}
+	}
+}
+
+func TestExpectedAttempts(t *testing.T) {
+	tests := []struct {
+		pattern Pattern
+		want    float64
+	}{
+		{Pattern{Prefix: "a"}, 58},
+		{Pattern{Prefix: "ab", Suffix: "c"}, 58 * 58 * 58},
+		// a and A are both base58, so ignoring case halves the attempts.
+		{Pattern{Prefix: "a", IgnoreCase: true}, 29},
+		// Only L of l and L is base58, and digits have no other case.
+		{Pattern{Prefix: "l", IgnoreCase: true}, 58},
+		{Pattern{Prefix: "1", IgnoreCase: true}, 58},
+		{Pattern{Prefix: "ab", Suffix: "1", IgnoreCase: true}, 29 * 29 * 58},
+	}
+	for _, tt := range tests {
+		if got := tt.pattern.ExpectedAttempts(); math.Abs(got-tt.want) > 1e-9*tt.want {
+			t.Errorf("%+v.ExpectedAttempts() = %v, want %v", tt.pattern, got, tt.want)
+		}
+	}
+}
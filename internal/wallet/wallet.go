@@ -0,0 +1,128 @@
+// Package wallet creates, lists and unlocks the wallets kept in a
+// storage.WalletStorage. It is shared by the GUI and the CLI.
+package wallet
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+
+	"unruggable-go/internal/keystore"
+	"unruggable-go/internal/storage"
+)
+
+// ErrWatchOnly is returned when a watch-only wallet is asked to sign.
+var ErrWatchOnly = errors.New("wallet is watch-only and cannot sign transactions")
+
+// Info describes a stored wallet without its key.
+type Info struct {
+	Address        string               `json:"address"`
+	Label          string               `json:"label,omitempty"`
+	WatchOnly      bool                 `json:"watchOnly"`
+	Hidden         bool                 `json:"hidden,omitempty"`
+	Origin         storage.WalletOrigin `json:"origin,omitempty"`
+	CreatedAt      time.Time            `json:"createdAt"`
+	DerivationPath string               `json:"derivationPath,omitempty"`
+}
+
+// List returns every stored wallet, oldest first.
+func List(s storage.WalletStorage) ([]Info, error) {
+	wallets, err := s.LoadWallets()
+	if err != nil {
+		return nil, fmt.Errorf("error loading wallets: %v", err)
+	}
+	metadata, err := s.LoadMetadata()
+	if err != nil {
+		return nil, fmt.Errorf("error loading wallet metadata: %v", err)
+	}
+
+	infos := make([]Info, 0, len(wallets))
+	for address, stored := range wallets {
+		meta := metadata[address]
+		infos = append(infos, Info{
+			Address:        address,
+			Label:          meta.Label,
+			WatchOnly:      stored.WatchOnly(),
+			Hidden:         meta.Hidden,
+			Origin:         meta.Origin,
+			CreatedAt:      meta.CreatedAt,
+			DerivationPath: meta.DerivationPath,
+		})
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		if !infos[i].CreatedAt.Equal(infos[j].CreatedAt) {
+			return infos[i].CreatedAt.Before(infos[j].CreatedAt)
+		}
+		return infos[i].Address < infos[j].Address
+	})
+	return infos, nil
+}
+
+// Save encrypts key with password and stores it together with meta.
+func Save(s storage.WalletStorage, key solana.PrivateKey, password string, meta storage.WalletMetadata) error {
+	if password == "" {
+		return errors.New("password cannot be empty")
+	}
+	sealed, err := keystore.Seal([]byte(key.String()), password)
+	if err != nil {
+		return err
+	}
+	pubKey := key.PublicKey().String()
+	if err := s.SaveWallet(pubKey, sealed); err != nil {
+		return err
+	}
+	return s.SaveMetadata(pubKey, meta)
+}
+
+// Unlock decrypts the stored key for walletID. Wallets still in the legacy
+// format are re-sealed in the current envelope format on success.
+func Unlock(s storage.WalletStorage, walletID, password string) (solana.PrivateKey, error) {
+	walletMap, err := s.LoadWallets()
+	if err != nil {
+		return nil, fmt.Errorf("error loading wallets: %v", err)
+	}
+
+	stored, ok := walletMap[walletID]
+	if !ok {
+		return nil, fmt.Errorf("wallet %s not found", walletID)
+	}
+	if stored.WatchOnly() {
+		return nil, ErrWatchOnly
+	}
+
+	decryptedKey, legacy, err := keystore.Open(stored.EncryptedKey, password)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt wallet: %v", err)
+	}
+
+	privateKey, err := solana.PrivateKeyFromBase58(string(decryptedKey))
+	if err != nil {
+		return nil, fmt.Errorf("invalid private key in wallet: %v", err)
+	}
+	if privateKey.PublicKey().String() != walletID {
+		return nil, fmt.Errorf("decrypted key does not match wallet %s", walletID)
+	}
+
+	if legacy {
+		if sealed, err := keystore.Seal(decryptedKey, password); err != nil {
+			log.Printf("Failed to migrate wallet %s: %v", walletID, err)
+		} else if err := s.SaveWallet(walletID, sealed); err != nil {
+			log.Printf("Failed to save migrated wallet %s: %v", walletID, err)
+		}
+	}
+
+	return privateKey, nil
+}
+
+// IsWatchOnly reports whether walletID is stored without a private key.
+func IsWatchOnly(s storage.WalletStorage, walletID string) bool {
+	walletMap, err := s.LoadWallets()
+	if err != nil {
+		return false
+	}
+	return walletMap[walletID].WatchOnly()
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	"unruggable-go/internal/storage"
 	"unruggable-go/internal/ui"
 
 	"fyne.io/fyne/v2"
@@ -11,7 +12,7 @@ import (
 )
 
 func main() {
-	myApp := app.NewWithID("com.unruggable.app")
+	myApp := app.NewWithID(storage.AppID)
 	myWindow := myApp.NewWindow("Unruggable")
 
 	// Create wallet tabs with state synchronization
@@ -22,10 +23,17 @@ func main() {
 	// Create the main content container
 	mainContent := container.NewStack()
 
-	// Set references for wallet tabs
-	walletTabs.SetMainContent(mainContent)
-	walletTabs.SetWindow(myWindow)
-	walletTabs.SetApp(myApp)
+	// Apply the auto-lock timeout before any wallet is unlocked
+	ui.InitSession(myApp)
+
+	// Restore the network profile chosen in a previous run
+	ui.InitNetwork(myApp)
+
+	// Restore the selected wallet and last view, dropping deleted wallets
+	ui.InitAppState(myApp)
+
+	// Record portfolio snapshots for the history screen
+	ui.InitHistory(myApp)
 
 	// Initialize wallet manager
 	walletManager := ui.NewWalletManager(myWindow, walletTabs, myApp)
@@ -55,11 +63,10 @@ func main() {
 
 	// Create the split layout
 	split := container.NewHSplit(sidebar, content)
-	split.SetOffset(0.2) // 20% width for sidebar
 
-	// Set the window content
+	// Set the window content, sized as it was left last time
 	myWindow.SetContent(split)
-	myWindow.Resize(fyne.NewSize(1000, 700))
+	ui.RestoreLayout(myApp, myWindow, split)
 
 	// Function to update main content
 	updateMainContent := func(newContent fyne.CanvasObject) {
@@ -67,12 +74,40 @@ func main() {
 		mainContent.Add(newContent)
 	}
 
+	// Screens are built once and then update themselves from the event bus,
+	// so form input and running bots survive navigation and wallet switches
+	screens := make(map[string]fyne.CanvasObject)
+	cachedScreen := func(view string, build func() fyne.CanvasObject) fyne.CanvasObject {
+		if screen, ok := screens[view]; ok {
+			return screen
+		}
+		screens[view] = build()
+		return screens[view]
+	}
+
+	// Report transactions sent by any screen or bot
+	ui.GetGlobalState().Events.TxStatus.Subscribe(func(e ui.TxStatusEvent) {
+		if e.Err != nil {
+			statusBar.SetText(fmt.Sprintf("%s: transaction %s failed: %v", e.Source, e.ID, e.Err))
+			return
+		}
+		statusBar.SetText(fmt.Sprintf("%s: transaction %s %s", e.Source, e.ID, e.Status))
+	})
+
 	// Setup sidebar navigation
 	sidebar.OnHomeClicked = func() {
-		updateMainContent(ui.NewHomeScreen())
+		updateMainContent(cachedScreen("home", ui.NewHomeScreen))
 		ui.GetGlobalState().SetCurrentView("home")
 	}
 
+	sidebar.OnPortfolioClicked = func() {
+		updateMainContent(cachedScreen("portfolio", func() fyne.CanvasObject {
+			return ui.NewPortfolioScreen(myWindow, myApp)
+		}))
+		ui.GetGlobalState().SetCurrentView("portfolio")
+		statusBar.SetText("")
+	}
+
 	sidebar.OnSendClicked = func() {
 		// Check if a wallet is selected
 		if walletID := ui.GetGlobalState().GetSelectedWallet(); walletID == "" {
@@ -82,11 +117,37 @@ func main() {
 			return
 		}
 
-		updateMainContent(ui.NewSendScreen(myWindow, myApp))
+		updateMainContent(cachedScreen("send", func() fyne.CanvasObject {
+			return ui.NewSendScreen(myWindow, myApp)
+		}))
 		ui.GetGlobalState().SetCurrentView("send")
 		statusBar.SetText("")
 	}
 
+	sidebar.OnCollectiblesClicked = func() {
+		updateMainContent(cachedScreen("collectibles", func() fyne.CanvasObject {
+			return ui.NewCollectiblesScreen(myWindow, myApp)
+		}))
+		ui.GetGlobalState().SetCurrentView("collectibles")
+		statusBar.SetText("")
+	}
+
+	sidebar.OnStakingClicked = func() {
+		updateMainContent(cachedScreen("staking", func() fyne.CanvasObject {
+			return ui.NewStakingScreen(myWindow, myApp)
+		}))
+		ui.GetGlobalState().SetCurrentView("staking")
+		statusBar.SetText("")
+	}
+
+	sidebar.OnHistoryClicked = func() {
+		updateMainContent(cachedScreen("history", func() fyne.CanvasObject {
+			return ui.NewHistoryScreen(myWindow)
+		}))
+		ui.GetGlobalState().SetCurrentView("history")
+		statusBar.SetText("")
+	}
+
 	sidebar.OnWalletClicked = func() {
 		updateMainContent(walletManager.NewWalletScreen())
 		ui.GetGlobalState().SetCurrentView("wallet")
@@ -102,7 +163,9 @@ func main() {
 			return
 		}
 
-		updateMainContent(ui.NewCalypsoScreen(myWindow, myApp))
+		updateMainContent(cachedScreen("calypso", func() fyne.CanvasObject {
+			return ui.NewCalypsoScreen(myWindow, myApp)
+		}))
 		ui.GetGlobalState().SetCurrentView("calypso")
 		statusBar.SetText("")
 	}
@@ -116,38 +179,86 @@ func main() {
 			return
 		}
 
-		updateMainContent(ui.NewConditionalBotScreen(myWindow, myApp))
+		updateMainContent(cachedScreen("conditionalbot", func() fyne.CanvasObject {
+			return ui.NewConditionalBotScreen(myWindow, myApp)
+		}))
 		ui.GetGlobalState().SetCurrentView("conditionalbot")
 		statusBar.SetText("")
 	}
 
 	sidebar.OnHardwareSignClicked = func() {
-		updateMainContent(ui.NewSignScreen())
+		updateMainContent(cachedScreen("hardware", ui.NewSignScreen))
 		ui.GetGlobalState().SetCurrentView("hardware")
 		statusBar.SetText("")
 	}
 
 	// Add transaction inspector function
 	sidebar.OnTxInspectorClicked = func() {
-		updateMainContent(ui.NewTransactionInspectorScreen(myWindow, myApp))
+		updateMainContent(cachedScreen("txinspector", func() fyne.CanvasObject {
+			return ui.NewTransactionInspectorScreen(myWindow, myApp)
+		}))
 		ui.GetGlobalState().SetCurrentView("txinspector")
 		statusBar.SetText("")
 	}
 
 	sidebar.OnMultisigCreateClicked = func() {
-		updateMainContent(ui.NewMultisigCreateScreen(myWindow))
+		updateMainContent(cachedScreen("multisigcreate", func() fyne.CanvasObject {
+			return ui.NewMultisigCreateScreen(myWindow)
+		}))
 		ui.GetGlobalState().SetCurrentView("multisigcreate")
 		statusBar.SetText("")
 	}
 
 	sidebar.OnMultisigInfoClicked = func() {
-		updateMainContent(ui.NewMultisigInfoScreen(myWindow))
+		updateMainContent(cachedScreen("multisiginfo", func() fyne.CanvasObject {
+			return ui.NewMultisigInfoScreen(myWindow)
+		}))
 		ui.GetGlobalState().SetCurrentView("multisiginfo")
 		statusBar.SetText("")
 	}
 
-	// Start with the wallet screen as the default view
-	sidebar.OnWalletClicked()
+	sidebar.OnNetworkClicked = func() {
+		updateMainContent(cachedScreen("network", func() fyne.CanvasObject {
+			return ui.NewNetworkScreen(myWindow, myApp)
+		}))
+		ui.GetGlobalState().SetCurrentView("network")
+		statusBar.SetText("")
+	}
+
+	sidebar.OnLocalAPIClicked = func() {
+		updateMainContent(cachedScreen("localapi", func() fyne.CanvasObject {
+			return ui.NewLocalAPIScreen(myWindow, myApp)
+		}))
+		ui.GetGlobalState().SetCurrentView("localapi")
+		statusBar.SetText("")
+	}
+
+	// Serve scripts if the local API was left enabled
+	ui.InitLocalAPI(myApp, myWindow)
+
+	// Reopen the view used last, falling back to the wallet screen
+	views := map[string]func(){
+		"home":           sidebar.OnHomeClicked,
+		"portfolio":      sidebar.OnPortfolioClicked,
+		"send":           sidebar.OnSendClicked,
+		"collectibles":   sidebar.OnCollectiblesClicked,
+		"staking":        sidebar.OnStakingClicked,
+		"history":        sidebar.OnHistoryClicked,
+		"wallet":         sidebar.OnWalletClicked,
+		"calypso":        sidebar.OnCalypsoClicked,
+		"conditionalbot": sidebar.OnConditionalBotClicked,
+		"hardware":       sidebar.OnHardwareSignClicked,
+		"txinspector":    sidebar.OnTxInspectorClicked,
+		"multisigcreate": sidebar.OnMultisigCreateClicked,
+		"multisiginfo":   sidebar.OnMultisigInfoClicked,
+		"network":        sidebar.OnNetworkClicked,
+		"localapi":       sidebar.OnLocalAPIClicked,
+	}
+	if open, ok := views[ui.GetGlobalState().GetCurrentView()]; ok {
+		open()
+	} else {
+		sidebar.OnWalletClicked()
+	}
 
 	// Show the window and start the application event loop
 	myWindow.ShowAndRun()
@@ -2,14 +2,10 @@ package ui
 
 import (
 	"bytes"
-	"crypto/aes"
-	"crypto/cipher"
-	"crypto/rand"
 	"encoding/base64"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
-	"io"
 	"io/ioutil"
 	"net/http"
 	"os"
@@ -24,6 +20,8 @@ import (
 	"github.com/taurusgroup/frost-ed25519/pkg/frost"
 	"github.com/taurusgroup/frost-ed25519/pkg/frost/party"
 	"github.com/taurusgroup/frost-ed25519/pkg/helpers"
+
+	"unruggable-go/internal/keystore"
 )
 
 type LogFunc func(message string)
@@ -293,7 +291,7 @@ func saveShares(groupKeyBase58 string, secretShare, publicShare []byte, partyID
 }
 
 func saveEncryptedShares(groupKeyBase58 string, secretShare, publicShare []byte, partyID int, password string, app fyne.App) error {
-	encryptedSecretShare, err := encrypt(secretShare, password)
+	encryptedSecretShare, err := encrypt_share(secretShare, password)
 	if err != nil {
 		return err
 	}
@@ -362,23 +360,7 @@ func GetSavedShares(app fyne.App) ([]SharesData, error) {
 	return shares, nil
 }
 
+// encrypt_share seals a secret share in a versioned keystore envelope.
 func encrypt_share(data []byte, passphrase string) (string, error) {
-	block, _ := aes.NewCipher([]byte(padKeyShare(passphrase)))
-	gcm, err := cipher.NewGCM(block)
-	if err != nil {
-		return "", err
-	}
-	nonce := make([]byte, gcm.NonceSize())
-	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
-		return "", err
-	}
-	ciphertext := gcm.Seal(nonce, nonce, data, nil)
-	return hex.EncodeToString(ciphertext), nil
-}
-
-func padKeyShare(key string) string {
-	for len(key) < 32 {
-		key += key
-	}
-	return key[:32]
+	return keystore.Seal(data, passphrase)
 }